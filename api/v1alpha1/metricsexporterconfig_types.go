@@ -0,0 +1,72 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MetricsExporterConfigName is the name of the MetricsExporterConfig read by the exporter, in the operator namespace
+const MetricsExporterConfigName = "osd-metrics-exporter"
+
+// MetricsExporterConfigSpec defines the configuration of the exporter
+type MetricsExporterConfigSpec struct {
+	// Controllers are the names of the controllers to run, e.g. ControlPlaneMachineSet. All controllers run when empty.
+	// +optional
+	Controllers []string `json:"controllers,omitempty"`
+
+	// ResyncPeriod is how often the controllers reconcile all objects they watch again, even if they did not change.
+	// +optional
+	ResyncPeriod *metav1.Duration `json:"resyncPeriod,omitempty"`
+
+	// ExtraLabels are added to every series of the exporter, e.g. environment: stage
+	// +optional
+	ExtraLabels map[string]string `json:"extraLabels,omitempty"`
+}
+
+// MetricsExporterConfigStatus defines the observed state of MetricsExporterConfig
+type MetricsExporterConfigStatus struct {
+	// ObservedGeneration is the generation of the spec the running exporter was configured with
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Observed Generation",type=integer,JSONPath=`.status.observedGeneration`
+
+// MetricsExporterConfig configures the exporter at runtime. The exporter restarts to apply a changed spec.
+type MetricsExporterConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MetricsExporterConfigSpec   `json:"spec,omitempty"`
+	Status MetricsExporterConfigStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// MetricsExporterConfigList contains a list of MetricsExporterConfig
+type MetricsExporterConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MetricsExporterConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MetricsExporterConfig{}, &MetricsExporterConfigList{})
+}
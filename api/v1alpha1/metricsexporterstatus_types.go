@@ -0,0 +1,82 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// MetricsExporterStatusName is the name of the MetricsExporterStatus maintained by the exporter
+	MetricsExporterStatusName = "osd-metrics-exporter"
+	// ReconciledCondition is the condition of a controller whose last reconciles did not return an error
+	ReconciledCondition = "Reconciled"
+)
+
+// ControllerStatus is the health of a controller of the exporter
+type ControllerStatus struct {
+	// Name is the name of the controller, the controller label of the osd_exporter_reconcile metrics
+	Name string `json:"name"`
+
+	// LastReconcileTime is when the controller was last seen reconciling
+	// +optional
+	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+
+	// LastSuccessfulReconcileTime is when the controller was last seen reconciling without an error
+	// +optional
+	LastSuccessfulReconcileTime *metav1.Time `json:"lastSuccessfulReconcileTime,omitempty"`
+
+	// Errors is the number of reconciles which returned an error since the exporter started
+	Errors int64 `json:"errors"`
+
+	// Conditions are the conditions of the controller, e.g. Reconciled
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ExporterStatus defines the observed state of the exporter
+type ExporterStatus struct {
+	// Controllers are the controllers running in the exporter, sorted by name
+	// +optional
+	Controllers []ControllerStatus `json:"controllers,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+
+// MetricsExporterStatus reports the health of the controllers of the exporter, so it can be judged without
+// scraping it. The leader replica maintains it.
+type MetricsExporterStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status ExporterStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// MetricsExporterStatusList contains a list of MetricsExporterStatus
+type MetricsExporterStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MetricsExporterStatus `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MetricsExporterStatus{}, &MetricsExporterStatusList{})
+}
@@ -0,0 +1,534 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	storagev1 "k8s.io/api/storage/v1"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/catalogsource"
+	"github.com/openshift/osd-metrics-exporter/controllers/cloudcredential"
+	"github.com/openshift/osd-metrics-exporter/controllers/clusteroperator"
+	"github.com/openshift/osd-metrics-exporter/controllers/clusterresourcequota"
+	"github.com/openshift/osd-metrics-exporter/controllers/clusterrole"
+	"github.com/openshift/osd-metrics-exporter/controllers/clusterversion"
+	"github.com/openshift/osd-metrics-exporter/controllers/configmap"
+	"github.com/openshift/osd-metrics-exporter/controllers/cpms"
+	detectioncontroller "github.com/openshift/osd-metrics-exporter/controllers/detection"
+	"github.com/openshift/osd-metrics-exporter/controllers/dns"
+	"github.com/openshift/osd-metrics-exporter/controllers/egress"
+	"github.com/openshift/osd-metrics-exporter/controllers/group"
+	"github.com/openshift/osd-metrics-exporter/controllers/hypershift"
+	"github.com/openshift/osd-metrics-exporter/controllers/image"
+	"github.com/openshift/osd-metrics-exporter/controllers/infrastructure"
+	"github.com/openshift/osd-metrics-exporter/controllers/limited_support"
+	"github.com/openshift/osd-metrics-exporter/controllers/machineset"
+	mustgathercontroller "github.com/openshift/osd-metrics-exporter/controllers/mustgather"
+	"github.com/openshift/osd-metrics-exporter/controllers/network"
+	"github.com/openshift/osd-metrics-exporter/controllers/networkpolicy"
+	"github.com/openshift/osd-metrics-exporter/controllers/node"
+	"github.com/openshift/osd-metrics-exporter/controllers/oauth"
+	"github.com/openshift/osd-metrics-exporter/controllers/oauthtoken"
+	objectcountcontroller "github.com/openshift/osd-metrics-exporter/controllers/objectcount"
+	"github.com/openshift/osd-metrics-exporter/controllers/olm"
+	"github.com/openshift/osd-metrics-exporter/controllers/persistentvolume"
+	"github.com/openshift/osd-metrics-exporter/controllers/priorityclass"
+	"github.com/openshift/osd-metrics-exporter/controllers/privacy"
+	"github.com/openshift/osd-metrics-exporter/controllers/proxy"
+	"github.com/openshift/osd-metrics-exporter/controllers/pullsecret"
+	"github.com/openshift/osd-metrics-exporter/controllers/scc"
+	"github.com/openshift/osd-metrics-exporter/controllers/service"
+	"github.com/openshift/osd-metrics-exporter/controllers/storageclass"
+	"github.com/openshift/osd-metrics-exporter/controllers/upgradeconfig"
+	"github.com/openshift/osd-metrics-exporter/controllers/webhook"
+	"github.com/openshift/osd-metrics-exporter/pkg/apiversion"
+	"github.com/openshift/osd-metrics-exporter/pkg/detection"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/openshift/osd-metrics-exporter/pkg/objectcount"
+	"github.com/openshift/osd-metrics-exporter/pkg/secretdata"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	quotav1 "github.com/openshift/api/quota/v1"
+	securityv1 "github.com/openshift/api/security/v1"
+	userv1 "github.com/openshift/api/user/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// exporterController is a controller of the exporter. The same table is set up with the manager and run against
+// a must-gather, so a controller added here is available in both.
+type exporterController struct {
+	name string
+	// offline controllers are run against a must-gather, the ClusterRole controller only removes finalizers
+	offline bool
+	// gated controllers are registered with the gate and only set up once their CRD is Established and the
+	// exporter is allowed to watch their resources, the others are set up with the manager
+	gated     bool
+	crdName   string
+	resources []authorizationv1.ResourceAttributes
+	kinds     []*apiversion.Kind
+	// reconcilers creates the reconcilers of the controller, the Detection and ObjectCount controllers have one
+	// for each detection or counter
+	reconcilers func(d controllerDependencies) []controllerReconciler
+}
+
+// controllerDependencies are passed to the reconcilers of the controllers
+type controllerDependencies struct {
+	client         client.Client
+	apiReader      client.Reader
+	aggregator     *metrics.AdoptionMetricsAggregator
+	clusterId      string
+	recorder       record.EventRecorder
+	detections     []detection.Detection
+	objectCounters []objectcount.Counter
+}
+
+// managedReconciler is a reconciler which sets itself up with the manager
+type managedReconciler interface {
+	reconcile.Reconciler
+	SetupWithManager(mgr ctrl.Manager) error
+}
+
+// controllerReconciler is a reconciler with the object it is reconciled for when run against a must-gather
+type controllerReconciler struct {
+	forObject  client.Object
+	reconciler managedReconciler
+	// logValues are logged with the errors of the reconciler
+	logValues []interface{}
+}
+
+// singleReconciler returns the reconciler of a controller with a single reconciler
+func singleReconciler(forObject client.Object, reconciler managedReconciler) []controllerReconciler {
+	return []controllerReconciler{{forObject: forObject, reconciler: reconciler}}
+}
+
+// exporterControllers are all controllers which can be enabled with --enable-controllers, in the order they are set up
+var exporterControllers = []exporterController{
+	{
+		name: "ClusterRole",
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&rbacv1.ClusterRole{}, &clusterrole.ClusterRoleReconciler{Client: d.client, Scheme: scheme})
+		},
+	},
+	{
+		name:    "ConfigMap",
+		offline: true,
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&corev1.ConfigMap{}, &configmap.ConfigMapReconciler{Client: d.client, Scheme: scheme, Metrics: configmap.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "PullSecret",
+		offline: true,
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&corev1.Secret{}, &pullsecret.PullSecretReconciler{Client: d.client, Scheme: scheme, Metrics: pullsecret.NewMetrics(d.aggregator), ClusterId: d.clusterId, SecretReader: secretdata.NewReader(d.apiReader)})
+		},
+	},
+	{
+		name:    "ClusterVersion",
+		offline: true,
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&configv1.ClusterVersion{}, &clusterversion.ClusterVersionReconciler{Client: d.client, Scheme: scheme, Metrics: clusterversion.NewMetrics(d.aggregator), MetricsAggregator: d.aggregator, ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "Group",
+		offline: true,
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&userv1.Group{}, &group.GroupReconciler{Client: d.client, Scheme: scheme, Metrics: group.NewMetrics(d.aggregator), ClusterId: d.clusterId, Recorder: d.recorder})
+		},
+	},
+	{
+		name:    "LimitedSupport",
+		offline: true,
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&corev1.ConfigMap{}, &limited_support.LimitedSupportConfigMapReconciler{Client: d.client, Scheme: scheme, Metrics: limited_support.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "OAuth",
+		offline: true,
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&configv1.OAuth{}, &oauth.OAuthReconciler{Client: d.client, Scheme: scheme, MetricsAggregator: d.aggregator})
+		},
+	},
+	{
+		name:    "Proxy",
+		offline: true,
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&configv1.Proxy{}, &proxy.ProxyReconciler{Client: d.client, Scheme: scheme, Metrics: proxy.NewMetrics(d.aggregator), MetricsAggregator: d.aggregator, ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "Detection",
+		offline: true,
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			var reconcilers []controllerReconciler
+			for _, detection := range d.detections {
+				reconcilers = append(reconcilers, controllerReconciler{
+					forObject:  newUnstructured(apiversion.NewKind(detection.GroupVersionKind())),
+					reconciler: &detectioncontroller.DetectionReconciler{Client: d.client, Scheme: scheme, MetricsAggregator: d.aggregator, ClusterId: d.clusterId, Detection: detection},
+					logValues:  []interface{}{"detection", detection.Name},
+				})
+			}
+			return reconcilers
+		},
+	},
+	{
+		name:    "ObjectCount",
+		offline: true,
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			var reconcilers []controllerReconciler
+			for _, counter := range d.objectCounters {
+				reconcilers = append(reconcilers, controllerReconciler{
+					forObject:  newUnstructured(apiversion.NewKind(counter.GroupVersionKind())),
+					reconciler: &objectcountcontroller.ObjectCountReconciler{Client: d.client, Scheme: scheme, MetricsAggregator: d.aggregator, ClusterId: d.clusterId, Counter: counter},
+					logValues:  []interface{}{"counter", counter.ControllerName()},
+				})
+			}
+			return reconcilers
+		},
+	},
+	{
+		name:    "AdmissionWebhook",
+		offline: true,
+		gated:   true,
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "admissionregistration.k8s.io", Resource: "validatingwebhookconfigurations"},
+			{Group: "admissionregistration.k8s.io", Resource: "mutatingwebhookconfigurations"},
+		},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&admissionregistrationv1.ValidatingWebhookConfiguration{}, &webhook.AdmissionWebhookReconciler{Client: d.client, Scheme: scheme, Metrics: webhook.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "SecurityContextConstraints",
+		offline: true,
+		gated:   true,
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "security.openshift.io", Resource: "securitycontextconstraints"},
+		},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&securityv1.SecurityContextConstraints{}, &scc.SecurityContextConstraintsReconciler{Client: d.client, Scheme: scheme, Metrics: scc.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "PersistentVolume",
+		offline: true,
+		gated:   true,
+		resources: []authorizationv1.ResourceAttributes{
+			{Resource: "persistentvolumes"},
+		},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&corev1.PersistentVolume{}, &persistentvolume.PersistentVolumeReconciler{Client: d.client, Scheme: scheme, Metrics: persistentvolume.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "StorageClass",
+		offline: true,
+		gated:   true,
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "storage.k8s.io", Resource: "storageclasses"},
+		},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&storagev1.StorageClass{}, &storageclass.StorageClassReconciler{Client: d.client, Scheme: scheme, Metrics: storageclass.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "PriorityClass",
+		offline: true,
+		gated:   true,
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "scheduling.k8s.io", Resource: "priorityclasses"},
+		},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&schedulingv1.PriorityClass{}, &priorityclass.PriorityClassReconciler{Client: d.client, Scheme: scheme, Metrics: priorityclass.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "ClusterResourceQuota",
+		offline: true,
+		gated:   true,
+		crdName: "clusterresourcequotas.quota.openshift.io",
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "quota.openshift.io", Resource: "clusterresourcequotas"},
+		},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&quotav1.ClusterResourceQuota{}, &clusterresourcequota.ClusterResourceQuotaReconciler{Client: d.client, Scheme: scheme, Metrics: clusterresourcequota.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "Network",
+		offline: true,
+		gated:   true,
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "config.openshift.io", Resource: "networks"},
+		},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&configv1.Network{}, &network.NetworkReconciler{Client: d.client, Scheme: scheme, Metrics: network.NewMetrics(d.aggregator), MetricsAggregator: d.aggregator, ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "ClusterOperator",
+		offline: true,
+		gated:   true,
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "config.openshift.io", Resource: "clusteroperators"},
+		},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&configv1.ClusterOperator{}, &clusteroperator.ClusterOperatorReconciler{Client: d.client, Scheme: scheme, MetricsAggregator: d.aggregator, ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "Egress",
+		offline: true,
+		gated:   true,
+		crdName: "egressips.k8s.ovn.org",
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "k8s.ovn.org", Resource: "egressips"},
+			{Group: "k8s.ovn.org", Resource: "egressfirewalls"},
+		},
+		kinds: []*apiversion.Kind{egress.EgressIPKind, egress.EgressFirewallKind},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(newUnstructured(egress.EgressIPKind), &egress.EgressReconciler{Client: d.client, Scheme: scheme, Metrics: egress.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "NetworkPolicy",
+		offline: true,
+		gated:   true,
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "networking.k8s.io", Resource: "networkpolicies"},
+		},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&networkingv1.NetworkPolicy{}, &networkpolicy.NetworkPolicyReconciler{Client: d.client, Scheme: scheme, Metrics: networkpolicy.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "Service",
+		offline: true,
+		gated:   true,
+		resources: []authorizationv1.ResourceAttributes{
+			{Resource: "services"},
+		},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&corev1.Service{}, &service.ServiceReconciler{Client: d.client, Scheme: scheme, Metrics: service.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "MustGather",
+		offline: true,
+		gated:   true,
+		resources: []authorizationv1.ResourceAttributes{
+			{Resource: "pods"},
+		},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&corev1.Pod{}, &mustgathercontroller.MustGatherReconciler{Client: d.client, Scheme: scheme, Metrics: mustgathercontroller.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "UpgradeConfig",
+		offline: true,
+		gated:   true,
+		crdName: "upgradeconfigs.upgrade.managed.openshift.io",
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "upgrade.managed.openshift.io", Resource: "upgradeconfigs"},
+		},
+		kinds: []*apiversion.Kind{upgradeconfig.UpgradeConfigKind},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(newUnstructured(upgradeconfig.UpgradeConfigKind), &upgradeconfig.UpgradeConfigReconciler{Client: d.client, Scheme: scheme, Metrics: upgradeconfig.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "DNS",
+		offline: true,
+		gated:   true,
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "operator.openshift.io", Resource: "dnses"},
+		},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&operatorv1.DNS{}, &dns.DNSReconciler{Client: d.client, Scheme: scheme, Metrics: dns.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "Infrastructure",
+		offline: true,
+		gated:   true,
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "config.openshift.io", Resource: "infrastructures"},
+		},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&configv1.Infrastructure{}, &infrastructure.InfrastructureReconciler{Client: d.client, Scheme: scheme, Metrics: infrastructure.NewMetrics(d.aggregator), MetricsAggregator: d.aggregator, ClusterId: d.clusterId, APIReader: d.apiReader})
+		},
+	},
+	{
+		name:    "CloudCredential",
+		offline: true,
+		gated:   true,
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "operator.openshift.io", Resource: "cloudcredentials"},
+			{Group: "config.openshift.io", Resource: "authentications"},
+			{Group: "config.openshift.io", Resource: "infrastructures"},
+		},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&operatorv1.CloudCredential{}, &cloudcredential.CloudCredentialReconciler{Client: d.client, Scheme: scheme, Metrics: cloudcredential.NewMetrics(d.aggregator), MetricsAggregator: d.aggregator, ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "Privacy",
+		offline: true,
+		gated:   true,
+		crdName: "publishingstrategies.cloudingress.managed.openshift.io",
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "operator.openshift.io", Resource: "ingresscontrollers"},
+			{Group: "cloudingress.managed.openshift.io", Resource: "publishingstrategies"},
+		},
+		kinds: []*apiversion.Kind{privacy.PublishingStrategyKind},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&operatorv1.IngressController{}, &privacy.PrivacyReconciler{Client: d.client, Scheme: scheme, Metrics: privacy.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "OAuthAccessToken",
+		offline: true,
+		gated:   true,
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "oauth.openshift.io", Resource: "oauthaccesstokens"},
+		},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&oauthv1.OAuthAccessToken{}, &oauthtoken.OAuthAccessTokenReconciler{Client: d.client, Scheme: scheme, Metrics: oauthtoken.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "Image",
+		offline: true,
+		gated:   true,
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "config.openshift.io", Resource: "images"},
+		},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&configv1.Image{}, &image.ImageReconciler{Client: d.client, Scheme: scheme, Metrics: image.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "OLM",
+		offline: true,
+		gated:   true,
+		crdName: "subscriptions.operators.coreos.com",
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "operators.coreos.com", Resource: "subscriptions"},
+			{Group: "operators.coreos.com", Resource: "clusterserviceversions"},
+		},
+		kinds: []*apiversion.Kind{olm.SubscriptionKind, olm.ClusterServiceVersionKind},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(newUnstructured(olm.SubscriptionKind), &olm.OLMReconciler{Client: d.client, Scheme: scheme, Metrics: olm.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "CatalogSource",
+		offline: true,
+		gated:   true,
+		crdName: "catalogsources.operators.coreos.com",
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "operators.coreos.com", Resource: "catalogsources"},
+		},
+		kinds: []*apiversion.Kind{catalogsource.CatalogSourceKind},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(newUnstructured(catalogsource.CatalogSourceKind), &catalogsource.CatalogSourceReconciler{Client: d.client, Scheme: scheme, Metrics: catalogsource.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "Node",
+		offline: true,
+		gated:   true,
+		crdName: "machines.machine.openshift.io",
+		resources: []authorizationv1.ResourceAttributes{
+			{Resource: "nodes"},
+			{Group: "machine.openshift.io", Resource: "machines"},
+		},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&corev1.Node{}, &node.NodeReconciler{Client: d.client, Scheme: scheme, Metrics: node.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "MachineSet",
+		offline: true,
+		gated:   true,
+		crdName: "machinesets.machine.openshift.io",
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "machine.openshift.io", Resource: "machinesets"},
+		},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(&machinev1beta1.MachineSet{}, &machineset.MachineSetReconciler{Client: d.client, Scheme: scheme, Metrics: machineset.NewMetrics(d.aggregator), ClusterId: d.clusterId})
+		},
+	},
+	{
+		name:    "ControlPlaneMachineSet",
+		offline: true,
+		gated:   true,
+		crdName: "controlplanemachinesets.machine.openshift.io",
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "machine.openshift.io", Resource: "controlplanemachinesets"},
+			{Group: "machine.openshift.io", Resource: "machines"},
+		},
+		kinds: []*apiversion.Kind{cpms.ControlPlaneMachineSetKind},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(newUnstructured(cpms.ControlPlaneMachineSetKind), &cpms.ControlPlaneMachineSetReconciler{Client: d.client, Scheme: scheme, Metrics: cpms.NewMetrics(d.aggregator), ClusterId: d.clusterId, Recorder: d.recorder})
+		},
+	},
+	{
+		name:    "HostedCluster",
+		offline: true,
+		gated:   true,
+		crdName: "hostedclusters.hypershift.openshift.io",
+		resources: []authorizationv1.ResourceAttributes{
+			{Group: "hypershift.openshift.io", Resource: "hostedclusters"},
+			{Group: "hypershift.openshift.io", Resource: "nodepools"},
+		},
+		kinds: []*apiversion.Kind{hypershift.HostedClusterKind, hypershift.NodePoolKind},
+		reconcilers: func(d controllerDependencies) []controllerReconciler {
+			return singleReconciler(newUnstructured(hypershift.HostedClusterKind), &hypershift.HostedClusterReconciler{Client: d.client, Scheme: scheme, Metrics: hypershift.NewMetrics(d.aggregator)})
+		},
+	},
+}
+
+// setupReconcilers returns the gate.Controller Setup of the reconcilers of a gated controller
+func setupReconcilers(reconcilers []controllerReconciler) func(mgr ctrl.Manager) error {
+	return func(mgr ctrl.Manager) error {
+		for _, r := range reconcilers {
+			if err := r.reconciler.SetupWithManager(mgr); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func newUnstructured(kind *apiversion.Kind) client.Object {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(kind.GroupVersionKind())
+	return obj
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalogsource
+
+import (
+	"context"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/apiversion"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	marketplaceNamespace = "openshift-marketplace"
+	connectionStateReady = "READY"
+)
+
+var log = logf.Log.WithName("controller_catalogsource")
+
+// CatalogSourceKind is read as an unstructured object, as there are no Go types for OLM in this repository
+var CatalogSourceKind = apiversion.NewKind(schema.GroupVersionKind{Group: "operators.coreos.com", Version: "v1alpha1", Kind: "CatalogSource"})
+
+// defaultCatalogSources are the CatalogSources the marketplace operator creates in openshift-marketplace
+var defaultCatalogSources = map[string]bool{
+	"redhat-operators":    true,
+	"certified-operators": true,
+	"community-operators": true,
+	"redhat-marketplace":  true,
+}
+
+// CatalogSourceReconciler reconciles a CatalogSource object
+type CatalogSourceReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+}
+
+// Reconcile lists all CatalogSources and reports the readiness of the ones that are not default marketplace sources
+func (r *CatalogSourceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling CatalogSource")
+
+	catalogSources := &unstructured.UnstructuredList{}
+	catalogSources.SetGroupVersionKind(CatalogSourceKind.ListGroupVersionKind())
+	if err := r.Client.List(ctx, catalogSources); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	ready := make(map[CatalogSourceKey]bool)
+	for _, cs := range catalogSources.Items {
+		if isDefault(&cs) {
+			continue
+		}
+		state, _, _ := unstructured.NestedString(cs.Object, "status", "connectionState", "lastObservedState")
+		ready[CatalogSourceKey{Namespace: cs.GetNamespace(), Name: cs.GetName()}] = state == connectionStateReady
+	}
+	r.Metrics.SetCustomCatalogSources(r.ClusterId, ready)
+	return ctrl.Result{}, nil
+}
+
+func isDefault(cs *unstructured.Unstructured) bool {
+	return cs.GetNamespace() == marketplaceNamespace && defaultCatalogSources[cs.GetName()]
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CatalogSourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	catalogSource := &unstructured.Unstructured{}
+	catalogSource.SetGroupVersionKind(CatalogSourceKind.GroupVersionKind())
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("CatalogSource")).
+		Named("catalogsource").
+		For(catalogSource).
+		Complete(r)
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalogsource
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestCatalogSource(namespace, name, state string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(CatalogSourceKind.GroupVersionKind())
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.Object["status"] = map[string]interface{}{
+		"connectionState": map[string]interface{}{"lastObservedState": state},
+	}
+	return obj
+}
+
+func TestReconcileCatalogSource_Reconcile(t *testing.T) {
+	objects := []client.Object{
+		makeTestCatalogSource("openshift-marketplace", "redhat-operators", "READY"),
+		makeTestCatalogSource("openshift-marketplace", "community-operators", "TRANSIENT_FAILURE"),
+		makeTestCatalogSource("openshift-marketplace", "mirrored-operators", "READY"),
+		makeTestCatalogSource("customer", "redhat-operators", "CONNECTING"),
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(objects...).Build()
+	reconciler := &CatalogSourceReconciler{
+		Client:    fakeClient,
+		Metrics:   NewMetrics(metrics.NewMetricsAggregator("cluster-id")),
+		ClusterId: "cluster-id",
+	}
+	_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: "openshift-marketplace", Name: "mirrored-operators"},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, float64(2), testutil.ToFloat64(reconciler.Metrics.count))
+	err = testutil.CollectAndCompare(reconciler.Metrics.ready, strings.NewReader(`
+# HELP custom_catalogsource_ready Indicates if a custom CatalogSource is ready to serve its catalog
+# TYPE custom_catalogsource_ready gauge
+custom_catalogsource_ready{_id="cluster-id",catalog="mirrored-operators",name="osd_exporter",namespace="openshift-marketplace"} 1
+custom_catalogsource_ready{_id="cluster-id",catalog="redhat-operators",name="osd_exporter",namespace="customer"} 0
+`))
+	require.NoError(t, err)
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalogsource
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+const (
+	namespaceLabel = "namespace"
+	catalogLabel   = "catalog"
+)
+
+// CatalogSourceKey identifies a custom_catalogsource_ready series
+type CatalogSourceKey struct {
+	Namespace string
+	Name      string
+}
+
+// Metrics report the CatalogSources the customer added besides the default marketplace sources, and whether each
+// of them serves its catalog, as a source which is not ready can block the Subscriptions of its namespace
+type Metrics struct {
+	metrics.MetricSet
+	count *metrics.Gauges
+	ready *metrics.Gauges
+}
+
+// NewMetrics registers the custom CatalogSource count and a readiness series per custom CatalogSource
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		count: a.NewGauges("custom_catalogsource_count", "Indicates the number of CatalogSources outside the default marketplace sources"),
+		ready: a.NewGauges("custom_catalogsource_ready", "Indicates if a custom CatalogSource is ready to serve its catalog",
+			namespaceLabel, catalogLabel),
+	}
+	m.MetricSet = metrics.NewMetricSet("CatalogSource", m.count, m.ready)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetCustomCatalogSources(uuid string, ready map[CatalogSourceKey]bool) {
+	m.count.With(uuid).Set(float64(len(ready)))
+	samples := make([]metrics.Sample, 0, len(ready))
+	for key, r := range ready {
+		samples = append(samples, metrics.Sample{LabelValues: []string{key.Namespace, key.Name}, Value: metrics.BoolToFloat(r)})
+	}
+	m.ready.SetSnapshot(uuid, samples)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudcredential
+
+import (
+	"context"
+	"strconv"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// clusterConfigName is the name of the CloudCredential, Authentication and Infrastructure configs
+const clusterConfigName = "cluster"
+
+var log = logf.Log.WithName("controller_cloudcredential")
+
+// CloudCredentialReconciler reconciles the CloudCredential operator config
+type CloudCredentialReconciler struct {
+	client.Client
+	Scheme  *runtime.Scheme
+	Metrics *Metrics
+	// MetricsAggregator receives the STS mode of osd_cluster_info
+	MetricsAggregator metrics.MetricsAggregator
+	ClusterId         string
+}
+
+// Reconcile reports if an AWS cluster uses STS or a GCP cluster uses Workload Identity Federation. Both run the
+// cloud-credential-operator in manual mode, with operators exchanging service account tokens of a custom issuer for
+// short-lived cloud credentials. The STS mode is also reported as fact of osd_cluster_info, which is false on other
+// platforms.
+func (r *CloudCredentialReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling CloudCredential")
+
+	infra := &configv1.Infrastructure{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: clusterConfigName}, infra); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if infra.Status.PlatformStatus == nil {
+		return ctrl.Result{}, nil
+	}
+	platform := infra.Status.PlatformStatus.Type
+	if platform != configv1.AWSPlatformType && platform != configv1.GCPPlatformType {
+		r.MetricsAggregator.SetClusterInfo(r.ClusterId, metrics.ClusterInfoSTS, strconv.FormatBool(false))
+		return ctrl.Result{}, nil
+	}
+
+	manual := false
+	cc := &operatorv1.CloudCredential{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: clusterConfigName}, cc)
+	if err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	if err == nil {
+		manual = cc.Spec.CredentialsMode == operatorv1.CloudCredentialsModeManual
+	}
+	customIssuer := false
+	auth := &configv1.Authentication{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: clusterConfigName}, auth)
+	if err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	if err == nil {
+		customIssuer = auth.Spec.ServiceAccountIssuer != ""
+	}
+
+	shortLived := manual && customIssuer
+	r.MetricsAggregator.SetClusterInfo(r.ClusterId, metrics.ClusterInfoSTS, strconv.FormatBool(platform == configv1.AWSPlatformType && shortLived))
+	if platform == configv1.AWSPlatformType {
+		r.Metrics.SetSTS(r.ClusterId, shortLived)
+	} else {
+		r.Metrics.SetWorkloadIdentityFederation(r.ClusterId, shortLived)
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CloudCredentialReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	toCloudCredential := handler.EnqueueRequestsFromMapFunc(func(client.Object) []ctrl.Request {
+		return []ctrl.Request{{NamespacedName: types.NamespacedName{Name: clusterConfigName}}}
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("CloudCredential")).
+		For(&operatorv1.CloudCredential{}).
+		Watches(&source.Kind{Type: &configv1.Authentication{}}, toCloudCredential).
+		Watches(&source.Kind{Type: &configv1.Infrastructure{}}, toCloudCredential).
+		Complete(r)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudcredential
+
+import (
+	"context"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestObjects(platform configv1.PlatformType, mode operatorv1.CloudCredentialsMode, issuer string) []client.Object {
+	return []client.Object{
+		&configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterConfigName},
+			Status:     configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{Type: platform}},
+		},
+		&operatorv1.CloudCredential{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterConfigName},
+			Spec:       operatorv1.CloudCredentialSpec{CredentialsMode: mode},
+		},
+		&configv1.Authentication{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterConfigName},
+			Spec:       configv1.AuthenticationSpec{ServiceAccountIssuer: issuer},
+		},
+	}
+}
+
+func newTestReconciler(t *testing.T, objects ...client.Object) *CloudCredentialReconciler {
+	s := runtime.NewScheme()
+	require.NoError(t, configv1.Install(s))
+	require.NoError(t, operatorv1.Install(s))
+	metricsAggregator := metrics.NewMetricsAggregator("cluster-id")
+	return &CloudCredentialReconciler{
+		Client:            fake.NewClientBuilder().WithScheme(s).WithObjects(objects...).Build(),
+		Metrics:           NewMetrics(metricsAggregator),
+		MetricsAggregator: metricsAggregator,
+		ClusterId:         "cluster-id",
+	}
+}
+
+func TestReconcileCloudCredential_Reconcile(t *testing.T) {
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: clusterConfigName}}
+	for _, tc := range []struct {
+		name        string
+		objects     []client.Object
+		expectedSTS float64
+		// expectedInfo is the sts label of osd_cluster_info
+		expectedInfo string
+	}{
+		{
+			name:         "STS",
+			objects:      makeTestObjects(configv1.AWSPlatformType, operatorv1.CloudCredentialsModeManual, "https://oidc.example.com/cluster"),
+			expectedSTS:  1,
+			expectedInfo: "true",
+		},
+		{
+			name:         "mint mode",
+			objects:      makeTestObjects(configv1.AWSPlatformType, operatorv1.CloudCredentialsModeDefault, ""),
+			expectedInfo: "false",
+		},
+		{
+			name:         "manual mode with static credentials",
+			objects:      makeTestObjects(configv1.AWSPlatformType, operatorv1.CloudCredentialsModeManual, ""),
+			expectedInfo: "false",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reconciler := newTestReconciler(t, tc.objects...)
+			_, err := reconciler.Reconcile(context.TODO(), request)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedSTS, testutil.ToFloat64(reconciler.Metrics.sts))
+			require.Equal(t, 0, testutil.CollectAndCount(reconciler.Metrics.workloadIdentityFederation))
+			info := reconciler.MetricsAggregator.(*metrics.AdoptionMetricsAggregator).GetClusterInfoMetric()
+			require.Equal(t, float64(1), testutil.ToFloat64(info.WithLabelValues("cluster-id", "", "", "", "", tc.expectedInfo)))
+		})
+	}
+
+	reconciler := newTestReconciler(t, makeTestObjects(configv1.GCPPlatformType, operatorv1.CloudCredentialsModeManual, "https://storage.googleapis.com/cluster")...)
+	_, err := reconciler.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	require.Equal(t, float64(1), testutil.ToFloat64(reconciler.Metrics.workloadIdentityFederation))
+	require.Equal(t, 0, testutil.CollectAndCount(reconciler.Metrics.sts))
+}
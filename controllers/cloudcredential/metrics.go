@@ -0,0 +1,45 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudcredential
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+// Metrics report whether the operators of the cluster get short-lived cloud credentials, through STS on AWS or
+// Workload Identity Federation on GCP, instead of long-lived keys
+type Metrics struct {
+	metrics.MetricSet
+	sts                        *metrics.Gauges
+	workloadIdentityFederation *metrics.Gauges
+}
+
+// NewMetrics registers cluster_sts_enabled and cluster_wif_enabled
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		sts:                        a.NewGauges("cluster_sts_enabled", "Indicates if an AWS cluster uses STS for the cloud credentials of its operators"),
+		workloadIdentityFederation: a.NewGauges("cluster_wif_enabled", "Indicates if a GCP cluster uses Workload Identity Federation for the cloud credentials of its operators"),
+	}
+	m.MetricSet = metrics.NewMetricSet("CloudCredential", m.sts, m.workloadIdentityFederation)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetSTS(uuid string, enabled bool) {
+	m.sts.With(uuid).Set(metrics.BoolToFloat(enabled))
+}
+
+func (m *Metrics) SetWorkloadIdentityFederation(uuid string, enabled bool) {
+	m.workloadIdentityFederation.With(uuid).Set(metrics.BoolToFloat(enabled))
+}
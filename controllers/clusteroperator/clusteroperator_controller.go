@@ -0,0 +1,76 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusteroperator
+
+import (
+	"context"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("controller_clusteroperator")
+
+// ClusterOperatorReconciler reconciles a ClusterOperator object
+type ClusterOperatorReconciler struct {
+	client.Client
+	Scheme            *runtime.Scheme
+	MetricsAggregator metrics.MetricsAggregator
+	ClusterId         string
+}
+
+// Reconcile lists all ClusterOperators and records degraded operators as an upgrade blocker
+func (r *ClusterOperatorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling ClusterOperator")
+
+	clusterOperators := &configv1.ClusterOperatorList{}
+	if err := r.Client.List(ctx, clusterOperators); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var degraded []string
+	for _, co := range clusterOperators.Items {
+		if isDegraded(co) {
+			degraded = append(degraded, co.Name)
+		}
+	}
+	if len(degraded) > 0 {
+		reqLogger.Info("cluster operators are degraded", "operators", degraded)
+	}
+	r.MetricsAggregator.SetUpgradeBlocker(metrics.UpgradeBlockerDegradedOperators, len(degraded) > 0)
+	return ctrl.Result{}, nil
+}
+
+func isDegraded(co configv1.ClusterOperator) bool {
+	for _, condition := range co.Status.Conditions {
+		if condition.Type == configv1.OperatorDegraded {
+			return condition.Status == configv1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterOperatorReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("ClusterOperator")).
+		For(&configv1.ClusterOperator{}).
+		Complete(r)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusteroperator
+
+import (
+	"context"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testClusterId = "cluster-id"
+
+func makeTestClusterOperator(name string, degraded configv1.ConditionStatus) *configv1.ClusterOperator {
+	return &configv1.ClusterOperator{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: configv1.ClusterOperatorStatus{
+			Conditions: []configv1.ClusterOperatorStatusCondition{
+				{Type: configv1.OperatorAvailable, Status: configv1.ConditionTrue},
+				{Type: configv1.OperatorDegraded, Status: degraded},
+			},
+		},
+	}
+}
+
+func TestReconcileClusterOperator_Reconcile(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		objects []client.Object
+		result  int
+	}{
+		{
+			name: "all operators healthy",
+			objects: []client.Object{
+				makeTestClusterOperator("ingress", configv1.ConditionFalse),
+				makeTestClusterOperator("dns", configv1.ConditionFalse),
+			},
+			result: 1,
+		},
+		{
+			name: "degraded operator",
+			objects: []client.Object{
+				makeTestClusterOperator("ingress", configv1.ConditionTrue),
+				makeTestClusterOperator("dns", configv1.ConditionFalse),
+			},
+			result: 0,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, configv1.Install(scheme))
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()
+			metricsAggregator := metrics.NewMetricsAggregator(testClusterId)
+			reconciler := &ClusterOperatorReconciler{
+				Client:            fakeClient,
+				MetricsAggregator: metricsAggregator,
+				ClusterId:         testClusterId,
+			}
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: "ingress"},
+			})
+			require.NoError(t, err)
+
+			reasons := metricsAggregator.EvaluateUpgradeReadiness(testClusterId)
+			if tc.result == 1 {
+				require.Empty(t, reasons)
+			} else {
+				require.Equal(t, []string{metrics.UpgradeBlockerDegradedOperators}, reasons)
+			}
+			value := testutil.ToFloat64(metricsAggregator.GetUpgradeReadyMetric())
+			require.EqualValues(t, tc.result, value)
+		})
+	}
+}
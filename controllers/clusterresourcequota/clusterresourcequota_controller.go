@@ -0,0 +1,65 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterresourcequota
+
+import (
+	"context"
+
+	quotav1 "github.com/openshift/api/quota/v1"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("controller_clusterresourcequota")
+
+// ClusterResourceQuotaReconciler reconciles a ClusterResourceQuota object
+type ClusterResourceQuotaReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+}
+
+// Reconcile lists all ClusterResourceQuotas and reports their number and the sum of their hard limits by resource,
+// cores for cpu and bytes for memory and storage
+func (r *ClusterResourceQuotaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling ClusterResourceQuota")
+
+	quotas := &quotav1.ClusterResourceQuotaList{}
+	if err := r.Client.List(ctx, quotas); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	hard := map[string]float64{}
+	for _, q := range quotas.Items {
+		for resource, quantity := range q.Spec.Quota.Hard {
+			hard[string(resource)] += quantity.AsApproximateFloat64()
+		}
+	}
+	r.Metrics.SetClusterResourceQuotas(r.ClusterId, len(quotas.Items), hard)
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterResourceQuotaReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("ClusterResourceQuota")).
+		For(&quotav1.ClusterResourceQuota{}).
+		WithEventFilter(utils.IgnoreStatusUpdates()).
+		Complete(r)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterresourcequota
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	quotav1 "github.com/openshift/api/quota/v1"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestClusterResourceQuota(name string, hard corev1.ResourceList) *quotav1.ClusterResourceQuota {
+	q := &quotav1.ClusterResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	q.Spec.Quota.Hard = hard
+	return q
+}
+
+func TestReconcileClusterResourceQuota_Reconcile(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, quotav1.Install(s))
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		makeTestClusterResourceQuota("team-a", corev1.ResourceList{
+			corev1.ResourceLimitsCPU:    resource.MustParse("1500m"),
+			corev1.ResourceLimitsMemory: resource.MustParse("4Gi"),
+		}),
+		makeTestClusterResourceQuota("team-b", corev1.ResourceList{
+			corev1.ResourceLimitsCPU: resource.MustParse("2"),
+			corev1.ResourcePods:      resource.MustParse("10"),
+		}),
+	).Build()
+	reconciler := &ClusterResourceQuotaReconciler{
+		Client:    fakeClient,
+		Metrics:   NewMetrics(metrics.NewMetricsAggregator("cluster-id")),
+		ClusterId: "cluster-id",
+	}
+	_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{})
+	require.NoError(t, err)
+	require.Equal(t, float64(2), testutil.ToFloat64(reconciler.Metrics.quotas))
+	err = testutil.CollectAndCompare(reconciler.Metrics.hardLimits, strings.NewReader(`
+# HELP clusterresourcequota_hard_limit Indicates the sum of the hard limits of the ClusterResourceQuotas by resource
+# TYPE clusterresourcequota_hard_limit gauge
+clusterresourcequota_hard_limit{_id="cluster-id",name="osd_exporter",resource="limits.cpu"} 3.5
+clusterresourcequota_hard_limit{_id="cluster-id",name="osd_exporter",resource="limits.memory"} 4.294967296e+09
+clusterresourcequota_hard_limit{_id="cluster-id",name="osd_exporter",resource="pods"} 10
+`))
+	require.NoError(t, err)
+}
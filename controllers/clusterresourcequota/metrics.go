@@ -0,0 +1,48 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterresourcequota
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+// resourceLabel is the label of the hard limit metric
+const resourceLabel = "resource"
+
+// Metrics report the ClusterResourceQuotas limiting the resources of several projects, and what they limit
+type Metrics struct {
+	metrics.MetricSet
+	quotas     *metrics.Gauges
+	hardLimits *metrics.Gauges
+}
+
+// NewMetrics registers the quota count and the sum of the hard limits by resource
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		quotas:     a.NewGauges("clusterresourcequota_count", "Indicates the number of ClusterResourceQuotas"),
+		hardLimits: a.NewGauges("clusterresourcequota_hard_limit", "Indicates the sum of the hard limits of the ClusterResourceQuotas by resource", resourceLabel),
+	}
+	m.MetricSet = metrics.NewMetricSet("ClusterResourceQuota", m.quotas, m.hardLimits)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetClusterResourceQuotas(uuid string, count int, hardLimits map[string]float64) {
+	m.quotas.With(uuid).Set(float64(count))
+	samples := make([]metrics.Sample, 0, len(hardLimits))
+	for resource, limit := range hardLimits {
+		samples = append(samples, metrics.Sample{LabelValues: []string{resource}, Value: limit})
+	}
+	m.hardLimits.SetSnapshot(uuid, samples)
+}
@@ -84,6 +84,7 @@ func (r *ClusterRoleReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 // SetupWithManager sets up the controller with the Manager.
 func (r *ClusterRoleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("ClusterRole")).
 		For(&rbacv1.ClusterRole{}).
 		WithEventFilter(predicate.Funcs{
 			CreateFunc: func(evt event.CreateEvent) bool {
@@ -0,0 +1,119 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterversion
+
+import (
+	"context"
+	"math"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/lifecycle"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// lifecycleRequeueInterval refreshes the days until the end of life, which change without the ClusterVersion changing
+	lifecycleRequeueInterval = time.Hour
+	// kubeSystemNamespace is created when the cluster is bootstrapped, before the cluster-version-operator
+	// creates the ClusterVersion
+	kubeSystemNamespace = "kube-system"
+)
+
+var log = logf.Log.WithName("controller_clusterversion")
+
+// now is replaced in tests
+var now = time.Now
+
+// ClusterVersionReconciler reconciles a ClusterVersion object
+type ClusterVersionReconciler struct {
+	client.Client
+	Scheme  *runtime.Scheme
+	Metrics *Metrics
+	// MetricsAggregator moves the series when the cluster id changes and receives the version of osd_cluster_info
+	MetricsAggregator metrics.MetricsAggregator
+	// ClusterId is the cluster id the exporter started with, and is updated when it changes
+	ClusterId string
+}
+
+// Reconcile moves all series to the new cluster id when the cluster id of the ClusterVersion changes, which
+// happens when the cluster is registered again in OCM. It also reports the days until the end of life of the
+// desired version, if the cluster is on an Extended Update Support channel and when the cluster was created.
+func (r *ClusterVersionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling ClusterVersion")
+
+	cv := &configv1.ClusterVersion{}
+	if err := r.Client.Get(ctx, req.NamespacedName, cv); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	clusterId := string(cv.Spec.ClusterID)
+	if clusterId != "" && clusterId != r.ClusterId {
+		reqLogger.Info("Cluster id changed, relabeling all metrics", "previous", r.ClusterId, "current", clusterId)
+		r.MetricsAggregator.RelabelClusterID(r.ClusterId, clusterId)
+		r.ClusterId = clusterId
+	}
+
+	eus := lifecycle.IsEUSChannel(cv.Spec.Channel)
+	minorVersion, daysUntilEOL := "", 0
+	if eol, ok := lifecycle.EndOfLife(cv.Status.Desired.Version, eus); ok {
+		minorVersion = lifecycle.MinorVersion(cv.Status.Desired.Version)
+		daysUntilEOL = int(math.Floor(eol.Sub(now()).Hours() / 24))
+	}
+	r.Metrics.SetVersionLifecycle(r.ClusterId, minorVersion, daysUntilEOL, eus)
+	r.MetricsAggregator.SetClusterInfo(r.ClusterId, metrics.ClusterInfoVersion, cv.Status.Desired.Version)
+
+	created, err := r.creationTime(ctx, cv)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	r.Metrics.SetClusterCreationTimestamp(r.ClusterId, created)
+	return ctrl.Result{RequeueAfter: lifecycleRequeueInterval}, nil
+}
+
+// creationTime returns when the cluster was created, which is when the kube-system namespace was created, or when
+// the ClusterVersion was created if that is earlier or the namespace is not found
+func (r *ClusterVersionReconciler) creationTime(ctx context.Context, cv *configv1.ClusterVersion) (time.Time, error) {
+	created := cv.CreationTimestamp.Time
+	ns := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: kubeSystemNamespace}, ns); err != nil {
+		if errors.IsNotFound(err) {
+			return created, nil
+		}
+		return time.Time{}, err
+	}
+	if ns.CreationTimestamp.Before(&cv.CreationTimestamp) {
+		created = ns.CreationTimestamp.Time
+	}
+	return created, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterVersionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("ClusterVersion")).
+		For(&configv1.ClusterVersion{}).
+		Complete(r)
+}
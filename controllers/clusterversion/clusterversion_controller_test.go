@@ -0,0 +1,176 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterversion
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics/metricsfakes"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileClusterVersion_Reconcile(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, configv1.Install(s))
+	require.NoError(t, corev1.AddToScheme(s))
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(&configv1.ClusterVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "version"},
+		Spec:       configv1.ClusterVersionSpec{ClusterID: "new-id"},
+	}).Build()
+	metricsAggregator := metrics.NewMetricsAggregator("old-id")
+	metricsAggregator.SetClusterID("old-id")
+	reconciler := &ClusterVersionReconciler{
+		Client:            fakeClient,
+		Metrics:           NewMetrics(metricsAggregator),
+		MetricsAggregator: metricsAggregator,
+		ClusterId:         "old-id",
+	}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "version"}}
+	_, err := reconciler.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+
+	require.Equal(t, "new-id", reconciler.ClusterId)
+	require.Equal(t, 1, testutil.CollectAndCount(metricsAggregator.GetClusterIDMetric()))
+	require.Equal(t, float64(1), testutil.ToFloat64(metricsAggregator.GetClusterIDMetric().WithLabelValues("new-id")))
+	changedAt := testutil.ToFloat64(metricsAggregator.GetClusterIDChangedMetric().WithLabelValues("new-id", "old-id"))
+	require.NotZero(t, changedAt)
+
+	// an unchanged id does not relabel again
+	_, err = reconciler.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	require.Equal(t, 1, testutil.CollectAndCount(metricsAggregator.GetClusterIDChangedMetric()))
+}
+
+func TestReconcileClusterVersion_ReconcileUpdates(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, configv1.Install(s))
+	require.NoError(t, corev1.AddToScheme(s))
+	created := metav1.NewTime(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(&configv1.ClusterVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "version", CreationTimestamp: created},
+		Spec:       configv1.ClusterVersionSpec{ClusterID: "new-id"},
+	}).Build()
+	metricsAggregator := metricsfakes.NewFakeMetricsAggregator("old-id")
+	reconciler := &ClusterVersionReconciler{
+		Client:            fakeClient,
+		Metrics:           NewMetrics(metricsAggregator.AdoptionMetricsAggregator),
+		MetricsAggregator: metricsAggregator,
+		ClusterId:         "old-id",
+	}
+	_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "version"}})
+	require.NoError(t, err)
+
+	// the series are moved before the metrics of the new id are updated
+	require.Equal(t, []metricsfakes.Update{
+		{Method: "RelabelClusterID", Args: []interface{}{"old-id", "new-id"}},
+		{Method: "Set", Args: []interface{}{"eus_channel_enabled", []string{"new-id"}, float64(0)}},
+		{Method: "SetClusterInfo", Args: []interface{}{"new-id", metrics.ClusterInfoVersion, ""}},
+		{Method: "Set", Args: []interface{}{"cluster_creation_timestamp_seconds", []string{"new-id"}, float64(created.Unix())}},
+	}, metricsAggregator.Updates())
+}
+
+func TestReconcileClusterVersion_ReconcileLifecycle(t *testing.T) {
+	current := time.Date(2023, 9, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	s := runtime.NewScheme()
+	require.NoError(t, configv1.Install(s))
+	require.NoError(t, corev1.AddToScheme(s))
+	cv := &configv1.ClusterVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "version"},
+		Spec:       configv1.ClusterVersionSpec{ClusterID: "cluster-id", Channel: "stable-4.10"},
+		Status:     configv1.ClusterVersionStatus{Desired: configv1.Release{Version: "4.10.36"}},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(cv).Build()
+	metricsAggregator := metrics.NewMetricsAggregator("cluster-id")
+	reconciler := &ClusterVersionReconciler{
+		Client:            fakeClient,
+		Metrics:           NewMetrics(metricsAggregator),
+		MetricsAggregator: metricsAggregator,
+		ClusterId:         "cluster-id",
+	}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "version"}}
+	result, err := reconciler.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	require.Equal(t, lifecycleRequeueInterval, result.RequeueAfter)
+	require.NoError(t, testutil.CollectAndCompare(reconciler.Metrics.daysUntilEOL, strings.NewReader(`
+# HELP version_days_until_eol Indicates the days until the end of life of the running minor version, negative once it has passed
+# TYPE version_days_until_eol gauge
+version_days_until_eol{_id="cluster-id",name="osd_exporter",version="4.10"} 8
+`)))
+	require.Equal(t, float64(0), testutil.ToFloat64(reconciler.Metrics.eusChannel))
+
+	// Extended Update Support moves the end of life
+	require.NoError(t, fakeClient.Get(context.TODO(), request.NamespacedName, cv))
+	cv.Spec.Channel = "eus-4.10"
+	require.NoError(t, fakeClient.Update(context.TODO(), cv))
+	_, err = reconciler.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	require.Equal(t, float64(190), testutil.ToFloat64(reconciler.Metrics.daysUntilEOL))
+	require.Equal(t, float64(1), testutil.ToFloat64(reconciler.Metrics.eusChannel))
+
+	// a version missing from the calendar
+	cv.Spec.Channel = "candidate-4.99"
+	cv.Status.Desired.Version = "4.99.0"
+	require.NoError(t, fakeClient.Update(context.TODO(), cv))
+	_, err = reconciler.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	require.Equal(t, 0, testutil.CollectAndCount(reconciler.Metrics.daysUntilEOL))
+}
+
+func TestReconcileClusterVersion_ReconcileCreationTimestamp(t *testing.T) {
+	installed := time.Date(2022, 3, 1, 8, 0, 0, 0, time.UTC)
+	s := runtime.NewScheme()
+	require.NoError(t, configv1.Install(s))
+	require.NoError(t, corev1.AddToScheme(s))
+	cv := &configv1.ClusterVersion{ObjectMeta: metav1.ObjectMeta{
+		Name:              "version",
+		CreationTimestamp: metav1.NewTime(installed.Add(10 * time.Minute)),
+	}}
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(cv).Build()
+	metricsAggregator := metrics.NewMetricsAggregator("cluster-id")
+	reconciler := &ClusterVersionReconciler{
+		Client:            fakeClient,
+		Metrics:           NewMetrics(metricsAggregator),
+		MetricsAggregator: metricsAggregator,
+		ClusterId:         "cluster-id",
+	}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "version"}}
+
+	// without the kube-system namespace the ClusterVersion is used
+	_, err := reconciler.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	require.Equal(t, float64(installed.Add(10*time.Minute).Unix()), testutil.ToFloat64(reconciler.Metrics.creation))
+
+	require.NoError(t, fakeClient.Create(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:              kubeSystemNamespace,
+		CreationTimestamp: metav1.NewTime(installed),
+	}}))
+	_, err = reconciler.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	require.Equal(t, float64(installed.Unix()), testutil.ToFloat64(reconciler.Metrics.creation))
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterversion
+
+import (
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+const versionLabel = "version"
+
+// Metrics report the lifecycle of the cluster version: the days until the end of life of its minor version, whether
+// it follows an Extended Update Support channel and when the cluster was created
+type Metrics struct {
+	metrics.MetricSet
+	daysUntilEOL *metrics.Gauges
+	eusChannel   *metrics.Gauges
+	creation     *metrics.Gauges
+}
+
+// NewMetrics registers the version lifecycle metrics and the creation timestamp of the cluster
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		daysUntilEOL: a.NewGauges("version_days_until_eol", "Indicates the days until the end of life of the running minor version, negative once it has passed",
+			versionLabel),
+		eusChannel: a.NewGauges("eus_channel_enabled", "Indicates if the cluster is on an Extended Update Support channel"),
+		creation:   a.NewGauges("cluster_creation_timestamp_seconds", "Indicates the creation time of the cluster in seconds since the epoch"),
+	}
+	m.MetricSet = metrics.NewMetricSet("ClusterVersion", m.daysUntilEOL, m.eusChannel, m.creation)
+	a.MustRegister(m)
+	return m
+}
+
+// SetVersionLifecycle reports the days until the end of life of the minor version, and removes the series
+// of the previous minor version after an upgrade. minorVersion is empty for versions missing from the
+// lifecycle calendar, which only removes the series.
+func (m *Metrics) SetVersionLifecycle(uuid string, minorVersion string, daysUntilEOL int, eusChannel bool) {
+	var samples []metrics.Sample
+	if minorVersion != "" {
+		samples = []metrics.Sample{{LabelValues: []string{minorVersion}, Value: float64(daysUntilEOL)}}
+	}
+	m.daysUntilEOL.SetSnapshot(uuid, samples)
+	m.eusChannel.With(uuid).Set(metrics.BoolToFloat(eusChannel))
+}
+
+func (m *Metrics) SetClusterCreationTimestamp(uuid string, created time.Time) {
+	m.creation.With(uuid).Set(float64(created.Unix()))
+}
@@ -20,7 +20,7 @@ import (
 
 	"github.com/openshift/cluster-network-operator/pkg/names"
 	"github.com/openshift/cluster-network-operator/pkg/util/validation"
-	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -42,9 +42,9 @@ var log = logf.Log.WithName("controller_configmap")
 // ConfigMapReconciler reconciles a ConfigMap object
 type ConfigMapReconciler struct {
 	client.Client
-	Scheme            *runtime.Scheme
-	MetricsAggregator *metrics.AdoptionMetricsAggregator
-	ClusterId         string
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
 }
 
 // Reconcile reads that state of the cluster for a ConfigMap object and makes changes based the contained data
@@ -59,8 +59,9 @@ func (r *ConfigMapReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
-			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// The certificates it contained are no longer trusted, so their metrics are deleted.
 			// Return and don't requeue
+			r.Metrics.DeleteClusterProxyCA(r.ClusterId)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -73,7 +74,7 @@ func (r *ConfigMapReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		// and handle gracefully rather then causing stacktrace.
 		if strings.Contains(err.Error(), "failed parsing certificate") {
 			reqLogger.Info("failed parsing certificate")
-			r.MetricsAggregator.SetClusterProxyCAValid(r.ClusterId, false)
+			r.Metrics.SetClusterProxyCAValid(r.ClusterId, false)
 			reqLogger.Info("setting CA valid metric to false")
 			return ctrl.Result{}, nil
 		}
@@ -88,8 +89,8 @@ func (r *ConfigMapReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	reqLogger.Info(fmt.Sprintf("Found %d cert bundles", countCertBundle))
 	for _, cert := range certBundle {
 		reqLogger.Info(fmt.Sprintf("Certificate Expiry %d", cert.NotAfter.Unix()))
-		r.MetricsAggregator.SetClusterProxyCAExpiry(r.ClusterId, cert.Subject.String(), cert.NotAfter.UTC().Unix())
-		r.MetricsAggregator.SetClusterProxyCAValid(r.ClusterId, true)
+		r.Metrics.SetClusterProxyCAExpiry(r.ClusterId, cert.Subject.String(), cert.NotAfter.UTC().Unix())
+		r.Metrics.SetClusterProxyCAValid(r.ClusterId, true)
 	}
 	return ctrl.Result{}, nil
 }
@@ -97,6 +98,7 @@ func (r *ConfigMapReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 // SetupWithManager sets up the controller with the Manager.
 func (r *ConfigMapReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("ConfigMap")).
 		For(&corev1.ConfigMap{}).
 		WithEventFilter(predicate.Funcs{
 			CreateFunc: func(evt event.CreateEvent) bool {
@@ -17,7 +17,6 @@ import (
 	"context"
 	"strings"
 	"testing"
-	"time"
 
 	"github.com/prometheus/client_golang/prometheus/testutil"
 
@@ -108,18 +107,15 @@ cluster_proxy_ca_expiry_timestamp{_id="i-am-a-cluster-id", name="osd_exporter",s
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			metricsAggregator := metrics.NewMetricsAggregator(time.Second, tc.clusterId)
-			done := metricsAggregator.Run()
-			defer close(done)
 			err := corev1.AddToScheme(scheme.Scheme)
 			require.NoError(t, err)
 
 			testConfigMap := makeTestConfigMap(userCABundle, openshiftConfig, tc.cfgMapData)
 			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(testConfigMap).Build()
 			reconciler := ConfigMapReconciler{
-				Client:            fakeClient,
-				MetricsAggregator: metricsAggregator,
-				ClusterId:         tc.clusterId,
+				Client:    fakeClient,
+				Metrics:   NewMetrics(metrics.NewMetricsAggregator(tc.clusterId)),
+				ClusterId: tc.clusterId,
 			}
 			result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
 				NamespacedName: types.NamespacedName{
@@ -127,16 +123,12 @@ cluster_proxy_ca_expiry_timestamp{_id="i-am-a-cluster-id", name="osd_exporter",s
 					Name:      userCABundle,
 				},
 			})
-
-			// sleep to allow the aggregator to aggregate metrics in the background
-			time.Sleep(time.Second * 3)
 			require.NoError(t, err)
 			require.NotNil(t, result)
 			var testCfgMap corev1.ConfigMap
 			err = fakeClient.Get(context.Background(), types.NamespacedName{Name: userCABundle, Namespace: openshiftConfig}, &testCfgMap)
 			require.NoError(t, err)
-			metric := metricsAggregator.GetClusterProxyCAExpiryMetrics()
-			err = testutil.CollectAndCompare(metric, strings.NewReader(tc.expectedResults))
+			err = testutil.CollectAndCompare(reconciler.Metrics.caExpiry, strings.NewReader(tc.expectedResults))
 			require.NoError(t, err)
 		})
 	}
@@ -159,18 +151,15 @@ cluster_proxy_ca_valid{_id="i-am-a-cluster-id", name="osd_exporter"} 0
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			metricsAggregator := metrics.NewMetricsAggregator(time.Second, tc.clusterId)
-			done := metricsAggregator.Run()
-			defer close(done)
 			err := corev1.AddToScheme(scheme.Scheme)
 			require.NoError(t, err)
 
 			testConfigMap := makeTestConfigMap(userCABundle, openshiftConfig, tc.cfgMapData)
 			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(testConfigMap).Build()
 			reconciler := ConfigMapReconciler{
-				Client:            fakeClient,
-				MetricsAggregator: metricsAggregator,
-				ClusterId:         tc.clusterId,
+				Client:    fakeClient,
+				Metrics:   NewMetrics(metrics.NewMetricsAggregator(tc.clusterId)),
+				ClusterId: tc.clusterId,
 			}
 			result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
 				NamespacedName: types.NamespacedName{
@@ -178,17 +167,31 @@ cluster_proxy_ca_valid{_id="i-am-a-cluster-id", name="osd_exporter"} 0
 					Name:      userCABundle,
 				},
 			})
-
-			// sleep to allow the aggregator to aggregate metrics in the background
-			time.Sleep(time.Second * 3)
 			require.NoError(t, err)
 			require.NotNil(t, result)
 			var testCfgMap corev1.ConfigMap
 			err = fakeClient.Get(context.Background(), types.NamespacedName{Name: userCABundle, Namespace: openshiftConfig}, &testCfgMap)
 			require.NoError(t, err)
-			metric := metricsAggregator.GetClusterProxyCAValidMetrics()
-			err = testutil.CollectAndCompare(metric, strings.NewReader(tc.expectedResults))
+			err = testutil.CollectAndCompare(reconciler.Metrics.caValid, strings.NewReader(tc.expectedResults))
 			require.NoError(t, err)
 		})
 	}
 }
+
+func TestReconcileConfigMap_ReconcileDeleted(t *testing.T) {
+	m := NewMetrics(metrics.NewMetricsAggregator("i-am-a-cluster-id"))
+	m.SetClusterProxyCAExpiry("i-am-a-cluster-id", "O=Default Company Ltd", 1)
+	m.SetClusterProxyCAValid("i-am-a-cluster-id", true)
+	require.NoError(t, corev1.AddToScheme(scheme.Scheme))
+	reconciler := ConfigMapReconciler{
+		Client:    fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(),
+		Metrics:   m,
+		ClusterId: "i-am-a-cluster-id",
+	}
+	_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: openshiftConfig, Name: userCABundle},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, testutil.CollectAndCount(m.caExpiry))
+	require.Equal(t, 0, testutil.CollectAndCount(m.caValid))
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+const subjectLabel = "subject"
+
+// Metrics report the CA bundle of the cluster-wide proxy in the user-ca-bundle ConfigMap: the expiry of each of its
+// certificates and whether the bundle could be parsed
+type Metrics struct {
+	metrics.MetricSet
+	caExpiry *metrics.Gauges
+	caValid  *metrics.Gauges
+}
+
+// NewMetrics registers the CA expiry and validity metrics
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		caExpiry: a.NewGauges("cluster_proxy_ca_expiry_timestamp", "Indicates cluster proxy CA expiry unix timestamp in UTC", subjectLabel),
+		caValid:  a.NewGauges("cluster_proxy_ca_valid", "Indicates if cluster proxy CA valid"),
+	}
+	m.MetricSet = metrics.NewMetricSet("ConfigMap", m.caExpiry, m.caValid)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetClusterProxyCAExpiry(uuid string, subject string, clusterProxyCAExpiry int64) {
+	m.caExpiry.With(uuid, subject).Set(float64(clusterProxyCAExpiry))
+}
+
+func (m *Metrics) SetClusterProxyCAValid(uuid string, valid bool) {
+	m.caValid.With(uuid).Set(metrics.BoolToFloat(valid))
+}
+
+// DeleteClusterProxyCA deletes the CA expiry and validity series, after the user-ca-bundle was deleted
+func (m *Metrics) DeleteClusterProxyCA(uuid string) {
+	m.DeleteSeries(uuid)
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpms
+
+import (
+	"context"
+	"encoding/json"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/apiversion"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// controlPlaneMachineSetName is the name of the only ControlPlaneMachineSet of a cluster
+	controlPlaneMachineSetName = "cluster"
+	machineRoleLabel           = "machine.openshift.io/cluster-api-machine-role"
+	masterRole                 = "master"
+	// activeState and inactiveState are the states of a ControlPlaneMachineSet, which only manages the control
+	// plane machines when it is active
+	activeState   = "Active"
+	inactiveState = "Inactive"
+	// stateChangedReason is the reason of the Event recorded when the state of the ControlPlaneMachineSet changes
+	stateChangedReason = "StateChanged"
+)
+
+var log = logf.Log.WithName("controller_cpms")
+
+// ControlPlaneMachineSetKind is read as an unstructured object, as the openshift/api version used by this
+// repository has no Go types for it
+var ControlPlaneMachineSetKind = apiversion.NewKind(schema.GroupVersionKind{Group: "machine.openshift.io", Version: "v1", Kind: "ControlPlaneMachineSet"})
+
+// instanceTypeProviderSpec holds the instance type fields of the AWS, Azure and GCP provider specs
+type instanceTypeProviderSpec struct {
+	InstanceType string `json:"instanceType,omitempty"`
+	VMSize       string `json:"vmSize,omitempty"`
+	MachineType  string `json:"machineType,omitempty"`
+}
+
+func (s instanceTypeProviderSpec) instanceType() string {
+	switch {
+	case s.InstanceType != "":
+		return s.InstanceType
+	case s.VMSize != "":
+		return s.VMSize
+	}
+	return s.MachineType
+}
+
+// ControlPlaneMachineSetReconciler reconciles the ControlPlaneMachineSet
+type ControlPlaneMachineSetReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+	// Recorder records an Event on the ControlPlaneMachineSet when its state changes. No Events are recorded
+	// without it.
+	Recorder record.EventRecorder
+	// state is the last state reconciled, empty until the first reconcile
+	state string
+}
+
+// Reconcile compares the instance type of the ControlPlaneMachineSet template with the instance types of the
+// master Machines, which differ while a resize of the control plane has not rolled out, and the state of the
+// ControlPlaneMachineSet
+func (r *ControlPlaneMachineSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling ControlPlaneMachineSet")
+
+	cpms := &unstructured.Unstructured{}
+	cpms.SetGroupVersionKind(ControlPlaneMachineSetKind.GroupVersionKind())
+	err := r.Client.Get(ctx, types.NamespacedName{Namespace: utils.MachineAPINamespace, Name: controlPlaneMachineSetName}, cpms)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// without a ControlPlaneMachineSet the control plane is not resized
+			r.Metrics.DeleteCPMS(r.ClusterId)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	state, _, _ := unstructured.NestedString(cpms.Object, "spec", "state")
+	if state == "" {
+		state = inactiveState
+	}
+	if err := r.Metrics.SetCPMSState(r.ClusterId, state); err != nil {
+		reqLogger.Error(err, "unable to report the ControlPlaneMachineSet state")
+	}
+	// the state before the exporter started is unknown, so the first reconcile records no Event
+	if r.Recorder != nil && r.state != "" && r.state != state {
+		r.Recorder.Eventf(cpms, corev1.EventTypeNormal, stateChangedReason, "ControlPlaneMachineSet changed from %s to %s", r.state, state)
+	}
+	r.state = state
+	providerSpec, found, err := unstructured.NestedMap(cpms.Object, "spec", "template", "machines_v1beta1_machine_openshift_io", "spec", "providerSpec", "value")
+	if err != nil || !found {
+		reqLogger.Info("ControlPlaneMachineSet has no machine providerSpec")
+		return ctrl.Result{}, nil
+	}
+	raw, err := json.Marshal(providerSpec)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	desired, err := instanceType(raw)
+	if err != nil || desired == "" {
+		reqLogger.Info("ControlPlaneMachineSet has no instance type")
+		return ctrl.Result{}, nil
+	}
+
+	machines := &machinev1beta1.MachineList{}
+	if err := r.Client.List(ctx, machines, client.InNamespace(utils.MachineAPINamespace), client.MatchingLabels{machineRoleLabel: masterRole}); err != nil {
+		return ctrl.Result{}, err
+	}
+	mismatch := false
+	for _, m := range machines.Items {
+		if m.DeletionTimestamp != nil || m.Spec.ProviderSpec.Value == nil {
+			continue
+		}
+		actual, err := instanceType(m.Spec.ProviderSpec.Value.Raw)
+		if err != nil {
+			reqLogger.Error(err, "invalid providerSpec", "Machine", m.Name)
+			continue
+		}
+		mismatch = mismatch || actual != desired
+	}
+	r.Metrics.SetCPMSInstanceTypeMismatch(r.ClusterId, mismatch)
+	return ctrl.Result{}, nil
+}
+
+func instanceType(providerSpec []byte) (string, error) {
+	spec := instanceTypeProviderSpec{}
+	if err := json.Unmarshal(providerSpec, &spec); err != nil {
+		return "", err
+	}
+	return spec.instanceType(), nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ControlPlaneMachineSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	cpms := &unstructured.Unstructured{}
+	cpms.SetGroupVersionKind(ControlPlaneMachineSetKind.GroupVersionKind())
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("ControlPlaneMachineSet")).
+		For(cpms).
+		Watches(&source.Kind{Type: &machinev1beta1.Machine{}}, handler.EnqueueRequestsFromMapFunc(func(client.Object) []ctrl.Request {
+			return []ctrl.Request{{NamespacedName: types.NamespacedName{Namespace: utils.MachineAPINamespace, Name: controlPlaneMachineSetName}}}
+		})).
+		WithEventFilter(utils.IgnoreStatusUpdates()).
+		Complete(r)
+}
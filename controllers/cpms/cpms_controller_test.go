@@ -0,0 +1,185 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpms
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics/metricstest"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestControlPlaneMachineSet(instanceType string) *unstructured.Unstructured {
+	cpms := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"machines_v1beta1_machine_openshift_io": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"providerSpec": map[string]interface{}{
+							"value": map[string]interface{}{"kind": "AWSMachineProviderConfig", "instanceType": instanceType},
+						},
+					},
+				},
+			},
+		},
+	}}
+	cpms.SetGroupVersionKind(ControlPlaneMachineSetKind.GroupVersionKind())
+	cpms.SetNamespace(utils.MachineAPINamespace)
+	cpms.SetName(controlPlaneMachineSetName)
+	return cpms
+}
+
+func makeTestMachine(name, role, providerSpec string) *machinev1beta1.Machine {
+	m := &machinev1beta1.Machine{ObjectMeta: metav1.ObjectMeta{
+		Name:      name,
+		Namespace: utils.MachineAPINamespace,
+		Labels:    map[string]string{machineRoleLabel: role},
+	}}
+	m.Spec.ProviderSpec.Value = &runtime.RawExtension{Raw: []byte(providerSpec)}
+	return m
+}
+
+func TestReconcileControlPlaneMachineSet_Reconcile(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, machinev1beta1.Install(s))
+	cpms := makeTestControlPlaneMachineSet("m5.xlarge")
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		cpms,
+		makeTestMachine("master-0", masterRole, `{"kind":"AWSMachineProviderConfig","instanceType":"m5.xlarge"}`),
+		makeTestMachine("master-1", masterRole, `{"kind":"AWSMachineProviderConfig","instanceType":"m5.xlarge"}`),
+		makeTestMachine("worker-0", "worker", `{"kind":"AWSMachineProviderConfig","instanceType":"m5.large"}`),
+	).Build()
+	recorder := record.NewFakeRecorder(10)
+	aggregator := metrics.NewMetricsAggregator("cluster-id")
+	reconciler := &ControlPlaneMachineSetReconciler{
+		Client:    fakeClient,
+		Metrics:   NewMetrics(aggregator),
+		ClusterId: "cluster-id",
+		Recorder:  recorder,
+	}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: utils.MachineAPINamespace, Name: controlPlaneMachineSetName}}
+	_, err := reconciler.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	// a ControlPlaneMachineSet without state is inactive
+	metricstest.RequireGolden(t, aggregator, "inactive")
+	require.Empty(t, recorder.Events, "the state before the first reconcile is unknown")
+
+	// a resize of the control plane which has not rolled out yet
+	require.NoError(t, unstructured.SetNestedField(cpms.Object, "m5.2xlarge",
+		"spec", "template", "machines_v1beta1_machine_openshift_io", "spec", "providerSpec", "value", "instanceType"))
+	require.NoError(t, unstructured.SetNestedField(cpms.Object, activeState, "spec", "state"))
+	require.NoError(t, fakeClient.Update(context.TODO(), cpms))
+	_, err = reconciler.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	// the instance type is not a label, so a resize does not leave a series of the previous instance type behind
+	metricstest.RequireGolden(t, aggregator, "active-resize")
+	require.Equal(t, "Normal StateChanged ControlPlaneMachineSet changed from Inactive to Active", <-recorder.Events)
+
+	// a deleted ControlPlaneMachineSet
+	require.NoError(t, fakeClient.Delete(context.TODO(), cpms))
+	_, err = reconciler.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	metricstest.RequireGolden(t, aggregator, "deleted")
+}
+
+func TestInstanceType(t *testing.T) {
+	for providerSpec, expected := range map[string]string{
+		`{"kind":"AWSMachineProviderConfig","instanceType":"m5.xlarge"}`:   "m5.xlarge",
+		`{"kind":"AzureMachineProviderSpec","vmSize":"Standard_D8s_v3"}`:   "Standard_D8s_v3",
+		`{"kind":"GCPMachineProviderSpec","machineType":"custom-8-32768"}`: "custom-8-32768",
+		`{"kind":"VSphereMachineProviderSpec"}`:                            "",
+	} {
+		actual, err := instanceType([]byte(providerSpec))
+		require.NoError(t, err)
+		require.Equal(t, expected, actual, providerSpec)
+	}
+}
+
+// providerSpecSeeds are provider specs of the platforms the instance type is read from, and of others
+var providerSpecSeeds = []string{
+	`{"kind":"AWSMachineProviderConfig","instanceType":"m5.xlarge"}`,
+	`{"kind":"AzureMachineProviderSpec","vmSize":"Standard_D8s_v3"}`,
+	`{"kind":"GCPMachineProviderSpec","machineType":"custom-8-32768"}`,
+	`{"kind":"VSphereMachineProviderSpec","numCPUs":4}`,
+	`{"instanceType":7}`,
+	`{"vmSize":null,"machineType":["n2-standard-4"]}`,
+	`[]`,
+	`null`,
+	`{`,
+	``,
+}
+
+func FuzzInstanceType(f *testing.F) {
+	for _, seed := range providerSpecSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, providerSpec []byte) {
+		actual, err := instanceType(providerSpec)
+		if err != nil {
+			require.Empty(t, actual)
+			return
+		}
+		spec := instanceTypeProviderSpec{}
+		require.NoError(t, json.Unmarshal(providerSpec, &spec))
+		require.Contains(t, []string{spec.InstanceType, spec.VMSize, spec.MachineType}, actual)
+	})
+}
+
+// FuzzReconcileControlPlaneMachineSet reconciles malformed provider specs of the ControlPlaneMachineSet and of the
+// master Machines, which must be reported as invalid rather than panic the reconciler
+func FuzzReconcileControlPlaneMachineSet(f *testing.F) {
+	for _, seed := range providerSpecSeeds {
+		f.Add(seed, `{"kind":"AWSMachineProviderConfig","instanceType":"m5.xlarge"}`)
+		f.Add(`{"kind":"AWSMachineProviderConfig","instanceType":"m5.xlarge"}`, seed)
+	}
+	s := runtime.NewScheme()
+	require.NoError(f, machinev1beta1.Install(s))
+	f.Fuzz(func(t *testing.T, cpmsProviderSpec, machineProviderSpec string) {
+		// the API server only stores JSON, so the provider spec of a Machine is valid JSON
+		if !json.Valid([]byte(machineProviderSpec)) {
+			t.Skip()
+		}
+		cpms := makeTestControlPlaneMachineSet("")
+		var value interface{} = cpmsProviderSpec
+		if json.Valid([]byte(cpmsProviderSpec)) {
+			require.NoError(t, json.Unmarshal([]byte(cpmsProviderSpec), &value))
+		}
+		require.NoError(t, unstructured.SetNestedField(cpms.Object, value,
+			"spec", "template", "machines_v1beta1_machine_openshift_io", "spec", "providerSpec", "value"))
+		reconciler := &ControlPlaneMachineSetReconciler{
+			Client: fake.NewClientBuilder().WithScheme(s).WithObjects(
+				cpms,
+				makeTestMachine("master-0", masterRole, machineProviderSpec),
+			).Build(),
+			Metrics:   NewMetrics(metrics.NewMetricsAggregator("cluster-id")),
+			ClusterId: "cluster-id",
+		}
+		_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+			NamespacedName: types.NamespacedName{Namespace: utils.MachineAPINamespace, Name: controlPlaneMachineSetName},
+		})
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpms
+
+import (
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+// Metrics report the state of the ControlPlaneMachineSet and whether the master machines have its instance type,
+// which they do not while a control plane resize is rolled out or after it stalled
+type Metrics struct {
+	metrics.MetricSet
+	instanceTypeMismatch *metrics.Gauges
+	state                *metrics.Enums
+}
+
+// NewMetrics registers the ControlPlaneMachineSet metrics with the alerts recommended on them
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		instanceTypeMismatch: a.NewGauges("cpms_instance_type_mismatch", "Indicates if the instance type of a master machine differs from the ControlPlaneMachineSet"),
+		state: a.NewEnums("cpms_state", "Indicates the state of the ControlPlaneMachineSet, 1 for the current state",
+			"state", []string{activeState, inactiveState}),
+	}
+	m.MetricSet = metrics.NewMetricSet("ControlPlaneMachineSet", m.instanceTypeMismatch, m.state).WithAlertRules(
+		metrics.AlertRule{
+			Alert:       "ControlPlaneMachineSetInstanceTypeMismatch",
+			Expr:        "cpms_instance_type_mismatch == 1",
+			For:         time.Hour,
+			Severity:    "warning",
+			Summary:     "A master machine does not have the instance type of the ControlPlaneMachineSet",
+			Description: "The instance type of a master machine has differed from the ControlPlaneMachineSet for an hour, the control plane may not be resized as expected.",
+		},
+		metrics.AlertRule{
+			Alert:       "ControlPlaneMachineSetInactive",
+			Expr:        `cpms_state{state="` + inactiveState + `"} == 1`,
+			For:         24 * time.Hour,
+			Severity:    "info",
+			Summary:     "The ControlPlaneMachineSet is inactive",
+			Description: "The ControlPlaneMachineSet has been inactive for a day, failed master machines are not replaced automatically.",
+		},
+	)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetCPMSInstanceTypeMismatch(uuid string, mismatch bool) {
+	m.instanceTypeMismatch.With(uuid).Set(metrics.BoolToFloat(mismatch))
+}
+
+func (m *Metrics) SetCPMSState(uuid string, state string) error {
+	return m.state.Set(state, uuid)
+}
+
+// DeleteCPMS deletes the series of the ControlPlaneMachineSet, after it was deleted
+func (m *Metrics) DeleteCPMS(uuid string) {
+	m.DeleteSeries(uuid)
+}
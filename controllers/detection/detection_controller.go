@@ -0,0 +1,82 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package detection
+
+import (
+	"context"
+	"strings"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/detection"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("controller_detection")
+
+// DetectionReconciler evaluates a single configured detection against all objects of its kind
+type DetectionReconciler struct {
+	client.Client
+	Scheme            *runtime.Scheme
+	MetricsAggregator metrics.MetricsAggregator
+	ClusterId         string
+	Detection         detection.Detection
+}
+
+// Reconcile lists all objects of the detection's kind and reports how many of them match
+func (r *DetectionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name, "Detection", r.Detection.Name)
+	reqLogger.Info("Reconciling Detection")
+
+	list := &unstructured.UnstructuredList{}
+	gvk := r.Detection.GroupVersionKind()
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	var opts []client.ListOption
+	if r.Detection.Namespace != "" {
+		opts = append(opts, client.InNamespace(r.Detection.Namespace))
+	}
+	if err := r.Client.List(ctx, list, opts...); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	count := 0
+	for i := range list.Items {
+		matches, err := r.Detection.Matches(&list.Items[i])
+		if err != nil {
+			// a single object failing to evaluate should not hide the result for all others
+			reqLogger.Error(err, "failed to evaluate detection", "Object.Namespace", list.Items[i].GetNamespace(), "Object.Name", list.Items[i].GetName())
+			continue
+		}
+		if matches {
+			count++
+		}
+	}
+	r.MetricsAggregator.SetDetectionMatchCount(r.ClusterId, r.Detection.Name, count)
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DetectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(r.Detection.GroupVersionKind())
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("Detection")).
+		Named("detection_" + strings.ReplaceAll(r.Detection.Name, "-", "_")).
+		For(obj).
+		Complete(r)
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package detection
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/detection"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testDetections = `
+detections:
+  - name: customer_configmaps_with_ca
+    apiVersion: v1
+    kind: ConfigMap
+    namespace: openshift-config
+    jsonPath: "{.data['ca-bundle\\.crt']}"
+    operator: Exists
+`
+
+func makeTestConfigMap(name, namespace string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Data:       data,
+	}
+}
+
+func TestReconcileDetection_Reconcile(t *testing.T) {
+	detections, err := detection.Parse([]byte(testDetections))
+	require.NoError(t, err)
+
+	metricsAggregator := metrics.NewMetricsAggregator("cluster-id")
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		makeTestConfigMap("user-ca-bundle", "openshift-config", map[string]string{"ca-bundle.crt": "..."}),
+		makeTestConfigMap("other-ca-bundle", "openshift-config", map[string]string{"ca-bundle.crt": "..."}),
+		makeTestConfigMap("no-ca", "openshift-config", map[string]string{"foo": "bar"}),
+		makeTestConfigMap("other-namespace", "default", map[string]string{"ca-bundle.crt": "..."}),
+	).Build()
+	reconciler := DetectionReconciler{
+		Client:            fakeClient,
+		MetricsAggregator: metricsAggregator,
+		ClusterId:         "cluster-id",
+		Detection:         detections[0],
+	}
+	_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: "openshift-config", Name: "user-ca-bundle"},
+	})
+	require.NoError(t, err)
+
+	err = testutil.CollectAndCompare(metricsAggregator.GetDetectionMetric(), strings.NewReader(`
+# HELP detection_match_count Indicates the number of objects matching a configured detection
+# TYPE detection_match_count gauge
+detection_match_count{_id="cluster-id",detection="customer_configmaps_with_ca",name="osd_exporter"} 2
+`))
+	require.NoError(t, err)
+}
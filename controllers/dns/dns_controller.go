@@ -0,0 +1,76 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"context"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("controller_dns")
+
+// DNSReconciler reconciles the DNS operator config
+type DNSReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+}
+
+// Reconcile reports the number of custom forwarding servers and if the upstream resolvers were customized
+func (r *DNSReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling DNS")
+
+	instance := &operatorv1.DNS{}
+	if err := r.Client.Get(ctx, req.NamespacedName, instance); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	r.Metrics.SetDNSForwarding(r.ClusterId, len(instance.Spec.Servers), hasCustomUpstreamResolvers(instance.Spec.UpstreamResolvers))
+	return ctrl.Result{}, nil
+}
+
+// hasCustomUpstreamResolvers returns true unless the default of forwarding sequentially to the node's
+// /etc/resolv.conf is configured
+func hasCustomUpstreamResolvers(resolvers operatorv1.UpstreamResolvers) bool {
+	if resolvers.Policy != "" && resolvers.Policy != operatorv1.SequentialForwardingPolicy {
+		return true
+	}
+	for _, upstream := range resolvers.Upstreams {
+		if upstream.Type != operatorv1.SystemResolveConfType {
+			return true
+		}
+	}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DNSReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("DNS")).
+		For(&operatorv1.DNS{}).
+		WithEventFilter(utils.IgnoreStatusUpdates()).
+		Complete(r)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"context"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestDNS(servers []operatorv1.Server, resolvers operatorv1.UpstreamResolvers) *operatorv1.DNS {
+	return &operatorv1.DNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: operatorv1.DNSSpec{
+			Servers:           servers,
+			UpstreamResolvers: resolvers,
+		},
+	}
+}
+
+func TestReconcileDNS_Reconcile(t *testing.T) {
+	defaultResolvers := operatorv1.UpstreamResolvers{
+		Upstreams: []operatorv1.Upstream{{Type: operatorv1.SystemResolveConfType}},
+		Policy:    operatorv1.SequentialForwardingPolicy,
+	}
+	for _, tc := range []struct {
+		name              string
+		dns               *operatorv1.DNS
+		expectedServers   int
+		expectedResolvers int
+	}{
+		{
+			name: "defaults",
+			dns:  makeTestDNS(nil, defaultResolvers),
+		},
+		{
+			name: "custom forwarders",
+			dns: makeTestDNS([]operatorv1.Server{
+				{Name: "corp", Zones: []string{"corp.example.com"}, ForwardPlugin: operatorv1.ForwardPlugin{Upstreams: []string{"10.0.0.53"}}},
+				{Name: "lab", Zones: []string{"lab.example.com"}, ForwardPlugin: operatorv1.ForwardPlugin{Upstreams: []string{"10.1.0.53"}}},
+			}, defaultResolvers),
+			expectedServers: 2,
+		},
+		{
+			name: "network upstream",
+			dns: makeTestDNS(nil, operatorv1.UpstreamResolvers{
+				Upstreams: []operatorv1.Upstream{{Type: operatorv1.NetworkResolverType, Address: "10.0.0.53", Port: 53}},
+			}),
+			expectedResolvers: 1,
+		},
+		{
+			name: "round robin policy",
+			dns: makeTestDNS(nil, operatorv1.UpstreamResolvers{
+				Upstreams: defaultResolvers.Upstreams,
+				Policy:    operatorv1.RoundRobinForwardingPolicy,
+			}),
+			expectedResolvers: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, operatorv1.Install(scheme))
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.dns).Build()
+			reconciler := &DNSReconciler{
+				Client:    fakeClient,
+				Metrics:   NewMetrics(metrics.NewMetricsAggregator("cluster-id")),
+				ClusterId: "cluster-id",
+			}
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: "default"},
+			})
+			require.NoError(t, err)
+			require.EqualValues(t, tc.expectedServers, testutil.ToFloat64(reconciler.Metrics.forwarders))
+			require.EqualValues(t, tc.expectedResolvers, testutil.ToFloat64(reconciler.Metrics.upstreamResolvers))
+		})
+	}
+}
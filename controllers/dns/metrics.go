@@ -0,0 +1,43 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+// Metrics report the DNS forwarding the customer configured on the DNS operator, which can break the resolution of
+// cluster and cloud provider names when the forwarded servers are unreachable
+type Metrics struct {
+	metrics.MetricSet
+	forwarders        *metrics.Gauges
+	upstreamResolvers *metrics.Gauges
+}
+
+// NewMetrics registers the forwarder count and whether the upstream resolvers were changed
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		forwarders: a.NewGauges("dns_custom_forwarder_count", "Indicates the number of custom DNS forwarding servers configured on the DNS operator"),
+		upstreamResolvers: a.NewGauges("dns_custom_upstream_resolvers_configured",
+			"Indicates if the DNS operator upstream resolvers or their policy differ from the defaults"),
+	}
+	m.MetricSet = metrics.NewMetricSet("DNS", m.forwarders, m.upstreamResolvers)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetDNSForwarding(uuid string, forwarderCount int, customUpstreamResolvers bool) {
+	m.forwarders.With(uuid).Set(float64(forwarderCount))
+	m.upstreamResolvers.With(uuid).Set(metrics.BoolToFloat(customUpstreamResolvers))
+}
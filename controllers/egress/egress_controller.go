@@ -0,0 +1,95 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package egress
+
+import (
+	"context"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/apiversion"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("controller_egress")
+
+// The OVN-Kubernetes egress resources. There are no Go types for them in the OpenShift API, so they are read as unstructured objects.
+var (
+	EgressIPKind       = apiversion.NewKind(schema.GroupVersionKind{Group: "k8s.ovn.org", Version: "v1", Kind: "EgressIP"})
+	EgressFirewallKind = apiversion.NewKind(schema.GroupVersionKind{Group: "k8s.ovn.org", Version: "v1", Kind: "EgressFirewall"})
+)
+
+// EgressReconciler reconciles EgressIP and EgressFirewall objects
+type EgressReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+}
+
+// Reconcile lists all EgressIPs and EgressFirewalls and reports their count and the number of firewall rules per namespace
+func (r *EgressReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling Egress")
+
+	egressIPs := newList(EgressIPKind)
+	if err := r.Client.List(ctx, egressIPs); err != nil {
+		return ctrl.Result{}, err
+	}
+	egressFirewalls := newList(EgressFirewallKind)
+	if err := r.Client.List(ctx, egressFirewalls); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	ruleCounts := make(map[string]int)
+	for _, firewall := range egressFirewalls.Items {
+		rules, _, err := unstructured.NestedSlice(firewall.Object, "spec", "egress")
+		if err != nil {
+			reqLogger.Error(err, "failed to read egress rules", "EgressFirewall.Namespace", firewall.GetNamespace())
+			continue
+		}
+		ruleCounts[firewall.GetNamespace()] += len(rules)
+	}
+	r.Metrics.SetEgress(r.ClusterId, len(egressIPs.Items), ruleCounts)
+	return ctrl.Result{}, nil
+}
+
+func newList(kind *apiversion.Kind) *unstructured.UnstructuredList {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(kind.ListGroupVersionKind())
+	return list
+}
+
+func newObject(kind *apiversion.Kind) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(kind.GroupVersionKind())
+	return obj
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *EgressReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("Egress")).
+		Named("egress").
+		For(newObject(EgressIPKind)).
+		Watches(&source.Kind{Type: newObject(EgressFirewallKind)}, &handler.EnqueueRequestForObject{}).
+		WithEventFilter(utils.IgnoreStatusUpdates()).
+		Complete(r)
+}
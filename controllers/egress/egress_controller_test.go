@@ -0,0 +1,84 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package egress
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testClusterId = "cluster-id"
+
+func makeTestEgressIP(name string) *unstructured.Unstructured {
+	obj := newObject(EgressIPKind)
+	obj.SetName(name)
+	obj.Object["spec"] = map[string]interface{}{
+		"egressIPs": []interface{}{"10.0.0.10"},
+	}
+	return obj
+}
+
+func makeTestEgressFirewall(namespace string, rules int) *unstructured.Unstructured {
+	obj := newObject(EgressFirewallKind)
+	obj.SetName("default")
+	obj.SetNamespace(namespace)
+	egress := make([]interface{}, rules)
+	for i := range egress {
+		egress[i] = map[string]interface{}{
+			"type": "Deny",
+			"to":   map[string]interface{}{"cidrSelector": "0.0.0.0/0"},
+		}
+	}
+	obj.Object["spec"] = map[string]interface{}{"egress": egress}
+	return obj
+}
+
+func TestReconcileEgress_Reconcile(t *testing.T) {
+	objects := []client.Object{
+		makeTestEgressIP("egressip-1"),
+		makeTestEgressIP("egressip-2"),
+		makeTestEgressFirewall("customer-a", 3),
+		makeTestEgressFirewall("customer-b", 1),
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(objects...).Build()
+	reconciler := &EgressReconciler{
+		Client:    fakeClient,
+		Metrics:   NewMetrics(metrics.NewMetricsAggregator(testClusterId)),
+		ClusterId: testClusterId,
+	}
+	_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "egressip-1"},
+	})
+	require.NoError(t, err)
+
+	require.EqualValues(t, 2, testutil.ToFloat64(reconciler.Metrics.egressIPs))
+	expected := `
+# HELP egressfirewall_rule_count Indicates the number of EgressFirewall rules by namespace
+# TYPE egressfirewall_rule_count gauge
+egressfirewall_rule_count{_id="cluster-id",name="osd_exporter",namespace="customer-a"} 3
+egressfirewall_rule_count{_id="cluster-id",name="osd_exporter",namespace="customer-b"} 1
+`
+	require.NoError(t, testutil.CollectAndCompare(reconciler.Metrics.firewallRules, strings.NewReader(expected)))
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package egress
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+const namespaceLabel = "namespace"
+
+// Metrics report the egress configuration of OVNKubernetes added by the customer: the EgressIP objects assigning
+// fixed source addresses, and the EgressFirewall rules restricting the traffic leaving each namespace
+type Metrics struct {
+	metrics.MetricSet
+	egressIPs     *metrics.Gauges
+	firewallRules *metrics.Gauges
+}
+
+// NewMetrics registers the EgressIP count and the EgressFirewall rule count by namespace
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		egressIPs:     a.NewGauges("egressip_count", "Indicates the number of EgressIP objects"),
+		firewallRules: a.NewGauges("egressfirewall_rule_count", "Indicates the number of EgressFirewall rules by namespace", namespaceLabel),
+	}
+	m.MetricSet = metrics.NewMetricSet("Egress", m.egressIPs, m.firewallRules)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetEgress(uuid string, egressIPCount int, firewallRuleCounts map[string]int) {
+	m.egressIPs.With(uuid).Set(float64(egressIPCount))
+	m.firewallRules.SetSnapshot(uuid, metrics.CountSamples(firewallRuleCounts))
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporterconfig
+
+import (
+	"context"
+
+	"github.com/openshift/osd-metrics-exporter/api/v1alpha1"
+	operatorConfig "github.com/openshift/osd-metrics-exporter/config"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+var log = logf.Log.WithName("controller_exporterconfig")
+
+var configName = types.NamespacedName{Namespace: operatorConfig.OperatorNamespace, Name: v1alpha1.MetricsExporterConfigName}
+
+// LoadSpec reads the spec of the MetricsExporterConfig, the spec is empty if there is none or its CRD is not installed
+func LoadSpec(ctx context.Context, reader client.Reader) (v1alpha1.MetricsExporterConfigSpec, error) {
+	config := &v1alpha1.MetricsExporterConfig{}
+	if err := reader.Get(ctx, configName, config); err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return v1alpha1.MetricsExporterConfigSpec{}, nil
+		}
+		return v1alpha1.MetricsExporterConfigSpec{}, err
+	}
+	return config.Spec, nil
+}
+
+// ExporterConfigReconciler restarts the exporter when the spec of the MetricsExporterConfig changes, as the
+// controllers and the cache are set up once. It records the applied generation in the status.
+type ExporterConfigReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// Applied is the spec the exporter was started with
+	Applied v1alpha1.MetricsExporterConfigSpec
+	// Restart stops the exporter, which is then started again with the changed spec
+	Restart func()
+}
+
+func (r *ExporterConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling MetricsExporterConfig")
+
+	config := &v1alpha1.MetricsExporterConfig{}
+	found := true
+	if err := r.Client.Get(ctx, configName, config); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		found = false
+	}
+	if !equality.Semantic.DeepEqual(r.Applied, config.Spec) {
+		reqLogger.Info("MetricsExporterConfig changed, restarting to apply it")
+		r.Restart()
+		return ctrl.Result{}, nil
+	}
+	if !found || config.Status.ObservedGeneration == config.Generation {
+		return ctrl.Result{}, nil
+	}
+	config.Status.ObservedGeneration = config.Generation
+	return ctrl.Result{}, r.Client.Status().Update(ctx, config)
+}
+
+func (r *ExporterConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("ExporterConfig")).
+		For(&v1alpha1.MetricsExporterConfig{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return o.GetNamespace() == configName.Namespace && o.GetName() == configName.Name
+		}))).
+		Complete(r)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporterconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestConfig(generation int64, spec v1alpha1.MetricsExporterConfigSpec) *v1alpha1.MetricsExporterConfig {
+	return &v1alpha1.MetricsExporterConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: configName.Namespace, Name: configName.Name, Generation: generation},
+		Spec:       spec,
+	}
+}
+
+func TestLoadSpec(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(s))
+	spec := v1alpha1.MetricsExporterConfigSpec{Controllers: []string{"Proxy"}, ResyncPeriod: &metav1.Duration{Duration: time.Hour}}
+	loaded, err := LoadSpec(context.TODO(), fake.NewClientBuilder().WithScheme(s).WithObjects(makeTestConfig(1, spec)).Build())
+	require.NoError(t, err)
+	require.Equal(t, spec, loaded)
+
+	loaded, err = LoadSpec(context.TODO(), fake.NewClientBuilder().WithScheme(s).Build())
+	require.NoError(t, err)
+	require.Equal(t, v1alpha1.MetricsExporterConfigSpec{}, loaded)
+}
+
+func TestReconcileExporterConfig_Reconcile(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(s))
+	applied := v1alpha1.MetricsExporterConfigSpec{Controllers: []string{"Proxy"}}
+
+	for _, tc := range []struct {
+		name            string
+		config          *v1alpha1.MetricsExporterConfig
+		expectedRestart bool
+	}{
+		{name: "unchanged", config: makeTestConfig(2, applied)},
+		{name: "changed", config: makeTestConfig(3, v1alpha1.MetricsExporterConfigSpec{Controllers: []string{"Proxy", "Node"}}), expectedRestart: true},
+		{name: "deleted", expectedRestart: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(s)
+			if tc.config != nil {
+				builder = builder.WithObjects(tc.config)
+			}
+			fakeClient := builder.Build()
+			restarted := false
+			reconciler := &ExporterConfigReconciler{
+				Client:  fakeClient,
+				Scheme:  s,
+				Applied: applied,
+				Restart: func() { restarted = true },
+			}
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: configName})
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedRestart, restarted)
+			if tc.config == nil {
+				return
+			}
+
+			// the generation is only observed once it is applied
+			config := &v1alpha1.MetricsExporterConfig{}
+			require.NoError(t, fakeClient.Get(context.TODO(), configName, config))
+			if tc.expectedRestart {
+				require.Zero(t, config.Status.ObservedGeneration)
+			} else {
+				require.Equal(t, tc.config.Generation, config.Status.ObservedGeneration)
+			}
+		})
+	}
+}
@@ -18,9 +18,10 @@ import (
 
 	userv1 "github.com/openshift/api/user/v1"
 	"github.com/openshift/osd-metrics-exporter/controllers/utils"
-	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -30,8 +31,13 @@ import (
 )
 
 const (
-	clusterAdminGroupName = "cluster-admins"
+	// ClusterAdminGroupName is the only Group which is reconciled
+	ClusterAdminGroupName = "cluster-admins"
 	finalizer             = "osd-metrics-exporter/finalizer"
+	// clusterAdminGrantedReason and clusterAdminRevokedReason are the reasons of the Events recorded when the
+	// cluster-admins Group gets its first user or loses its last one
+	clusterAdminGrantedReason = "ClusterAdminGranted"
+	clusterAdminRevokedReason = "ClusterAdminRevoked"
 )
 
 var log = logf.Log.WithName("controller_group")
@@ -39,9 +45,14 @@ var log = logf.Log.WithName("controller_group")
 // GroupReconciler reconciles a Group object
 type GroupReconciler struct {
 	client.Client
-	Scheme            *runtime.Scheme
-	MetricsAggregator *metrics.AdoptionMetricsAggregator
-	ClusterId         string
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+	// Recorder records an Event on the Group when cluster-admin is granted or revoked. No Events are recorded
+	// without it.
+	Recorder record.EventRecorder
+	// clusterAdmin is whether cluster-admin was granted on the last reconcile, nil until the first reconcile
+	clusterAdmin *bool
 }
 
 // Reconcile reads that state of the cluster for a Group object and makes changes based on the state read
@@ -70,9 +81,9 @@ func (r *GroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 				return ctrl.Result{}, err
 			}
 		}
-		r.MetricsAggregator.SetClusterAdmin(r.ClusterId, len(group.Users) > 0)
+		r.setClusterAdmin(group, len(group.Users) > 0)
 	} else {
-		r.MetricsAggregator.SetClusterAdmin(r.ClusterId, false)
+		r.setClusterAdmin(group, false)
 		if utils.ContainsString(group.Finalizers, finalizer) {
 			controllerutil.RemoveFinalizer(group, finalizer)
 			if err := r.Client.Update(ctx, group); err != nil {
@@ -83,22 +94,37 @@ func (r *GroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	return ctrl.Result{}, nil
 }
 
+// setClusterAdmin reports whether cluster-admin is granted, and records an Event when it changed since the last
+// reconcile. Whether it was granted before the exporter started is unknown, so the first reconcile records no Event.
+func (r *GroupReconciler) setClusterAdmin(group *userv1.Group, clusterAdmin bool) {
+	r.Metrics.SetClusterAdmin(r.ClusterId, clusterAdmin)
+	if r.Recorder != nil && r.clusterAdmin != nil && *r.clusterAdmin != clusterAdmin {
+		if clusterAdmin {
+			r.Recorder.Event(group, corev1.EventTypeNormal, clusterAdminGrantedReason, "cluster-admin was granted to the users of the cluster-admins Group")
+		} else {
+			r.Recorder.Event(group, corev1.EventTypeNormal, clusterAdminRevokedReason, "cluster-admin was revoked, the cluster-admins Group has no users")
+		}
+	}
+	r.clusterAdmin = &clusterAdmin
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *GroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("Group")).
 		For(&userv1.Group{}).
 		WithEventFilter(predicate.Funcs{
 			CreateFunc: func(evt event.CreateEvent) bool {
-				return evt.Object.GetName() == clusterAdminGroupName
+				return evt.Object.GetName() == ClusterAdminGroupName
 			},
 			DeleteFunc: func(evt event.DeleteEvent) bool {
-				return evt.Object.GetName() == clusterAdminGroupName
+				return evt.Object.GetName() == ClusterAdminGroupName
 			},
 			UpdateFunc: func(evt event.UpdateEvent) bool {
-				return evt.ObjectNew.GetName() == clusterAdminGroupName
+				return evt.ObjectNew.GetName() == ClusterAdminGroupName
 			},
 			GenericFunc: func(evt event.GenericEvent) bool {
-				return evt.Object.GetName() == clusterAdminGroupName
+				return evt.Object.GetName() == ClusterAdminGroupName
 			},
 		}).
 		Complete(r)
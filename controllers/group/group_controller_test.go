@@ -3,7 +3,6 @@ package group
 import (
 	"context"
 	"testing"
-	"time"
 
 	userv1 "github.com/openshift/api/user/v1"
 	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
@@ -12,6 +11,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -45,7 +45,7 @@ func TestReconcileGroup_Reconcile(t *testing.T) {
 			err := userv1.Install(scheme.Scheme)
 			require.NoError(t, err)
 			group := &userv1.Group{
-				ObjectMeta: metav1.ObjectMeta{Name: clusterAdminGroupName},
+				ObjectMeta: metav1.ObjectMeta{Name: ClusterAdminGroupName},
 				Users:      tc.users,
 			}
 			if tc.delete {
@@ -54,23 +54,50 @@ func TestReconcileGroup_Reconcile(t *testing.T) {
 			}
 			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(group).Build()
 			reconcileGroup := &GroupReconciler{
-				Client:            fakeClient,
-				MetricsAggregator: metrics.NewMetricsAggregator(time.Second*10, tc.clusterId),
+				Client:  fakeClient,
+				Metrics: NewMetrics(metrics.NewMetricsAggregator(tc.clusterId)),
 			}
 			_, err = reconcileGroup.Reconcile(context.TODO(), ctrl.Request{
-				NamespacedName: types.NamespacedName{Name: clusterAdminGroupName},
+				NamespacedName: types.NamespacedName{Name: ClusterAdminGroupName},
 			})
 			require.NoError(t, err)
-			err = fakeClient.Get(context.Background(), client.ObjectKey{Name: clusterAdminGroupName}, group)
+			err = fakeClient.Get(context.Background(), client.ObjectKey{Name: ClusterAdminGroupName}, group)
 			require.NoError(t, err)
 			if tc.delete {
 				require.NotContains(t, group.Finalizers, finalizer)
 			} else {
 				require.Contains(t, group.Finalizers, finalizer)
 			}
-			metric := reconcileGroup.MetricsAggregator.GetClusterRoleMetric()
-			value := testutil.ToFloat64(metric)
+			value := testutil.ToFloat64(reconcileGroup.Metrics.clusterAdmin)
 			require.EqualValues(t, tc.result, value)
 		})
 	}
 }
+
+func TestReconcileGroup_Events(t *testing.T) {
+	require.NoError(t, userv1.Install(scheme.Scheme))
+	group := &userv1.Group{ObjectMeta: metav1.ObjectMeta{Name: ClusterAdminGroupName}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(group).Build()
+	recorder := record.NewFakeRecorder(10)
+	reconcileGroup := &GroupReconciler{
+		Client:   fakeClient,
+		Metrics:  NewMetrics(metrics.NewMetricsAggregator("")),
+		Recorder: recorder,
+	}
+	reconcile := func(users ...string) {
+		require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: ClusterAdminGroupName}, group))
+		group.Users = users
+		require.NoError(t, fakeClient.Update(context.TODO(), group))
+		_, err := reconcileGroup.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: ClusterAdminGroupName}})
+		require.NoError(t, err)
+	}
+
+	reconcile()
+	require.Empty(t, recorder.Events, "whether cluster-admin was granted before the first reconcile is unknown")
+	reconcile("abc")
+	require.Equal(t, "Normal ClusterAdminGranted cluster-admin was granted to the users of the cluster-admins Group", <-recorder.Events)
+	reconcile("abc", "def")
+	require.Empty(t, recorder.Events)
+	reconcile()
+	require.Equal(t, "Normal ClusterAdminRevoked cluster-admin was revoked, the cluster-admins Group has no users", <-recorder.Events)
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package group
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+// Metrics report whether cluster-admin is granted to the customer, which is the case while the cluster-admins
+// Group has users
+type Metrics struct {
+	metrics.MetricSet
+	clusterAdmin *metrics.Gauges
+}
+
+// NewMetrics registers cluster_admin_enabled, which reports cluster-admin as not granted until the Group is reconciled
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		clusterAdmin: a.NewGauges("cluster_admin_enabled", "Indicates if the cluster-admin role is enabled"),
+	}
+	m.MetricSet = metrics.NewMetricSet("Group", m.clusterAdmin)
+	a.MustRegister(m)
+	m.SetClusterAdmin(a.ClusterID(), false)
+	return m
+}
+
+func (m *Metrics) SetClusterAdmin(uuid string, enabled bool) {
+	m.clusterAdmin.With(uuid).Set(metrics.BoolToFloat(enabled))
+}
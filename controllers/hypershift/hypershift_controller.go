@@ -0,0 +1,125 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hypershift
+
+import (
+	"context"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/apiversion"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const conditionAvailable = "Available"
+
+var log = logf.Log.WithName("controller_hypershift")
+
+// The HyperShift resources of a management cluster, read as unstructured objects to avoid depending on the HyperShift API.
+// v1alpha1 has the same fields the exporter reads.
+var (
+	HostedClusterKind = apiversion.NewKind(schema.GroupVersionKind{Group: "hypershift.openshift.io", Version: "v1beta1", Kind: "HostedCluster"}, "v1alpha1")
+	NodePoolKind      = apiversion.NewKind(schema.GroupVersionKind{Group: "hypershift.openshift.io", Version: "v1beta1", Kind: "NodePool"}, "v1alpha1")
+)
+
+// HostedClusterReconciler reconciles HostedCluster and NodePool objects
+type HostedClusterReconciler struct {
+	client.Client
+	Scheme  *runtime.Scheme
+	Metrics *Metrics
+}
+
+// Reconcile lists all HostedClusters and NodePools and reports the metrics of every hosted cluster with its own cluster id
+func (r *HostedClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling HostedCluster")
+
+	hostedClusters := newList(HostedClusterKind)
+	if err := r.Client.List(ctx, hostedClusters); err != nil {
+		return ctrl.Result{}, err
+	}
+	nodePools := newList(NodePoolKind)
+	if err := r.Client.List(ctx, nodePools); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	byKey := make(map[client.ObjectKey]*HostedCluster, len(hostedClusters.Items))
+	for _, hc := range hostedClusters.Items {
+		id, _, _ := unstructured.NestedString(hc.Object, "spec", "clusterID")
+		if id == "" {
+			// the id is assigned by HyperShift shortly after creation
+			continue
+		}
+		byKey[client.ObjectKeyFromObject(&hc)] = &HostedCluster{
+			ID:               id,
+			Available:        isAvailable(&hc),
+			NodePoolReplicas: make(map[string]int),
+		}
+	}
+	for _, np := range nodePools.Items {
+		clusterName, _, _ := unstructured.NestedString(np.Object, "spec", "clusterName")
+		hc, ok := byKey[client.ObjectKey{Namespace: np.GetNamespace(), Name: clusterName}]
+		if !ok {
+			continue
+		}
+		replicas, _, _ := unstructured.NestedInt64(np.Object, "status", "replicas")
+		hc.NodePoolReplicas[np.GetName()] = int(replicas)
+	}
+
+	result := make([]HostedCluster, 0, len(byKey))
+	for _, hc := range byKey {
+		result = append(result, *hc)
+	}
+	r.Metrics.SetHostedClusters(result)
+	return ctrl.Result{}, nil
+}
+
+func isAvailable(hc *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(hc.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == conditionAvailable {
+			return condition["status"] == "True"
+		}
+	}
+	return false
+}
+
+func newList(kind *apiversion.Kind) *unstructured.UnstructuredList {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(kind.ListGroupVersionKind())
+	return list
+}
+
+func newObject(kind *apiversion.Kind) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(kind.GroupVersionKind())
+	return obj
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *HostedClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("HostedCluster")).
+		Named("hypershift").
+		For(newObject(HostedClusterKind)).
+		Watches(&source.Kind{Type: newObject(NodePoolKind)}, &handler.EnqueueRequestForObject{}).
+		Complete(r)
+}
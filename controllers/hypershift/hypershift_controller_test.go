@@ -0,0 +1,102 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hypershift
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestHostedCluster(namespace, name, clusterID, available string) *unstructured.Unstructured {
+	obj := newObject(HostedClusterKind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.Object["spec"] = map[string]interface{}{"clusterID": clusterID}
+	obj.Object["status"] = map[string]interface{}{
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Degraded", "status": "False"},
+			map[string]interface{}{"type": "Available", "status": available},
+		},
+	}
+	return obj
+}
+
+func makeTestNodePool(namespace, name, clusterName string, replicas int64) *unstructured.Unstructured {
+	obj := newObject(NodePoolKind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.Object["spec"] = map[string]interface{}{"clusterName": clusterName}
+	obj.Object["status"] = map[string]interface{}{"replicas": replicas}
+	return obj
+}
+
+func TestReconcileHostedCluster_Reconcile(t *testing.T) {
+	objects := []client.Object{
+		makeTestHostedCluster("clusters", "one", "id-one", "True"),
+		makeTestNodePool("clusters", "one-workers", "one", 3),
+		makeTestNodePool("clusters", "one-infra", "one", 2),
+		makeTestHostedCluster("clusters", "two", "id-two", "False"),
+		makeTestNodePool("clusters", "two-workers", "two", 0),
+		// not yet assigned a cluster id
+		makeTestHostedCluster("clusters", "three", "", "False"),
+		makeTestNodePool("clusters", "three-workers", "three", 1),
+		// a NodePool of a cluster in another namespace
+		makeTestNodePool("other", "one-workers", "one", 5),
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(objects...).Build()
+	reconciler := &HostedClusterReconciler{
+		Client:  fakeClient,
+		Metrics: NewMetrics(metrics.NewMetricsAggregator("management-cluster-id")),
+	}
+	_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: "clusters", Name: "one"},
+	})
+	require.NoError(t, err)
+
+	err = testutil.CollectAndCompare(reconciler.Metrics.available, strings.NewReader(`
+# HELP hostedcluster_available Indicates if a HyperShift hosted cluster is available
+# TYPE hostedcluster_available gauge
+hostedcluster_available{_id="id-one",name="osd_exporter"} 1
+hostedcluster_available{_id="id-two",name="osd_exporter"} 0
+`))
+	require.NoError(t, err)
+	err = testutil.CollectAndCompare(reconciler.Metrics.nodePoolReplicas, strings.NewReader(`
+# HELP nodepool_replicas Indicates the number of replicas of a HyperShift NodePool
+# TYPE nodepool_replicas gauge
+nodepool_replicas{_id="id-one",name="osd_exporter",nodepool="one-infra"} 2
+nodepool_replicas{_id="id-one",name="osd_exporter",nodepool="one-workers"} 3
+nodepool_replicas{_id="id-two",name="osd_exporter",nodepool="two-workers"} 0
+`))
+	require.NoError(t, err)
+
+	// the series of a deleted hosted cluster are deleted
+	require.NoError(t, fakeClient.Delete(context.TODO(), makeTestHostedCluster("clusters", "two", "id-two", "False")))
+	_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: "clusters", Name: "two"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, testutil.CollectAndCount(reconciler.Metrics.available))
+	require.Equal(t, 2, testutil.CollectAndCount(reconciler.Metrics.nodePoolReplicas))
+}
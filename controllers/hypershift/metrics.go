@@ -0,0 +1,60 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hypershift
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+const nodePoolLabel = "nodepool"
+
+// HostedCluster is a HyperShift hosted cluster seen from its management cluster
+type HostedCluster struct {
+	ID               string
+	Available        bool
+	NodePoolReplicas map[string]int
+}
+
+// Metrics report the hosted clusters of a HyperShift management cluster. Unlike the other metrics their _id is the
+// id of the hosted cluster, so each hosted cluster has its own series.
+type Metrics struct {
+	metrics.MetricSet
+	available        *metrics.Gauges
+	nodePoolReplicas *metrics.Gauges
+}
+
+// NewMetrics registers the availability of the hosted clusters and the replicas of their NodePools
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		available:        a.NewGauges("hostedcluster_available", "Indicates if a HyperShift hosted cluster is available"),
+		nodePoolReplicas: a.NewGauges("nodepool_replicas", "Indicates the number of replicas of a HyperShift NodePool", nodePoolLabel),
+	}
+	m.MetricSet = metrics.NewMetricSet("HostedCluster", m.available, m.nodePoolReplicas)
+	a.MustRegister(m)
+	return m
+}
+
+// SetHostedClusters replaces the metrics of all hosted clusters, which use the hosted cluster id as _id
+func (m *Metrics) SetHostedClusters(hostedClusters []HostedCluster) {
+	available := make([]metrics.Sample, 0, len(hostedClusters))
+	var replicas []metrics.Sample
+	for _, hc := range hostedClusters {
+		available = append(available, metrics.Sample{LabelValues: []string{hc.ID}, Value: metrics.BoolToFloat(hc.Available)})
+		for nodePool, r := range hc.NodePoolReplicas {
+			replicas = append(replicas, metrics.Sample{LabelValues: []string{hc.ID, nodePool}, Value: float64(r)})
+		}
+	}
+	m.available.ReplaceSeries(available)
+	m.nodePoolReplicas.ReplaceSeries(replicas)
+}
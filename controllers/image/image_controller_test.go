@@ -0,0 +1,65 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testClusterId = "cluster-id"
+
+func TestImageReconciler_Reconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, configv1.Install(scheme))
+	image := &configv1.Image{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.ImageSpec{
+			RegistrySources: configv1.RegistrySources{
+				InsecureRegistries: []string{"registry.example.com:5000", "*.internal.example.com"},
+				BlockedRegistries:  []string{"docker.io"},
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(image).Build()
+	reconciler := &ImageReconciler{
+		Client:    fakeClient,
+		Metrics:   NewMetrics(metrics.NewMetricsAggregator(testClusterId)),
+		ClusterId: testClusterId,
+	}
+
+	_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "cluster"}})
+	require.NoError(t, err)
+	require.NoError(t, testutil.CollectAndCompare(reconciler.Metrics.insecureRegistries, strings.NewReader(`
+# HELP insecure_registry_count Indicates the number of registries allowed without TLS in the cluster image config
+# TYPE insecure_registry_count gauge
+insecure_registry_count{_id="cluster-id",name="osd_exporter"} 2
+`)))
+	require.NoError(t, testutil.CollectAndCompare(reconciler.Metrics.blockedRegistries, strings.NewReader(`
+# HELP blocked_registry_count Indicates the number of registries blocked in the cluster image config
+# TYPE blocked_registry_count gauge
+blocked_registry_count{_id="cluster-id",name="osd_exporter"} 1
+`)))
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+// Metrics count the registries of the cluster image config which are allowed without TLS or blocked, as blocking a
+// registry the platform pulls from breaks upgrades
+type Metrics struct {
+	metrics.MetricSet
+	insecureRegistries *metrics.Gauges
+	blockedRegistries  *metrics.Gauges
+}
+
+// NewMetrics registers insecure_registry_count and blocked_registry_count
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		insecureRegistries: a.NewGauges("insecure_registry_count", "Indicates the number of registries allowed without TLS in the cluster image config"),
+		blockedRegistries:  a.NewGauges("blocked_registry_count", "Indicates the number of registries blocked in the cluster image config"),
+	}
+	m.MetricSet = metrics.NewMetricSet("Image", m.insecureRegistries, m.blockedRegistries)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetImageRegistrySources(uuid string, insecure int, blocked int) {
+	m.insecureRegistries.With(uuid).Set(float64(insecure))
+	m.blockedRegistries.With(uuid).Set(float64(blocked))
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package infrastructure
+
+import (
+	"context"
+	"strconv"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// installConfigNamespace and installConfigName hold the install-config the cluster was installed with
+	installConfigNamespace = "kube-system"
+	installConfigName      = "cluster-config-v1"
+	installConfigKey       = "install-config"
+	// internalPublish is the publish strategy of clusters installed without public endpoints
+	internalPublish = "Internal"
+)
+
+var log = logf.Log.WithName("controller_infrastructure")
+
+// installConfig holds the fields of the install-config read by this controller
+type installConfig struct {
+	Publish string `json:"publish,omitempty"`
+	FIPS    bool   `json:"fips,omitempty"`
+}
+
+// InfrastructureReconciler reconciles the Infrastructure config
+type InfrastructureReconciler struct {
+	client.Client
+	Scheme  *runtime.Scheme
+	Metrics *Metrics
+	// MetricsAggregator receives the platform and FIPS mode of osd_cluster_info
+	MetricsAggregator metrics.MetricsAggregator
+	ClusterId         string
+	// APIReader reads the install-config, which is outside of the namespaces of the cache
+	APIReader client.Reader
+}
+
+// Reconcile reports if an AWS cluster uses PrivateLink or a GCP cluster uses Private Service Connect. Both are
+// installed without public endpoints, while the other private clusters are installed public and made private
+// afterwards by the cloud-ingress-operator. It also reports the platform and if the cluster was installed in FIPS
+// mode as facts of osd_cluster_info.
+func (r *InfrastructureReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling Infrastructure")
+
+	infra := &configv1.Infrastructure{}
+	if err := r.Client.Get(ctx, req.NamespacedName, infra); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if infra.Status.PlatformStatus == nil {
+		return ctrl.Result{}, nil
+	}
+	platform := infra.Status.PlatformStatus.Type
+	config, err := r.readInstallConfig(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	r.MetricsAggregator.SetClusterInfo(r.ClusterId, metrics.ClusterInfoPlatform, string(platform))
+	r.MetricsAggregator.SetClusterInfo(r.ClusterId, metrics.ClusterInfoFIPS, strconv.FormatBool(config.FIPS))
+	if platform != configv1.AWSPlatformType && platform != configv1.GCPPlatformType {
+		return ctrl.Result{}, nil
+	}
+
+	internal := config.Publish == internalPublish
+	if platform == configv1.AWSPlatformType {
+		r.Metrics.SetPrivateLink(r.ClusterId, internal)
+	} else {
+		r.Metrics.SetPrivateServiceConnect(r.ClusterId, internal)
+	}
+	return ctrl.Result{}, nil
+}
+
+// readInstallConfig returns the install-config the cluster was installed with. Clusters without install-config
+// were not installed with the installer, so they are neither published internally only nor in FIPS mode.
+func (r *InfrastructureReconciler) readInstallConfig(ctx context.Context) (installConfig, error) {
+	config := installConfig{}
+	cm := &corev1.ConfigMap{}
+	if err := r.APIReader.Get(ctx, types.NamespacedName{Namespace: installConfigNamespace, Name: installConfigName}, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return config, nil
+		}
+		return config, err
+	}
+	if err := yaml.Unmarshal([]byte(cm.Data[installConfigKey]), &config); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *InfrastructureReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("Infrastructure")).
+		For(&configv1.Infrastructure{}).
+		Complete(r)
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package infrastructure
+
+import (
+	"context"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestInfrastructure(platform configv1.PlatformType) *configv1.Infrastructure {
+	return &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status:     configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{Type: platform}},
+	}
+}
+
+func makeTestInstallConfig(publish string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: installConfigNamespace, Name: installConfigName},
+		Data:       map[string]string{installConfigKey: "apiVersion: v1\npublish: " + publish + "\n"},
+	}
+}
+
+func privateLinkMetric(m *Metrics) *metrics.Gauges { return m.privateLink }
+
+func privateServiceConnectMetric(m *Metrics) *metrics.Gauges { return m.privateServiceConnect }
+
+func TestReconcileInfrastructure_Reconcile(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		objects []client.Object
+		// expected is the value of the metric of the platform, none is reported for other platforms
+		expected         float64
+		expectedMetric   func(*Metrics) *metrics.Gauges
+		unexpectedMetric func(*Metrics) *metrics.Gauges
+	}{
+		{
+			name:             "AWS PrivateLink",
+			objects:          []client.Object{makeTestInfrastructure(configv1.AWSPlatformType), makeTestInstallConfig("Internal")},
+			expected:         1,
+			expectedMetric:   privateLinkMetric,
+			unexpectedMetric: privateServiceConnectMetric,
+		},
+		{
+			name:             "AWS public",
+			objects:          []client.Object{makeTestInfrastructure(configv1.AWSPlatformType), makeTestInstallConfig("External")},
+			expected:         0,
+			expectedMetric:   privateLinkMetric,
+			unexpectedMetric: privateServiceConnectMetric,
+		},
+		{
+			name:             "GCP Private Service Connect",
+			objects:          []client.Object{makeTestInfrastructure(configv1.GCPPlatformType), makeTestInstallConfig("Internal")},
+			expected:         1,
+			expectedMetric:   privateServiceConnectMetric,
+			unexpectedMetric: privateLinkMetric,
+		},
+		{
+			name:             "GCP without install-config",
+			objects:          []client.Object{makeTestInfrastructure(configv1.GCPPlatformType)},
+			expected:         0,
+			expectedMetric:   privateServiceConnectMetric,
+			unexpectedMetric: privateLinkMetric,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reconciler := newTestReconciler(t, tc.objects...)
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "cluster"}})
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, testutil.ToFloat64(tc.expectedMetric(reconciler.Metrics)))
+			require.Equal(t, 0, testutil.CollectAndCount(tc.unexpectedMetric(reconciler.Metrics)))
+		})
+	}
+}
+
+func TestReconcileInfrastructure_ReconcileOtherPlatform(t *testing.T) {
+	reconciler := newTestReconciler(t, makeTestInfrastructure(configv1.AzurePlatformType), makeTestInstallConfig("Internal"))
+	_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "cluster"}})
+	require.NoError(t, err)
+	require.Equal(t, 0, testutil.CollectAndCount(reconciler.Metrics.privateLink))
+	require.Equal(t, 0, testutil.CollectAndCount(reconciler.Metrics.privateServiceConnect))
+}
+
+func TestReconcileInfrastructure_ReconcileClusterInfo(t *testing.T) {
+	installConfig := makeTestInstallConfig("External")
+	installConfig.Data[installConfigKey] += "fips: true\n"
+	reconciler := newTestReconciler(t, makeTestInfrastructure(configv1.AzurePlatformType), installConfig)
+	_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "cluster"}})
+	require.NoError(t, err)
+	info := reconciler.MetricsAggregator.(*metrics.AdoptionMetricsAggregator).GetClusterInfoMetric()
+	require.Equal(t, 1, testutil.CollectAndCount(info))
+	require.Equal(t, float64(1), testutil.ToFloat64(info.WithLabelValues("cluster-id", "", "Azure", "", "true", "")))
+}
+
+func newTestReconciler(t *testing.T, objects ...client.Object) *InfrastructureReconciler {
+	s := runtime.NewScheme()
+	require.NoError(t, configv1.Install(s))
+	require.NoError(t, corev1.AddToScheme(s))
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(objects...).Build()
+	metricsAggregator := metrics.NewMetricsAggregator("cluster-id")
+	return &InfrastructureReconciler{
+		Client:            fakeClient,
+		Metrics:           NewMetrics(metricsAggregator),
+		MetricsAggregator: metricsAggregator,
+		ClusterId:         "cluster-id",
+		APIReader:         fakeClient,
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package infrastructure
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+// Metrics report whether the cluster is reached through a private endpoint of its cloud provider, PrivateLink on AWS
+// or Private Service Connect on GCP
+type Metrics struct {
+	metrics.MetricSet
+	privateLink           *metrics.Gauges
+	privateServiceConnect *metrics.Gauges
+}
+
+// NewMetrics registers cluster_privatelink_enabled and cluster_psc_enabled
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		privateLink:           a.NewGauges("cluster_privatelink_enabled", "Indicates if an AWS cluster uses PrivateLink"),
+		privateServiceConnect: a.NewGauges("cluster_psc_enabled", "Indicates if a GCP cluster uses Private Service Connect"),
+	}
+	m.MetricSet = metrics.NewMetricSet("Infrastructure", m.privateLink, m.privateServiceConnect)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetPrivateLink(uuid string, enabled bool) {
+	m.privateLink.With(uuid).Set(metrics.BoolToFloat(enabled))
+}
+
+func (m *Metrics) SetPrivateServiceConnect(uuid string, enabled bool) {
+	m.privateServiceConnect.With(uuid).Set(metrics.BoolToFloat(enabled))
+}
@@ -16,7 +16,7 @@ package limited_support
 import (
 	"context"
 	"fmt"
-	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -38,9 +38,9 @@ var log = logf.Log.WithName("controller_limited_support")
 // LimitedSupportConfigMapReconciler reconciles a ConfigMap object
 type LimitedSupportConfigMapReconciler struct {
 	client.Client
-	Scheme            *runtime.Scheme
-	MetricsAggregator *metrics.AdoptionMetricsAggregator
-	ClusterId         string
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
 }
 
 // Reconcile reads that state of the cluster for a ConfigMap object limited-support and makes changes based the contained data
@@ -57,20 +57,21 @@ func (r *LimitedSupportConfigMapReconciler) Reconcile(ctx context.Context, req c
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
 			reqLogger.Info(fmt.Sprintf("Did not find ConfigMap %v", limitedSupportConfigMapName))
-			r.MetricsAggregator.SetLimitedSupport(r.ClusterId, false)
+			r.Metrics.SetLimitedSupport(r.ClusterId, false)
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		return ctrl.Result{}, err
 	}
 	reqLogger.Info(fmt.Sprintf("Found ConfigMap %v", limitedSupportConfigMapName))
-	r.MetricsAggregator.SetLimitedSupport(r.ClusterId, true)
+	r.Metrics.SetLimitedSupport(r.ClusterId, true)
 	return ctrl.Result{}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *LimitedSupportConfigMapReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("LimitedSupport")).
 		For(&corev1.ConfigMap{}).
 		WithEventFilter(predicate.Funcs{
 			CreateFunc: func(evt event.CreateEvent) bool {
@@ -4,7 +4,6 @@ import (
 	"context"
 	"strings"
 	"testing"
-	"time"
 
 	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
 	"github.com/prometheus/client_golang/prometheus/testutil"
@@ -41,18 +40,15 @@ limited_support_enabled{_id="i-am-a-cluster-id",name="osd_exporter"} 1
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			metricsAggregator := metrics.NewMetricsAggregator(time.Second, tc.clusterId)
-			done := metricsAggregator.Run()
-			defer close(done)
 			err := corev1.AddToScheme(scheme.Scheme)
 			require.NoError(t, err)
 
 			testConfigMap := makeTestConfigMap(limitedSupportConfigMapName, limitedSupportConfigMapNamespace)
 			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(testConfigMap).Build()
 			reconciler := LimitedSupportConfigMapReconciler{
-				Client:            fakeClient,
-				MetricsAggregator: metricsAggregator,
-				ClusterId:         tc.clusterId,
+				Client:    fakeClient,
+				Metrics:   NewMetrics(metrics.NewMetricsAggregator(tc.clusterId)),
+				ClusterId: tc.clusterId,
 			}
 			result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
 				NamespacedName: types.NamespacedName{
@@ -60,16 +56,12 @@ limited_support_enabled{_id="i-am-a-cluster-id",name="osd_exporter"} 1
 					Name:      limitedSupportConfigMapName,
 				},
 			})
-
-			// sleep to allow the aggregator to aggregate metrics in the background
-			time.Sleep(time.Second * 3)
 			require.NoError(t, err)
 			require.NotNil(t, result)
 			var testCfgMap corev1.ConfigMap
 			err = fakeClient.Get(context.Background(), types.NamespacedName{Name: limitedSupportConfigMapName, Namespace: limitedSupportConfigMapNamespace}, &testCfgMap)
 			require.NoError(t, err)
-			metric := metricsAggregator.GetLimitedsupportStatus()
-			err = testutil.CollectAndCompare(metric, strings.NewReader(tc.expectedResults))
+			err = testutil.CollectAndCompare(reconciler.Metrics.limitedSupport, strings.NewReader(tc.expectedResults))
 			require.NoError(t, err)
 		})
 	}
@@ -90,18 +82,15 @@ limited_support_enabled{_id="i-am-a-cluster-id",name="osd_exporter"} 0
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			metricsAggregator := metrics.NewMetricsAggregator(time.Second, tc.clusterId)
-			done := metricsAggregator.Run()
-			defer close(done)
 			err := corev1.AddToScheme(scheme.Scheme)
 			require.NoError(t, err)
 
 			testConfigMap := makeTestConfigMap(limitedSupportConfigMapName, "default")
 			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(testConfigMap).Build()
 			reconciler := LimitedSupportConfigMapReconciler{
-				Client:            fakeClient,
-				MetricsAggregator: metricsAggregator,
-				ClusterId:         tc.clusterId,
+				Client:    fakeClient,
+				Metrics:   NewMetrics(metrics.NewMetricsAggregator(tc.clusterId)),
+				ClusterId: tc.clusterId,
 			}
 			result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
 				NamespacedName: types.NamespacedName{
@@ -109,16 +98,12 @@ limited_support_enabled{_id="i-am-a-cluster-id",name="osd_exporter"} 0
 					Name:      limitedSupportConfigMapName,
 				},
 			})
-
-			// sleep to allow the aggregator to aggregate metrics in the background
-			time.Sleep(time.Second * 3)
 			require.NoError(t, err)
 			require.NotNil(t, result)
 			var testCfgMap corev1.ConfigMap
 			err = fakeClient.Get(context.Background(), types.NamespacedName{Name: limitedSupportConfigMapName, Namespace: "default"}, &testCfgMap)
 			require.NoError(t, err)
-			metric := metricsAggregator.GetLimitedsupportStatus()
-			err = testutil.CollectAndCompare(metric, strings.NewReader(tc.expectedResults))
+			err = testutil.CollectAndCompare(reconciler.Metrics.limitedSupport, strings.NewReader(tc.expectedResults))
 			require.NoError(t, err)
 		})
 	}
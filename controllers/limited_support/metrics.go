@@ -0,0 +1,40 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package limited_support
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+// Metrics report whether the cluster is in limited support, which is recorded in the limited-support ConfigMap of
+// the exporter namespace
+type Metrics struct {
+	metrics.MetricSet
+	limitedSupport *metrics.Gauges
+}
+
+// NewMetrics registers limited_support_enabled, which reports no limited support until the ConfigMap is reconciled
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		limitedSupport: a.NewGauges("limited_support_enabled", "Indicates if limited support is enabled"),
+	}
+	m.MetricSet = metrics.NewMetricSet("LimitedSupport", m.limitedSupport)
+	a.MustRegister(m)
+	m.SetLimitedSupport(a.ClusterID(), false)
+	return m
+}
+
+func (m *Metrics) SetLimitedSupport(uuid string, enabled bool) {
+	m.limitedSupport.With(uuid).Set(metrics.BoolToFloat(enabled))
+}
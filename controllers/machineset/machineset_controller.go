@@ -0,0 +1,86 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machineset
+
+import (
+	"context"
+	"encoding/json"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("controller_machineset")
+
+// spotProviderSpec holds the fields of the AWS, Azure and GCP provider specs requesting spot or preemptible instances
+type spotProviderSpec struct {
+	SpotMarketOptions *json.RawMessage `json:"spotMarketOptions,omitempty"`
+	SpotVMOptions     *json.RawMessage `json:"spotVMOptions,omitempty"`
+	Preemptible       bool             `json:"preemptible,omitempty"`
+}
+
+// MachineSetReconciler reconciles a MachineSet object
+type MachineSetReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+}
+
+// Reconcile lists all MachineSets and reports which of them create spot or preemptible instances
+func (r *MachineSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling MachineSet")
+
+	machineSets := &machinev1beta1.MachineSetList{}
+	if err := r.Client.List(ctx, machineSets, client.InNamespace(utils.MachineAPINamespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	spot := make(map[string]bool, len(machineSets.Items))
+	for _, ms := range machineSets.Items {
+		enabled, err := isSpot(ms)
+		if err != nil {
+			reqLogger.Error(err, "invalid providerSpec", "MachineSet", ms.Name)
+		}
+		spot[ms.Name] = enabled
+	}
+	r.Metrics.SetSpotInstancesEnabled(r.ClusterId, spot)
+	return ctrl.Result{}, nil
+}
+
+func isSpot(ms machinev1beta1.MachineSet) (bool, error) {
+	value := ms.Spec.Template.Spec.ProviderSpec.Value
+	if value == nil || len(value.Raw) == 0 {
+		return false, nil
+	}
+	spec := spotProviderSpec{}
+	if err := json.Unmarshal(value.Raw, &spec); err != nil {
+		return false, err
+	}
+	return spec.SpotMarketOptions != nil || spec.SpotVMOptions != nil || spec.Preemptible, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MachineSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("MachineSet")).
+		For(&machinev1beta1.MachineSet{}).
+		WithEventFilter(utils.IgnoreStatusUpdates()).
+		Complete(r)
+}
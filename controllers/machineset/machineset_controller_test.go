@@ -0,0 +1,81 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machineset
+
+import (
+	"context"
+	"testing"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics/metricstest"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestMachineSet(name, providerSpec string) *machinev1beta1.MachineSet {
+	ms := &machinev1beta1.MachineSet{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: utils.MachineAPINamespace}}
+	ms.Spec.Template.Spec.ProviderSpec.Value = &runtime.RawExtension{Raw: []byte(providerSpec)}
+	return ms
+}
+
+func TestReconcileMachineSet_Reconcile(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, machinev1beta1.Install(s))
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		makeTestMachineSet("aws-on-demand", `{"kind":"AWSMachineProviderConfig","instanceType":"m5.xlarge"}`),
+		makeTestMachineSet("aws-spot", `{"kind":"AWSMachineProviderConfig","spotMarketOptions":{}}`),
+		makeTestMachineSet("azure-spot", `{"kind":"AzureMachineProviderSpec","spotVMOptions":{"maxPrice":"0.1"}}`),
+		makeTestMachineSet("gcp-preemptible", `{"kind":"GCPMachineProviderSpec","preemptible":true}`),
+		makeTestMachineSet("gcp-standard", `{"kind":"GCPMachineProviderSpec","preemptible":false}`),
+	).Build()
+	metricsAggregator := metrics.NewMetricsAggregator("cluster-id")
+	reconciler := &MachineSetReconciler{
+		Client:    fakeClient,
+		Metrics:   NewMetrics(metricsAggregator),
+		ClusterId: "cluster-id",
+	}
+	_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: utils.MachineAPINamespace, Name: "aws-spot"},
+	})
+	require.NoError(t, err)
+
+	metricstest.RequireGolden(t, metricsAggregator, "spot-instances")
+}
+
+func FuzzIsSpot(f *testing.F) {
+	for _, seed := range []string{
+		`{"kind":"AWSMachineProviderConfig","spotMarketOptions":{"maxPrice":"0.1"}}`,
+		`{"kind":"AzureMachineProviderSpec","spotVMOptions":null}`,
+		`{"kind":"GCPMachineProviderSpec","preemptible":true}`,
+		`{"kind":"VSphereMachineProviderSpec"}`,
+		`{"preemptible":"true"}`,
+		`[]`,
+		`{`,
+		``,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, providerSpec []byte) {
+		spot, err := isSpot(*makeTestMachineSet("fuzz", string(providerSpec)))
+		if err != nil {
+			require.False(t, spot, "an invalid providerSpec is not spot")
+		}
+	})
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machineset
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+const machineSetLabel = "machineset"
+
+// Metrics report the MachineSets creating spot or preemptible instances, whose machines can be reclaimed by the cloud
+// provider at any time
+type Metrics struct {
+	metrics.MetricSet
+	spotInstances *metrics.Gauges
+}
+
+// NewMetrics registers spot_instances_enabled, with a series per MachineSet
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		spotInstances: a.NewGauges("spot_instances_enabled", "Indicates if a MachineSet creates spot or preemptible instances", machineSetLabel),
+	}
+	m.MetricSet = metrics.NewMetricSet("MachineSet", m.spotInstances)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetSpotInstancesEnabled(uuid string, machineSets map[string]bool) {
+	samples := make([]metrics.Sample, 0, len(machineSets))
+	for machineSet, enabled := range machineSets {
+		samples = append(samples, metrics.Sample{LabelValues: []string{machineSet}, Value: metrics.BoolToFloat(enabled)})
+	}
+	m.spotInstances.SetSnapshot(uuid, samples)
+}
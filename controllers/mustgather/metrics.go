@@ -0,0 +1,42 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mustgather
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+// Metrics report whether a must-gather is collecting diagnostics from the cluster and how often one ran
+type Metrics struct {
+	metrics.MetricSet
+	running *metrics.Gauges
+	runs    *metrics.Gauges
+}
+
+// NewMetrics registers must_gather_running and must_gather_run_count, which counts the runs seen since the exporter
+// started
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		running: a.NewGauges("must_gather_running", "Indicates if a must-gather is collecting diagnostics from the cluster"),
+		runs:    a.NewGauges("must_gather_run_count", "Indicates the number of must-gather runs seen since the exporter started"),
+	}
+	m.MetricSet = metrics.NewMetricSet("MustGather", m.running, m.runs)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetMustGather(uuid string, running bool, runs int) {
+	m.running.With(uuid).Set(metrics.BoolToFloat(running))
+	m.runs.With(uuid).Set(float64(runs))
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mustgather
+
+import (
+	"context"
+	"strings"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// NamespacePrefix prefixes the temporary namespace oc adm must-gather creates for each run
+	NamespacePrefix = "openshift-must-gather-"
+	// PodLabel and PodLabelValue are set by oc adm must-gather on its pods, only those pods are cached
+	PodLabel      = "app"
+	PodLabelValue = "must-gather"
+)
+
+var log = logf.Log.WithName("controller_mustgather")
+
+// MustGatherReconciler reconciles the Pods of must-gather runs
+type MustGatherReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+
+	// runs are the must-gather namespaces seen since the exporter started, namespaces are removed once their
+	// pods are gone and counted in finishedRuns. The controller runs a single worker, so they are not locked.
+	runs         map[string]bool
+	finishedRuns int
+}
+
+// Reconcile lists the must-gather Pods and reports if a must-gather is running and the number of must-gather runs
+// seen since the exporter started
+func (r *MustGatherReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling must-gather Pod")
+
+	pods := &corev1.PodList{}
+	if err := r.Client.List(ctx, pods, client.MatchingLabels{PodLabel: PodLabelValue}); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if r.runs == nil {
+		r.runs = make(map[string]bool)
+	}
+	namespaces := make(map[string]bool)
+	running := false
+	for _, pod := range pods.Items {
+		if !strings.HasPrefix(pod.Namespace, NamespacePrefix) {
+			continue
+		}
+		namespaces[pod.Namespace] = true
+		r.runs[pod.Namespace] = true
+		if pod.DeletionTimestamp == nil && (pod.Status.Phase == corev1.PodPending || pod.Status.Phase == corev1.PodRunning) {
+			running = true
+		}
+	}
+	for ns := range r.runs {
+		if !namespaces[ns] {
+			delete(r.runs, ns)
+			r.finishedRuns++
+		}
+	}
+	r.Metrics.SetMustGather(r.ClusterId, running, r.finishedRuns+len(r.runs))
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MustGatherReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("MustGather")).
+		For(&corev1.Pod{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return strings.HasPrefix(o.GetNamespace(), NamespacePrefix)
+		}))).
+		Complete(r)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mustgather
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestPod(namespace string, phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "must-gather", Namespace: namespace, Labels: map[string]string{PodLabel: PodLabelValue}},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestReconcileMustGather_Reconcile(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(s))
+	running := makeTestPod(NamespacePrefix+"abcde", corev1.PodRunning)
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		running,
+		makeTestPod(NamespacePrefix+"fghij", corev1.PodSucceeded),
+		// a pod with the label outside of a must-gather namespace
+		makeTestPod("default", corev1.PodRunning),
+	).Build()
+	reconciler := &MustGatherReconciler{
+		Client:    fakeClient,
+		Metrics:   NewMetrics(metrics.NewMetricsAggregator("cluster-id")),
+		ClusterId: "cluster-id",
+	}
+	_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{})
+	require.NoError(t, err)
+	require.Equal(t, float64(1), testutil.ToFloat64(reconciler.Metrics.running))
+	require.Equal(t, float64(2), testutil.ToFloat64(reconciler.Metrics.runs))
+
+	// the namespace of a finished run is deleted, a new run starts
+	require.NoError(t, fakeClient.Delete(context.TODO(), running))
+	require.NoError(t, fakeClient.Create(context.TODO(), makeTestPod(NamespacePrefix+"klmno", corev1.PodPending)))
+	_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{})
+	require.NoError(t, err)
+	require.Equal(t, float64(1), testutil.ToFloat64(reconciler.Metrics.running))
+	require.Equal(t, float64(3), testutil.ToFloat64(reconciler.Metrics.runs))
+
+	require.NoError(t, fakeClient.DeleteAllOf(context.TODO(), &corev1.Pod{}))
+	_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{})
+	require.NoError(t, err)
+	require.Equal(t, float64(0), testutil.ToFloat64(reconciler.Metrics.running))
+	require.Equal(t, float64(3), testutil.ToFloat64(reconciler.Metrics.runs))
+}
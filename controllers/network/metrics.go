@@ -0,0 +1,48 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+const (
+	typeLabel            = "type"
+	migrationTargetLabel = "migration_target"
+)
+
+// Metrics report the network type of the cluster and its MTU. While the network type is migrated the type series has
+// the target of the migration, and it is replaced by a series without one once the migration completed.
+type Metrics struct {
+	metrics.MetricSet
+	networkType *metrics.Gauges
+	mtu         *metrics.Gauges
+}
+
+// NewMetrics registers cluster_network_type and cluster_network_mtu
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		networkType: a.NewGauges("cluster_network_type", "Indicates the cluster network type and the network type a migration is in progress to",
+			typeLabel, migrationTargetLabel),
+		mtu: a.NewGauges("cluster_network_mtu", "Indicates the MTU of the cluster network"),
+	}
+	m.MetricSet = metrics.NewMetricSet("Network", m.networkType, m.mtu)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetClusterNetwork(uuid string, networkType string, migrationTarget string, mtu int) {
+	m.networkType.SetSnapshot(uuid, []metrics.Sample{{LabelValues: []string{networkType, migrationTarget}, Value: 1}})
+	m.mtu.With(uuid).Set(float64(mtu))
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("controller_network")
+
+// NetworkReconciler reconciles the cluster Network config
+type NetworkReconciler struct {
+	client.Client
+	Scheme  *runtime.Scheme
+	Metrics *Metrics
+	// MetricsAggregator receives the network type of osd_cluster_info
+	MetricsAggregator metrics.MetricsAggregator
+	ClusterId         string
+}
+
+// Reconcile reports the network type and MTU from the Network status. The status reflects the network
+// operator, including the target network type while a migration is in progress.
+func (r *NetworkReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling Network")
+
+	instance := &configv1.Network{}
+	if err := r.Client.Get(ctx, req.NamespacedName, instance); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if instance.Status.NetworkType == "" {
+		reqLogger.Info("network type not reported yet")
+		return ctrl.Result{}, nil
+	}
+
+	var migrationTarget string
+	if instance.Status.Migration != nil {
+		migrationTarget = instance.Status.Migration.NetworkType
+	}
+	r.Metrics.SetClusterNetwork(r.ClusterId, instance.Status.NetworkType, migrationTarget, instance.Status.ClusterNetworkMTU)
+	r.MetricsAggregator.SetClusterInfo(r.ClusterId, metrics.ClusterInfoNetworkType, instance.Status.NetworkType)
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NetworkReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("Network")).
+		For(&configv1.Network{}).
+		Complete(r)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testClusterId = "cluster-id"
+
+func makeTestNetwork(networkType string, mtu int, migrationTarget string) *configv1.Network {
+	network := &configv1.Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status: configv1.NetworkStatus{
+			NetworkType:       networkType,
+			ClusterNetworkMTU: mtu,
+		},
+	}
+	if migrationTarget != "" {
+		network.Status.Migration = &configv1.NetworkMigration{NetworkType: migrationTarget}
+	}
+	return network
+}
+
+func TestReconcileNetwork_Reconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, configv1.Install(scheme))
+	network := makeTestNetwork("OpenShiftSDN", 1450, "OVNKubernetes")
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(network).Build()
+	metricsAggregator := metrics.NewMetricsAggregator(testClusterId)
+	reconciler := &NetworkReconciler{
+		Client:            fakeClient,
+		Metrics:           NewMetrics(metricsAggregator),
+		MetricsAggregator: metricsAggregator,
+		ClusterId:         testClusterId,
+	}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cluster"}}
+
+	_, err := reconciler.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	expected := `
+# HELP cluster_network_type Indicates the cluster network type and the network type a migration is in progress to
+# TYPE cluster_network_type gauge
+cluster_network_type{_id="cluster-id",migration_target="OVNKubernetes",name="osd_exporter",type="OpenShiftSDN"} 1
+`
+	require.NoError(t, testutil.CollectAndCompare(reconciler.Metrics.networkType, strings.NewReader(expected)))
+	require.EqualValues(t, 1450, testutil.ToFloat64(reconciler.Metrics.mtu))
+
+	// migration completed
+	migrated := makeTestNetwork("OVNKubernetes", 1400, "")
+	migrated.ResourceVersion = network.ResourceVersion
+	require.NoError(t, fakeClient.Update(context.TODO(), migrated))
+	_, err = reconciler.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	expected = `
+# HELP cluster_network_type Indicates the cluster network type and the network type a migration is in progress to
+# TYPE cluster_network_type gauge
+cluster_network_type{_id="cluster-id",migration_target="",name="osd_exporter",type="OVNKubernetes"} 1
+`
+	require.NoError(t, testutil.CollectAndCompare(reconciler.Metrics.networkType, strings.NewReader(expected)))
+	require.EqualValues(t, 1400, testutil.ToFloat64(reconciler.Metrics.mtu))
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+const namespaceTypeLabel = "namespace_type"
+
+// Metrics count the NetworkPolicies in customer namespaces and in the managed namespaces, where a policy can cut
+// off platform components
+type Metrics struct {
+	metrics.MetricSet
+	networkPolicies *metrics.Gauges
+}
+
+// NewMetrics registers networkpolicy_count, with a series per namespace type
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		networkPolicies: a.NewGauges("networkpolicy_count", "Indicates the number of NetworkPolicies in customer and managed namespaces",
+			namespaceTypeLabel),
+	}
+	m.MetricSet = metrics.NewMetricSet("NetworkPolicy", m.networkPolicies)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetNetworkPolicyCount(uuid string, namespaceType string, count int) {
+	m.networkPolicies.With(uuid, namespaceType).Set(float64(count))
+}
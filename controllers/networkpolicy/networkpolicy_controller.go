@@ -0,0 +1,72 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"context"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	customerNamespaceType = "customer"
+	managedNamespaceType  = "managed"
+)
+
+var log = logf.Log.WithName("controller_networkpolicy")
+
+// NetworkPolicyReconciler reconciles a NetworkPolicy object
+type NetworkPolicyReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+}
+
+// Reconcile lists the NetworkPolicies of all namespaces and counts them by customer and managed namespaces
+func (r *NetworkPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling NetworkPolicy")
+
+	networkPolicies := &networkingv1.NetworkPolicyList{}
+	if err := r.Client.List(ctx, networkPolicies); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	counts := map[string]int{}
+	for _, np := range networkPolicies.Items {
+		if utils.IsManagedNamespace(np.Namespace) {
+			counts[managedNamespaceType]++
+		} else {
+			counts[customerNamespaceType]++
+		}
+	}
+	for _, namespaceType := range []string{customerNamespaceType, managedNamespaceType} {
+		r.Metrics.SetNetworkPolicyCount(r.ClusterId, namespaceType, counts[namespaceType])
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NetworkPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("NetworkPolicy")).
+		For(&networkingv1.NetworkPolicy{}).
+		Complete(r)
+}
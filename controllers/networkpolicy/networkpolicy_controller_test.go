@@ -0,0 +1,62 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestNetworkPolicy(name, namespace string) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	}
+}
+
+func TestReconcileNetworkPolicy_Reconcile(t *testing.T) {
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		makeTestNetworkPolicy("allow-same-namespace", "customer-a"),
+		makeTestNetworkPolicy("deny-all", "customer-a"),
+		makeTestNetworkPolicy("allow-from-ingress", "customer-b"),
+		makeTestNetworkPolicy("allow-monitoring", "openshift-monitoring"),
+	).Build()
+	reconciler := &NetworkPolicyReconciler{
+		Client:    fakeClient,
+		Metrics:   NewMetrics(metrics.NewMetricsAggregator("cluster-id")),
+		ClusterId: "cluster-id",
+	}
+	_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: "customer-a", Name: "deny-all"},
+	})
+	require.NoError(t, err)
+
+	err = testutil.CollectAndCompare(reconciler.Metrics.networkPolicies, strings.NewReader(`
+# HELP networkpolicy_count Indicates the number of NetworkPolicies in customer and managed namespaces
+# TYPE networkpolicy_count gauge
+networkpolicy_count{_id="cluster-id",name="osd_exporter",namespace_type="customer"} 3
+networkpolicy_count{_id="cluster-id",name="osd_exporter",namespace_type="managed"} 1
+`))
+	require.NoError(t, err)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+const (
+	nodeLabel         = "node"
+	lifecycleLabel    = "lifecycle"
+	gpuTypeLabel      = "gpu_type"
+	archLabel         = "arch"
+	instanceTypeLabel = "instance_type"
+	roleLabel         = "role"
+)
+
+// Metrics report the nodes of the cluster: the nodes being drained and for how long, the nodes by instance lifecycle,
+// GPU type and CPU architecture, the infra nodes by instance type and the allocatable capacity by node role
+type Metrics struct {
+	metrics.MetricSet
+	drainInProgress   *metrics.Gauges
+	drainDuration     *metrics.Gauges
+	lifecycles        *metrics.Gauges
+	gpuNodes          *metrics.Gauges
+	architectures     *metrics.Gauges
+	infraNodes        *metrics.Gauges
+	allocatableCPU    *metrics.Gauges
+	allocatableMemory *metrics.Gauges
+}
+
+// NewMetrics registers the node metrics. Metrics by label value are replaced on every reconcile, so values without
+// nodes left are removed.
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		drainInProgress:   a.NewGauges("node_drain_in_progress", "Indicates a node being drained", nodeLabel),
+		drainDuration:     a.NewGauges("node_drain_duration_seconds", "Indicates how long a node has been draining", nodeLabel),
+		lifecycles:        a.NewGauges("node_lifecycle_count", "Indicates the number of nodes by instance lifecycle, spot or on demand", lifecycleLabel),
+		gpuNodes:          a.NewGauges("gpu_node_count", "Indicates the number of nodes with a GPU by GPU type", gpuTypeLabel),
+		architectures:     a.NewGauges("node_architecture_count", "Indicates the number of nodes by CPU architecture", archLabel),
+		infraNodes:        a.NewGauges("infra_node_count", "Indicates the number of infra nodes by instance type", instanceTypeLabel),
+		allocatableCPU:    a.NewGauges("cluster_allocatable_cpu_cores", "Indicates the allocatable CPU cores of the nodes by node role", roleLabel),
+		allocatableMemory: a.NewGauges("cluster_allocatable_memory_bytes", "Indicates the allocatable memory of the nodes by node role", roleLabel),
+	}
+	m.MetricSet = metrics.NewMetricSet("Node", m.drainInProgress, m.drainDuration, m.lifecycles, m.gpuNodes, m.architectures,
+		m.infraNodes, m.allocatableCPU, m.allocatableMemory)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetNodeDrains(uuid string, durations map[string]time.Duration) {
+	inProgress := make([]metrics.Sample, 0, len(durations))
+	drainDurations := make([]metrics.Sample, 0, len(durations))
+	for node, d := range durations {
+		inProgress = append(inProgress, metrics.Sample{LabelValues: []string{node}, Value: 1})
+		drainDurations = append(drainDurations, metrics.Sample{LabelValues: []string{node}, Value: d.Seconds()})
+	}
+	m.drainInProgress.SetSnapshot(uuid, inProgress)
+	m.drainDuration.SetSnapshot(uuid, drainDurations)
+}
+
+func (m *Metrics) SetNodeLifecycleCount(uuid string, lifecycle string, count int) {
+	m.lifecycles.With(uuid, lifecycle).Set(float64(count))
+}
+
+func (m *Metrics) SetGPUNodeCounts(uuid string, counts map[string]int) {
+	m.gpuNodes.SetSnapshot(uuid, metrics.CountSamples(counts))
+}
+
+func (m *Metrics) SetNodeArchitectureCounts(uuid string, counts map[string]int) {
+	m.architectures.SetSnapshot(uuid, metrics.CountSamples(counts))
+}
+
+func (m *Metrics) SetInfraNodeCounts(uuid string, counts map[string]int) {
+	m.infraNodes.SetSnapshot(uuid, metrics.CountSamples(counts))
+}
+
+// SetClusterAllocatable replaces the allocatable capacity series, so roles without nodes left are removed
+func (m *Metrics) SetClusterAllocatable(uuid string, cpuCores map[string]float64, memoryBytes map[string]float64) {
+	m.allocatableCPU.SetSnapshot(uuid, metrics.ValueSamples(cpuCores))
+	m.allocatableMemory.SetSnapshot(uuid, metrics.ValueSamples(memoryBytes))
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// the machine-config-daemon requests a drain by setting desiredDrain to drain-<hash>, and sets lastAppliedDrain
+	// to the same value once the drain completed
+	desiredDrainAnnotation     = "machineconfiguration.openshift.io/desiredDrain"
+	lastAppliedDrainAnnotation = "machineconfiguration.openshift.io/lastAppliedDrain"
+	drainRequestPrefix         = "drain-"
+	// excludeNodeDrainingAnnotation makes the machine-api delete a Machine without draining its node
+	excludeNodeDrainingAnnotation = "machine.openshift.io/exclude-node-draining"
+
+	// interruptibleInstanceLabel is set by the machine-api on the nodes of spot and preemptible instances
+	interruptibleInstanceLabel = "machine.openshift.io/interruptible-instance"
+	spotLifecycle              = "spot"
+	onDemandLifecycle          = "on_demand"
+
+	// nodeRoleLabelPrefix prefixes the role labels of nodes, e.g. node-role.kubernetes.io/worker
+	nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+	masterRole          = "master"
+	controlPlaneRole    = "control-plane"
+	infraRole           = "infra"
+	workerRole          = "worker"
+	otherRole           = "other"
+	// unknownInstanceType is reported for nodes without instance type label, e.g. on bare metal
+	unknownInstanceType = "unknown"
+
+	// drainRequeueInterval refreshes the drain durations while a drain is in progress
+	drainRequeueInterval = 30 * time.Second
+)
+
+var log = logf.Log.WithName("controller_node")
+
+// gpuVendors are GPU vendors with the extended resource of their device plugin and the label node-feature-discovery
+// sets for their PCI vendor id, so GPU nodes are also counted where the GPU operator is not installed yet
+var gpuVendors = []struct {
+	name     string
+	resource corev1.ResourceName
+	pciLabel string
+	// productLabel is set by the GPU feature discovery of the vendor
+	productLabel string
+}{
+	{name: "nvidia", resource: "nvidia.com/gpu", pciLabel: "feature.node.kubernetes.io/pci-10de.present", productLabel: "nvidia.com/gpu.product"},
+	{name: "amd", resource: "amd.com/gpu", pciLabel: "feature.node.kubernetes.io/pci-1002.present"},
+}
+
+// now is replaced in tests
+var now = time.Now
+
+// NodeReconciler reconciles a Node object
+type NodeReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+
+	// drainObserved is when a drain requested by the machine-config-daemon was first seen, as it does not
+	// record when the drain started. The controller runs a single worker, so it is not locked.
+	drainObserved map[string]time.Time
+}
+
+// Reconcile lists all Nodes and Machines and reports the nodes being drained, either for the deletion of
+// their Machine or by the machine-config-daemon, the number of spot and on demand nodes, of GPU nodes and of
+// nodes by architecture, of infra nodes by instance type and the allocatable capacity by node role
+func (r *NodeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling Node")
+
+	nodes := &corev1.NodeList{}
+	if err := r.Client.List(ctx, nodes); err != nil {
+		return ctrl.Result{}, err
+	}
+	machines := &machinev1beta1.MachineList{}
+	if err := r.Client.List(ctx, machines, client.InNamespace(utils.MachineAPINamespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	current := now()
+	drains := make(map[string]time.Duration)
+	for _, m := range machines.Items {
+		if m.DeletionTimestamp == nil || m.Status.NodeRef == nil {
+			continue
+		}
+		if _, excluded := m.Annotations[excludeNodeDrainingAnnotation]; excluded {
+			continue
+		}
+		drains[m.Status.NodeRef.Name] = current.Sub(m.DeletionTimestamp.Time)
+	}
+
+	nodeNames := make(map[string]bool, len(nodes.Items))
+	lifecycles := map[string]int{}
+	gpus := map[string]int{}
+	architectures := map[string]int{}
+	infraNodes := map[string]int{}
+	allocatableCPU := map[string]float64{}
+	allocatableMemory := map[string]float64{}
+	if r.drainObserved == nil {
+		r.drainObserved = make(map[string]time.Time)
+	}
+	for _, n := range nodes.Items {
+		nodeNames[n.Name] = true
+		if _, interruptible := n.Labels[interruptibleInstanceLabel]; interruptible {
+			lifecycles[spotLifecycle]++
+		} else {
+			lifecycles[onDemandLifecycle]++
+		}
+		if t := gpuType(n); t != "" {
+			gpus[t]++
+		}
+		if arch := architecture(n); arch != "" {
+			architectures[arch]++
+		}
+		role := nodeRole(n)
+		if role == infraRole {
+			infraNodes[instanceType(n)]++
+		}
+		allocatableCPU[role] += float64(n.Status.Allocatable.Cpu().MilliValue()) / 1000
+		allocatableMemory[role] += float64(n.Status.Allocatable.Memory().Value())
+		if !isDrainRequested(n) {
+			delete(r.drainObserved, n.Name)
+			continue
+		}
+		observed, ok := r.drainObserved[n.Name]
+		if !ok {
+			observed = current
+			r.drainObserved[n.Name] = observed
+		}
+		if _, ok := drains[n.Name]; !ok {
+			drains[n.Name] = current.Sub(observed)
+		}
+	}
+	for name := range drains {
+		// the Machine of a drained node can outlive the node
+		if !nodeNames[name] {
+			delete(drains, name)
+		}
+	}
+	for name := range r.drainObserved {
+		if !nodeNames[name] {
+			delete(r.drainObserved, name)
+		}
+	}
+
+	r.Metrics.SetNodeDrains(r.ClusterId, drains)
+	for _, lifecycle := range []string{spotLifecycle, onDemandLifecycle} {
+		r.Metrics.SetNodeLifecycleCount(r.ClusterId, lifecycle, lifecycles[lifecycle])
+	}
+	r.Metrics.SetGPUNodeCounts(r.ClusterId, gpus)
+	r.Metrics.SetNodeArchitectureCounts(r.ClusterId, architectures)
+	r.Metrics.SetInfraNodeCounts(r.ClusterId, infraNodes)
+	r.Metrics.SetClusterAllocatable(r.ClusterId, allocatableCPU, allocatableMemory)
+	if len(drains) > 0 {
+		return ctrl.Result{RequeueAfter: drainRequeueInterval}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// isDrainRequested returns true if the machine-config-daemon requested a drain that did not complete yet
+func isDrainRequested(n corev1.Node) bool {
+	desired := n.Annotations[desiredDrainAnnotation]
+	return strings.HasPrefix(desired, drainRequestPrefix) && desired != n.Annotations[lastAppliedDrainAnnotation]
+}
+
+// gpuType returns the product of the GPU of a node, or its vendor if the product is not known, and an empty
+// string for nodes without GPU
+func gpuType(n corev1.Node) string {
+	for _, v := range gpuVendors {
+		capacity := n.Status.Capacity[v.resource]
+		if capacity.IsZero() && n.Labels[v.pciLabel] != "true" {
+			continue
+		}
+		if product := n.Labels[v.productLabel]; product != "" {
+			return product
+		}
+		return v.name
+	}
+	return ""
+}
+
+// architecture returns the architecture reported by the kubelet of a node, e.g. amd64 or arm64, falling back to
+// the architecture label before the kubelet reported its status
+func architecture(n corev1.Node) string {
+	if n.Status.NodeInfo.Architecture != "" {
+		return n.Status.NodeInfo.Architecture
+	}
+	return n.Labels[corev1.LabelArchStable]
+}
+
+// instanceType returns the instance type of a node set by its cloud provider, falling back to the deprecated
+// beta label of older nodes
+func instanceType(n corev1.Node) string {
+	for _, label := range []string{corev1.LabelInstanceTypeStable, corev1.LabelInstanceType} {
+		if t := n.Labels[label]; t != "" {
+			return t
+		}
+	}
+	return unknownInstanceType
+}
+
+// nodeRole returns a single role of a node, so nodes with several roles are not counted twice. Control plane
+// nodes are masters even if they are schedulable workers, and infra nodes keep the worker label in OSD.
+func nodeRole(n corev1.Node) string {
+	for _, role := range []string{masterRole, controlPlaneRole, infraRole, workerRole} {
+		if _, ok := n.Labels[nodeRoleLabelPrefix+role]; ok {
+			if role == controlPlaneRole {
+				return masterRole
+			}
+			return role
+		}
+	}
+	return otherRole
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NodeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("Node")).
+		For(&corev1.Node{}).
+		Watches(&source.Kind{Type: &machinev1beta1.Machine{}}, &handler.EnqueueRequestForObject{}).
+		// the kubelet keeps updating the conditions, images and volumes of its node, which are not read
+		WithEventFilter(utils.IgnoreFieldUpdates("status.conditions", "status.images", "status.volumesInUse", "status.volumesAttached")).
+		Complete(r)
+}
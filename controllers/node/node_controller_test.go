@@ -0,0 +1,221 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestNode(name string, annotations map[string]string) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations}}
+}
+
+func makeTestArchNode(name string, arch string, archLabel string) *corev1.Node {
+	n := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	n.Status.NodeInfo.Architecture = arch
+	if archLabel != "" {
+		n.Labels = map[string]string{corev1.LabelArchStable: archLabel}
+	}
+	return n
+}
+
+func makeTestRoleNode(name string, roles []string, cpu string, memory string) *corev1.Node {
+	n := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}}}
+	for _, role := range roles {
+		n.Labels[nodeRoleLabelPrefix+role] = ""
+	}
+	n.Status.Allocatable = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}
+	return n
+}
+
+func makeTestInfraNode(name string, labels map[string]string) *corev1.Node {
+	n := makeTestRoleNode(name, []string{"infra", "worker"}, "0", "0")
+	for k, v := range labels {
+		n.Labels[k] = v
+	}
+	return n
+}
+
+func makeTestGPUNode(name string, labels map[string]string, gpus int64) *corev1.Node {
+	n := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	if gpus > 0 {
+		n.Status.Capacity = corev1.ResourceList{"nvidia.com/gpu": *resource.NewQuantity(gpus, resource.DecimalSI)}
+	}
+	return n
+}
+
+func makeTestSpotNode(name string) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{interruptibleInstanceLabel: ""}}}
+}
+
+func makeTestMachine(name, nodeName string, deleted time.Time, annotations map[string]string) *machinev1beta1.Machine {
+	return &machinev1beta1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         utils.MachineAPINamespace,
+			Annotations:       annotations,
+			DeletionTimestamp: &metav1.Time{Time: deleted},
+			Finalizers:        []string{"machine.machine.openshift.io"},
+		},
+		Status: machinev1beta1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: nodeName}},
+	}
+}
+
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, machinev1beta1.AddToScheme(s))
+	return s
+}
+
+func TestReconcileNode_Reconcile(t *testing.T) {
+	current := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	objects := []client.Object{
+		makeTestNode("deleted-machine", nil),
+		makeTestMachine("deleted-machine", "deleted-machine", current.Add(-10*time.Minute), nil),
+		makeTestNode("excluded", nil),
+		makeTestMachine("excluded", "excluded", current.Add(-10*time.Minute), map[string]string{excludeNodeDrainingAnnotation: ""}),
+		makeTestMachine("node-gone", "node-gone", current.Add(-10*time.Minute), nil),
+		makeTestNode("mcd-drain", map[string]string{
+			desiredDrainAnnotation:     "drain-rendered-worker-2",
+			lastAppliedDrainAnnotation: "uncordon-rendered-worker-1",
+		}),
+		makeTestNode("mcd-drained", map[string]string{
+			desiredDrainAnnotation:     "drain-rendered-worker-2",
+			lastAppliedDrainAnnotation: "drain-rendered-worker-2",
+		}),
+		makeTestSpotNode("spot"),
+		makeTestGPUNode("gpu-t4", map[string]string{"nvidia.com/gpu.product": "Tesla-T4"}, 1),
+		makeTestGPUNode("gpu-t4-2", map[string]string{"nvidia.com/gpu.product": "Tesla-T4"}, 4),
+		makeTestGPUNode("gpu-no-operator", map[string]string{"feature.node.kubernetes.io/pci-10de.present": "true"}, 0),
+		makeTestGPUNode("amd", map[string]string{"feature.node.kubernetes.io/pci-1002.present": "true"}, 0),
+		makeTestArchNode("arm", "arm64", "arm64"),
+		makeTestArchNode("arm-not-ready", "", "arm64"),
+		makeTestArchNode("x86", "amd64", "amd64"),
+		makeTestRoleNode("master", []string{"master", "control-plane"}, "3500m", "14Gi"),
+		makeTestRoleNode("compact", []string{"control-plane", "worker"}, "3500m", "14Gi"),
+		makeTestRoleNode("infra", []string{"infra", "worker"}, "15500m", "60Gi"),
+		makeTestRoleNode("worker", []string{"worker"}, "3500m", "14Gi"),
+		makeTestInfraNode("infra-r5", map[string]string{corev1.LabelInstanceTypeStable: "r5.xlarge"}),
+		makeTestInfraNode("infra-r5-2", map[string]string{corev1.LabelInstanceTypeStable: "r5.xlarge"}),
+		makeTestInfraNode("infra-beta", map[string]string{corev1.LabelInstanceType: "r5.2xlarge"}),
+		makeTestRoleNode("worker-2", []string{"worker"}, "3500m", "14Gi"),
+		makeTestNode("uncordoning", map[string]string{
+			desiredDrainAnnotation:     "uncordon-rendered-worker-2",
+			lastAppliedDrainAnnotation: "drain-rendered-worker-2",
+		}),
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(objects...).Build()
+	reconciler := &NodeReconciler{
+		Client:    fakeClient,
+		Metrics:   NewMetrics(metrics.NewMetricsAggregator("cluster-id")),
+		ClusterId: "cluster-id",
+	}
+	result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "mcd-drain"}})
+	require.NoError(t, err)
+	require.Equal(t, drainRequeueInterval, result.RequeueAfter)
+
+	// the machine-config-daemon drain is timed from when it was first observed
+	current = current.Add(time.Minute)
+	_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "mcd-drain"}})
+	require.NoError(t, err)
+
+	err = testutil.CollectAndCompare(reconciler.Metrics.drainInProgress, strings.NewReader(`
+# HELP node_drain_in_progress Indicates a node being drained
+# TYPE node_drain_in_progress gauge
+node_drain_in_progress{_id="cluster-id",name="osd_exporter",node="deleted-machine"} 1
+node_drain_in_progress{_id="cluster-id",name="osd_exporter",node="mcd-drain"} 1
+`))
+	require.NoError(t, err)
+	err = testutil.CollectAndCompare(reconciler.Metrics.drainDuration, strings.NewReader(`
+# HELP node_drain_duration_seconds Indicates how long a node has been draining
+# TYPE node_drain_duration_seconds gauge
+node_drain_duration_seconds{_id="cluster-id",name="osd_exporter",node="deleted-machine"} 660
+node_drain_duration_seconds{_id="cluster-id",name="osd_exporter",node="mcd-drain"} 60
+`))
+	require.NoError(t, err)
+
+	err = testutil.CollectAndCompare(reconciler.Metrics.lifecycles, strings.NewReader(`
+# HELP node_lifecycle_count Indicates the number of nodes by instance lifecycle, spot or on demand
+# TYPE node_lifecycle_count gauge
+node_lifecycle_count{_id="cluster-id",lifecycle="on_demand",name="osd_exporter"} 20
+node_lifecycle_count{_id="cluster-id",lifecycle="spot",name="osd_exporter"} 1
+`))
+	require.NoError(t, err)
+	err = testutil.CollectAndCompare(reconciler.Metrics.gpuNodes, strings.NewReader(`
+# HELP gpu_node_count Indicates the number of nodes with a GPU by GPU type
+# TYPE gpu_node_count gauge
+gpu_node_count{_id="cluster-id",gpu_type="Tesla-T4",name="osd_exporter"} 2
+gpu_node_count{_id="cluster-id",gpu_type="amd",name="osd_exporter"} 1
+gpu_node_count{_id="cluster-id",gpu_type="nvidia",name="osd_exporter"} 1
+`))
+	require.NoError(t, err)
+	err = testutil.CollectAndCompare(reconciler.Metrics.architectures, strings.NewReader(`
+# HELP node_architecture_count Indicates the number of nodes by CPU architecture
+# TYPE node_architecture_count gauge
+node_architecture_count{_id="cluster-id",arch="amd64",name="osd_exporter"} 1
+node_architecture_count{_id="cluster-id",arch="arm64",name="osd_exporter"} 2
+`))
+	require.NoError(t, err)
+	err = testutil.CollectAndCompare(reconciler.Metrics.infraNodes, strings.NewReader(`
+# HELP infra_node_count Indicates the number of infra nodes by instance type
+# TYPE infra_node_count gauge
+infra_node_count{_id="cluster-id",instance_type="r5.2xlarge",name="osd_exporter"} 1
+infra_node_count{_id="cluster-id",instance_type="r5.xlarge",name="osd_exporter"} 2
+infra_node_count{_id="cluster-id",instance_type="unknown",name="osd_exporter"} 1
+`))
+	require.NoError(t, err)
+	err = testutil.CollectAndCompare(reconciler.Metrics.allocatableCPU, strings.NewReader(`
+# HELP cluster_allocatable_cpu_cores Indicates the allocatable CPU cores of the nodes by node role
+# TYPE cluster_allocatable_cpu_cores gauge
+cluster_allocatable_cpu_cores{_id="cluster-id",name="osd_exporter",role="infra"} 15.5
+cluster_allocatable_cpu_cores{_id="cluster-id",name="osd_exporter",role="master"} 7
+cluster_allocatable_cpu_cores{_id="cluster-id",name="osd_exporter",role="other"} 0
+cluster_allocatable_cpu_cores{_id="cluster-id",name="osd_exporter",role="worker"} 7
+`))
+	require.NoError(t, err)
+	err = testutil.CollectAndCompare(reconciler.Metrics.allocatableMemory, strings.NewReader(`
+# HELP cluster_allocatable_memory_bytes Indicates the allocatable memory of the nodes by node role
+# TYPE cluster_allocatable_memory_bytes gauge
+cluster_allocatable_memory_bytes{_id="cluster-id",name="osd_exporter",role="infra"} 6.442450944e+10
+cluster_allocatable_memory_bytes{_id="cluster-id",name="osd_exporter",role="master"} 3.0064771072e+10
+cluster_allocatable_memory_bytes{_id="cluster-id",name="osd_exporter",role="other"} 0
+cluster_allocatable_memory_bytes{_id="cluster-id",name="osd_exporter",role="worker"} 3.0064771072e+10
+`))
+	require.NoError(t, err)
+}
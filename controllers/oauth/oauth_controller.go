@@ -35,7 +35,7 @@ const finalizer = "finalizers.osd.metrics.exporter.openshift.io"
 type OAuthReconciler struct {
 	client.Client
 	Scheme            *runtime.Scheme
-	MetricsAggregator *metrics.AdoptionMetricsAggregator
+	MetricsAggregator metrics.MetricsAggregator
 }
 
 // Reconcile reads that state of the cluster for a OAuth object and makes changes based on the state read
@@ -86,6 +86,7 @@ func (r *OAuthReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 // SetupWithManager sets up the controller with the Manager.
 func (r *OAuthReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("OAuth")).
 		For(&configv1.OAuth{}).
 		Complete(r)
 }
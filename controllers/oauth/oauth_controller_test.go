@@ -16,7 +16,6 @@ package oauth
 import (
 	"context"
 	"testing"
-	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
@@ -90,9 +89,7 @@ func TestReconcileOAuth_Reconcile(t *testing.T) {
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			metricsAggregator := metrics.NewMetricsAggregator(time.Second, "cluster-id")
-			done := metricsAggregator.Run()
-			defer close(done)
+			metricsAggregator := metrics.NewMetricsAggregator("cluster-id")
 			err := configv1.Install(scheme.Scheme)
 			require.NoError(t, err)
 			if tc.existingProviders == nil {
@@ -143,8 +140,6 @@ func TestReconcileOAuth_Reconcile(t *testing.T) {
 			})
 
 			// Validate our metrics reflect the changes to the OAuth IdentityProviderType list
-			// sleep to allow the aggregator to aggregate metrics in the background
-			time.Sleep(time.Second * 3)
 			require.NoError(t, err)
 			require.NotNil(t, result)
 			var testOAuth configv1.OAuth
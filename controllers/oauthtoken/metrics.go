@@ -0,0 +1,43 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oauthtoken
+
+import (
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+// Metrics report the OAuth access tokens stored in the cluster, including the expired tokens which were not pruned yet
+type Metrics struct {
+	metrics.MetricSet
+	tokens *metrics.Gauges
+	maxAge *metrics.Gauges
+}
+
+// NewMetrics registers the token count and the age of the oldest token
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		tokens: a.NewGauges("oauth_token_count", "Indicates the number of OAuth access tokens"),
+		maxAge: a.NewGauges("oauth_token_max_age_seconds", "Indicates the age of the oldest OAuth access token"),
+	}
+	m.MetricSet = metrics.NewMetricSet("OAuthAccessToken", m.tokens, m.maxAge)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetOAuthTokens(uuid string, count int, maxAge time.Duration) {
+	m.tokens.With(uuid).Set(float64(count))
+	m.maxAge.With(uuid).Set(maxAge.Seconds())
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oauthtoken
+
+import (
+	"context"
+	"time"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ageRequeueInterval refreshes the age of the oldest token, which grows without any token changing
+const ageRequeueInterval = 10 * time.Minute
+
+var log = logf.Log.WithName("controller_oauthtoken")
+
+// now is replaced in tests
+var now = time.Now
+
+// OAuthAccessTokenReconciler reconciles an OAuthAccessToken object
+type OAuthAccessTokenReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+}
+
+// Reconcile lists all OAuthAccessTokens and reports their number and the age of the oldest one. Expired tokens are
+// counted too, as they are stored until they are pruned.
+func (r *OAuthAccessTokenReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling OAuthAccessToken")
+
+	tokens := &oauthv1.OAuthAccessTokenList{}
+	if err := r.Client.List(ctx, tokens); err != nil {
+		return ctrl.Result{}, err
+	}
+	current := now()
+	var maxAge time.Duration
+	for _, token := range tokens.Items {
+		if age := current.Sub(token.CreationTimestamp.Time); age > maxAge {
+			maxAge = age
+		}
+	}
+	r.Metrics.SetOAuthTokens(r.ClusterId, len(tokens.Items), maxAge)
+	return ctrl.Result{RequeueAfter: ageRequeueInterval}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *OAuthAccessTokenReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("OAuthAccessToken")).
+		For(&oauthv1.OAuthAccessToken{}).
+		Complete(r)
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oauthtoken
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestToken(name string, created time.Time) *oauthv1.OAuthAccessToken {
+	return &oauthv1.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)}}
+}
+
+func TestReconcileOAuthAccessToken_Reconcile(t *testing.T) {
+	current := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	s := runtime.NewScheme()
+	require.NoError(t, oauthv1.Install(s))
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		makeTestToken("sha256~a", current.Add(-time.Hour)),
+		makeTestToken("sha256~b", current.Add(-48*time.Hour)),
+		makeTestToken("sha256~c", current.Add(-time.Minute)),
+	).Build()
+	reconciler := &OAuthAccessTokenReconciler{
+		Client:    fakeClient,
+		Metrics:   NewMetrics(metrics.NewMetricsAggregator("cluster-id")),
+		ClusterId: "cluster-id",
+	}
+	result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{})
+	require.NoError(t, err)
+	require.Equal(t, ageRequeueInterval, result.RequeueAfter)
+	require.Equal(t, float64(3), testutil.ToFloat64(reconciler.Metrics.tokens))
+	require.Equal(t, (48 * time.Hour).Seconds(), testutil.ToFloat64(reconciler.Metrics.maxAge))
+}
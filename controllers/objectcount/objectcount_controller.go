@@ -0,0 +1,90 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package objectcount
+
+import (
+	"context"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/openshift/osd-metrics-exporter/pkg/objectcount"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("controller_objectcount")
+
+// ObjectCountReconciler counts the objects matching a single configured counter
+type ObjectCountReconciler struct {
+	client.Client
+	Scheme            *runtime.Scheme
+	MetricsAggregator metrics.MetricsAggregator
+	ClusterId         string
+	Counter           objectcount.Counter
+}
+
+// Reconcile lists the objects of the counter's kind matching its selector and reports how many of them
+// are in namespaces matching its namespace selector
+func (r *ObjectCountReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name, "Counter", r.Counter.ControllerName())
+	reqLogger.Info("Reconciling ObjectCount")
+
+	list := &unstructured.UnstructuredList{}
+	gvk := r.Counter.GroupVersionKind()
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err := r.Client.List(ctx, list, client.MatchingLabelsSelector{Selector: r.Counter.LabelSelector()}); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	count := len(list.Items)
+	if namespaceSelector := r.Counter.NamespaceLabelSelector(); namespaceSelector != nil {
+		namespaces := &corev1.NamespaceList{}
+		if err := r.Client.List(ctx, namespaces, client.MatchingLabelsSelector{Selector: namespaceSelector}); err != nil {
+			return ctrl.Result{}, err
+		}
+		selected := make(map[string]bool, len(namespaces.Items))
+		for _, ns := range namespaces.Items {
+			selected[ns.Name] = true
+		}
+		count = 0
+		for _, item := range list.Items {
+			if selected[item.GetNamespace()] {
+				count++
+			}
+		}
+	}
+	r.MetricsAggregator.SetObjectCount(r.ClusterId, r.Counter.KindLabel(), r.Counter.Selector, r.Counter.NamespaceSelector, count)
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// Namespaces are watched as well when a namespace selector is set, as relabelling a namespace changes the count.
+func (r *ObjectCountReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(r.Counter.GroupVersionKind())
+	builder := ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("ObjectCount")).
+		Named(r.Counter.ControllerName()).
+		For(obj)
+	if r.Counter.NamespaceLabelSelector() != nil {
+		builder = builder.Watches(&source.Kind{Type: &corev1.Namespace{}}, &handler.EnqueueRequestForObject{})
+	}
+	return builder.Complete(r)
+}
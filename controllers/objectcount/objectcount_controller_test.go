@@ -0,0 +1,83 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package objectcount
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/openshift/osd-metrics-exporter/pkg/objectcount"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testCounters = `
+objectCounters:
+  - apiVersion: v1
+    kind: ConfigMap
+    selector: team=a
+    namespaceSelector: "!openshift.io/run-level"
+  - apiVersion: v1
+    kind: ConfigMap
+`
+
+func makeTestNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func makeTestConfigMap(name, namespace string, labels map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels}}
+}
+
+func TestReconcileObjectCount_Reconcile(t *testing.T) {
+	counters, err := objectcount.Parse([]byte(testCounters))
+	require.NoError(t, err)
+
+	metricsAggregator := metrics.NewMetricsAggregator("cluster-id")
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		makeTestNamespace("customer", nil),
+		makeTestNamespace("openshift-etcd", map[string]string{"openshift.io/run-level": "0"}),
+		makeTestConfigMap("a", "customer", map[string]string{"team": "a"}),
+		makeTestConfigMap("b", "customer", map[string]string{"team": "b"}),
+		makeTestConfigMap("c", "openshift-etcd", map[string]string{"team": "a"}),
+	).Build()
+	for _, counter := range counters {
+		reconciler := ObjectCountReconciler{
+			Client:            fakeClient,
+			MetricsAggregator: metricsAggregator,
+			ClusterId:         "cluster-id",
+			Counter:           counter,
+		}
+		_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{
+			NamespacedName: types.NamespacedName{Namespace: "customer", Name: "a"},
+		})
+		require.NoError(t, err)
+	}
+
+	err = testutil.CollectAndCompare(metricsAggregator.GetObjectCountMetric(), strings.NewReader(`
+# HELP object_count Indicates the number of objects of a kind matching a configured selector
+# TYPE object_count gauge
+object_count{_id="cluster-id",kind="ConfigMap",name="osd_exporter",namespace_selector="",selector=""} 3
+object_count{_id="cluster-id",kind="ConfigMap",name="osd_exporter",namespace_selector="!openshift.io/run-level",selector="team=a"} 1
+`))
+	require.NoError(t, err)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package olm
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+const (
+	namespaceLabel = "namespace"
+	packageLabel   = "package"
+	catalogLabel   = "catalog"
+	channelLabel   = "channel"
+)
+
+// OLMOperator is an operator installed through an OLM Subscription
+type OLMOperator struct {
+	Namespace string
+	Package   string
+	Catalog   string
+	Channel   string
+	Failed    bool
+}
+
+// Metrics report the operators installed through OLM Subscriptions, with the catalog and channel they are installed
+// from and whether their ClusterServiceVersion failed
+type Metrics struct {
+	metrics.MetricSet
+	installed *metrics.Gauges
+	failed    *metrics.Gauges
+}
+
+// NewMetrics registers olm_operator_installed and olm_operator_failed, with a series per Subscription
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		installed: a.NewGauges("olm_operator_installed", "Indicates an operator installed through an OLM Subscription",
+			namespaceLabel, packageLabel, catalogLabel, channelLabel),
+		failed: a.NewGauges("olm_operator_failed", "Indicates if the ClusterServiceVersion installed for an OLM Subscription failed",
+			namespaceLabel, packageLabel),
+	}
+	m.MetricSet = metrics.NewMetricSet("OLM", m.installed, m.failed)
+	a.MustRegister(m)
+	return m
+}
+
+// SetOLMOperators replaces all OLM operator series, so uninstalled operators are removed
+func (m *Metrics) SetOLMOperators(uuid string, operators []OLMOperator) {
+	installed := make([]metrics.Sample, len(operators))
+	failed := make([]metrics.Sample, len(operators))
+	for i, o := range operators {
+		installed[i] = metrics.Sample{LabelValues: []string{o.Namespace, o.Package, o.Catalog, o.Channel}, Value: 1}
+		failed[i] = metrics.Sample{LabelValues: []string{o.Namespace, o.Package}, Value: metrics.BoolToFloat(o.Failed)}
+	}
+	m.installed.SetSnapshot(uuid, installed)
+	m.failed.SetSnapshot(uuid, failed)
+}
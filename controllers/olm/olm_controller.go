@@ -0,0 +1,115 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package olm
+
+import (
+	"context"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/apiversion"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// CopiedFromLabel is set by OLM on the copies of a ClusterServiceVersion it creates in every target namespace
+	CopiedFromLabel = "olm.copiedFrom"
+
+	csvPhaseFailed = "Failed"
+)
+
+var log = logf.Log.WithName("controller_olm")
+
+// The OLM resources. There are no Go types for them in this repository, so they are read as unstructured objects.
+var (
+	SubscriptionKind          = apiversion.NewKind(schema.GroupVersionKind{Group: "operators.coreos.com", Version: "v1alpha1", Kind: "Subscription"})
+	ClusterServiceVersionKind = apiversion.NewKind(schema.GroupVersionKind{Group: "operators.coreos.com", Version: "v1alpha1", Kind: "ClusterServiceVersion"})
+)
+
+// OLMReconciler reconciles Subscription and ClusterServiceVersion objects
+type OLMReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+}
+
+// Reconcile lists all Subscriptions and reports the installed operators and if their installed CSV failed
+func (r *OLMReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling OLM operators")
+
+	subscriptions := newList(SubscriptionKind)
+	if err := r.Client.List(ctx, subscriptions); err != nil {
+		return ctrl.Result{}, err
+	}
+	csvs := newList(ClusterServiceVersionKind)
+	if err := r.Client.List(ctx, csvs); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	failedCSVs := make(map[client.ObjectKey]bool)
+	for _, csv := range csvs.Items {
+		if _, copied := csv.GetLabels()[CopiedFromLabel]; copied {
+			continue
+		}
+		phase, _, _ := unstructured.NestedString(csv.Object, "status", "phase")
+		failedCSVs[client.ObjectKeyFromObject(&csv)] = phase == csvPhaseFailed
+	}
+
+	operators := make([]OLMOperator, 0, len(subscriptions.Items))
+	for _, sub := range subscriptions.Items {
+		pkg, _, _ := unstructured.NestedString(sub.Object, "spec", "name")
+		catalog, _, _ := unstructured.NestedString(sub.Object, "spec", "source")
+		channel, _, _ := unstructured.NestedString(sub.Object, "spec", "channel")
+		installedCSV, _, _ := unstructured.NestedString(sub.Object, "status", "installedCSV")
+		operators = append(operators, OLMOperator{
+			Namespace: sub.GetNamespace(),
+			Package:   pkg,
+			Catalog:   catalog,
+			Channel:   channel,
+			Failed:    failedCSVs[client.ObjectKey{Namespace: sub.GetNamespace(), Name: installedCSV}],
+		})
+	}
+	r.Metrics.SetOLMOperators(r.ClusterId, operators)
+	return ctrl.Result{}, nil
+}
+
+func newList(kind *apiversion.Kind) *unstructured.UnstructuredList {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(kind.ListGroupVersionKind())
+	return list
+}
+
+func newObject(kind *apiversion.Kind) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(kind.GroupVersionKind())
+	return obj
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *OLMReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("OLM")).
+		Named("olm").
+		For(newObject(SubscriptionKind)).
+		Watches(&source.Kind{Type: newObject(ClusterServiceVersionKind)}, &handler.EnqueueRequestForObject{}).
+		Complete(r)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package olm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestSubscription(namespace, pkg, catalog, channel, installedCSV string) *unstructured.Unstructured {
+	obj := newObject(SubscriptionKind)
+	obj.SetNamespace(namespace)
+	obj.SetName(pkg)
+	obj.Object["spec"] = map[string]interface{}{"name": pkg, "source": catalog, "channel": channel}
+	obj.Object["status"] = map[string]interface{}{"installedCSV": installedCSV}
+	return obj
+}
+
+func makeTestCSV(namespace, name, phase string, labels map[string]string) *unstructured.Unstructured {
+	obj := newObject(ClusterServiceVersionKind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetLabels(labels)
+	obj.Object["status"] = map[string]interface{}{"phase": phase}
+	return obj
+}
+
+func TestReconcileOLM_Reconcile(t *testing.T) {
+	objects := []client.Object{
+		makeTestSubscription("openshift-logging", "cluster-logging", "redhat-operators", "stable", "cluster-logging.v5.5.3"),
+		makeTestCSV("openshift-logging", "cluster-logging.v5.5.3", "Succeeded", nil),
+		makeTestSubscription("customer", "grafana-operator", "community-operators", "v4", "grafana-operator.v4.7.0"),
+		makeTestCSV("customer", "grafana-operator.v4.7.0", "Failed", nil),
+		// a copy of a failed CSV does not belong to a subscription of its namespace
+		makeTestCSV("openshift-logging", "grafana-operator.v4.7.0", "Failed", map[string]string{CopiedFromLabel: "customer"}),
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(objects...).Build()
+	reconciler := &OLMReconciler{
+		Client:    fakeClient,
+		Metrics:   NewMetrics(metrics.NewMetricsAggregator("cluster-id")),
+		ClusterId: "cluster-id",
+	}
+	_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: "customer", Name: "grafana-operator"},
+	})
+	require.NoError(t, err)
+
+	err = testutil.CollectAndCompare(reconciler.Metrics.installed, strings.NewReader(`
+# HELP olm_operator_installed Indicates an operator installed through an OLM Subscription
+# TYPE olm_operator_installed gauge
+olm_operator_installed{_id="cluster-id",catalog="community-operators",channel="v4",name="osd_exporter",namespace="customer",package="grafana-operator"} 1
+olm_operator_installed{_id="cluster-id",catalog="redhat-operators",channel="stable",name="osd_exporter",namespace="openshift-logging",package="cluster-logging"} 1
+`))
+	require.NoError(t, err)
+	err = testutil.CollectAndCompare(reconciler.Metrics.failed, strings.NewReader(`
+# HELP olm_operator_failed Indicates if the ClusterServiceVersion installed for an OLM Subscription failed
+# TYPE olm_operator_failed gauge
+olm_operator_failed{_id="cluster-id",name="osd_exporter",namespace="customer",package="grafana-operator"} 1
+olm_operator_failed{_id="cluster-id",name="osd_exporter",namespace="openshift-logging",package="cluster-logging"} 0
+`))
+	require.NoError(t, err)
+}
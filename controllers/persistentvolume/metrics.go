@@ -0,0 +1,65 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistentvolume
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+const (
+	storageClassLabel = "storageclass"
+	phaseLabel        = "phase"
+)
+
+// PersistentVolumeKey identifies a persistentvolume_count series
+type PersistentVolumeKey struct {
+	StorageClass string
+	Phase        string
+}
+
+// Metrics report the persistent volumes of the cluster by storage class, e.g. to find volumes of a storage class
+// which is not managed or volumes stuck in the Released or Failed phase
+type Metrics struct {
+	metrics.MetricSet
+	volumes  *metrics.Gauges
+	capacity *metrics.Gauges
+}
+
+// NewMetrics registers the volume count by storage class and phase and the provisioned capacity by storage class
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		volumes: a.NewGauges("persistentvolume_count", "Indicates the number of persistent volumes by storage class and phase",
+			storageClassLabel, phaseLabel),
+		capacity: a.NewGauges("persistentvolume_capacity_bytes", "Indicates the total provisioned capacity of persistent volumes by storage class in bytes",
+			storageClassLabel),
+	}
+	m.MetricSet = metrics.NewMetricSet("PersistentVolume", m.volumes, m.capacity)
+	a.MustRegister(m)
+	return m
+}
+
+// SetPersistentVolumes replaces all persistent volume series with the given counts and capacities,
+// so storage classes and phases which no longer have any volume are removed.
+func (m *Metrics) SetPersistentVolumes(uuid string, counts map[PersistentVolumeKey]int, capacityBytes map[string]int64) {
+	volumes := make([]metrics.Sample, 0, len(counts))
+	for key, count := range counts {
+		volumes = append(volumes, metrics.Sample{LabelValues: []string{key.StorageClass, key.Phase}, Value: float64(count)})
+	}
+	m.volumes.SetSnapshot(uuid, volumes)
+	capacities := make([]metrics.Sample, 0, len(capacityBytes))
+	for storageClass, capacity := range capacityBytes {
+		capacities = append(capacities, metrics.Sample{LabelValues: []string{storageClass}, Value: float64(capacity)})
+	}
+	m.capacity.SetSnapshot(uuid, capacities)
+}
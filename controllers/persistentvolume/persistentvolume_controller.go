@@ -0,0 +1,66 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistentvolume
+
+import (
+	"context"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("controller_persistentvolume")
+
+// PersistentVolumeReconciler reconciles a PersistentVolume object
+type PersistentVolumeReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+}
+
+// Reconcile lists all PersistentVolumes and reports their count by storage class and phase
+// together with the provisioned capacity per storage class.
+func (r *PersistentVolumeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling PersistentVolume")
+
+	pvList := &corev1.PersistentVolumeList{}
+	if err := r.Client.List(ctx, pvList); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	counts := make(map[PersistentVolumeKey]int)
+	capacityBytes := make(map[string]int64)
+	for _, pv := range pvList.Items {
+		counts[PersistentVolumeKey{StorageClass: pv.Spec.StorageClassName, Phase: string(pv.Status.Phase)}]++
+		if storage, ok := pv.Spec.Capacity[corev1.ResourceStorage]; ok {
+			capacityBytes[pv.Spec.StorageClassName] += storage.Value()
+		}
+	}
+	r.Metrics.SetPersistentVolumes(r.ClusterId, counts, capacityBytes)
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PersistentVolumeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("PersistentVolume")).
+		For(&corev1.PersistentVolume{}).
+		Complete(r)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistentvolume
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testClusterId = "i-am-a-cluster-id"
+
+func makeTestPV(name, storageClass, capacity string, phase corev1.PersistentVolumePhase) *corev1.PersistentVolume {
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PersistentVolumeSpec{
+			StorageClassName: storageClass,
+			Capacity: corev1.ResourceList{
+				corev1.ResourceStorage: resource.MustParse(capacity),
+			},
+		},
+		Status: corev1.PersistentVolumeStatus{Phase: phase},
+	}
+}
+
+func TestReconcilePersistentVolume_Reconcile(t *testing.T) {
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		makeTestPV("pv-1", "gp3-csi", "1Gi", corev1.VolumeBound),
+		makeTestPV("pv-2", "gp3-csi", "2Gi", corev1.VolumeBound),
+		makeTestPV("pv-3", "gp3-csi", "1Gi", corev1.VolumeReleased),
+		makeTestPV("pv-4", "efs", "5Gi", corev1.VolumeAvailable),
+	).Build()
+	reconciler := PersistentVolumeReconciler{
+		Client:    fakeClient,
+		Metrics:   NewMetrics(metrics.NewMetricsAggregator(testClusterId)),
+		ClusterId: testClusterId,
+	}
+	reconcile := func() {
+		_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "pv-1"}})
+		require.NoError(t, err)
+	}
+
+	reconcile()
+	err := testutil.CollectAndCompare(reconciler.Metrics.volumes, strings.NewReader(`
+# HELP persistentvolume_count Indicates the number of persistent volumes by storage class and phase
+# TYPE persistentvolume_count gauge
+persistentvolume_count{_id="i-am-a-cluster-id",name="osd_exporter",phase="Available",storageclass="efs"} 1
+persistentvolume_count{_id="i-am-a-cluster-id",name="osd_exporter",phase="Bound",storageclass="gp3-csi"} 2
+persistentvolume_count{_id="i-am-a-cluster-id",name="osd_exporter",phase="Released",storageclass="gp3-csi"} 1
+`))
+	require.NoError(t, err)
+	err = testutil.CollectAndCompare(reconciler.Metrics.capacity, strings.NewReader(`
+# HELP persistentvolume_capacity_bytes Indicates the total provisioned capacity of persistent volumes by storage class in bytes
+# TYPE persistentvolume_capacity_bytes gauge
+persistentvolume_capacity_bytes{_id="i-am-a-cluster-id",name="osd_exporter",storageclass="efs"} 5.36870912e+09
+persistentvolume_capacity_bytes{_id="i-am-a-cluster-id",name="osd_exporter",storageclass="gp3-csi"} 4.294967296e+09
+`))
+	require.NoError(t, err)
+
+	// deleting the only volume of a storage class removes its series
+	require.NoError(t, fakeClient.Delete(context.TODO(), makeTestPV("pv-4", "efs", "5Gi", corev1.VolumeAvailable)))
+	reconcile()
+	err = testutil.CollectAndCompare(reconciler.Metrics.capacity, strings.NewReader(`
+# HELP persistentvolume_capacity_bytes Indicates the total provisioned capacity of persistent volumes by storage class in bytes
+# TYPE persistentvolume_capacity_bytes gauge
+persistentvolume_capacity_bytes{_id="i-am-a-cluster-id",name="osd_exporter",storageclass="gp3-csi"} 4.294967296e+09
+`))
+	require.NoError(t, err)
+}
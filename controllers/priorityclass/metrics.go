@@ -0,0 +1,42 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priorityclass
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+// Metrics report the PriorityClasses added by the customer. Pods of a custom PriorityClass at least as high as a
+// system one can preempt platform pods.
+type Metrics struct {
+	metrics.MetricSet
+	custom        *metrics.Gauges
+	exceedsSystem *metrics.Gauges
+}
+
+// NewMetrics registers the custom PriorityClass count and whether one of them reaches a system priority
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		custom:        a.NewGauges("custom_priorityclass_count", "Indicates the number of PriorityClasses which are not created by Kubernetes or OpenShift"),
+		exceedsSystem: a.NewGauges("custom_priorityclass_exceeds_system", "Indicates if a custom PriorityClass is at least as high as a Kubernetes or OpenShift PriorityClass"),
+	}
+	m.MetricSet = metrics.NewMetricSet("PriorityClass", m.custom, m.exceedsSystem)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetCustomPriorityClasses(uuid string, count int, exceedsSystem bool) {
+	m.custom.With(uuid).Set(float64(count))
+	m.exceedsSystem.With(uuid).Set(metrics.BoolToFloat(exceedsSystem))
+}
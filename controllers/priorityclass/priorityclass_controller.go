@@ -0,0 +1,91 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priorityclass
+
+import (
+	"context"
+	"strings"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("controller_priorityclass")
+
+// platformPriorityClassPrefixes prefix the PriorityClasses of Kubernetes, e.g. system-cluster-critical, and of
+// OpenShift, e.g. openshift-user-critical
+var platformPriorityClassPrefixes = []string{"system-", "openshift-"}
+
+// PriorityClassReconciler reconciles a PriorityClass object
+type PriorityClassReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+}
+
+// Reconcile lists all PriorityClasses and reports the number of custom ones and if any of them is at least as high as
+// a platform PriorityClass, so customer workloads can preempt managed components
+func (r *PriorityClassReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling PriorityClass")
+
+	priorityClasses := &schedulingv1.PriorityClassList{}
+	if err := r.Client.List(ctx, priorityClasses); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var custom []schedulingv1.PriorityClass
+	var lowestPlatform *int32
+	for _, pc := range priorityClasses.Items {
+		if !isPlatform(pc) {
+			custom = append(custom, pc)
+			continue
+		}
+		if lowestPlatform == nil || pc.Value < *lowestPlatform {
+			value := pc.Value
+			lowestPlatform = &value
+		}
+	}
+	exceeds := false
+	for _, pc := range custom {
+		if lowestPlatform != nil && pc.Value >= *lowestPlatform {
+			reqLogger.Info("custom PriorityClass is as high as a platform one", "PriorityClass", pc.Name, "value", pc.Value)
+			exceeds = true
+		}
+	}
+	r.Metrics.SetCustomPriorityClasses(r.ClusterId, len(custom), exceeds)
+	return ctrl.Result{}, nil
+}
+
+func isPlatform(pc schedulingv1.PriorityClass) bool {
+	for _, prefix := range platformPriorityClassPrefixes {
+		if strings.HasPrefix(pc.Name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PriorityClassReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("PriorityClass")).
+		For(&schedulingv1.PriorityClass{}).
+		Complete(r)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priorityclass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestPriorityClass(name string, value int32) *schedulingv1.PriorityClass {
+	return &schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: name}, Value: value}
+}
+
+func TestReconcilePriorityClass_Reconcile(t *testing.T) {
+	platform := []client.Object{
+		makeTestPriorityClass("system-node-critical", 2000001000),
+		makeTestPriorityClass("system-cluster-critical", 2000000000),
+		makeTestPriorityClass("openshift-user-critical", 1000000000),
+	}
+	for _, tc := range []struct {
+		name            string
+		objects         []client.Object
+		expectedCount   float64
+		expectedExceeds float64
+	}{
+		{
+			name: "platform only",
+		},
+		{
+			name:          "low custom priority",
+			objects:       []client.Object{makeTestPriorityClass("batch", 1000), makeTestPriorityClass("web", 100000)},
+			expectedCount: 2,
+		},
+		{
+			name:            "custom priority as high as openshift-user-critical",
+			objects:         []client.Object{makeTestPriorityClass("batch", 1000), makeTestPriorityClass("database", 1000000000)},
+			expectedCount:   2,
+			expectedExceeds: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := runtime.NewScheme()
+			require.NoError(t, schedulingv1.AddToScheme(s))
+			fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(append(tc.objects, platform...)...).Build()
+			reconciler := &PriorityClassReconciler{
+				Client:    fakeClient,
+				Metrics:   NewMetrics(metrics.NewMetricsAggregator("cluster-id")),
+				ClusterId: "cluster-id",
+			}
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{})
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedCount, testutil.ToFloat64(reconciler.Metrics.custom))
+			require.Equal(t, tc.expectedExceeds, testutil.ToFloat64(reconciler.Metrics.exceedsSystem))
+		})
+	}
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package privacy
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+// Metrics report whether the API and the default ingress of the cluster are private, i.e. only exposed on internal
+// load balancers
+type Metrics struct {
+	metrics.MetricSet
+	apiPrivate     *metrics.Gauges
+	ingressPrivate *metrics.Gauges
+}
+
+// NewMetrics registers cluster_api_private and cluster_ingress_private
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		apiPrivate:     a.NewGauges("cluster_api_private", "Indicates if the API of the cluster is only exposed on an internal load balancer"),
+		ingressPrivate: a.NewGauges("cluster_ingress_private", "Indicates if the default ingress of the cluster is only exposed on an internal load balancer"),
+	}
+	m.MetricSet = metrics.NewMetricSet("Privacy", m.apiPrivate, m.ingressPrivate)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetClusterPrivacy(uuid string, apiPrivate bool, ingressPrivate bool) {
+	m.apiPrivate.With(uuid).Set(metrics.BoolToFloat(apiPrivate))
+	m.ingressPrivate.With(uuid).Set(metrics.BoolToFloat(ingressPrivate))
+}
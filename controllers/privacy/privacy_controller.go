@@ -0,0 +1,120 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package privacy
+
+import (
+	"context"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/apiversion"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// IngressOperatorNamespace is the only namespace the IngressController is read in
+	IngressOperatorNamespace     = "openshift-ingress-operator"
+	defaultIngressControllerName = "default"
+	// CloudIngressOperatorNamespace is the only namespace the PublishingStrategies are read in
+	CloudIngressOperatorNamespace = "openshift-cloud-ingress-operator"
+	// internalListening is the listening of a PublishingStrategy exposing the API on an internal load balancer only
+	internalListening = "internal"
+)
+
+var log = logf.Log.WithName("controller_privacy")
+
+// PublishingStrategyKind is the cloud-ingress-operator PublishingStrategy, which sets whether the API and the
+// ingresses of OSD clusters are exposed publicly. There are no Go types for it in this repository, so it is read
+// as unstructured objects.
+var PublishingStrategyKind = apiversion.NewKind(schema.GroupVersionKind{Group: "cloudingress.managed.openshift.io", Version: "v1alpha1", Kind: "PublishingStrategy"})
+
+// PrivacyReconciler reconciles the default IngressController and the PublishingStrategy
+type PrivacyReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+}
+
+// Reconcile reports if the API is only exposed internally, as set by the PublishingStrategy, and if the default
+// ingress is only exposed internally, as set by the load balancer scope of the default IngressController
+func (r *PrivacyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling cluster privacy")
+
+	ingressPrivate := false
+	ic := &operatorv1.IngressController{}
+	err := r.Client.Get(ctx, types.NamespacedName{Namespace: IngressOperatorNamespace, Name: defaultIngressControllerName}, ic)
+	if err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	if err == nil {
+		ingressPrivate = isInternal(ic)
+	}
+
+	apiPrivate := false
+	strategies := &unstructured.UnstructuredList{}
+	strategies.SetGroupVersionKind(PublishingStrategyKind.ListGroupVersionKind())
+	if err := r.Client.List(ctx, strategies, client.InNamespace(CloudIngressOperatorNamespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+	for _, ps := range strategies.Items {
+		listening, _, _ := unstructured.NestedString(ps.Object, "spec", "defaultAPIServerIngress", "listening")
+		if strings.EqualFold(listening, internalListening) {
+			apiPrivate = true
+		}
+	}
+	r.Metrics.SetClusterPrivacy(r.ClusterId, apiPrivate, ingressPrivate)
+	return ctrl.Result{}, nil
+}
+
+// isInternal returns true if the IngressController is exposed on an internal load balancer. The status holds the
+// strategy in effect, the spec is used until the ingress operator reported it.
+func isInternal(ic *operatorv1.IngressController) bool {
+	strategy := ic.Status.EndpointPublishingStrategy
+	if strategy == nil {
+		strategy = ic.Spec.EndpointPublishingStrategy
+	}
+	if strategy == nil || strategy.Type != operatorv1.LoadBalancerServiceStrategyType || strategy.LoadBalancer == nil {
+		return false
+	}
+	return strategy.LoadBalancer.Scope == operatorv1.InternalLoadBalancer
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PrivacyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	ps := &unstructured.Unstructured{}
+	ps.SetGroupVersionKind(PublishingStrategyKind.GroupVersionKind())
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("Privacy")).
+		For(&operatorv1.IngressController{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return o.GetNamespace() == IngressOperatorNamespace && o.GetName() == defaultIngressControllerName
+		}))).
+		Watches(&source.Kind{Type: ps}, handler.EnqueueRequestsFromMapFunc(func(client.Object) []ctrl.Request {
+			return []ctrl.Request{{NamespacedName: types.NamespacedName{Namespace: IngressOperatorNamespace, Name: defaultIngressControllerName}}}
+		})).
+		Complete(r)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package privacy
+
+import (
+	"context"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestIngressController(scope operatorv1.LoadBalancerScope) *operatorv1.IngressController {
+	return &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Namespace: IngressOperatorNamespace, Name: defaultIngressControllerName},
+		Status: operatorv1.IngressControllerStatus{EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+			Type:         operatorv1.LoadBalancerServiceStrategyType,
+			LoadBalancer: &operatorv1.LoadBalancerStrategy{Scope: scope},
+		}},
+	}
+}
+
+func makeTestPublishingStrategy(listening string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(PublishingStrategyKind.GroupVersionKind())
+	obj.SetNamespace(CloudIngressOperatorNamespace)
+	obj.SetName("publishingstrategy")
+	obj.Object["spec"] = map[string]interface{}{
+		"defaultAPIServerIngress": map[string]interface{}{"listening": listening},
+	}
+	return obj
+}
+
+func TestReconcilePrivacy_Reconcile(t *testing.T) {
+	for _, tc := range []struct {
+		name                   string
+		objects                []client.Object
+		expectedAPIPrivate     float64
+		expectedIngressPrivate float64
+	}{
+		{
+			name: "nothing configured",
+		},
+		{
+			name:    "public",
+			objects: []client.Object{makeTestIngressController(operatorv1.ExternalLoadBalancer), makeTestPublishingStrategy("external")},
+		},
+		{
+			name:                   "private",
+			objects:                []client.Object{makeTestIngressController(operatorv1.InternalLoadBalancer), makeTestPublishingStrategy("internal")},
+			expectedAPIPrivate:     1,
+			expectedIngressPrivate: 1,
+		},
+		{
+			name:               "private API only",
+			objects:            []client.Object{makeTestIngressController(operatorv1.ExternalLoadBalancer), makeTestPublishingStrategy("internal")},
+			expectedAPIPrivate: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := runtime.NewScheme()
+			require.NoError(t, operatorv1.Install(s))
+			fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(tc.objects...).Build()
+			reconciler := &PrivacyReconciler{
+				Client:    fakeClient,
+				Metrics:   NewMetrics(metrics.NewMetricsAggregator("cluster-id")),
+				ClusterId: "cluster-id",
+			}
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{})
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedAPIPrivate, testutil.ToFloat64(reconciler.Metrics.apiPrivate))
+			require.Equal(t, tc.expectedIngressPrivate, testutil.ToFloat64(reconciler.Metrics.ingressPrivate))
+		})
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+const (
+	httpLabel      = "http"
+	httpsLabel     = "https"
+	trustedCALabel = "trusted_ca"
+)
+
+// Metrics report the cluster-wide proxy, with a label per proxy setting which is 1 when the setting is configured
+type Metrics struct {
+	metrics.MetricSet
+	clusterProxy *metrics.Gauges
+}
+
+// NewMetrics registers cluster_proxy
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		clusterProxy: a.NewGauges("cluster_proxy", "Indicates cluster proxy state", httpLabel, httpsLabel, trustedCALabel),
+	}
+	m.MetricSet = metrics.NewMetricSet("Proxy", m.clusterProxy)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetClusterProxy(uuid string, proxyHTTP string, proxyHTTPS string, proxyTrustedCA string, proxyEnabled int) {
+	m.clusterProxy.With(uuid, proxyHTTP, proxyHTTPS, proxyTrustedCA).Set(float64(proxyEnabled))
+}
@@ -17,6 +17,7 @@ import (
 	"context"
 
 	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
 	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -30,8 +31,10 @@ var log = logf.Log.WithName("controller_proxy")
 // ProxyReconciler reconciles a Proxy object
 type ProxyReconciler struct {
 	client.Client
-	Scheme            *runtime.Scheme
-	MetricsAggregator *metrics.AdoptionMetricsAggregator
+	Scheme  *runtime.Scheme
+	Metrics *Metrics
+	// MetricsAggregator receives cluster_id
+	MetricsAggregator metrics.MetricsAggregator
 	ClusterId         string
 }
 
@@ -80,13 +83,14 @@ func (r *ProxyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		proxyTrustedCA = "1"
 	}
 	// aggregate metrics
-	r.MetricsAggregator.SetClusterProxy(r.ClusterId, proxyHTTP, proxyHTTPS, proxyTrustedCA, proxyEnabled)
+	r.Metrics.SetClusterProxy(r.ClusterId, proxyHTTP, proxyHTTPS, proxyTrustedCA, proxyEnabled)
 	return ctrl.Result{}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ProxyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("Proxy")).
 		For(&configv1.Proxy{}).
 		Complete(r)
 }
@@ -17,7 +17,6 @@ import (
 	"context"
 	"strings"
 	"testing"
-	"time"
 
 	"github.com/prometheus/client_golang/prometheus/testutil"
 
@@ -95,15 +94,14 @@ cluster_proxy{_id="cluster-id",http="1",https="1",name="osd_exporter",trusted_ca
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			metricsAggregator := metrics.NewMetricsAggregator(time.Second, "cluster-id")
-			done := metricsAggregator.Run()
-			defer close(done)
+			metricsAggregator := metrics.NewMetricsAggregator("cluster-id")
 			err := configv1.Install(scheme.Scheme)
 			require.NoError(t, err)
 
 			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(makeTestProxy(testName, testNamespace, tc.proxySpec, tc.proxyStatus)).Build()
 			reconciler := ProxyReconciler{
 				Client:            fakeClient,
+				Metrics:           NewMetrics(metricsAggregator),
 				MetricsAggregator: metricsAggregator,
 				ClusterId:         "cluster-id",
 			}
@@ -114,9 +112,6 @@ cluster_proxy{_id="cluster-id",http="1",https="1",name="osd_exporter",trusted_ca
 				},
 			})
 			require.NoError(t, err)
-
-			// sleep to allow the aggregator to aggregate metrics in the background
-			time.Sleep(time.Second * 3)
 			require.NoError(t, err)
 			require.NotNil(t, result)
 			var testProxy configv1.Proxy
@@ -127,8 +122,7 @@ cluster_proxy{_id="cluster-id",http="1",https="1",name="osd_exporter",trusted_ca
 			err = testutil.CollectAndCompare(metric, strings.NewReader(tc.expectedClusterIDResults))
 			require.NoError(t, err)
 
-			metric = metricsAggregator.GetClusterProxyMetric()
-			err = testutil.CollectAndCompare(metric, strings.NewReader(tc.expectedProxyResults))
+			err = testutil.CollectAndCompare(reconciler.Metrics.clusterProxy, strings.NewReader(tc.expectedProxyResults))
 			require.NoError(t, err)
 		})
 	}
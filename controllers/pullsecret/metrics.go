@@ -0,0 +1,49 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pullsecret
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+// Metrics report whether the customer added registries to the global pull secret or removed managed registries from
+// it, and how many registries were added
+type Metrics struct {
+	metrics.MetricSet
+	modified             *metrics.Gauges
+	additionalRegistries *metrics.Gauges
+}
+
+// NewMetrics registers global_pullsecret_modified and the count of the additional registries
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		modified: a.NewGauges("global_pullsecret_modified", "Indicates if the registries of the global pull secret differ from the managed ones"),
+		additionalRegistries: a.NewGauges("global_pullsecret_additional_registry_count",
+			"Indicates the number of registries in the global pull secret in addition to the managed ones"),
+	}
+	m.MetricSet = metrics.NewMetricSet("PullSecret", m.modified, m.additionalRegistries)
+	a.MustRegister(m)
+	return m
+}
+
+// SetGlobalPullSecret reports the registries added to and removed from the managed ones in the global pull secret
+func (m *Metrics) SetGlobalPullSecret(uuid string, additionalRegistries int, removedRegistries int) {
+	m.modified.With(uuid).Set(metrics.BoolToFloat(additionalRegistries > 0 || removedRegistries > 0))
+	m.additionalRegistries.With(uuid).Set(float64(additionalRegistries))
+}
+
+// DeleteGlobalPullSecret deletes the series of the global pull secret, after it was deleted
+func (m *Metrics) DeleteGlobalPullSecret(uuid string) {
+	m.DeleteSeries(uuid)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pullsecret
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/secretdata"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	pullSecretNamespace = "openshift-config"
+	pullSecretName      = "pull-secret"
+)
+
+var log = logf.Log.WithName("controller_pullsecret")
+
+// managedRegistries are the registries of the pull secret a cluster is installed with from OCM
+var managedRegistries = []string{
+	"cloud.openshift.com",
+	"quay.io",
+	"registry.connect.redhat.com",
+	"registry.redhat.io",
+}
+
+// dockerConfigJSON is the content of a kubernetes.io/dockerconfigjson Secret, without the credentials
+type dockerConfigJSON struct {
+	Auths map[string]json.RawMessage `json:"auths"`
+}
+
+// PullSecretReconciler reconciles the global pull secret
+type PullSecretReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+	// SecretReader reads the pull secret, which is only watched by its metadata
+	SecretReader *secretdata.Reader
+	// registries are the registries of the pull secret last parsed, nil until it is parsed or while it is invalid
+	registries *registryCounts
+}
+
+// registryCounts are the numbers of registries of the pull secret which are not managed, and of managed registries
+// missing from it
+type registryCounts struct {
+	additional int
+	removed    int
+}
+
+// Reconcile compares the registries of the global pull secret openshift-config/pull-secret with the managed ones.
+// Registries added by the customer, or managed ones removed, are a common cause of image pull failures.
+func (r *PullSecretReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling pull secret")
+
+	secret, changed, err := r.SecretReader.Get(ctx, types.NamespacedName{Namespace: pullSecretNamespace, Name: pullSecretName})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// without a pull secret no registry differs from the managed ones
+			r.registries = nil
+			r.Metrics.DeleteGlobalPullSecret(r.ClusterId)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	// the pull secret is only parsed again when its data changed, but the metrics are always set, so a resync restores
+	// series which were dropped since, e.g. by their expiry or the series limit
+	if changed {
+		r.registries = nil
+		config := &dockerConfigJSON{}
+		if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], config); err != nil {
+			// the secret is invalid until the customer fixes it, requeueing does not help
+			reqLogger.Error(err, "unable to parse the pull secret")
+			return ctrl.Result{}, nil
+		}
+		additional, removed := compareRegistries(config.Auths)
+		r.registries = &registryCounts{additional: additional, removed: removed}
+	}
+	if r.registries != nil {
+		r.Metrics.SetGlobalPullSecret(r.ClusterId, r.registries.additional, r.registries.removed)
+	}
+	return ctrl.Result{}, nil
+}
+
+// compareRegistries returns the number of registries of auths which are not managed, and of managed
+// registries missing from auths
+func compareRegistries(auths map[string]json.RawMessage) (additional int, removed int) {
+	managed := make(map[string]bool, len(managedRegistries))
+	for _, registry := range managedRegistries {
+		managed[registry] = true
+		if _, ok := auths[registry]; !ok {
+			removed++
+		}
+	}
+	for registry := range auths {
+		if !managed[registry] {
+			additional++
+		}
+	}
+	return additional, removed
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PullSecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("PullSecret")).
+		For(&corev1.Secret{}, builder.OnlyMetadata).
+		WithEventFilter(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return obj.GetNamespace() == pullSecretNamespace && obj.GetName() == pullSecretName
+		})).
+		Complete(r)
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pullsecret
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics/metricsfakes"
+	"github.com/openshift/osd-metrics-exporter/pkg/secretdata"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestPullSecret(dockerConfigJSON string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: pullSecretName, Namespace: pullSecretNamespace},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(dockerConfigJSON)},
+	}
+}
+
+func TestPullSecretReconciler_Reconcile(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		dockerConfigJSON string
+		additional       string
+		modified         string
+	}{
+		{
+			name:             "managed",
+			dockerConfigJSON: `{"auths":{"cloud.openshift.com":{"auth":"a"},"quay.io":{"auth":"a"},"registry.connect.redhat.com":{"auth":"a"},"registry.redhat.io":{"auth":"a"}}}`,
+			additional:       `global_pullsecret_additional_registry_count{_id="cluster-id",name="osd_exporter"} 0`,
+			modified:         `global_pullsecret_modified{_id="cluster-id",name="osd_exporter"} 0`,
+		},
+		{
+			name:             "additional registries",
+			dockerConfigJSON: `{"auths":{"cloud.openshift.com":{"auth":"a"},"quay.io":{"auth":"a"},"registry.connect.redhat.com":{"auth":"a"},"registry.redhat.io":{"auth":"a"},"docker.io":{"auth":"a"},"registry.example.com:5000":{"auth":"a"}}}`,
+			additional:       `global_pullsecret_additional_registry_count{_id="cluster-id",name="osd_exporter"} 2`,
+			modified:         `global_pullsecret_modified{_id="cluster-id",name="osd_exporter"} 1`,
+		},
+		{
+			name:             "managed registry removed",
+			dockerConfigJSON: `{"auths":{"cloud.openshift.com":{"auth":"a"},"quay.io":{"auth":"a"}}}`,
+			additional:       `global_pullsecret_additional_registry_count{_id="cluster-id",name="osd_exporter"} 0`,
+			modified:         `global_pullsecret_modified{_id="cluster-id",name="osd_exporter"} 1`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(makeTestPullSecret(tc.dockerConfigJSON)).Build()
+			reconciler := &PullSecretReconciler{
+				Client:       fakeClient,
+				Scheme:       scheme.Scheme,
+				Metrics:      NewMetrics(metrics.NewMetricsAggregator("cluster-id")),
+				ClusterId:    "cluster-id",
+				SecretReader: secretdata.NewReader(fakeClient),
+			}
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: pullSecretNamespace, Name: pullSecretName}})
+			require.NoError(t, err)
+
+			err = testutil.CollectAndCompare(reconciler.Metrics.additionalRegistries, strings.NewReader(`
+# HELP global_pullsecret_additional_registry_count Indicates the number of registries in the global pull secret in addition to the managed ones
+# TYPE global_pullsecret_additional_registry_count gauge
+`+tc.additional+"\n"))
+			require.NoError(t, err)
+			err = testutil.CollectAndCompare(reconciler.Metrics.modified, strings.NewReader(`
+# HELP global_pullsecret_modified Indicates if the registries of the global pull secret differ from the managed ones
+# TYPE global_pullsecret_modified gauge
+`+tc.modified+"\n"))
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestPullSecretReconciler_ReconcileDeleted(t *testing.T) {
+	secret := makeTestPullSecret(`{"auths":{"docker.io":{"auth":"a"}}}`)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+	reconciler := &PullSecretReconciler{
+		Client:       fakeClient,
+		Scheme:       scheme.Scheme,
+		Metrics:      NewMetrics(metrics.NewMetricsAggregator("cluster-id")),
+		ClusterId:    "cluster-id",
+		SecretReader: secretdata.NewReader(fakeClient),
+	}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: pullSecretNamespace, Name: pullSecretName}}
+	_, err := reconciler.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	require.Equal(t, 1, testutil.CollectAndCount(reconciler.Metrics.modified))
+
+	require.NoError(t, fakeClient.Delete(context.TODO(), secret))
+	_, err = reconciler.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	require.Equal(t, 0, testutil.CollectAndCount(reconciler.Metrics.modified))
+	require.Equal(t, 0, testutil.CollectAndCount(reconciler.Metrics.additionalRegistries))
+}
+
+func TestPullSecretReconciler_ReconcileUnchanged(t *testing.T) {
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(makeTestPullSecret(`{"auths":{"docker.io":{"auth":"a"}}}`)).Build()
+	metricsAggregator := metricsfakes.NewFakeMetricsAggregator("cluster-id")
+	reconciler := &PullSecretReconciler{
+		Client:       fakeClient,
+		Scheme:       scheme.Scheme,
+		Metrics:      NewMetrics(metricsAggregator.AdoptionMetricsAggregator),
+		ClusterId:    "cluster-id",
+		SecretReader: secretdata.NewReader(fakeClient),
+	}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: pullSecretNamespace, Name: pullSecretName}}
+	expected := []metricsfakes.Update{
+		{Method: "Set", Args: []interface{}{"global_pullsecret_modified", []string{"cluster-id"}, float64(1)}},
+		{Method: "Set", Args: []interface{}{"global_pullsecret_additional_registry_count", []string{"cluster-id"}, float64(1)}},
+	}
+	_, err := reconciler.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	require.Equal(t, expected, metricsAggregator.Updates())
+
+	// the metrics are set again while the data of the pull secret does not change, e.g. after their series expired
+	metricsAggregator.Reset()
+	_, err = reconciler.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	require.Equal(t, expected, metricsAggregator.Updates())
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scc
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+// Metrics report the SecurityContextConstraints added by the customer. A custom SCC with a higher priority than the
+// default ones is tried first when pods are admitted, so it can change the security context of platform pods.
+type Metrics struct {
+	metrics.MetricSet
+	count       *metrics.Gauges
+	priorityMax *metrics.Gauges
+}
+
+// NewMetrics registers the count and the highest priority of the custom SecurityContextConstraints
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		count:       a.NewGauges("custom_scc_count", "Indicates the number of SecurityContextConstraints not shipped with OpenShift"),
+		priorityMax: a.NewGauges("custom_scc_priority_max", "Indicates the highest priority of SecurityContextConstraints not shipped with OpenShift"),
+	}
+	m.MetricSet = metrics.NewMetricSet("SecurityContextConstraints", m.count, m.priorityMax)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetCustomSCC(uuid string, count int, priorityMax int32) {
+	m.count.With(uuid).Set(float64(count))
+	m.priorityMax.With(uuid).Set(float64(priorityMax))
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scc
+
+import (
+	"context"
+
+	securityv1 "github.com/openshift/api/security/v1"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("controller_scc")
+
+// defaultSCCNames are the SecurityContextConstraints shipped with OCP
+var defaultSCCNames = []string{
+	"anyuid",
+	"hostaccess",
+	"hostmount-anyuid",
+	"hostnetwork",
+	"hostnetwork-v2",
+	"machine-api-termination-handler",
+	"node-exporter",
+	"nonroot",
+	"nonroot-v2",
+	"privileged",
+	"restricted",
+	"restricted-v2",
+}
+
+// SecurityContextConstraintsReconciler reconciles a SecurityContextConstraints object
+type SecurityContextConstraintsReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+}
+
+// Reconcile lists all SecurityContextConstraints and reports the number of custom ones and their highest priority
+func (r *SecurityContextConstraintsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling SecurityContextConstraints")
+
+	sccList := &securityv1.SecurityContextConstraintsList{}
+	if err := r.Client.List(ctx, sccList); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var count int
+	var priorityMax int32
+	for _, scc := range sccList.Items {
+		if utils.ContainsString(defaultSCCNames, scc.Name) {
+			continue
+		}
+		count++
+		if scc.Priority != nil && *scc.Priority > priorityMax {
+			priorityMax = *scc.Priority
+		}
+	}
+	r.Metrics.SetCustomSCC(r.ClusterId, count, priorityMax)
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SecurityContextConstraintsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("SecurityContextConstraints")).
+		For(&securityv1.SecurityContextConstraints{}).
+		Complete(r)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scc
+
+import (
+	"context"
+	"testing"
+
+	securityv1 "github.com/openshift/api/security/v1"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestSCC(name string, priority *int32) *securityv1.SecurityContextConstraints {
+	return &securityv1.SecurityContextConstraints{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Priority:   priority,
+	}
+}
+
+func TestReconcileSecurityContextConstraints_Reconcile(t *testing.T) {
+	low := int32(5)
+	high := int32(20)
+
+	for _, tc := range []struct {
+		name                string
+		objects             []client.Object
+		expectedCount       int
+		expectedPriorityMax int
+	}{
+		{
+			name: "only default sccs",
+			objects: []client.Object{
+				makeTestSCC("restricted-v2", nil),
+				makeTestSCC("anyuid", &high),
+			},
+		},
+		{
+			name: "custom sccs",
+			objects: []client.Object{
+				makeTestSCC("privileged", nil),
+				makeTestSCC("customer-scc", &low),
+				makeTestSCC("customer-high-priority", &high),
+				makeTestSCC("customer-nil-priority", nil),
+				// created by the OpenShift Pipelines operator, not shipped with OCP
+				makeTestSCC("pipelines-scc", nil),
+			},
+			expectedCount:       4,
+			expectedPriorityMax: 20,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := securityv1.Install(scheme.Scheme)
+			require.NoError(t, err)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tc.objects...).Build()
+			reconciler := &SecurityContextConstraintsReconciler{
+				Client:    fakeClient,
+				Metrics:   NewMetrics(metrics.NewMetricsAggregator("cluster-id")),
+				ClusterId: "cluster-id",
+			}
+			_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: "customer-scc"},
+			})
+			require.NoError(t, err)
+
+			count := testutil.ToFloat64(reconciler.Metrics.count)
+			require.EqualValues(t, tc.expectedCount, count)
+			priorityMax := testutil.ToFloat64(reconciler.Metrics.priorityMax)
+			require.EqualValues(t, tc.expectedPriorityMax, priorityMax)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+const scopeLabel = "scope"
+
+// Metrics count the LoadBalancer services by the scope of their cloud load balancer, so services exposed to the
+// internet on a private cluster can be found
+type Metrics struct {
+	metrics.MetricSet
+	loadBalancers *metrics.Gauges
+}
+
+// NewMetrics registers service_loadbalancer_count, with a series per internal or external scope
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		loadBalancers: a.NewGauges("service_loadbalancer_count", "Indicates the number of LoadBalancer type services by internal or external scope",
+			scopeLabel),
+	}
+	m.MetricSet = metrics.NewMetricSet("Service", m.loadBalancers)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetLoadBalancerServiceCount(uuid string, scope string, count int) {
+	m.loadBalancers.With(uuid, scope).Set(float64(count))
+}
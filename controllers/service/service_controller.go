@@ -0,0 +1,103 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	internalScope = "internal"
+	externalScope = "external"
+)
+
+var log = logf.Log.WithName("controller_service")
+
+// internalLoadBalancerAnnotations are the annotations requesting an internal load balancer per platform, with the value
+// requesting it. An empty value means any value other than false.
+var internalLoadBalancerAnnotations = map[string]string{
+	"service.beta.kubernetes.io/aws-load-balancer-internal":   "",
+	"service.beta.kubernetes.io/azure-load-balancer-internal": "",
+	"networking.gke.io/load-balancer-type":                    "Internal",
+	"cloud.google.com/load-balancer-type":                     "Internal",
+}
+
+// ServiceReconciler reconciles a Service object
+type ServiceReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+}
+
+// Reconcile lists the Services of all namespaces and counts LoadBalancer services by scope,
+// as every one of them consumes a cloud load balancer
+func (r *ServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling Service")
+
+	services := &corev1.ServiceList{}
+	if err := r.Client.List(ctx, services); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	counts := map[string]int{}
+	for _, svc := range services.Items {
+		if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			continue
+		}
+		if isInternal(svc) {
+			counts[internalScope]++
+		} else {
+			counts[externalScope]++
+		}
+	}
+	for _, scope := range []string{internalScope, externalScope} {
+		r.Metrics.SetLoadBalancerServiceCount(r.ClusterId, scope, counts[scope])
+	}
+	return ctrl.Result{}, nil
+}
+
+func isInternal(svc corev1.Service) bool {
+	for annotation, internalValue := range internalLoadBalancerAnnotations {
+		value, ok := svc.Annotations[annotation]
+		if !ok {
+			continue
+		}
+		if internalValue == "" && value != "false" {
+			return true
+		}
+		if internalValue != "" && strings.EqualFold(value, internalValue) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("Service")).
+		For(&corev1.Service{}).
+		WithEventFilter(utils.IgnoreStatusUpdates()).
+		Complete(r)
+}
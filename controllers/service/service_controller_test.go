@@ -0,0 +1,67 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestService(name string, serviceType corev1.ServiceType, annotations map[string]string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", Annotations: annotations},
+		Spec:       corev1.ServiceSpec{Type: serviceType},
+	}
+}
+
+func TestReconcileService_Reconcile(t *testing.T) {
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		makeTestService("cluster-ip", corev1.ServiceTypeClusterIP, nil),
+		makeTestService("external", corev1.ServiceTypeLoadBalancer, nil),
+		makeTestService("aws-internal", corev1.ServiceTypeLoadBalancer,
+			map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "true"}),
+		makeTestService("aws-not-internal", corev1.ServiceTypeLoadBalancer,
+			map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "false"}),
+		makeTestService("gcp-internal", corev1.ServiceTypeLoadBalancer,
+			map[string]string{"networking.gke.io/load-balancer-type": "Internal"}),
+	).Build()
+	reconciler := &ServiceReconciler{
+		Client:    fakeClient,
+		Metrics:   NewMetrics(metrics.NewMetricsAggregator("cluster-id")),
+		ClusterId: "cluster-id",
+	}
+	_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: "test", Name: "external"},
+	})
+	require.NoError(t, err)
+
+	err = testutil.CollectAndCompare(reconciler.Metrics.loadBalancers, strings.NewReader(`
+# HELP service_loadbalancer_count Indicates the number of LoadBalancer type services by internal or external scope
+# TYPE service_loadbalancer_count gauge
+service_loadbalancer_count{_id="cluster-id",name="osd_exporter",scope="external"} 2
+service_loadbalancer_count{_id="cluster-id",name="osd_exporter",scope="internal"} 2
+`))
+	require.NoError(t, err)
+}
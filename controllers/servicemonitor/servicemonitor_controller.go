@@ -0,0 +1,112 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicemonitor
+
+import (
+	"context"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	promOperatorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+var log = logf.Log.WithName("controller_servicemonitor")
+
+// DefaultPrometheusServiceAccount is the service account of the Prometheus of the cluster monitoring
+var DefaultPrometheusServiceAccount = types.NamespacedName{Namespace: "openshift-monitoring", Name: "prometheus-k8s"}
+
+// prometheusRoleName is the name of the Role and RoleBinding allowing Prometheus to discover the targets of the
+// ServiceMonitor
+const prometheusRoleName = "prometheus-k8s"
+
+// ServiceMonitorReconciler creates the ServiceMonitor scraping the exporter, and the Role and RoleBinding allowing
+// Prometheus to discover its targets, and restores them when they are changed or deleted
+type ServiceMonitorReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// ServiceMonitor is the desired ServiceMonitor
+	ServiceMonitor *promOperatorv1.ServiceMonitor
+	// PrometheusServiceAccount is the service account of the Prometheus scraping the ServiceMonitor
+	PrometheusServiceAccount types.NamespacedName
+}
+
+func (r *ServiceMonitorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling ServiceMonitor")
+	return ctrl.Result{}, r.Ensure(ctx)
+}
+
+// Ensure creates or updates the ServiceMonitor and the RBAC of Prometheus. The Role and RoleBinding are owned by the
+// ServiceMonitor, so they are deleted with it.
+func (r *ServiceMonitorReconciler) Ensure(ctx context.Context) error {
+	serviceMonitor := &promOperatorv1.ServiceMonitor{ObjectMeta: metav1.ObjectMeta{Name: r.ServiceMonitor.Name, Namespace: r.ServiceMonitor.Namespace}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, serviceMonitor, func() error {
+		serviceMonitor.Labels = r.ServiceMonitor.Labels
+		serviceMonitor.Spec = r.ServiceMonitor.Spec
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: prometheusRoleName, Namespace: serviceMonitor.Namespace}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, role, func() error {
+		role.Rules = []rbacv1.PolicyRule{{
+			APIGroups: []string{""},
+			Resources: []string{"services", "endpoints", "pods"},
+			Verbs:     []string{"get", "list", "watch"},
+		}}
+		return controllerutil.SetControllerReference(serviceMonitor, role, r.Scheme)
+	}); err != nil {
+		return err
+	}
+
+	roleBinding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: prometheusRoleName, Namespace: serviceMonitor.Namespace}}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, roleBinding, func() error {
+		// the role of a binding cannot be changed, it is always this Role
+		roleBinding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: role.Name}
+		roleBinding.Subjects = []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      r.PrometheusServiceAccount.Name,
+			Namespace: r.PrometheusServiceAccount.Namespace,
+		}}
+		return controllerutil.SetControllerReference(serviceMonitor, roleBinding, r.Scheme)
+	})
+	return err
+}
+
+// SetupWithManager reconciles the ServiceMonitor and the objects it owns, and ensures them once the cache is
+// started, as there are no events while none of them exist
+func (r *ServiceMonitorReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.Add(manager.RunnableFunc(r.Ensure)); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("ServiceMonitor")).
+		For(&promOperatorv1.ServiceMonitor{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return o.GetNamespace() == r.ServiceMonitor.Namespace && o.GetName() == r.ServiceMonitor.Name
+		}))).
+		Owns(&rbacv1.Role{}).
+		Owns(&rbacv1.RoleBinding{}).
+		Complete(r)
+}
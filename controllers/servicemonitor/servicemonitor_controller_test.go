@@ -0,0 +1,87 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicemonitor
+
+import (
+	"context"
+	"testing"
+
+	promOperatorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestServiceMonitor(port string) *promOperatorv1.ServiceMonitor {
+	return &promOperatorv1.ServiceMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "osd-metrics-exporter", Namespace: "test", Labels: map[string]string{"name": "osd-metrics-exporter"}},
+		Spec: promOperatorv1.ServiceMonitorSpec{
+			Endpoints: []promOperatorv1.Endpoint{{Port: port, Path: "/metrics"}},
+		},
+	}
+}
+
+func TestReconcileServiceMonitor_Reconcile(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, promOperatorv1.AddToScheme(s))
+	require.NoError(t, rbacv1.AddToScheme(s))
+	desired := makeTestServiceMonitor("metrics")
+
+	for _, tc := range []struct {
+		name    string
+		objects []client.Object
+	}{
+		{name: "missing"},
+		{name: "changed", objects: []client.Object{
+			makeTestServiceMonitor("changed"),
+			&rbacv1.Role{
+				ObjectMeta: metav1.ObjectMeta{Name: prometheusRoleName, Namespace: "test"},
+				Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+			},
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(tc.objects...).Build()
+			reconciler := &ServiceMonitorReconciler{
+				Client:                   fakeClient,
+				Scheme:                   s,
+				ServiceMonitor:           desired,
+				PrometheusServiceAccount: DefaultPrometheusServiceAccount,
+			}
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(desired)})
+			require.NoError(t, err)
+
+			serviceMonitor := &promOperatorv1.ServiceMonitor{}
+			require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(desired), serviceMonitor))
+			require.Equal(t, desired.Spec, serviceMonitor.Spec)
+
+			role := &rbacv1.Role{}
+			require.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "test", Name: prometheusRoleName}, role))
+			require.Equal(t, []string{"services", "endpoints", "pods"}, role.Rules[0].Resources)
+			require.Equal(t, []string{"get", "list", "watch"}, role.Rules[0].Verbs)
+			require.True(t, metav1.IsControlledBy(role, serviceMonitor))
+
+			roleBinding := &rbacv1.RoleBinding{}
+			require.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "test", Name: prometheusRoleName}, roleBinding))
+			require.Equal(t, prometheusRoleName, roleBinding.RoleRef.Name)
+			require.Equal(t, []rbacv1.Subject{{Kind: "ServiceAccount", Name: "prometheus-k8s", Namespace: "openshift-monitoring"}}, roleBinding.Subjects)
+			require.True(t, metav1.IsControlledBy(roleBinding, serviceMonitor))
+		})
+	}
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageclass
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+// Metrics report whether new volumes without a storage class get the managed storage class, which is not the case
+// once the customer marked another storage class as default
+type Metrics struct {
+	metrics.MetricSet
+	defaultManaged *metrics.Gauges
+}
+
+// NewMetrics registers default_storageclass_is_managed
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		defaultManaged: a.NewGauges("default_storageclass_is_managed", "Indicates if the managed storage class is the only default storage class"),
+	}
+	m.MetricSet = metrics.NewMetricSet("StorageClass", m.defaultManaged)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetDefaultStorageClassManaged(uuid string, managed bool) {
+	m.defaultManaged.With(uuid).Set(metrics.BoolToFloat(managed))
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageclass
+
+import (
+	"context"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+)
+
+var log = logf.Log.WithName("controller_storageclass")
+
+// managedStorageClassNames are the default storage classes created for OSD clusters per platform
+var managedStorageClassNames = []string{
+	"gp3-csi",
+	"gp2",
+	"standard-csi",
+}
+
+// StorageClassReconciler reconciles a StorageClass object
+type StorageClassReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+}
+
+// Reconcile lists all StorageClasses and reports if the managed storage class is the only default one
+func (r *StorageClassReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling StorageClass")
+
+	storageClasses := &storagev1.StorageClassList{}
+	if err := r.Client.List(ctx, storageClasses); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var defaults []string
+	for _, sc := range storageClasses.Items {
+		if sc.Annotations[defaultStorageClassAnnotation] == "true" {
+			defaults = append(defaults, sc.Name)
+		}
+	}
+	managed := len(defaults) == 1 && utils.ContainsString(managedStorageClassNames, defaults[0])
+	if !managed {
+		reqLogger.Info("default storage class is not the managed one", "defaults", defaults)
+	}
+	r.Metrics.SetDefaultStorageClassManaged(r.ClusterId, managed)
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *StorageClassReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("StorageClass")).
+		For(&storagev1.StorageClass{}).
+		Complete(r)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageclass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTestStorageClass(name string, isDefault bool) *storagev1.StorageClass {
+	sc := &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+	if isDefault {
+		sc.Annotations = map[string]string{defaultStorageClassAnnotation: "true"}
+	}
+	return sc
+}
+
+func TestReconcileStorageClass_Reconcile(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		objects []client.Object
+		result  int
+	}{
+		{
+			name: "managed default",
+			objects: []client.Object{
+				makeTestStorageClass("gp3-csi", true),
+				makeTestStorageClass("gp2-csi", false),
+			},
+			result: 1,
+		},
+		{
+			name: "customer default",
+			objects: []client.Object{
+				makeTestStorageClass("gp3-csi", false),
+				makeTestStorageClass("customer", true),
+			},
+			result: 0,
+		},
+		{
+			name: "multiple defaults",
+			objects: []client.Object{
+				makeTestStorageClass("gp3-csi", true),
+				makeTestStorageClass("customer", true),
+			},
+			result: 0,
+		},
+		{
+			name:    "no default",
+			objects: []client.Object{makeTestStorageClass("gp3-csi", false)},
+			result:  0,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tc.objects...).Build()
+			reconciler := &StorageClassReconciler{
+				Client:    fakeClient,
+				Metrics:   NewMetrics(metrics.NewMetricsAggregator("cluster-id")),
+				ClusterId: "cluster-id",
+			}
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: "gp3-csi"},
+			})
+			require.NoError(t, err)
+			value := testutil.ToFloat64(reconciler.Metrics.defaultManaged)
+			require.EqualValues(t, tc.result, value)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgradeconfig
+
+import (
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+const versionLabel = "version"
+
+// Metrics report the upgrades managed through UpgradeConfigs: whether the cluster is in the maintenance window of
+// an upgrade, and the upgrades which are scheduled and have not completed yet
+type Metrics struct {
+	metrics.MetricSet
+	maintenanceWindow *metrics.Gauges
+	scheduled         *metrics.Gauges
+	untilUpgrade      *metrics.Gauges
+}
+
+// NewMetrics registers the maintenance window metric and the scheduled upgrade metrics, which have a series per
+// version
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		maintenanceWindow: a.NewGauges("in_maintenance_window", "Indicates if the cluster is in its scheduled upgrade maintenance window"),
+		scheduled: a.NewGauges("upgradeconfig_scheduled", "Indicates the version of an upgrade scheduled through an UpgradeConfig which has not completed yet",
+			versionLabel),
+		untilUpgrade: a.NewGauges("upgradeconfig_seconds_until_upgrade", "Indicates the seconds until a scheduled upgrade starts, 0 once its upgrade time has passed",
+			versionLabel),
+	}
+	m.MetricSet = metrics.NewMetricSet("UpgradeConfig", m.maintenanceWindow, m.scheduled, m.untilUpgrade)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetInMaintenanceWindow(uuid string, inWindow bool) {
+	m.maintenanceWindow.With(uuid).Set(metrics.BoolToFloat(inWindow))
+}
+
+// SetScheduledUpgrades reports the upgrades which have not completed yet, with the time until they start by version
+func (m *Metrics) SetScheduledUpgrades(uuid string, timeUntilUpgrade map[string]time.Duration) {
+	scheduled := make([]metrics.Sample, 0, len(timeUntilUpgrade))
+	untilUpgrade := make([]metrics.Sample, 0, len(timeUntilUpgrade))
+	for version, until := range timeUntilUpgrade {
+		scheduled = append(scheduled, metrics.Sample{LabelValues: []string{version}, Value: 1})
+		untilUpgrade = append(untilUpgrade, metrics.Sample{LabelValues: []string{version}, Value: until.Seconds()})
+	}
+	m.scheduled.SetSnapshot(uuid, scheduled)
+	m.untilUpgrade.SetSnapshot(uuid, untilUpgrade)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgradeconfig
+
+import (
+	"context"
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/apiversion"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// MaintenanceWindowDuration is how long after the scheduled upgrade time the cluster is considered in maintenance.
+	// It matches the upgrade timeout of the managed-upgrade-operator.
+	MaintenanceWindowDuration = 2 * time.Hour
+
+	// untilUpgradeRequeueInterval refreshes the time until a scheduled upgrade
+	untilUpgradeRequeueInterval = 5 * time.Minute
+
+	upgradingPhase = "Upgrading"
+	upgradedPhase  = "Upgraded"
+)
+
+var log = logf.Log.WithName("controller_upgradeconfig")
+
+// UpgradeConfigKind is the managed-upgrade-operator UpgradeConfig, which holds the upgrade schedule set through OCM.
+// There are no Go types for it in this repository, so it is read as unstructured objects.
+var UpgradeConfigKind = apiversion.NewKind(schema.GroupVersionKind{Group: "upgrade.managed.openshift.io", Version: "v1alpha1", Kind: "UpgradeConfig"})
+
+// now is replaced in tests
+var now = time.Now
+
+// UpgradeConfigReconciler reconciles an UpgradeConfig object
+type UpgradeConfigReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+}
+
+// Reconcile reports if the cluster is in the maintenance window of a scheduled upgrade or an upgrade is in progress,
+// and the upgrades which have not completed yet with the time until they start. The request is requeued for the start
+// or end of the window, as the window opens and closes without the object changing, and to refresh the time until
+// the upgrade.
+func (r *UpgradeConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling UpgradeConfig")
+
+	upgradeConfigs := &unstructured.UnstructuredList{}
+	upgradeConfigs.SetGroupVersionKind(UpgradeConfigKind.ListGroupVersionKind())
+	if err := r.Client.List(ctx, upgradeConfigs); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	current := now()
+	inWindow := false
+	timeUntilUpgrade := map[string]time.Duration{}
+	var requeueAfter time.Duration
+	for _, uc := range upgradeConfigs.Items {
+		desired, _, _ := unstructured.NestedString(uc.Object, "spec", "desired", "version")
+		phase := desiredVersionPhase(uc)
+		if phase == upgradingPhase {
+			inWindow = true
+			timeUntilUpgrade[desired] = 0
+			continue
+		}
+		upgradeAt, found, err := unstructured.NestedString(uc.Object, "spec", "upgradeAt")
+		if err != nil || !found {
+			continue
+		}
+		start, err := time.Parse(time.RFC3339, upgradeAt)
+		if err != nil {
+			reqLogger.Error(err, "invalid upgradeAt", "upgradeAt", upgradeAt)
+			continue
+		}
+		if desired != "" && phase != upgradedPhase {
+			timeUntilUpgrade[desired] = 0
+			if current.Before(start) {
+				timeUntilUpgrade[desired] = start.Sub(current)
+				requeueAfter = minDuration(requeueAfter, untilUpgradeRequeueInterval)
+			}
+		}
+		end := start.Add(MaintenanceWindowDuration)
+		switch {
+		case current.Before(start):
+			requeueAfter = minDuration(requeueAfter, start.Sub(current))
+		case current.Before(end):
+			inWindow = true
+			requeueAfter = minDuration(requeueAfter, end.Sub(current))
+		}
+	}
+	r.Metrics.SetInMaintenanceWindow(r.ClusterId, inWindow)
+	r.Metrics.SetScheduledUpgrades(r.ClusterId, timeUntilUpgrade)
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// desiredVersionPhase returns the phase of the upgrade to the desired version, or an empty string if it has not started
+func desiredVersionPhase(uc unstructured.Unstructured) string {
+	desired, _, _ := unstructured.NestedString(uc.Object, "spec", "desired", "version")
+	history, _, _ := unstructured.NestedSlice(uc.Object, "status", "history")
+	for _, h := range history {
+		entry, ok := h.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if phase, ok := entry["phase"].(string); ok && entry["version"] == desired {
+			return phase
+		}
+	}
+	return ""
+}
+
+// minDuration returns the smaller of two durations, treating zero as unset
+func minDuration(a, b time.Duration) time.Duration {
+	if a == 0 || b < a {
+		return b
+	}
+	return a
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *UpgradeConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(UpgradeConfigKind.GroupVersionKind())
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("UpgradeConfig")).
+		For(obj).
+		Complete(r)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgradeconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testUpgradeAt = "2022-10-20T10:00:00Z"
+
+func makeTestUpgradeConfig(upgradeAt string, phase string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(UpgradeConfigKind.GroupVersionKind())
+	obj.SetName("managed-upgrade-config")
+	obj.SetNamespace("openshift-managed-upgrade-operator")
+	obj.Object["spec"] = map[string]interface{}{
+		"upgradeAt": upgradeAt,
+		"desired":   map[string]interface{}{"version": "4.11.9"},
+	}
+	if phase != "" {
+		obj.Object["status"] = map[string]interface{}{
+			"history": []interface{}{
+				map[string]interface{}{"version": "4.11.9", "phase": phase},
+			},
+		}
+	}
+	return obj
+}
+
+func TestReconcileUpgradeConfig_Reconcile(t *testing.T) {
+	upgradeAt, err := time.Parse(time.RFC3339, testUpgradeAt)
+	require.NoError(t, err)
+	defer func() { now = time.Now }()
+
+	for _, tc := range []struct {
+		name            string
+		now             time.Time
+		objects         []client.Object
+		expected        int
+		expectedRequeue time.Duration
+		// expectedScheduled is set if the upgrade is reported as scheduled, starting after expectedUntilUpgrade
+		expectedScheduled    bool
+		expectedUntilUpgrade time.Duration
+	}{
+		{
+			name:     "no upgrade scheduled",
+			now:      upgradeAt,
+			expected: 0,
+		},
+		{
+			name:                 "before the window",
+			now:                  upgradeAt.Add(-time.Hour),
+			objects:              []client.Object{makeTestUpgradeConfig(testUpgradeAt, "")},
+			expected:             0,
+			expectedRequeue:      untilUpgradeRequeueInterval,
+			expectedScheduled:    true,
+			expectedUntilUpgrade: time.Hour,
+		},
+		{
+			name:                 "shortly before the window",
+			now:                  upgradeAt.Add(-time.Minute),
+			objects:              []client.Object{makeTestUpgradeConfig(testUpgradeAt, "New")},
+			expected:             0,
+			expectedRequeue:      time.Minute,
+			expectedScheduled:    true,
+			expectedUntilUpgrade: time.Minute,
+		},
+		{
+			name:              "in the window",
+			now:               upgradeAt.Add(30 * time.Minute),
+			objects:           []client.Object{makeTestUpgradeConfig(testUpgradeAt, "Pending")},
+			expected:          1,
+			expectedRequeue:   MaintenanceWindowDuration - 30*time.Minute,
+			expectedScheduled: true,
+		},
+		{
+			name:     "after the window",
+			now:      upgradeAt.Add(MaintenanceWindowDuration),
+			objects:  []client.Object{makeTestUpgradeConfig(testUpgradeAt, "Upgraded")},
+			expected: 0,
+		},
+		{
+			name:              "upgrade running past the window",
+			now:               upgradeAt.Add(MaintenanceWindowDuration + time.Hour),
+			objects:           []client.Object{makeTestUpgradeConfig(testUpgradeAt, "Upgrading")},
+			expected:          1,
+			expectedScheduled: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			now = func() time.Time { return tc.now }
+			fakeClient := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(tc.objects...).Build()
+			reconciler := &UpgradeConfigReconciler{
+				Client:    fakeClient,
+				Metrics:   NewMetrics(metrics.NewMetricsAggregator("cluster-id")),
+				ClusterId: "cluster-id",
+			}
+			result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Namespace: "openshift-managed-upgrade-operator", Name: "managed-upgrade-config"},
+			})
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedRequeue, result.RequeueAfter)
+			require.EqualValues(t, tc.expected, testutil.ToFloat64(reconciler.Metrics.maintenanceWindow))
+			if !tc.expectedScheduled {
+				require.Equal(t, 0, testutil.CollectAndCount(reconciler.Metrics.scheduled))
+				return
+			}
+			require.EqualValues(t, 1, testutil.ToFloat64(reconciler.Metrics.scheduled.With("cluster-id", "4.11.9")))
+			require.Equal(t, tc.expectedUntilUpgrade.Seconds(), testutil.ToFloat64(reconciler.Metrics.untilUpgrade.With("cluster-id", "4.11.9")))
+		})
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// IgnoreFieldUpdates returns a predicate skipping the updates which change nothing but the given fields of an
+// object, e.g. status or status.conditions, and its resourceVersion and managedFields. It is meant for controllers
+// whose metrics do not depend on these fields. Resyncs do not change the resourceVersion and are never skipped, so
+// the metrics still heal on every resync period.
+func IgnoreFieldUpdates(fields ...string) predicate.Predicate {
+	paths := [][]string{{"metadata", "resourceVersion"}, {"metadata", "managedFields"}}
+	for _, field := range fields {
+		paths = append(paths, strings.Split(field, "."))
+	}
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil || e.ObjectOld.GetResourceVersion() == e.ObjectNew.GetResourceVersion() {
+				return true
+			}
+			old, err := withoutFields(e.ObjectOld, paths)
+			if err != nil {
+				return true
+			}
+			updated, err := withoutFields(e.ObjectNew, paths)
+			if err != nil {
+				return true
+			}
+			return !reflect.DeepEqual(old, updated)
+		},
+	}
+}
+
+// IgnoreStatusUpdates returns a predicate skipping the updates which only change the status of an object, for
+// controllers reading only its metadata and spec
+func IgnoreStatusUpdates() predicate.Predicate {
+	return IgnoreFieldUpdates("status")
+}
+
+// withoutFields returns the object as unstructured content without the fields at paths. The object is not changed.
+func withoutFields(obj client.Object, paths [][]string) (map[string]interface{}, error) {
+	var content map[string]interface{}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		content = u.DeepCopy().Object
+	} else {
+		var err error
+		content, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, path := range paths {
+		unstructured.RemoveNestedField(content, path...)
+	}
+	return content, nil
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestIgnoreStatusUpdates(t *testing.T) {
+	old := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "b", ResourceVersion: "1"},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+	}
+	update := func(mutate func(*corev1.Service)) bool {
+		updated := old.DeepCopy()
+		updated.ResourceVersion = "2"
+		mutate(updated)
+		return IgnoreStatusUpdates().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated})
+	}
+
+	require.False(t, update(func(s *corev1.Service) {
+		s.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "lb"}}
+	}), "status updates are skipped")
+	require.False(t, update(func(s *corev1.Service) {
+		s.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubectl"}}
+	}), "managed fields updates are skipped")
+	require.True(t, update(func(s *corev1.Service) { s.Spec.Type = corev1.ServiceTypeClusterIP }))
+	require.True(t, update(func(s *corev1.Service) { s.Annotations = map[string]string{"a": "b"} }))
+	require.True(t, IgnoreStatusUpdates().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: old}), "resyncs are reconciled")
+	require.True(t, IgnoreStatusUpdates().Delete(event.DeleteEvent{Object: old}))
+}
+
+func TestIgnoreFieldUpdates_Unstructured(t *testing.T) {
+	old := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "a", "resourceVersion": "1"},
+		"spec":     map[string]interface{}{"state": "Active"},
+		"status":   map[string]interface{}{"conditions": []interface{}{"x"}, "replicas": int64(3)},
+	}}
+	update := func(field string, value interface{}) bool {
+		updated := old.DeepCopy()
+		updated.SetResourceVersion("2")
+		require.NoError(t, unstructured.SetNestedField(updated.Object, value, "status", field))
+		return IgnoreFieldUpdates("status.conditions").Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated})
+	}
+
+	require.False(t, update("conditions", []interface{}{"y"}))
+	require.True(t, update("replicas", int64(2)))
+	require.Equal(t, "1", old.GetResourceVersion(), "the objects are not changed")
+}
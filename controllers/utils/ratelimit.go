@@ -0,0 +1,112 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+)
+
+// baseBackoff is the delay of the first retry of an object whose reconcile failed, doubled on every failure
+const baseBackoff = 5 * time.Millisecond
+
+// RateLimit limits how often a controller reconciles, so a flapping object cannot hot-loop the exporter
+type RateLimit struct {
+	// QPS and Burst limit the reconciles of all objects of the controller
+	QPS   float64
+	Burst int
+	// MaxBackoff is the longest delay before an object whose reconciles keep failing is retried
+	MaxBackoff time.Duration
+}
+
+// DefaultRateLimit is the rate limit of controller-runtime
+var DefaultRateLimit = RateLimit{QPS: 10, Burst: 100, MaxBackoff: 1000 * time.Second}
+
+var rateLimits = struct {
+	sync.Mutex
+	defaults RateLimit
+	// controllers are the rate limits of single controllers by name, overriding the defaults
+	controllers map[string]RateLimit
+}{defaults: DefaultRateLimit}
+
+// SetRateLimits sets the rate limits of the controllers set up afterwards
+func SetRateLimits(defaults RateLimit, controllers map[string]RateLimit) {
+	rateLimits.Lock()
+	defer rateLimits.Unlock()
+	rateLimits.defaults = defaults
+	rateLimits.controllers = controllers
+}
+
+// ControllerOptions returns the options of the controller with the name it is enabled with, with its rate limit
+func ControllerOptions(name string) controller.Options {
+	rateLimits.Lock()
+	limit, ok := rateLimits.controllers[name]
+	if !ok {
+		limit = rateLimits.defaults
+	}
+	rateLimits.Unlock()
+	return controller.Options{
+		RateLimiter: workqueue.NewMaxOfRateLimiter(
+			workqueue.NewItemExponentialFailureRateLimiter(baseBackoff, limit.MaxBackoff),
+			&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(limit.QPS), limit.Burst)},
+		),
+	}
+}
+
+// ParseRateLimits parses the rate limits of single controllers, e.g. ControlPlaneMachineSet=1:5:5m,Node=2:10:1m,
+// with the QPS, burst and max backoff of each. Fields which are left empty are the ones of defaults.
+func ParseRateLimits(s string, defaults RateLimit) (map[string]RateLimit, error) {
+	limits := make(map[string]RateLimit)
+	if s == "" {
+		return limits, nil
+	}
+	for _, entry := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(entry, "=")
+		fields := strings.Split(value, ":")
+		if !ok || name == "" || len(fields) != 3 {
+			return nil, fmt.Errorf("invalid rate limit %q, expected <controller>=<qps>:<burst>:<max backoff>", entry)
+		}
+		limit := defaults
+		if fields[0] != "" {
+			qps, err := strconv.ParseFloat(fields[0], 64)
+			if err != nil || qps <= 0 {
+				return nil, fmt.Errorf("invalid QPS %q of controller %s", fields[0], name)
+			}
+			limit.QPS = qps
+		}
+		if fields[1] != "" {
+			burst, err := strconv.Atoi(fields[1])
+			if err != nil || burst <= 0 {
+				return nil, fmt.Errorf("invalid burst %q of controller %s", fields[1], name)
+			}
+			limit.Burst = burst
+		}
+		if fields[2] != "" {
+			maxBackoff, err := time.ParseDuration(fields[2])
+			if err != nil || maxBackoff < baseBackoff {
+				return nil, fmt.Errorf("invalid max backoff %q of controller %s", fields[2], name)
+			}
+			limit.MaxBackoff = maxBackoff
+		}
+		limits[name] = limit
+	}
+	return limits, nil
+}
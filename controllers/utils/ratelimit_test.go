@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimits(t *testing.T) {
+	limits, err := ParseRateLimits("ControlPlaneMachineSet=1:5:5m,Node=::1m", DefaultRateLimit)
+	require.NoError(t, err)
+	require.Equal(t, map[string]RateLimit{
+		"ControlPlaneMachineSet": {QPS: 1, Burst: 5, MaxBackoff: 5 * time.Minute},
+		"Node":                   {QPS: DefaultRateLimit.QPS, Burst: DefaultRateLimit.Burst, MaxBackoff: time.Minute},
+	}, limits)
+
+	limits, err = ParseRateLimits("", DefaultRateLimit)
+	require.NoError(t, err)
+	require.Empty(t, limits)
+
+	for _, invalid := range []string{"Node", "Node=1:5", "=1:5:5m", "Node=0:5:5m", "Node=1:x:5m", "Node=1:5:1ms"} {
+		_, err := ParseRateLimits(invalid, DefaultRateLimit)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestControllerOptions(t *testing.T) {
+	defer SetRateLimits(DefaultRateLimit, nil)
+	SetRateLimits(DefaultRateLimit, map[string]RateLimit{
+		"Node":  {QPS: 1, Burst: 1, MaxBackoff: time.Second},
+		"Proxy": {QPS: 1000, Burst: 1000, MaxBackoff: time.Second},
+	})
+
+	limiter := ControllerOptions("Node").RateLimiter
+	require.Equal(t, baseBackoff, limiter.When("a"), "the burst allows the first reconcile")
+	require.Greater(t, limiter.When("b"), 500*time.Millisecond, "the bucket is empty")
+
+	limiter = ControllerOptions("Proxy").RateLimiter
+	for i := 0; i < 20; i++ {
+		limiter.When("a")
+	}
+	require.Equal(t, time.Second, limiter.When("a"), "the backoff is capped")
+
+	require.Equal(t, baseBackoff, ControllerOptions("ClusterRole").RateLimiter.When("a"))
+}
@@ -13,6 +13,11 @@ limitations under the License.
 
 package utils
 
+import "strings"
+
+// MachineAPINamespace is where the machine-api keeps the Machines and MachineSets
+const MachineAPINamespace = "openshift-machine-api"
+
 func ContainsString(stringArray []string, candidate string) bool {
 	for _, s := range stringArray {
 		if s == candidate {
@@ -21,3 +26,16 @@ func ContainsString(stringArray []string, candidate string) bool {
 	}
 	return false
 }
+
+// managedNamespacePrefixes are the prefixes of namespaces shipped with the platform
+var managedNamespacePrefixes = []string{"openshift-", "kube-"}
+
+// IsManagedNamespace returns true if the namespace is one of the platform namespaces
+func IsManagedNamespace(namespace string) bool {
+	for _, prefix := range managedNamespacePrefixes {
+		if strings.HasPrefix(namespace, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+const (
+	typeLabel          = "type"
+	failurePolicyLabel = "failure_policy"
+)
+
+// Metrics count the admission webhooks not served from a platform namespace, which can block or change the
+// requests of managed components
+type Metrics struct {
+	metrics.MetricSet
+	webhooks *metrics.Gauges
+}
+
+// NewMetrics registers admission_webhook_count, with a series per webhook type and failure policy
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		webhooks: a.NewGauges("admission_webhook_count", "Indicates the number of third-party admission webhooks", typeLabel, failurePolicyLabel),
+	}
+	m.MetricSet = metrics.NewMetricSet("AdmissionWebhook", m.webhooks)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetAdmissionWebhookCount(uuid string, webhookType string, failurePolicy string, count int) {
+	m.webhooks.With(uuid, webhookType, failurePolicy).Set(float64(count))
+}
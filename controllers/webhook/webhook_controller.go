@@ -0,0 +1,114 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	validatingWebhookType = "validating"
+	mutatingWebhookType   = "mutating"
+)
+
+var log = logf.Log.WithName("controller_webhook")
+
+var knownFailurePolicies = []admissionregistrationv1.FailurePolicyType{
+	admissionregistrationv1.Fail,
+	admissionregistrationv1.Ignore,
+}
+
+// AdmissionWebhookReconciler reconciles ValidatingWebhookConfiguration and MutatingWebhookConfiguration objects
+type AdmissionWebhookReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Metrics
+	ClusterId string
+}
+
+// Reconcile lists all webhook configurations and counts the third-party webhooks by type and failure policy.
+// Every event results in a full recount, so the request itself is only used for logging.
+func (r *AdmissionWebhookReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling admission webhooks")
+
+	validating := &admissionregistrationv1.ValidatingWebhookConfigurationList{}
+	if err := r.Client.List(ctx, validating); err != nil {
+		return ctrl.Result{}, err
+	}
+	mutating := &admissionregistrationv1.MutatingWebhookConfigurationList{}
+	if err := r.Client.List(ctx, mutating); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	counts := map[string]map[admissionregistrationv1.FailurePolicyType]int{
+		validatingWebhookType: {},
+		mutatingWebhookType:   {},
+	}
+	for _, configuration := range validating.Items {
+		for _, w := range configuration.Webhooks {
+			if isManagedWebhook(w.ClientConfig) {
+				continue
+			}
+			counts[validatingWebhookType][failurePolicy(w.FailurePolicy)]++
+		}
+	}
+	for _, configuration := range mutating.Items {
+		for _, w := range configuration.Webhooks {
+			if isManagedWebhook(w.ClientConfig) {
+				continue
+			}
+			counts[mutatingWebhookType][failurePolicy(w.FailurePolicy)]++
+		}
+	}
+
+	for webhookType, policies := range counts {
+		for _, policy := range knownFailurePolicies {
+			r.Metrics.SetAdmissionWebhookCount(r.ClusterId, webhookType, string(policy), policies[policy])
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// isManagedWebhook returns true if the webhook is served from a platform namespace.
+// Webhooks called through a URL are never considered managed.
+func isManagedWebhook(clientConfig admissionregistrationv1.WebhookClientConfig) bool {
+	return clientConfig.Service != nil && utils.IsManagedNamespace(clientConfig.Service.Namespace)
+}
+
+// failurePolicy returns the effective failure policy, which defaults to Fail in admissionregistration/v1
+func failurePolicy(policy *admissionregistrationv1.FailurePolicyType) admissionregistrationv1.FailurePolicyType {
+	if policy == nil {
+		return admissionregistrationv1.Fail
+	}
+	return *policy
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *AdmissionWebhookReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(utils.ControllerOptions("AdmissionWebhook")).
+		For(&admissionregistrationv1.ValidatingWebhookConfiguration{}).
+		Watches(&source.Kind{Type: &admissionregistrationv1.MutatingWebhookConfiguration{}}, &handler.EnqueueRequestForObject{}).
+		Complete(r)
+}
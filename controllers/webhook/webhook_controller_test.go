@@ -0,0 +1,116 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testClusterId = "i-am-a-cluster-id"
+
+func serviceClientConfig(namespace string) admissionregistrationv1.WebhookClientConfig {
+	return admissionregistrationv1.WebhookClientConfig{
+		Service: &admissionregistrationv1.ServiceReference{Namespace: namespace, Name: "webhook"},
+	}
+}
+
+func makeTestValidatingWebhook(name string, clientConfig admissionregistrationv1.WebhookClientConfig, policy *admissionregistrationv1.FailurePolicyType) *admissionregistrationv1.ValidatingWebhookConfiguration {
+	return &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{Name: name + ".example.com", ClientConfig: clientConfig, FailurePolicy: policy},
+		},
+	}
+}
+
+func makeTestMutatingWebhook(name string, clientConfig admissionregistrationv1.WebhookClientConfig, policy *admissionregistrationv1.FailurePolicyType) *admissionregistrationv1.MutatingWebhookConfiguration {
+	return &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{
+			{Name: name + ".example.com", ClientConfig: clientConfig, FailurePolicy: policy},
+		},
+	}
+}
+
+func TestReconcileAdmissionWebhook_Reconcile(t *testing.T) {
+	fail := admissionregistrationv1.Fail
+	ignore := admissionregistrationv1.Ignore
+	url := "https://webhook.example.com"
+
+	for _, tc := range []struct {
+		name            string
+		objects         []client.Object
+		expectedResults string
+	}{
+		{
+			name: "no webhooks",
+			expectedResults: `
+# HELP admission_webhook_count Indicates the number of third-party admission webhooks
+# TYPE admission_webhook_count gauge
+admission_webhook_count{_id="i-am-a-cluster-id",failure_policy="Fail",name="osd_exporter",type="mutating"} 0
+admission_webhook_count{_id="i-am-a-cluster-id",failure_policy="Fail",name="osd_exporter",type="validating"} 0
+admission_webhook_count{_id="i-am-a-cluster-id",failure_policy="Ignore",name="osd_exporter",type="mutating"} 0
+admission_webhook_count{_id="i-am-a-cluster-id",failure_policy="Ignore",name="osd_exporter",type="validating"} 0
+`,
+		},
+		{
+			name: "managed webhooks are ignored",
+			objects: []client.Object{
+				makeTestValidatingWebhook("managed-validating", serviceClientConfig("openshift-validation-webhook"), &fail),
+				makeTestMutatingWebhook("managed-mutating", serviceClientConfig("kube-system"), &ignore),
+				makeTestValidatingWebhook("customer-validating", serviceClientConfig("customer"), nil),
+				makeTestValidatingWebhook("customer-url", admissionregistrationv1.WebhookClientConfig{URL: &url}, &ignore),
+				makeTestMutatingWebhook("customer-mutating", serviceClientConfig("customer"), &fail),
+			},
+			expectedResults: `
+# HELP admission_webhook_count Indicates the number of third-party admission webhooks
+# TYPE admission_webhook_count gauge
+admission_webhook_count{_id="i-am-a-cluster-id",failure_policy="Fail",name="osd_exporter",type="mutating"} 1
+admission_webhook_count{_id="i-am-a-cluster-id",failure_policy="Fail",name="osd_exporter",type="validating"} 1
+admission_webhook_count{_id="i-am-a-cluster-id",failure_policy="Ignore",name="osd_exporter",type="mutating"} 0
+admission_webhook_count{_id="i-am-a-cluster-id",failure_policy="Ignore",name="osd_exporter",type="validating"} 1
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tc.objects...).Build()
+			reconciler := AdmissionWebhookReconciler{
+				Client:    fakeClient,
+				Metrics:   NewMetrics(metrics.NewMetricsAggregator(testClusterId)),
+				ClusterId: testClusterId,
+			}
+			result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: "test"},
+			})
+			require.NoError(t, err)
+			require.NotNil(t, result)
+
+			err = testutil.CollectAndCompare(reconciler.Metrics.webhooks, strings.NewReader(tc.expectedResults))
+			require.NoError(t, err)
+		})
+	}
+}
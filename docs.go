@@ -0,0 +1,105 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+// docsCommand is the subcommand documenting the exporter from its code, e.g. osd-metrics-exporter docs metrics
+const docsCommand = "docs"
+
+// documents write a document from an aggregator with the metrics of all controllers, with the arguments after
+// their name
+var documents = map[string]func(args []string, aggregator *metrics.AdoptionMetricsAggregator, out io.Writer) error{
+	"metrics": documentMetrics,
+}
+
+// runDocs writes the document named by the first argument to out
+func runDocs(args []string, out io.Writer) error {
+	var names []string
+	for name := range documents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(args) == 0 {
+		return fmt.Errorf("expected one of %s", strings.Join(names, ", "))
+	}
+	document, ok := documents[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown document %q, expected one of %s", args[0], strings.Join(names, ", "))
+	}
+	aggregator, err := exporterAggregator()
+	if err != nil {
+		return err
+	}
+	return document(args[1:], aggregator, out)
+}
+
+// builtinController is the controller shown for the metrics without a Collector registering them, which are
+// builtin to the aggregator
+const builtinController = "aggregator"
+
+// documentMetrics writes the catalog of the metrics of the exporter as a markdown table, or with --format=json as
+// the catalog served on /catalog
+func documentMetrics(args []string, aggregator *metrics.AdoptionMetricsAggregator, out io.Writer) error {
+	flags := flag.NewFlagSet(docsCommand+" metrics", flag.ContinueOnError)
+	format := flags.String("format", "markdown", "markdown for a table of the metrics, json for the catalog")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	catalog := aggregator.Catalog()
+	switch *format {
+	case "markdown":
+		var b strings.Builder
+		fmt.Fprintf(&b, "# Metrics\n\n<!-- Generated by osd-metrics-exporter %s metrics, do not edit. -->\n\n", docsCommand)
+		b.WriteString("| Name | Type | Labels | Controller | Help |\n")
+		b.WriteString("| --- | --- | --- | --- | --- |\n")
+		for _, entry := range catalog {
+			labels := make([]string, len(entry.Labels))
+			for i, label := range entry.Labels {
+				labels[i] = "`" + label + "`"
+			}
+			controller := entry.Controller
+			if controller == "" {
+				controller = builtinController
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s |\n", entry.Name, entry.Type, strings.Join(labels, ", "),
+				controller, markdownCell(entry.Help))
+		}
+		_, err := io.WriteString(out, b.String())
+		return err
+	case "json":
+		data, err := json.MarshalIndent(catalog, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(out, "%s\n", data)
+		return err
+	default:
+		return fmt.Errorf("unknown format %q, expected markdown or json", *format)
+	}
+}
+
+// markdownCell escapes text for a cell of a markdown table
+func markdownCell(text string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(text)
+}
@@ -0,0 +1,322 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	promOperatorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/openshift/osd-metrics-exporter/pkg/silence"
+)
+
+// generateCommand is the subcommand generating the config of the monitoring pipeline from the metrics of the
+// exporter, e.g. osd-metrics-exporter generate telemeter-allowlist
+const generateCommand = "generate"
+
+// generators generate a config from an aggregator with the metrics of all controllers, with the arguments after
+// their name
+var generators = map[string]func(args []string, aggregator *metrics.AdoptionMetricsAggregator, out io.Writer) error{
+	"telemeter-allowlist": generateTelemeterAllowlist,
+	"prometheusrules":     generatePrometheusRules,
+	"dashboard":           generateDashboard,
+}
+
+// runGenerate runs the generator named by the first argument and writes the config to out
+func runGenerate(args []string, out io.Writer) error {
+	var names []string
+	for name := range generators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(args) == 0 {
+		return fmt.Errorf("expected one of %s", strings.Join(names, ", "))
+	}
+	generate, ok := generators[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown generator %q, expected one of %s", args[0], strings.Join(names, ", "))
+	}
+	aggregator, err := exporterAggregator()
+	if err != nil {
+		return err
+	}
+	return generate(args[1:], aggregator, out)
+}
+
+// exporterAggregator returns an aggregator with the metrics of all controllers and the reconcile metrics. The
+// metrics of detections and object counters are builtin, so none have to be loaded.
+func exporterAggregator() (*metrics.AdoptionMetricsAggregator, error) {
+	aggregator := metrics.NewMetricsAggregator("")
+	// the controllers and the silence poller register their metrics when they are created
+	offlineControllers(nil, aggregator, "", nil, nil)
+	silence.NewMetrics(aggregator)
+	if err := aggregator.ExportReconcileMetrics("", prometheus.NewRegistry()); err != nil {
+		return nil, err
+	}
+	return aggregator, nil
+}
+
+// seriesNames returns the names of the series of a metric, a histogram has its _bucket, _sum and _count series
+func seriesNames(entry metrics.CatalogEntry) []string {
+	if entry.Type == metrics.HistogramType {
+		return []string{entry.Name + "_bucket", entry.Name + "_sum", entry.Name + "_count"}
+	}
+	return []string{entry.Name}
+}
+
+// telemeterAllowlist is the allowlist of the telemeter client of the cluster monitoring operator
+type telemeterAllowlist struct {
+	Matches []string `json:"matches"`
+}
+
+// generateTelemeterAllowlist writes the telemeter allowlist matching every series of the exporter, or with
+// --format=relabel the relabel config of a ServiceMonitor keeping them
+func generateTelemeterAllowlist(args []string, aggregator *metrics.AdoptionMetricsAggregator, out io.Writer) error {
+	flags := flag.NewFlagSet(generateCommand+" telemeter-allowlist", flag.ContinueOnError)
+	format := flags.String("format", "matches", "matches for the telemeter client allowlist, relabel for metricRelabelings keeping the series")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	var names []string
+	for _, entry := range aggregator.Catalog() {
+		names = append(names, seriesNames(entry)...)
+	}
+
+	var config interface{}
+	switch *format {
+	case "matches":
+		allowlist := telemeterAllowlist{}
+		for _, name := range names {
+			allowlist.Matches = append(allowlist.Matches, fmt.Sprintf(`{__name__=%q}`, name))
+		}
+		config = allowlist
+	case "relabel":
+		quoted := make([]string, len(names))
+		for i, name := range names {
+			quoted[i] = regexp.QuoteMeta(name)
+		}
+		config = []*promOperatorv1.RelabelConfig{{
+			SourceLabels: []promOperatorv1.LabelName{"__name__"},
+			Action:       "keep",
+			Regex:        "(" + strings.Join(quoted, "|") + ")",
+		}}
+	default:
+		return fmt.Errorf("unknown format %q, expected matches or relabel", *format)
+	}
+	return writeGenerated(out, "telemeter-allowlist", config)
+}
+
+// generatePrometheusRules writes a PrometheusRule with the alert rules recommended by the collectors, with a group
+// of rules per collector
+func generatePrometheusRules(args []string, aggregator *metrics.AdoptionMetricsAggregator, out io.Writer) error {
+	flags := flag.NewFlagSet(generateCommand+" prometheusrules", flag.ContinueOnError)
+	namespace := flags.String("namespace", watchNamespaces[0], "The namespace of the PrometheusRule")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	rule := &promOperatorv1.PrometheusRule{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: promOperatorv1.SchemeGroupVersion.String(),
+			Kind:       promOperatorv1.PrometheusRuleKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: "osd-metrics-exporter", Namespace: *namespace},
+	}
+	for _, group := range aggregator.AlertGroups() {
+		ruleGroup := promOperatorv1.RuleGroup{Name: "osd-metrics-exporter-" + strings.ToLower(group.Name)}
+		for _, r := range group.Rules {
+			rule := promOperatorv1.Rule{
+				Alert:       r.Alert,
+				Expr:        intstr.FromString(r.Expr),
+				Annotations: map[string]string{},
+			}
+			if r.For > 0 {
+				rule.For = model.Duration(r.For).String()
+			}
+			if r.Severity != "" {
+				rule.Labels = map[string]string{"severity": r.Severity}
+			}
+			if r.Summary != "" {
+				rule.Annotations["summary"] = r.Summary
+			}
+			if r.Description != "" {
+				rule.Annotations["description"] = r.Description
+			}
+			ruleGroup.Rules = append(ruleGroup.Rules, rule)
+		}
+		rule.Spec.Groups = append(rule.Spec.Groups, ruleGroup)
+	}
+	return writeGenerated(out, "prometheusrules", rule)
+}
+
+// clusterIDLabel is the label of the cluster id of the metrics, and the variable selecting it in the dashboard
+const clusterIDLabel = "_id"
+
+// grafanaDashboard is the subset of the Grafana dashboard model the generated dashboard uses
+type grafanaDashboard struct {
+	UID           string            `json:"uid"`
+	Title         string            `json:"title"`
+	Description   string            `json:"description"`
+	Tags          []string          `json:"tags"`
+	SchemaVersion int               `json:"schemaVersion"`
+	Time          grafanaTimeRange  `json:"time"`
+	Templating    grafanaTemplating `json:"templating"`
+	Panels        []grafanaPanel    `json:"panels"`
+}
+
+type grafanaTimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type grafanaTemplating struct {
+	List []grafanaVariable `json:"list"`
+}
+
+type grafanaVariable struct {
+	Name       string `json:"name"`
+	Label      string `json:"label"`
+	Type       string `json:"type"`
+	Datasource string `json:"datasource,omitempty"`
+	Query      string `json:"query"`
+	Refresh    int    `json:"refresh,omitempty"`
+}
+
+type grafanaPanel struct {
+	ID          int             `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Type        string          `json:"type"`
+	Datasource  string          `json:"datasource"`
+	GridPos     grafanaGridPos  `json:"gridPos"`
+	Targets     []grafanaTarget `json:"targets"`
+}
+
+type grafanaGridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type grafanaTarget struct {
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat"`
+	RefID        string `json:"refId"`
+}
+
+// dashboardTarget returns the query of the panel of a metric, selecting the cluster of the _id variable. Counters are
+// shown as their rate and histograms as their 99th percentile.
+func dashboardTarget(entry metrics.CatalogEntry, rateInterval string) grafanaTarget {
+	selector := ""
+	var labels, legend []string
+	for _, label := range entry.Labels {
+		if label == clusterIDLabel {
+			selector = fmt.Sprintf(`{%s="$%s"}`, clusterIDLabel, clusterIDLabel)
+			continue
+		}
+		labels = append(labels, label)
+		legend = append(legend, "{{"+label+"}}")
+	}
+	target := grafanaTarget{Expr: entry.Name + selector, LegendFormat: strings.Join(legend, " "), RefID: "A"}
+	switch entry.Type {
+	case metrics.CounterType:
+		target.Expr = fmt.Sprintf("rate(%s%s[%s])", entry.Name, selector, rateInterval)
+	case metrics.HistogramType:
+		target.Expr = fmt.Sprintf("histogram_quantile(0.99, sum by (%s) (rate(%s_bucket%s[%s])))",
+			strings.Join(append(labels, "le"), ", "), entry.Name, selector, rateInterval)
+		target.LegendFormat = strings.Join(append(legend, "p99"), " ")
+	}
+	if target.LegendFormat == "" {
+		target.LegendFormat = entry.Name
+	}
+	return target
+}
+
+// generateDashboard writes a Grafana dashboard with a panel per metric of the exporter, and the _id variable
+// selecting the cluster
+func generateDashboard(args []string, aggregator *metrics.AdoptionMetricsAggregator, out io.Writer) error {
+	flags := flag.NewFlagSet(generateCommand+" dashboard", flag.ContinueOnError)
+	rateInterval := flags.String("rate-interval", "5m", "The range of the rate of counters and histograms")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if _, err := model.ParseDuration(*rateInterval); err != nil {
+		return fmt.Errorf("invalid rate interval %q: %w", *rateInterval, err)
+	}
+	const datasource = "${datasource}"
+	dashboard := grafanaDashboard{
+		UID:           "osd-metrics-exporter",
+		Title:         "OSD Metrics Exporter",
+		Description:   fmt.Sprintf("Generated by osd-metrics-exporter %s dashboard, do not edit.", generateCommand),
+		Tags:          []string{"osd-metrics-exporter"},
+		SchemaVersion: 36,
+		Time:          grafanaTimeRange{From: "now-6h", To: "now"},
+		Templating: grafanaTemplating{List: []grafanaVariable{
+			{Name: "datasource", Label: "Data source", Type: "datasource", Query: "prometheus"},
+			{
+				Name:       clusterIDLabel,
+				Label:      "Cluster id",
+				Type:       "query",
+				Datasource: datasource,
+				Query:      fmt.Sprintf(`label_values({name="osd_exporter"}, %s)`, clusterIDLabel),
+				// refresh the cluster ids when the time range changes
+				Refresh: 2,
+			},
+		}},
+	}
+	// two panels per row
+	const width, height = 12, 8
+	for i, entry := range aggregator.Catalog() {
+		dashboard.Panels = append(dashboard.Panels, grafanaPanel{
+			ID:          i + 1,
+			Title:       entry.Name,
+			Description: entry.Help,
+			Type:        "timeseries",
+			Datasource:  datasource,
+			GridPos:     grafanaGridPos{H: height, W: width, X: i % 2 * width, Y: i / 2 * height},
+			Targets:     []grafanaTarget{dashboardTarget(entry, *rateInterval)},
+		})
+	}
+	data, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "%s\n", data)
+	return err
+}
+
+// writeGenerated writes the config as YAML, with a comment naming the generator
+func writeGenerated(out io.Writer, generator string, config interface{}) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(out, "# Generated by osd-metrics-exporter %s %s, do not edit.\n", generateCommand, generator); err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
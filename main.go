@@ -15,37 +15,91 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap/zapcore"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	customMetrics "github.com/openshift/operator-custom-metrics/pkg/metrics"
+	metricsv1alpha1 "github.com/openshift/osd-metrics-exporter/api/v1alpha1"
 	operatorConfig "github.com/openshift/osd-metrics-exporter/config"
-	"github.com/openshift/osd-metrics-exporter/controllers/clusterrole"
-	"github.com/openshift/osd-metrics-exporter/controllers/configmap"
+	"github.com/openshift/osd-metrics-exporter/controllers/catalogsource"
+	"github.com/openshift/osd-metrics-exporter/controllers/cpms"
+	"github.com/openshift/osd-metrics-exporter/controllers/egress"
+	"github.com/openshift/osd-metrics-exporter/controllers/exporterconfig"
 	"github.com/openshift/osd-metrics-exporter/controllers/group"
-	"github.com/openshift/osd-metrics-exporter/controllers/limited_support"
-	"github.com/openshift/osd-metrics-exporter/controllers/oauth"
-	"github.com/openshift/osd-metrics-exporter/controllers/proxy"
+	"github.com/openshift/osd-metrics-exporter/controllers/hypershift"
+	mustgathercontroller "github.com/openshift/osd-metrics-exporter/controllers/mustgather"
+	"github.com/openshift/osd-metrics-exporter/controllers/olm"
+	"github.com/openshift/osd-metrics-exporter/controllers/privacy"
+	"github.com/openshift/osd-metrics-exporter/controllers/servicemonitor"
+	"github.com/openshift/osd-metrics-exporter/controllers/upgradeconfig"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/apiusage"
+	"github.com/openshift/osd-metrics-exporter/pkg/cloudwatch"
+	"github.com/openshift/osd-metrics-exporter/pkg/detection"
+	"github.com/openshift/osd-metrics-exporter/pkg/exporterstatus"
+	"github.com/openshift/osd-metrics-exporter/pkg/gate"
+	"github.com/openshift/osd-metrics-exporter/pkg/logging"
 	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/openshift/osd-metrics-exporter/pkg/metricsauth"
+	"github.com/openshift/osd-metrics-exporter/pkg/objectcount"
+	"github.com/openshift/osd-metrics-exporter/pkg/otlp"
+	"github.com/openshift/osd-metrics-exporter/pkg/profiling"
+	"github.com/openshift/osd-metrics-exporter/pkg/remotewrite"
+	"github.com/openshift/osd-metrics-exporter/pkg/scopedcache"
+	"github.com/openshift/osd-metrics-exporter/pkg/silence"
+	"github.com/openshift/osd-metrics-exporter/pkg/upgrade"
 
 	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	quotav1 "github.com/openshift/api/quota/v1"
 	routev1 "github.com/openshift/api/route/v1"
+	securityv1 "github.com/openshift/api/security/v1"
 	userv1 "github.com/openshift/api/user/v1"
 	promOperatorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -53,9 +107,19 @@ import (
 
 // Change below variables to serve metrics on different host or port.
 var (
-	scheme          = runtime.NewScheme()
-	setupLog        = ctrl.Log.WithName("setup")
-	metricsPort     = "8383"
+	scheme        = runtime.NewScheme()
+	setupLog      = ctrl.Log.WithName("setup")
+	metricsPort   = "8383"
+	serviceCAFile = "/var/run/secrets/kubernetes.io/serviceaccount/service-ca.crt"
+	// metricsCertSecretName is the secret the service CA creates the serving certificate of the metrics Service in,
+	// which is mounted in --metrics-cert-dir
+	metricsCertSecretName = "osd-metrics-exporter-metrics-tls"
+	// prometheusServiceCAFile is the service CA bundle mounted in the Prometheus of the cluster monitoring
+	prometheusServiceCAFile = "/etc/prometheus/configmaps/serving-certs-ca-bundle/service-ca.crt"
+	// prometheusTokenFile is the token of the service account of the Prometheus of the cluster monitoring
+	prometheusTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	// eventSource is the component of the Events the controllers record
+	eventSource     = "osd-metrics-exporter"
 	watchNamespaces = []string{
 		"openshift-osd-metrics",
 		"openshift-config",
@@ -71,101 +135,590 @@ func init() {
 	utilruntime.Must(rbacv1.AddToScheme(scheme))
 	utilruntime.Must(routev1.Install(scheme))
 	utilruntime.Must(userv1.Install(scheme))
+	utilruntime.Must(securityv1.Install(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+	utilruntime.Must(operatorv1.Install(scheme))
+	utilruntime.Must(machinev1beta1.Install(scheme))
+	utilruntime.Must(oauthv1.Install(scheme))
+	utilruntime.Must(quotav1.Install(scheme))
+	utilruntime.Must(metricsv1alpha1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == generateCommand {
+		if err := runGenerate(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == docsCommand {
+		if err := runDocs(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var enableLeaderElection bool
+	var leaseDuration time.Duration
+	var renewDeadline time.Duration
+	var retryPeriod time.Duration
+	var readyOnlyWhenLeader bool
+	var gracefulShutdownTimeout time.Duration
+	var finalFlush bool
+	var reconcileRateLimit utils.RateLimit
+	var reconcileRateLimits string
 	var probeAddr string
+	var detectionsFile string
+	var objectCountersFile string
+	var schemaVersionLabel bool
+	var metricsV2Addr string
+	var seedMetricsFile string
+	var hypershiftManagement bool
+	var aggregatorLivenessTimeout time.Duration
+	var reconcileTimeout time.Duration
+	var resyncPeriod time.Duration
+	var exporterStatusInterval time.Duration
+	var dryRun bool
+	var fromMustGather string
+	var offlineControllerNames string
+	var metricOwnershipFile string
+	var alertmanagerURL string
+	var silencePlatformNamespaces string
+	var traceMetrics string
+	var logLevels string
+	var seriesTTLs string
+	var seriesLimit int
+	var enableControllers string
+	var extraLabelsFlag string
+	var fallbackClusterId string
+	var clusterIdOverride string
+	var localMode bool
+	var metricsCertDir string
+	var metricsAuth bool
+	var profilingAddr string
+	var remoteWriteURL string
+	var remoteWriteInterval time.Duration
+	var remoteWriteBearerTokenFile string
+	var remoteWriteCertFile string
+	var remoteWriteKeyFile string
+	var remoteWriteCAFile string
+	var otlpEndpoint string
+	var otlpInterval time.Duration
+	var otlpCAFile string
+	var runOnceMode bool
+	var pushgatewayURL string
+	var manageServiceMonitor bool
+	var cloudWatchMetrics string
+	var cloudWatchNamespace string
+	var cloudWatchRegion string
+	var cloudWatchInterval time.Duration
+	var cloudWatchRoleARN string
+	var cloudWatchTokenFile string
 
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&leaseDuration, "leader-election-lease-duration", 15*time.Second,
+		"How long a replica waits after the last renewal of the leader Lease before taking it over, with --enable-leader-election.")
+	flag.DurationVar(&renewDeadline, "leader-election-renew-deadline", 10*time.Second,
+		"How long the leader retries to renew the Lease before it stops leading and exits, with --enable-leader-election.")
+	flag.DurationVar(&retryPeriod, "leader-election-retry-period", 2*time.Second,
+		"How often the replicas try to acquire or renew the Lease, with --enable-leader-election.")
+	flag.DurationVar(&gracefulShutdownTimeout, "graceful-shutdown-timeout", 30*time.Second,
+		"How long the controllers may take to finish their reconciles when the exporter stops.")
+	flag.BoolVar(&finalFlush, "final-flush", false,
+		"Push the final values of the metrics to the remote write, OTLP, CloudWatch and Pushgateway sinks once the controllers stopped, when the exporter stops.")
+	flag.Float64Var(&reconcileRateLimit.QPS, "reconcile-qps", utils.DefaultRateLimit.QPS,
+		"The reconciles per second of every controller, beyond its burst.")
+	flag.IntVar(&reconcileRateLimit.Burst, "reconcile-burst", utils.DefaultRateLimit.Burst,
+		"The reconciles of every controller allowed at once before --reconcile-qps applies.")
+	flag.DurationVar(&reconcileRateLimit.MaxBackoff, "reconcile-max-backoff", utils.DefaultRateLimit.MaxBackoff,
+		"The longest delay before an object whose reconciles keep failing is retried.")
+	flag.StringVar(&reconcileRateLimits, "reconcile-rate-limits", "",
+		"Comma separated controller=qps:burst:maxBackoff rate limits of single controllers, e.g. ControlPlaneMachineSet=1:5:5m. Empty fields are those of the flags above.")
+	flag.BoolVar(&readyOnlyWhenLeader, "ready-only-when-leader", false,
+		"Fail the readiness probe of the replicas which are not the leader, with --enable-leader-election. The Deployment must allow unavailable replicas to roll out.")
+
+	flag.StringVar(&detectionsFile, "detections-file", "",
+		"Path to a file with declarative detections to export as detection_match_count metrics.")
+	flag.StringVar(&objectCountersFile, "object-counters-file", "",
+		"Path to a file with object counters to export as object_count metrics.")
+	flag.BoolVar(&schemaVersionLabel, "metrics-schema-version-label", false,
+		"Add a schema_version constant label to the metrics served on /metrics.")
+	flag.StringVar(&metricsV2Addr, "metrics-v2-bind-address", "",
+		"The address the "+metrics.MetricsV2Path+" endpoint binds to. The endpoint is disabled when empty.")
+	flag.StringVar(&seedMetricsFile, "seed-metrics-file", "",
+		"Path to a file with metric series to set at start, for dashboard and alert development.")
+	flag.BoolVar(&hypershiftManagement, "hypershift-management", false,
+		"Export the metrics of the HyperShift hosted clusters of this management cluster, with the hosted cluster id as _id.")
+	flag.DurationVar(&aggregatorLivenessTimeout, "aggregator-liveness-timeout", 5*time.Second,
+		"Fail the liveness check when the metrics aggregator stays locked for longer than this.")
+	flag.DurationVar(&resyncPeriod, "resync-period", 0,
+		"How often the controllers reconcile all objects they watch again. 0 keeps the default of controller-runtime, resyncPeriod of the MetricsExporterConfig takes precedence.")
+	flag.DurationVar(&exporterStatusInterval, "exporter-status-interval", exporterstatus.DefaultInterval,
+		"How often the leader writes the health of the controllers to the MetricsExporterStatus. 0 disables it.")
+	flag.DurationVar(&reconcileTimeout, "reconcile-timeout", 0,
+		"Fail the readiness check when a controller had no successful reconcile for longer than this, it must exceed the resync period. 0 disables the check.")
+	flag.StringVar(&traceMetrics, "trace-metrics", "",
+		"Comma separated names of metrics to log every update of, with the caller and the old and new values.")
+	flag.StringVar(&seriesTTLs, "series-ttl", "",
+		"Comma separated metric=duration pairs, the series of a metric which were not updated within its duration are removed.")
+	flag.IntVar(&seriesLimit, "series-limit", 0,
+		"The maximum number of series of each metric, new series of a metric at the limit are dropped. 0 disables the limit.")
+	flag.StringVar(&fromMustGather, "from-must-gather", "",
+		"The directory of a must-gather to compute the metrics from. The metrics are written to stdout and the exporter exits.")
+	flag.StringVar(&enableControllers, "enable-controllers", "",
+		"Comma separated names of the controllers to run, e.g. ControlPlaneMachineSet,Proxy. All of them are run if empty.")
+	flag.StringVar(&extraLabelsFlag, "extra-labels", "",
+		"Comma separated name=value pairs of labels added to every series of the exporter, e.g. environment=stage.")
+	flag.StringVar(&metricsCertDir, "metrics-cert-dir", "",
+		"The directory of the tls.crt and tls.key to serve /metrics with TLS. The certificate is reloaded when it is rotated.")
+	flag.BoolVar(&metricsAuth, "metrics-auth", false,
+		"Only serve /metrics to bearer tokens of users allowed to get /metrics, checked with TokenReviews and SubjectAccessReviews.")
+	flag.StringVar(&profilingAddr, "profiling-addr", "",
+		"The loopback address pprof and the Go runtime metrics are served on, e.g. localhost:6060. They are disabled when empty.")
+	flag.StringVar(&remoteWriteURL, "remote-write-url", "",
+		"The Prometheus remote write endpoint the metrics are pushed to, for clusters whose Prometheus cannot scrape the exporter. Pushing is disabled when empty.")
+	flag.DurationVar(&remoteWriteInterval, "remote-write-interval", remotewrite.DefaultInterval,
+		"How often the metrics are pushed to --remote-write-url.")
+	flag.StringVar(&remoteWriteBearerTokenFile, "remote-write-bearer-token-file", "",
+		"Path to the bearer token the pushes to --remote-write-url authenticate with.")
+	flag.StringVar(&remoteWriteCertFile, "remote-write-cert-file", "",
+		"Path to the client certificate the pushes to --remote-write-url authenticate with.")
+	flag.StringVar(&remoteWriteKeyFile, "remote-write-key-file", "",
+		"Path to the key of --remote-write-cert-file.")
+	flag.StringVar(&remoteWriteCAFile, "remote-write-ca-file", "",
+		"Path to the CA bundle --remote-write-url is verified with. The system roots are used when empty.")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "",
+		"The OpenTelemetry collector the metrics are exported to over OTLP/gRPC, e.g. https://otel-collector:4317. Exporting is disabled when empty.")
+	flag.DurationVar(&otlpInterval, "otlp-interval", otlp.DefaultInterval,
+		"How often the metrics are exported to --otlp-endpoint.")
+	flag.StringVar(&otlpCAFile, "otlp-ca-file", "",
+		"Path to the CA bundle --otlp-endpoint is verified with. The system roots are used when empty.")
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"Run the controllers without a Lease and log every gauge update as JSON, without registering, serving or pushing any metric, recording Events or persisting any write.")
+	flag.BoolVar(&runOnceMode, "run-once", false,
+		"Reconcile every watched object once, push the metrics to --pushgateway-url or write them to stdout, and exit, e.g. in a CronJob.")
+	flag.StringVar(&pushgatewayURL, "pushgateway-url", "",
+		"The Pushgateway the metrics of --run-once, or of --final-flush, are pushed to.")
+	flag.BoolVar(&manageServiceMonitor, "manage-service-monitor", false,
+		"Create and reconcile the ServiceMonitor scraping the exporter and the Role and RoleBinding allowing Prometheus to discover it.")
+	flag.StringVar(&fallbackClusterId, "cluster-id", "",
+		"The cluster id used when it cannot be read from the ClusterVersion, e.g. when the ClusterVersion is not readable.")
+	flag.StringVar(&clusterIdOverride, "cluster-id-override", "",
+		"The cluster id of all series, instead of the id of the ClusterVersion, e.g. when running with --local against a test cluster.")
+	flag.BoolVar(&localMode, "local", false,
+		"Run out of cluster against the cluster of --kubeconfig, e.g. from a workstation: the metrics are served on localhost, "+
+			"without a Lease, a Service or a ServiceMonitor.")
+	flag.StringVar(&offlineControllerNames, "collectors", "",
+		"Comma separated names of the controllers to run with --from-must-gather. All of them are run if empty.")
+	flag.StringVar(&metricOwnershipFile, "metric-ownership-file", "",
+		"Path to a file with the owning team and alerting SLO of metrics, appended to their HELP text and served on "+metrics.CatalogPath+".")
+	flag.StringVar(&alertmanagerURL, "alertmanager-url", "",
+		"The URL of the Alertmanager to report the silences of platform alerts from, e.g. "+silence.DefaultAlertmanagerURL+". Silences are not reported when empty.")
+	flag.StringVar(&silencePlatformNamespaces, "silence-platform-namespaces", silence.DefaultPlatformNamespaces,
+		"Regular expression matching the namespaces of platform alerts, for --alertmanager-url.")
+	flag.StringVar(&cloudWatchMetrics, "cloudwatch-metrics", "",
+		"Comma separated names of gauges to publish to CloudWatch, e.g. cpms_enabled,cluster_admin_enabled. Nothing is published when empty.")
+	flag.StringVar(&cloudWatchNamespace, "cloudwatch-namespace", cloudwatch.DefaultNamespace,
+		"The CloudWatch namespace --cloudwatch-metrics are published in.")
+	flag.StringVar(&cloudWatchRegion, "cloudwatch-region", "",
+		"The region --cloudwatch-metrics are published in. The region of the cluster is used when empty.")
+	flag.DurationVar(&cloudWatchInterval, "cloudwatch-interval", cloudwatch.DefaultInterval,
+		"How often --cloudwatch-metrics are published.")
+	flag.StringVar(&cloudWatchRoleARN, "cloudwatch-role-arn", os.Getenv("AWS_ROLE_ARN"),
+		"The role --cloudwatch-metrics are published with, assumed with --cloudwatch-web-identity-token-file.")
+	flag.StringVar(&cloudWatchTokenFile, "cloudwatch-web-identity-token-file", defaultWebIdentityTokenFile(),
+		"The projected service account token the role of --cloudwatch-role-arn is assumed with.")
+
+	flag.StringVar(&logLevels, "log-levels", "",
+		"Comma separated logger=level pairs overriding --zap-log-level for single loggers, e.g. controller_cpms=debug,gate=error. "+
+			"Every gauge update is logged when "+metrics.TraceLoggerName+" is at debug level.")
+	// Debug logs are opt-in, as they include every gauge update
+	logOpts := zap.Options{Development: true, Level: zapcore.InfoLevel}
+	logOpts.BindFlags(flag.CommandLine)
 
 	flag.Parse()
 
-	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
+	// The updates logged in dry run mode are meant to be processed, so the logs are JSON
+	if dryRun {
+		logOpts.Development = false
+	}
+	levels, levelsErr := logging.ParseLevels(logLevels, logOpts.Level)
+	if levelsErr == nil {
+		logOpts.Level = levels.Min()
+		logOpts.ZapOpts = append(logOpts.ZapOpts, levels.WrapCore())
+	}
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&logOpts)))
+	if levelsErr != nil {
+		setupLog.Error(levelsErr, "invalid --log-levels")
+		os.Exit(1)
+	}
+
+	if dryRun && runOnceMode {
+		setupLog.Error(errors.New("--dry-run and --run-once are exclusive"), "invalid flags")
+		os.Exit(1)
+	}
+	// A local exporter must not take the Lease from the exporter deployed to the cluster
+	if localMode {
+		enableLeaderElection = false
+	}
+
+	var detections []detection.Detection
+	if detectionsFile != "" {
+		var err error
+		detections, err = detection.Load(detectionsFile)
+		if err != nil {
+			setupLog.Error(err, "unable to load detections", "file", detectionsFile)
+			os.Exit(1)
+		}
+	}
+
+	var objectCounters []objectcount.Counter
+	if objectCountersFile != "" {
+		var err error
+		objectCounters, err = objectcount.Load(objectCountersFile)
+		if err != nil {
+			setupLog.Error(err, "unable to load object counters", "file", objectCountersFile)
+			os.Exit(1)
+		}
+	}
+
+	if resyncPeriod < 0 {
+		setupLog.Error(fmt.Errorf("got %s", resyncPeriod), "--resync-period must not be negative")
+		os.Exit(1)
+	}
+	if reconcileRateLimit.QPS <= 0 || reconcileRateLimit.Burst <= 0 {
+		err := fmt.Errorf("--reconcile-qps %v and --reconcile-burst %d must be positive", reconcileRateLimit.QPS, reconcileRateLimit.Burst)
+		setupLog.Error(err, "invalid reconcile rate limit")
+		os.Exit(1)
+	}
+	controllerRateLimits, err := utils.ParseRateLimits(reconcileRateLimits, reconcileRateLimit)
+	if err != nil {
+		setupLog.Error(err, "unable to parse the reconcile rate limits")
+		os.Exit(1)
+	}
+	// The rate limits are read when the controllers are set up
+	utils.SetRateLimits(reconcileRateLimit, controllerRateLimits)
+
+	extraLabels, err := metrics.ParseExtraLabels(extraLabelsFlag)
+	if err != nil {
+		setupLog.Error(err, "unable to parse extra labels")
+		os.Exit(1)
+	}
+
+	if fromMustGather != "" {
+		var names []string
+		if offlineControllerNames != "" {
+			names = strings.Split(offlineControllerNames, ",")
+		}
+		if err := runOffline(context.TODO(), fromMustGather, fallbackClusterId, names, detections, objectCounters, extraLabels, os.Stdout); err != nil {
+			setupLog.Error(err, "unable to compute metrics from must-gather", "dir", fromMustGather)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Record the verbs and resources used by every client created from this config
+	apiUsage := apiusage.NewTracker()
+	cfg := ctrl.GetConfigOrDie()
+	cfg.Wrap(apiUsage.Wrap)
+
+	var metricOwnership map[string]metrics.Ownership
+	if metricOwnershipFile != "" {
+		var err error
+		metricOwnership, err = metrics.LoadOwnership(metricOwnershipFile)
+		if err != nil {
+			setupLog.Error(err, "unable to load metric ownership", "file", metricOwnershipFile)
+			os.Exit(1)
+		}
+	}
+
+	var seedMetrics []metrics.SeedMetric
+	if seedMetricsFile != "" {
+		var err error
+		seedMetrics, err = metrics.LoadSeeds(seedMetricsFile)
+		if err != nil {
+			setupLog.Error(err, "unable to load seed metrics", "file", seedMetricsFile)
+			os.Exit(1)
+		}
+	}
+
+	// The MetricsExporterConfig is read before the manager is created, as its resync period configures the cache
+	configReader, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create client")
+		os.Exit(1)
+	}
+	exporterConfig, err := exporterconfig.LoadSpec(context.TODO(), configReader)
+	if err != nil {
+		setupLog.Error(err, "unable to load the MetricsExporterConfig")
+		os.Exit(1)
+	}
+
+	// Namespaced kinds watched in all namespaces are cached cluster wide, everything else only in watchNamespaces
+	clusterWideKinds := []schema.GroupKind{
+		egress.EgressFirewallKind.GroupKind(),
+		{Group: networkingv1.GroupName, Kind: "NetworkPolicy"},
+		{Group: corev1.GroupName, Kind: "Service"},
+		{Group: corev1.GroupName, Kind: "Pod"},
+		upgradeconfig.UpgradeConfigKind.GroupKind(),
+		olm.SubscriptionKind.GroupKind(),
+		olm.ClusterServiceVersionKind.GroupKind(),
+		catalogsource.CatalogSourceKind.GroupKind(),
+		{Group: machinev1beta1.GroupName, Kind: "Machine"},
+		{Group: machinev1beta1.GroupName, Kind: "MachineSet"},
+		cpms.ControlPlaneMachineSetKind.GroupKind(),
+		{Group: operatorv1.GroupName, Kind: "IngressController"},
+		privacy.PublishingStrategyKind.GroupKind(),
+	}
+	if hypershiftManagement {
+		clusterWideKinds = append(clusterWideKinds, hypershift.HostedClusterKind.GroupKind(), hypershift.NodePoolKind.GroupKind())
+	}
+	for _, d := range detections {
+		if !utils.ContainsString(watchNamespaces, d.Namespace) {
+			clusterWideKinds = append(clusterWideKinds, d.GroupVersionKind().GroupKind())
+		}
+	}
+	for _, c := range objectCounters {
+		clusterWideKinds = append(clusterWideKinds, c.GroupVersionKind().GroupKind())
+	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	// OLM copies every ClusterServiceVersion of an AllNamespaces operator into each namespace, the copies are not cached
+	csv := &unstructured.Unstructured{}
+	csv.SetGroupVersionKind(olm.ClusterServiceVersionKind.GroupVersionKind())
+	notCopiedCSV, err := labels.Parse("!" + olm.CopiedFromLabel)
+	if err != nil {
+		setupLog.Error(err, "unable to parse the ClusterServiceVersion label selector")
+		os.Exit(1)
+	}
+	cacheSelectors := cache.SelectorsByObject{
+		csv: {Label: notCopiedCSV},
+		// only the pods of must-gather runs are watched
+		&corev1.Pod{}: {Label: labels.SelectorFromSet(labels.Set{mustgathercontroller.PodLabel: mustgathercontroller.PodLabelValue})},
+	}
+
+	// The controllers only read these kinds in a single namespace or by a single name, the other objects are not
+	// cached. Detections and object counters may read them anywhere, so the kinds they read are cached in full.
+	scopedKinds := []struct {
+		gvks  []schema.GroupVersionKind
+		field fields.Selector
+	}{
+		{[]schema.GroupVersionKind{machinev1beta1.GroupVersion.WithKind("Machine")}, inNamespace(utils.MachineAPINamespace)},
+		{[]schema.GroupVersionKind{machinev1beta1.GroupVersion.WithKind("MachineSet")}, inNamespace(utils.MachineAPINamespace)},
+		{cpms.ControlPlaneMachineSetKind.GroupVersionKinds(), inNamespace(utils.MachineAPINamespace)},
+		{[]schema.GroupVersionKind{operatorv1.GroupVersion.WithKind("IngressController")}, inNamespace(privacy.IngressOperatorNamespace)},
+		{privacy.PublishingStrategyKind.GroupVersionKinds(), inNamespace(privacy.CloudIngressOperatorNamespace)},
+		{[]schema.GroupVersionKind{userv1.GroupVersion.WithKind("Group")}, fields.OneTermEqualSelector("metadata.name", group.ClusterAdminGroupName)},
+	}
+	var userKinds []schema.GroupKind
+	for _, d := range detections {
+		userKinds = append(userKinds, d.GroupVersionKind().GroupKind())
+	}
+	for _, c := range objectCounters {
+		userKinds = append(userKinds, c.GroupVersionKind().GroupKind())
+	}
+	for _, scoped := range scopedKinds {
+		gk := scoped.gvks[0].GroupKind()
+		if containsGroupKind(userKinds, gk) {
+			setupLog.Info("caching all objects of a kind read by detections or object counters", "kind", gk)
+			continue
+		}
+		// cluster scoped kinds are selected by the cluster wide cache too
+		if !containsGroupKind(clusterWideKinds, gk) {
+			clusterWideKinds = append(clusterWideKinds, gk)
+		}
+		// the selectors are matched by version, so every version the kind may be read with is selected
+		for _, gvk := range scoped.gvks {
+			obj := &unstructured.Unstructured{}
+			obj.SetGroupVersionKind(gvk)
+			cacheSelectors[obj] = cache.ObjectSelector{Field: scoped.field}
+		}
+	}
+
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
 		Scheme: scheme,
 		// Disable metrics serving
 		MetricsBindAddress: "0",
 		Port:               9443,
 
 		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "osd-metrics-exporter-lock",
-		NewCache:               cache.MultiNamespacedCacheBuilder(watchNamespaces),
+		// Only the leader runs the controllers and the pushers, the other replicas wait to take over the Lease
+		// A dry run must not take the Lease from the exporter it is validated next to
+		LeaderElection:             enableLeaderElection && !dryRun,
+		LeaderElectionID:           "osd-metrics-exporter-lock",
+		LeaderElectionNamespace:    operatorConfig.OperatorNamespace,
+		LeaderElectionResourceLock: resourcelock.LeasesResourceLock,
+		// The exporter exits when the manager stops, so the Lease is released for the next leader right away
+		LeaderElectionReleaseOnCancel: true,
+		LeaseDuration:                 &leaseDuration,
+		RenewDeadline:                 &renewDeadline,
+		RetryPeriod:                   &retryPeriod,
+		NewCache:                      scopedcache.Builder(watchNamespaces, cacheSelectors, clusterWideKinds...),
+		SyncPeriod:                    resolveSyncPeriod(resyncPeriod, exporterConfig),
+		// The controllers stop taking work from their queues and finish the reconciles in flight
+		GracefulShutdownTimeout: &gracefulShutdownTimeout,
+		NewClient:               newClient(dryRun),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	setupLog.Info("retrieving cluster id")
-	clusterId, err := getClusterID(mgr.GetAPIReader())
-	if err != nil {
-		setupLog.Error(err, "Failed to retrieve")
-		os.Exit(1)
+	clusterId := clusterIdOverride
+	if clusterId == "" {
+		setupLog.Info("retrieving cluster id")
+		clusterId, err = getClusterID(mgr.GetAPIReader(), fallbackClusterId)
+		if err != nil {
+			setupLog.Error(err, "Failed to retrieve")
+			os.Exit(1)
+		}
 	}
+	apiUsage.ReportTo(func(u apiusage.Usage) {
+		metrics.GetMetricsAggregator(clusterId).SetAPIUsage(clusterId, u.Verb, u.Group, u.Resource)
+	})
 
-	if err = (&clusterrole.ClusterRoleReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "ClusterRole")
-		os.Exit(1)
+	// Disabled controllers are not set up, so they neither cache their resources nor register their metrics.
+	// The controllers of the MetricsExporterConfig take precedence over --enable-controllers.
+	var controllerNames []string
+	if enableControllers != "" {
+		controllerNames = strings.Split(enableControllers, ",")
+	}
+	if len(exporterConfig.Controllers) > 0 {
+		controllerNames = exporterConfig.Controllers
 	}
+	enabledControllers := newControllerSelection(controllerNames)
 
-	if err = (&configmap.ConfigMapReconciler{
-		Client:            mgr.GetClient(),
-		Scheme:            mgr.GetScheme(),
-		MetricsAggregator: metrics.GetMetricsAggregator(clusterId),
-		ClusterId:         clusterId,
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Configmap")
-		os.Exit(1)
+	// restart stops the manager, the exporter exits and is started again with the changed MetricsExporterConfig
+	ctx, restart := context.WithCancel(ctrl.SetupSignalHandler())
+
+	if runOnceMode {
+		if err := runOnce(ctx, mgr.GetCache(), mgr.GetClient(), clusterId, controllerNames, detections, objectCounters, extraLabels, pushgatewayURL); err != nil {
+			setupLog.Error(err, "unable to run once", "pushgateway", pushgatewayURL)
+			os.Exit(1)
+		}
+		return
 	}
 
-	if err = (&group.GroupReconciler{
-		Client:            mgr.GetClient(),
-		Scheme:            mgr.GetScheme(),
-		MetricsAggregator: metrics.GetMetricsAggregator(clusterId),
-		ClusterId:         clusterId,
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Group")
-		os.Exit(1)
+	// No Events are recorded in dry run mode
+	var recorder record.EventRecorder
+	if !dryRun {
+		recorder = mgr.GetEventRecorderFor(eventSource)
 	}
 
-	if err = (&limited_support.LimitedSupportConfigMapReconciler{
-		Client:            mgr.GetClient(),
-		Scheme:            mgr.GetScheme(),
-		MetricsAggregator: metrics.GetMetricsAggregator(clusterId),
-		ClusterId:         clusterId,
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Limited Support")
+	// Controllers watching cluster wide resources are registered with the gate. They are only set up once the CRD
+	// they depend on is Established and the exporter is allowed to watch their resources, so installing an operator
+	// or granting RBAC later on starts them without restarting the exporter.
+	controllerGate, err := gate.NewGate(mgr, metrics.GetMetricsAggregator(clusterId), clusterId)
+	if err != nil {
+		setupLog.Error(err, "unable to create controller gate")
 		os.Exit(1)
 	}
-
-	if err = (&oauth.OAuthReconciler{
-		Client:            mgr.GetClient(),
-		Scheme:            mgr.GetScheme(),
-		MetricsAggregator: metrics.GetMetricsAggregator(clusterId),
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "OAuth")
+	dependencies := controllerDependencies{
+		client:         mgr.GetClient(),
+		apiReader:      mgr.GetAPIReader(),
+		aggregator:     metrics.GetMetricsAggregator(clusterId),
+		clusterId:      clusterId,
+		recorder:       recorder,
+		detections:     detections,
+		objectCounters: objectCounters,
+	}
+	for _, c := range exporterControllers {
+		// enabled is checked first so the controllers skipped below are known controller names
+		if !enabledControllers.enabled(c.name) {
+			continue
+		}
+		// The ClusterVersion controller would move the series of an overridden cluster id to the id of the ClusterVersion
+		if c.name == "ClusterVersion" && clusterIdOverride != "" {
+			continue
+		}
+		if c.name == "HostedCluster" && !hypershiftManagement {
+			continue
+		}
+		reconcilers := c.reconcilers(dependencies)
+		if c.gated {
+			controllerGate.Register(gate.Controller{
+				Name:      c.name,
+				CRDName:   c.crdName,
+				Resources: c.resources,
+				Kinds:     c.kinds,
+				Setup:     setupReconcilers(reconcilers),
+			})
+			continue
+		}
+		for _, r := range reconcilers {
+			if err := r.reconciler.SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", append([]interface{}{"controller", c.name}, r.logValues...)...)
+				os.Exit(1)
+			}
+		}
+	}
+	// The MetricsExporterConfig is watched once its CRD is installed, it cannot be disabled
+	controllerGate.Register(gate.Controller{
+		Name:    "ExporterConfig",
+		CRDName: "metricsexporterconfigs.metrics.managed.openshift.io",
+		Resources: []authorizationv1.ResourceAttributes{
+			{Namespace: operatorConfig.OperatorNamespace, Group: metricsv1alpha1.GroupVersion.Group, Resource: "metricsexporterconfigs"},
+		},
+		Setup: (&exporterconfig.ExporterConfigReconciler{
+			Client:  mgr.GetClient(),
+			Scheme:  mgr.GetScheme(),
+			Applied: exporterConfig,
+			Restart: restart,
+		}).SetupWithManager,
+	})
+	// The MetricsExporterStatus is maintained once its CRD is installed
+	if exporterStatusInterval > 0 {
+		reporter := exporterstatus.NewReporter(mgr.GetClient(), ctrlmetrics.Registry, exporterStatusInterval)
+		controllerGate.Register(gate.Controller{
+			Name:    "ExporterStatus",
+			CRDName: "metricsexporterstatuses.metrics.managed.openshift.io",
+			Resources: []authorizationv1.ResourceAttributes{
+				{Group: metricsv1alpha1.GroupVersion.Group, Resource: "metricsexporterstatuses"},
+			},
+			Setup: func(mgr ctrl.Manager) error {
+				return mgr.Add(reporter)
+			},
+		})
+	}
+	if unknown := enabledControllers.unknown(); len(unknown) > 0 {
+		err := fmt.Errorf("unknown controllers %s", strings.Join(unknown, ","))
+		if len(exporterConfig.Controllers) == 0 {
+			setupLog.Error(err, "unable to enable controllers")
+			os.Exit(1)
+		}
+		// an invalid MetricsExporterConfig should not stop the exporter from starting
+		setupLog.Error(err, "ignoring the unknown controllers of the MetricsExporterConfig")
+	}
+	for name := range controllerRateLimits {
+		if !enabledControllers.checked[name] && name != "ExporterConfig" && name != "ServiceMonitor" {
+			setupLog.Error(fmt.Errorf("unknown controller %s", name), "unable to set the reconcile rate limits")
+			os.Exit(1)
+		}
+	}
+	if err := mgr.Add(controllerGate); err != nil {
+		setupLog.Error(err, "unable to set up gated controllers")
 		os.Exit(1)
 	}
-
-	if err = (&proxy.ProxyReconciler{
-		Client:            mgr.GetClient(),
-		Scheme:            mgr.GetScheme(),
-		MetricsAggregator: metrics.GetMetricsAggregator(clusterId),
-		ClusterId:         clusterId,
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Proxy")
+	if err := mgr.Add(upgrade.NewReadinessEvaluator(metrics.GetMetricsAggregator(clusterId), clusterId)); err != nil {
+		setupLog.Error(err, "unable to set up upgrade readiness evaluation")
 		os.Exit(1)
 	}
+	if alertmanagerURL != "" {
+		poller, err := newSilencePoller(cfg, alertmanagerURL, silencePlatformNamespaces, metrics.GetMetricsAggregator(clusterId), clusterId)
+		if err != nil {
+			setupLog.Error(err, "unable to create silence poller", "url", alertmanagerURL)
+			os.Exit(1)
+		}
+		if err := mgr.Add(poller); err != nil {
+			setupLog.Error(err, "unable to set up silence poller")
+			os.Exit(1)
+		}
+	}
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
@@ -175,30 +728,577 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if err := mgr.AddReadyzCheck("informers", cacheSyncChecker(mgr.GetCache(), cacheSyncCheckTimeout)); err != nil {
+		setupLog.Error(err, "unable to set up informers ready check")
+		os.Exit(1)
+	}
+	if reconcileTimeout > 0 {
+		if err := mgr.AddReadyzCheck("reconcile", metrics.ReconcileChecker(ctrlmetrics.Registry, reconcileTimeout)); err != nil {
+			setupLog.Error(err, "unable to set up reconcile ready check")
+			os.Exit(1)
+		}
+	}
+	if enableLeaderElection && readyOnlyWhenLeader {
+		if err := mgr.AddReadyzCheck("leader", metrics.LeaderChecker(mgr.Elected())); err != nil {
+			setupLog.Error(err, "unable to set up leader ready check")
+			os.Exit(1)
+		}
+	}
 
 	// Setup metrics collector
 	collector := metrics.GetMetricsAggregator(clusterId)
-	done := collector.Run()
-	defer close(done)
-	metricsConfig := customMetrics.NewBuilder(operatorConfig.OperatorNamespace, operatorConfig.OperatorName).
-		WithPath("/metrics").
-		WithPort(metricsPort).
-		WithServiceMonitor().
-		WithCollectors(metrics.GetMetricsAggregator(clusterId).GetMetrics()).
-		GetConfig()
-	if err = customMetrics.ConfigureMetrics(context.TODO(), *metricsConfig); err != nil {
-		setupLog.Error(err, "Failed to run metrics server")
+	if dryRun {
+		collector.TraceAllMetrics()
+	} else {
+		if traceMetrics != "" {
+			if err := collector.TraceMetrics(strings.Split(traceMetrics, ",")...); err != nil {
+				setupLog.Error(err, "unable to trace metrics")
+				os.Exit(1)
+			}
+		}
+		if levels.Enabled(metrics.TraceLoggerName, zapcore.DebugLevel) {
+			collector.DebugAllMetrics()
+		}
+	}
+	ttls, err := metrics.ParseSeriesTTLs(seriesTTLs)
+	if err != nil {
+		setupLog.Error(err, "unable to parse series ttls")
+		os.Exit(1)
+	}
+	if err := collector.ExpireSeries(ttls); err != nil {
+		setupLog.Error(err, "unable to expire series")
+		os.Exit(1)
+	}
+	if err := collector.LimitSeries(clusterId, seriesLimit); err != nil {
+		setupLog.Error(err, "unable to limit series")
+		os.Exit(1)
+	}
+	collector.TrackSeriesUpdates()
+	if err := collector.ExportReconcileMetrics(clusterId, ctrlmetrics.Registry); err != nil {
+		setupLog.Error(err, "unable to export reconcile metrics")
+		os.Exit(1)
+	}
+	if err := collector.SetOwnership(metricOwnership); err != nil {
+		setupLog.Error(err, "unable to set metric ownership", "file", metricOwnershipFile)
+		os.Exit(1)
+	}
+	if err := collector.Seed(clusterId, seedMetrics); err != nil {
+		setupLog.Error(err, "unable to seed metrics", "file", seedMetricsFile)
+		os.Exit(1)
+	}
+	// A locked aggregator only fails the liveness probe, restarting the exporter is the only way to recover
+	if err := mgr.AddHealthzCheck("aggregator", collector.LivenessChecker(aggregatorLivenessTimeout)); err != nil {
+		setupLog.Error(err, "unable to set up aggregator health check")
+		os.Exit(1)
+	}
+	// The extra labels of the MetricsExporterConfig take precedence over --extra-labels
+	if len(exporterConfig.ExtraLabels) > 0 {
+		if err := collector.CheckExtraLabels(exporterConfig.ExtraLabels); err != nil {
+			// an invalid MetricsExporterConfig should not stop the exporter from starting
+			setupLog.Error(err, "ignoring the extra labels of the MetricsExporterConfig")
+		} else {
+			extraLabels = exporterConfig.ExtraLabels
+		}
+	}
+	if err := collector.CheckExtraLabels(extraLabels); err != nil {
+		setupLog.Error(err, "unable to add extra labels")
+		os.Exit(1)
+	}
+	if dryRun {
+		setupLog.Info("starting manager in dry run mode, the metrics are only logged")
+		if err := mgr.Start(ctx); err != nil {
+			setupLog.Error(err, "problem running manager")
+			os.Exit(1)
+		}
+		return
+	}
+	var metricsRegisterer prometheus.Registerer = prometheus.DefaultRegisterer
+	var metricsGatherer prometheus.Gatherer = prometheus.DefaultGatherer
+	if schemaVersionLabel {
+		registry, err := metrics.NewSchemaRegistry(metrics.SchemaVersionV1, extraLabels, collector.GetMetrics())
+		if err != nil {
+			setupLog.Error(err, "unable to create metrics registry", "schemaVersion", metrics.SchemaVersionV1)
+			os.Exit(1)
+		}
+		metricsRegisterer, metricsGatherer = registry, registry
+	} else if err := customMetrics.RegisterMetrics(prometheus.WrapRegistererWith(extraLabels, prometheus.DefaultRegisterer), collector.GetMetrics()); err != nil {
+		setupLog.Error(err, "unable to register metrics")
+		os.Exit(1)
+	}
+	// The server of operator-custom-metrics cannot negotiate OpenMetrics, so /metrics is served here and only
+	// the Service and ServiceMonitor are generated with it
+	// Replicas which are not the leader serve no series, so scrapes of them succeed without duplicating the leader
+	metricsHandlers := map[string]http.Handler{
+		"/metrics": metrics.StandbyHandler(mgr.Elected(),
+			metrics.NewHandler(metricsRegisterer, collector.OwnershipGatherer(metricsGatherer)), metrics.EmptyMetricsHandler()),
+		metrics.CatalogPath:  collector.NewCatalogHandler(),
+		metrics.SnapshotPath: metrics.StandbyHandler(mgr.Elected(), collector.NewSnapshotHandler(), metrics.EmptySnapshotHandler()),
+	}
+	if metricsAuth {
+		for path, handler := range metricsHandlers {
+			metricsHandlers[path] = metricsauth.NewHandler(mgr.GetClient(), handler, metricsauth.DefaultCacheTTL)
+		}
+	}
+	metricsAddr := ":" + metricsPort
+	if localMode {
+		metricsAddr = "localhost:" + metricsPort
+	}
+	if err := mgr.Add(&metricsServer{
+		addr:     metricsAddr,
+		handlers: metricsHandlers,
+		certDir:  metricsCertDir,
+	}); err != nil {
+		setupLog.Error(err, "unable to set up metrics server", "path", "/metrics")
 		os.Exit(1)
 	}
+	// The Service of a local exporter would select the pods of the deployed exporter
+	if !localMode {
+		setupClient, err := client.New(cfg, client.Options{Scheme: scheme})
+		if err != nil {
+			setupLog.Error(err, "unable to create client")
+			os.Exit(1)
+		}
+		serviceMonitor, err := ensureMetricsService(context.TODO(), setupClient, metricsCertDir != "", metricsAuth, manageServiceMonitor)
+		if err != nil {
+			setupLog.Error(err, "Failed to create the metrics service")
+			os.Exit(1)
+		}
+		if manageServiceMonitor {
+			if err := (&servicemonitor.ServiceMonitorReconciler{
+				Client:                   mgr.GetClient(),
+				Scheme:                   mgr.GetScheme(),
+				ServiceMonitor:           serviceMonitor,
+				PrometheusServiceAccount: servicemonitor.DefaultPrometheusServiceAccount,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "ServiceMonitor")
+				os.Exit(1)
+			}
+		}
+	}
+
+	// The v2 schema is served on its own address, so it can be scraped independently of /metrics
+	if metricsV2Addr != "" {
+		collectorList, err := collector.GetMetricsForSchema(metrics.SchemaVersionV2)
+		if err != nil {
+			setupLog.Error(err, "unable to get metrics", "schemaVersion", metrics.SchemaVersionV2)
+			os.Exit(1)
+		}
+		registry, err := metrics.NewSchemaRegistry(metrics.SchemaVersionV2, extraLabels, collectorList)
+		if err != nil {
+			setupLog.Error(err, "unable to create metrics registry", "schemaVersion", metrics.SchemaVersionV2)
+			os.Exit(1)
+		}
+		if err := mgr.Add(&metricsServer{
+			addr: metricsV2Addr,
+			handlers: map[string]http.Handler{
+				metrics.MetricsV2Path: metrics.StandbyHandler(mgr.Elected(),
+					metrics.NewHandler(registry, collector.OwnershipGatherer(registry)), metrics.EmptyMetricsHandler()),
+			},
+		}); err != nil {
+			setupLog.Error(err, "unable to set up metrics server", "path", metrics.MetricsV2Path)
+			os.Exit(1)
+		}
+	}
+
+	// The sinks the metrics are pushed to, for the final flush
+	var flushers []namedFlusher
+
+	// The metrics are pushed as they are served on /metrics
+	if remoteWriteURL != "" {
+		pusher, err := newRemoteWritePusher(remoteWriteURL, remoteWriteInterval, rest.TLSClientConfig{
+			CertFile: remoteWriteCertFile,
+			KeyFile:  remoteWriteKeyFile,
+			CAFile:   remoteWriteCAFile,
+		}, remoteWriteBearerTokenFile, collector.OwnershipGatherer(metricsGatherer))
+		if err != nil {
+			setupLog.Error(err, "unable to create remote write pusher", "url", remoteWriteURL)
+			os.Exit(1)
+		}
+		if err := mgr.Add(pusher); err != nil {
+			setupLog.Error(err, "unable to set up remote write pusher")
+			os.Exit(1)
+		}
+		flushers = append(flushers, namedFlusher{name: "remote write", flusher: pusher})
+	}
+
+	// The metrics are exported over OTLP in parallel with /metrics
+	if otlpEndpoint != "" {
+		exporter, err := newOTLPExporter(otlpEndpoint, otlpInterval, otlpCAFile, collector.OwnershipGatherer(metricsGatherer), clusterId)
+		if err != nil {
+			setupLog.Error(err, "unable to create OTLP exporter", "endpoint", otlpEndpoint)
+			os.Exit(1)
+		}
+		if err := mgr.Add(exporter); err != nil {
+			setupLog.Error(err, "unable to set up OTLP exporter")
+			os.Exit(1)
+		}
+		flushers = append(flushers, namedFlusher{name: "OTLP", flusher: exporter})
+	}
+
+	// The selected gauges are published to CloudWatch as they are served on /metrics
+	if cloudWatchMetrics != "" {
+		publisher, err := newCloudWatchPublisher(context.TODO(), mgr.GetAPIReader(), strings.Split(cloudWatchMetrics, ","), collector.Catalog(),
+			cloudWatchNamespace, cloudWatchRegion, cloudWatchInterval, cloudWatchRoleARN, cloudWatchTokenFile, collector.OwnershipGatherer(metricsGatherer))
+		if err != nil {
+			setupLog.Error(err, "unable to create CloudWatch publisher", "namespace", cloudWatchNamespace)
+			os.Exit(1)
+		}
+		if err := mgr.Add(publisher); err != nil {
+			setupLog.Error(err, "unable to set up CloudWatch publisher")
+			os.Exit(1)
+		}
+		flushers = append(flushers, namedFlusher{name: "CloudWatch", flusher: publisher})
+	}
+	if pushgatewayURL != "" {
+		flushers = append(flushers, namedFlusher{name: "Pushgateway", flusher: pushgatewayFlusher{url: pushgatewayURL, gatherer: collector.OwnershipGatherer(metricsGatherer)}})
+	}
+
+	// pprof and the Go runtime metrics are only served on a loopback address, to be reached with a port-forward
+	if profilingAddr != "" {
+		if err := profiling.ValidateAddr(profilingAddr); err != nil {
+			setupLog.Error(err, "unable to serve profiles")
+			os.Exit(1)
+		}
+		handlers, err := profiling.Handlers()
+		if err != nil {
+			setupLog.Error(err, "unable to create profiling handlers")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&metricsServer{addr: profilingAddr, handlers: handlers}); err != nil {
+			setupLog.Error(err, "unable to set up profiling server", "addr", profilingAddr)
+			os.Exit(1)
+		}
+	}
+
+	var flusher *finalFlusher
+	if finalFlush {
+		flusher = newFinalFlusher(mgr.GetCache(), mgr.Elected(), flushers)
+		if err := mgr.Add(flusher); err != nil {
+			setupLog.Error(err, "unable to set up final flush")
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	err = mgr.Start(ctx)
+	if flusher != nil {
+		flusher.Wait()
+	}
+	if err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
 
-func getClusterID(client client.Reader) (string, error) {
+// metricsServer serves handlers by path on addr until the manager stops, with TLS if certDir is set. It runs on
+// every replica, not only on the leader, so each replica can be scraped.
+type metricsServer struct {
+	addr     string
+	handlers map[string]http.Handler
+	// certDir holds the tls.crt and tls.key of the server, which are reloaded when the service CA rotates them
+	certDir string
+}
+
+func (m *metricsServer) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	for path, handler := range m.handlers {
+		mux.Handle(path, handler)
+	}
+	server := &http.Server{Addr: m.addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	if m.certDir == "" {
+		return m.serve(ctx, server, server.ListenAndServe)
+	}
+	watcher, err := certwatcher.New(filepath.Join(m.certDir, "tls.crt"), filepath.Join(m.certDir, "tls.key"))
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := watcher.Start(ctx); err != nil {
+			setupLog.Error(err, "unable to watch the metrics certificate", "dir", m.certDir)
+		}
+	}()
+	server.TLSConfig = &tls.Config{GetCertificate: watcher.GetCertificate, MinVersion: tls.VersionTLS12}
+	return m.serve(ctx, server, func() error { return server.ListenAndServeTLS("", "") })
+}
+
+// serve runs listen until the server is shut down when ctx is done
+func (m *metricsServer) serve(ctx context.Context, server *http.Server, listen func() error) error {
+	go func() {
+		<-ctx.Done()
+		_ = server.Shutdown(context.Background())
+	}()
+	if err := listen(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (m *metricsServer) NeedLeaderElection() bool {
+	return false
+}
+
+// newClient returns how the manager creates its client. In dry run mode the writes of the controllers are sent
+// with dryRun=All, so they are validated by the API server without being persisted.
+func newClient(dryRun bool) cluster.NewClientFunc {
+	if !dryRun {
+		return cluster.DefaultNewClient
+	}
+	return func(cache cache.Cache, config *rest.Config, options client.Options, uncachedObjects ...client.Object) (client.Client, error) {
+		c, err := cluster.DefaultNewClient(cache, config, options, uncachedObjects...)
+		if err != nil {
+			return nil, err
+		}
+		return client.NewDryRunClient(c), nil
+	}
+}
+
+// cacheSyncCheckTimeout is how long the informers ready check waits for the caches to sync
+const cacheSyncCheckTimeout = time.Second
+
+// cacheSyncChecker returns a readyz check failing until the informers of the cache have synced, as the metrics
+// computed from a partially filled cache are wrong
+func cacheSyncChecker(c cache.Cache, timeout time.Duration) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+		if !c.WaitForCacheSync(ctx) {
+			return errors.New("the informers have not synced")
+		}
+		return nil
+	}
+}
+
+// ensureMetricsService creates or updates the Service of the metrics server and returns the ServiceMonitor scraping
+// it. With manageServiceMonitor, it also ensures the ServiceMonitor and the RBAC of Prometheus, which the
+// ServiceMonitorReconciler then keeps reconciling. With TLS, the service CA issues the serving certificate of the
+// Service, which Prometheus verifies with the service CA bundle. With authentication, Prometheus sends the token of its
+// service account.
+func ensureMetricsService(ctx context.Context, c client.Client, tlsEnabled, authEnabled, manageServiceMonitor bool) (*promOperatorv1.ServiceMonitor, error) {
+	port, err := strconv.ParseInt(metricsPort, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	desiredService, err := customMetrics.GenerateService(int32(port), "/metrics", operatorConfig.OperatorName, operatorConfig.OperatorNamespace, nil)
+	if err != nil {
+		return nil, err
+	}
+	if tlsEnabled {
+		desiredService.Annotations = map[string]string{"service.beta.openshift.io/serving-cert-secret-name": metricsCertSecretName}
+	}
+	desiredServiceMonitor := customMetrics.GenerateServiceMonitor(desiredService)
+	if tlsEnabled {
+		desiredServiceMonitor.Spec.Endpoints[0].Scheme = "https"
+		desiredServiceMonitor.Spec.Endpoints[0].TLSConfig = &promOperatorv1.TLSConfig{
+			SafeTLSConfig: promOperatorv1.SafeTLSConfig{ServerName: fmt.Sprintf("%s.%s.svc", desiredService.Name, desiredService.Namespace)},
+			CAFile:        prometheusServiceCAFile,
+		}
+	}
+	if authEnabled {
+		desiredServiceMonitor.Spec.Endpoints[0].BearerTokenFile = prometheusTokenFile
+	}
+
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: desiredService.Name, Namespace: desiredService.Namespace}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, c, service, func() error {
+		service.Labels = desiredService.Labels
+		if tlsEnabled {
+			if service.Annotations == nil {
+				service.Annotations = map[string]string{}
+			}
+			for k, v := range desiredService.Annotations {
+				service.Annotations[k] = v
+			}
+		}
+		service.Spec.Ports = desiredService.Spec.Ports
+		service.Spec.Selector = desiredService.Spec.Selector
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if manageServiceMonitor {
+		if err := (&servicemonitor.ServiceMonitorReconciler{
+			Client:                   c,
+			Scheme:                   c.Scheme(),
+			ServiceMonitor:           desiredServiceMonitor,
+			PrometheusServiceAccount: servicemonitor.DefaultPrometheusServiceAccount,
+		}).Ensure(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return desiredServiceMonitor, nil
+}
+
+// newRemoteWritePusher creates a Pusher authenticating with the client certificate of tlsConfig or the token of
+// bearerTokenFile. Both are reread when they are rotated.
+func newRemoteWritePusher(url string, interval time.Duration, tlsConfig rest.TLSClientConfig, bearerTokenFile string, gatherer prometheus.Gatherer) (*remotewrite.Pusher, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid remote write interval %s", interval)
+	}
+	httpClient, err := rest.HTTPClientFor(&rest.Config{
+		TLSClientConfig: tlsConfig,
+		BearerTokenFile: bearerTokenFile,
+		Timeout:         interval,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return remotewrite.NewPusher(httpClient, url, gatherer, interval), nil
+}
+
+// newOTLPExporter creates an Exporter verifying the collector with the CA bundle of caFile, or the system roots
+func newOTLPExporter(endpoint string, interval time.Duration, caFile string, gatherer prometheus.Gatherer, clusterId string) (*otlp.Exporter, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid OTLP interval %s", interval)
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates in %s", caFile)
+		}
+	}
+	httpClient, err := otlp.NewClient(endpoint, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	return otlp.NewExporter(httpClient, endpoint, gatherer, interval, map[string]string{"k8s.cluster.uid": clusterId}), nil
+}
+
+// defaultWebIdentityTokenFile returns the token file of the pod identity webhook, or the service account token
+// projected for STS in OpenShift
+func defaultWebIdentityTokenFile() string {
+	if tokenFile := os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"); tokenFile != "" {
+		return tokenFile
+	}
+	return "/var/run/secrets/openshift/serviceaccount/token"
+}
+
+// newCloudWatchPublisher creates a Publisher of the gauges of names, assuming roleARN with the token of tokenFile.
+// The region of the cluster is read from the Infrastructure when region is empty.
+func newCloudWatchPublisher(ctx context.Context, reader client.Reader, names []string, catalog []metrics.CatalogEntry, namespace, region string, interval time.Duration, roleARN, tokenFile string, gatherer prometheus.Gatherer) (*cloudwatch.Publisher, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid CloudWatch interval %s", interval)
+	}
+	if roleARN == "" {
+		return nil, errors.New("no role to publish to CloudWatch with, set --cloudwatch-role-arn")
+	}
+	metricTypes := make(map[string]string, len(catalog))
+	for _, entry := range catalog {
+		metricTypes[entry.Name] = entry.Type
+	}
+	for _, name := range names {
+		if metricTypes[name] != metrics.GaugeType {
+			return nil, fmt.Errorf("%q is not a gauge of the exporter, only gauges are published to CloudWatch", name)
+		}
+	}
+	if region == "" {
+		infrastructure := &configv1.Infrastructure{}
+		if err := reader.Get(ctx, types.NamespacedName{Name: "cluster"}, infrastructure); err != nil {
+			return nil, err
+		}
+		if infrastructure.Status.PlatformStatus == nil || infrastructure.Status.PlatformStatus.AWS == nil {
+			return nil, errors.New("the cluster is not on AWS, set --cloudwatch-region")
+		}
+		region = infrastructure.Status.PlatformStatus.AWS.Region
+	}
+	httpClient := &http.Client{Timeout: interval}
+	credentials := cloudwatch.NewWebIdentityCredentials(httpClient, region, roleARN, tokenFile, operatorConfig.OperatorName)
+	return cloudwatch.NewPublisher(httpClient, region, namespace, names, credentials, gatherer, interval), nil
+}
+
+// newSilencePoller creates a silence poller authenticating with the token of the exporter. The platform
+// Alertmanager is served with a certificate of the service CA.
+func newSilencePoller(cfg *rest.Config, url string, platformNamespaces string, aggregator *metrics.AdoptionMetricsAggregator, clusterId string) (*silence.Poller, error) {
+	namespaces, err := regexp.Compile(platformNamespaces)
+	if err != nil {
+		return nil, err
+	}
+	alertmanagerCfg := rest.CopyConfig(cfg)
+	// the API usage tracker only understands Kubernetes API requests
+	alertmanagerCfg.WrapTransport = nil
+	alertmanagerCfg.TLSClientConfig = rest.TLSClientConfig{CAFile: serviceCAFile}
+	httpClient, err := rest.HTTPClientFor(alertmanagerCfg)
+	if err != nil {
+		return nil, err
+	}
+	return silence.NewPoller(httpClient, url, namespaces, silence.NewMetrics(aggregator), clusterId), nil
+}
+
+// inNamespace selects the objects of a single namespace
+func inNamespace(namespace string) fields.Selector {
+	return fields.OneTermEqualSelector("metadata.namespace", namespace)
+}
+
+func containsGroupKind(kinds []schema.GroupKind, candidate schema.GroupKind) bool {
+	for _, gk := range kinds {
+		if gk == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// controllerSelection is the set of controllers enabled with --enable-controllers or the MetricsExporterConfig
+type controllerSelection struct {
+	names map[string]bool
+	// checked are the names of all controllers, to find unknown names
+	checked map[string]bool
+}
+
+func newControllerSelection(names []string) *controllerSelection {
+	s := &controllerSelection{names: make(map[string]bool), checked: make(map[string]bool)}
+	for _, name := range names {
+		s.names[name] = true
+	}
+	return s
+}
+
+// enabled reports if the controller should be set up, all controllers are enabled when no names were given
+func (s *controllerSelection) enabled(name string) bool {
+	s.checked[name] = true
+	return len(s.names) == 0 || s.names[name]
+}
+
+// unknown returns the names which did not match any controller checked with enabled, sorted
+func (s *controllerSelection) unknown() []string {
+	var unknown []string
+	for name := range s.names {
+		if !s.checked[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// resolveSyncPeriod returns the resync period of the cache, the resyncPeriod of the MetricsExporterConfig if it is set,
+// else the --resync-period flag. It returns nil for the default of controller-runtime when neither is set.
+func resolveSyncPeriod(flagPeriod time.Duration, spec metricsv1alpha1.MetricsExporterConfigSpec) *time.Duration {
+	if spec.ResyncPeriod != nil && spec.ResyncPeriod.Duration > 0 {
+		return &spec.ResyncPeriod.Duration
+	}
+	if flagPeriod > 0 {
+		return &flagPeriod
+	}
+	return nil
+}
+
+// getClusterID reads the cluster id from the ClusterVersion, or returns the fallback if it cannot be read and the
+// fallback is set. The ClusterVersion controller moves the series to the cluster id of the ClusterVersion once
+// it can be read or when it changes.
+func getClusterID(client client.Reader, fallback string) (string, error) {
+	clusterId, err := readClusterID(client)
+	if err != nil && fallback != "" {
+		setupLog.Error(err, "unable to read the cluster id, using the fallback", "clusterId", fallback)
+		return fallback, nil
+	}
+	return clusterId, err
+}
+
+func readClusterID(client client.Reader) (string, error) {
 	cv := &configv1.ClusterVersion{}
 	if err := client.Get(context.TODO(), types.NamespacedName{Name: "version"}, cv); err != nil {
 		return "", err
@@ -0,0 +1,129 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	metricsv1alpha1 "github.com/openshift/osd-metrics-exporter/api/v1alpha1"
+	operatorConfig "github.com/openshift/osd-metrics-exporter/config"
+	promOperatorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEnsureMetricsService(t *testing.T) {
+	for _, tc := range []struct {
+		name                 string
+		manageServiceMonitor bool
+	}{
+		{name: "without --manage-service-monitor"},
+		{name: "with --manage-service-monitor", manageServiceMonitor: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+			desired, err := ensureMetricsService(context.TODO(), fakeClient, false, false, tc.manageServiceMonitor)
+			require.NoError(t, err)
+
+			key := types.NamespacedName{Namespace: operatorConfig.OperatorNamespace, Name: operatorConfig.OperatorName}
+			require.NoError(t, fakeClient.Get(context.TODO(), key, &corev1.Service{}))
+
+			serviceMonitor := &promOperatorv1.ServiceMonitor{}
+			err = fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(desired), serviceMonitor)
+			roleErr := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: operatorConfig.OperatorNamespace, Name: "prometheus-k8s"}, &rbacv1.Role{})
+			roleBindingErr := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: operatorConfig.OperatorNamespace, Name: "prometheus-k8s"}, &rbacv1.RoleBinding{})
+			if !tc.manageServiceMonitor {
+				require.True(t, errors.IsNotFound(err), "the ServiceMonitor is created without the flag")
+				require.True(t, errors.IsNotFound(roleErr), "the Role is created without the flag")
+				require.True(t, errors.IsNotFound(roleBindingErr), "the RoleBinding is created without the flag")
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, desired.Spec, serviceMonitor.Spec)
+			require.NoError(t, roleErr)
+			require.NoError(t, roleBindingErr)
+		})
+	}
+}
+
+func TestGetClusterID(t *testing.T) {
+	clusterVersion := &configv1.ClusterVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "version"},
+		Spec:       configv1.ClusterVersionSpec{ClusterID: "cluster-id"},
+	}
+	for _, tc := range []struct {
+		name      string
+		objects   []client.Object
+		fallback  string
+		expected  string
+		expectErr bool
+	}{
+		{name: "ClusterVersion", objects: []client.Object{clusterVersion}, fallback: "fallback-id", expected: "cluster-id"},
+		{name: "fallback without a ClusterVersion", fallback: "fallback-id", expected: "fallback-id"},
+		{name: "no ClusterVersion and no fallback", expectErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()
+			clusterId, err := getClusterID(fakeClient, tc.fallback)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, clusterId)
+		})
+	}
+}
+
+func TestResolveSyncPeriod(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		flagPeriod time.Duration
+		spec       metricsv1alpha1.MetricsExporterConfigSpec
+		expected   *time.Duration
+	}{
+		{name: "default of controller-runtime"},
+		{name: "--resync-period", flagPeriod: 2 * time.Hour, expected: durationPointer(2 * time.Hour)},
+		{name: "MetricsExporterConfig", spec: metricsv1alpha1.MetricsExporterConfigSpec{ResyncPeriod: &metav1.Duration{Duration: time.Hour}}, expected: durationPointer(time.Hour)},
+		{
+			name:       "MetricsExporterConfig takes precedence",
+			flagPeriod: 2 * time.Hour,
+			spec:       metricsv1alpha1.MetricsExporterConfigSpec{ResyncPeriod: &metav1.Duration{Duration: time.Hour}},
+			expected:   durationPointer(time.Hour),
+		},
+		{
+			name:       "zero resyncPeriod of the MetricsExporterConfig",
+			flagPeriod: 2 * time.Hour,
+			spec:       metricsv1alpha1.MetricsExporterConfigSpec{ResyncPeriod: &metav1.Duration{}},
+			expected:   durationPointer(2 * time.Hour),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, resolveSyncPeriod(tc.flagPeriod, tc.spec))
+		})
+	}
+}
+
+func durationPointer(d time.Duration) *time.Duration {
+	return &d
+}
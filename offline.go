@@ -0,0 +1,161 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/detection"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/openshift/osd-metrics-exporter/pkg/mustgather"
+	"github.com/openshift/osd-metrics-exporter/pkg/objectcount"
+)
+
+// offlineController is a controller run against a must-gather. It is reconciled once for each object of
+// its For kind, or once with an empty request if there are none, so controllers computing their metrics
+// from a list still report them.
+type offlineController struct {
+	name       string
+	forObject  client.Object
+	reconciler reconcile.Reconciler
+}
+
+// offlineControllers returns the reconcilers of the exporter controllers which report metrics, with the names
+// they are registered with
+func offlineControllers(c client.Client, aggregator *metrics.AdoptionMetricsAggregator, clusterId string, detections []detection.Detection, objectCounters []objectcount.Counter) []offlineController {
+	dependencies := controllerDependencies{
+		client:         c,
+		apiReader:      c,
+		aggregator:     aggregator,
+		clusterId:      clusterId,
+		detections:     detections,
+		objectCounters: objectCounters,
+	}
+	var controllers []offlineController
+	for _, ec := range exporterControllers {
+		if !ec.offline {
+			continue
+		}
+		for _, r := range ec.reconcilers(dependencies) {
+			controllers = append(controllers, offlineController{ec.name, r.forObject, r.reconciler})
+		}
+	}
+	return controllers
+}
+
+// runOffline runs the named controllers, or all of them if names is empty, against the must-gather in dir
+// and writes the metrics they report to out in the text format, with the extra labels. The fallback cluster id
+// is used if the must-gather has no ClusterVersion.
+func runOffline(ctx context.Context, dir string, fallbackClusterId string, names []string, detections []detection.Detection, objectCounters []objectcount.Counter, extraLabels prometheus.Labels, out io.Writer) error {
+	c, err := mustgather.NewClient(scheme, dir)
+	if err != nil {
+		return fmt.Errorf("unable to load must-gather: %w", err)
+	}
+	clusterId, err := getClusterID(c, fallbackClusterId)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve cluster id: %w", err)
+	}
+	registry, err := reconcileOnce(ctx, c, clusterId, names, detections, objectCounters, extraLabels)
+	if err != nil {
+		return err
+	}
+	return writeMetrics(registry, out)
+}
+
+// reconcileOnce reconciles every object of the named controllers, or of all of them if names is empty, once with c
+// and returns a registry with the metrics they report, with the extra labels
+func reconcileOnce(ctx context.Context, c client.Client, clusterId string, names []string, detections []detection.Detection, objectCounters []objectcount.Counter, extraLabels prometheus.Labels) (*prometheus.Registry, error) {
+	aggregator := metrics.GetMetricsAggregator(clusterId)
+
+	for _, name := range names {
+		known := false
+		for _, ec := range exporterControllers {
+			known = known || (ec.offline && ec.name == name)
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown controller %q", name)
+		}
+	}
+	for _, oc := range offlineControllers(c, aggregator, clusterId, detections, objectCounters) {
+		if len(names) > 0 && !utils.ContainsString(names, oc.name) {
+			continue
+		}
+		if err := reconcileOffline(ctx, c, oc); err != nil {
+			// a controller failing should not hide the metrics of all others
+			setupLog.Error(err, "unable to reconcile", "controller", oc.name)
+		}
+	}
+
+	if err := aggregator.CheckExtraLabels(extraLabels); err != nil {
+		return nil, err
+	}
+	registry := prometheus.NewRegistry()
+	registerer := prometheus.WrapRegistererWith(extraLabels, registry)
+	for _, collector := range aggregator.GetMetrics() {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// writeMetrics writes the metrics of gatherer to out in the text format
+func writeMetrics(gatherer prometheus.Gatherer, out io.Writer) error {
+	families, err := gatherer.Gather()
+	if err != nil {
+		return err
+	}
+	encoder := expfmt.NewEncoder(out, expfmt.FmtText)
+	for _, family := range families {
+		if err := encoder.Encode(family); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func reconcileOffline(ctx context.Context, c client.Client, oc offlineController) error {
+	gvk, err := apiutil.GVKForObject(oc.forObject, scheme)
+	if err != nil {
+		return err
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err := c.List(ctx, list); err != nil {
+		return err
+	}
+	requests := []reconcile.Request{{}}
+	if len(list.Items) > 0 {
+		requests = make([]reconcile.Request, len(list.Items))
+		for i, item := range list.Items {
+			requests[i] = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&item)}
+		}
+	}
+	for _, req := range requests {
+		if _, err := oc.reconciler.Reconcile(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+// Package apiusage records which verbs and resources the exporter uses on the Kubernetes API, so the
+// permissions actually used can be compared with the permissions granted by the shipped RBAC.
+package apiusage
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Usage is a verb used on a resource, in RBAC terms
+type Usage struct {
+	Verb     string
+	Group    string
+	Resource string
+}
+
+// Tracker records API usage from a wrapped client transport
+type Tracker struct {
+	mutex  sync.Mutex
+	used   map[Usage]struct{}
+	report func(Usage)
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{
+		used: make(map[Usage]struct{}),
+	}
+}
+
+// Wrap returns a transport recording every request sent through rt. It matches the rest.Config.Wrap signature.
+func (t *Tracker) Wrap(rt http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if usage, ok := parseRequest(req); ok {
+			t.record(usage)
+		}
+		return rt.RoundTrip(req)
+	})
+}
+
+// ReportTo calls report for every usage recorded so far and for every new usage recorded afterwards
+func (t *Tracker) ReportTo(report func(Usage)) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.report = report
+	for usage := range t.used {
+		report(usage)
+	}
+}
+
+func (t *Tracker) record(usage Usage) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if _, ok := t.used[usage]; ok {
+		return
+	}
+	t.used[usage] = struct{}{}
+	if t.report != nil {
+		t.report(usage)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// parseRequest maps a request to the RBAC verb and resource it is authorized against. Requests which are
+// not resource requests, like discovery, are ignored.
+//
+// Resource paths have the form
+//
+//	/api/{version}/[namespaces/{namespace}/]{resource}[/{name}[/{subresource}]]
+//	/apis/{group}/{version}/[namespaces/{namespace}/]{resource}[/{name}[/{subresource}]]
+func parseRequest(req *http.Request) (Usage, bool) {
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	var group string
+	switch {
+	case len(parts) >= 3 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) >= 4 && parts[0] == "apis":
+		group = parts[1]
+		parts = parts[3:]
+	default:
+		return Usage{}, false
+	}
+	// namespaces/{namespace}/{resource} is a namespaced request, namespaces[/{name}] is a request for namespaces
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		parts = parts[2:]
+	}
+
+	resource := parts[0]
+	hasName := len(parts) >= 2
+	if len(parts) >= 3 {
+		resource = resource + "/" + parts[2]
+	}
+
+	var verb string
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		switch {
+		case req.URL.Query().Get("watch") == "true" || req.URL.Query().Get("watch") == "1":
+			verb = "watch"
+		case hasName:
+			verb = "get"
+		default:
+			verb = "list"
+		}
+	case http.MethodPost:
+		verb = "create"
+	case http.MethodPut:
+		verb = "update"
+	case http.MethodPatch:
+		verb = "patch"
+	case http.MethodDelete:
+		if hasName {
+			verb = "delete"
+		} else {
+			verb = "deletecollection"
+		}
+	default:
+		return Usage{}, false
+	}
+	return Usage{Verb: verb, Group: group, Resource: resource}, true
+}
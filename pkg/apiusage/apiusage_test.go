@@ -0,0 +1,71 @@
+package apiusage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRequest(t *testing.T) {
+	for _, tc := range []struct {
+		method   string
+		url      string
+		expected Usage
+		ignored  bool
+	}{
+		{method: http.MethodGet, url: "/api/v1/namespaces/openshift-config/configmaps", expected: Usage{Verb: "list", Resource: "configmaps"}},
+		{method: http.MethodGet, url: "/api/v1/namespaces/openshift-config/configmaps?watch=true", expected: Usage{Verb: "watch", Resource: "configmaps"}},
+		{method: http.MethodGet, url: "/api/v1/namespaces/openshift-config/configmaps/user-ca-bundle", expected: Usage{Verb: "get", Resource: "configmaps"}},
+		{method: http.MethodGet, url: "/api/v1/namespaces/openshift-osd-metrics", expected: Usage{Verb: "get", Resource: "namespaces"}},
+		{method: http.MethodGet, url: "/api/v1/persistentvolumes", expected: Usage{Verb: "list", Resource: "persistentvolumes"}},
+		{method: http.MethodGet, url: "/apis/config.openshift.io/v1/clusterversions/version", expected: Usage{Verb: "get", Group: "config.openshift.io", Resource: "clusterversions"}},
+		{method: http.MethodPut, url: "/apis/user.openshift.io/v1/groups/cluster-admins", expected: Usage{Verb: "update", Group: "user.openshift.io", Resource: "groups"}},
+		{method: http.MethodPut, url: "/apis/config.openshift.io/v1/clusteroperators/foo/status", expected: Usage{Verb: "update", Group: "config.openshift.io", Resource: "clusteroperators/status"}},
+		{method: http.MethodPost, url: "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews", expected: Usage{Verb: "create", Group: "authorization.k8s.io", Resource: "selfsubjectaccessreviews"}},
+		{method: http.MethodDelete, url: "/api/v1/namespaces/test/pods", expected: Usage{Verb: "deletecollection", Resource: "pods"}},
+		{method: http.MethodGet, url: "/apis", ignored: true},
+		{method: http.MethodGet, url: "/apis/config.openshift.io/v1", ignored: true},
+		{method: http.MethodGet, url: "/healthz", ignored: true},
+	} {
+		t.Run(tc.method+" "+tc.url, func(t *testing.T) {
+			usage, ok := parseRequest(httptest.NewRequest(tc.method, tc.url, nil))
+			if tc.ignored {
+				require.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			require.Equal(t, tc.expected, usage)
+		})
+	}
+}
+
+func TestTracker_ReportTo(t *testing.T) {
+	tracker := NewTracker()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	client := &http.Client{Transport: tracker.Wrap(http.DefaultTransport)}
+
+	get := func(path string) {
+		resp, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	get("/api/v1/persistentvolumes")
+	get("/api/v1/persistentvolumes")
+
+	var reported []Usage
+	tracker.ReportTo(func(u Usage) {
+		reported = append(reported, u)
+	})
+	require.Equal(t, []Usage{{Verb: "list", Resource: "persistentvolumes"}}, reported)
+
+	get("/apis/config.openshift.io/v1/proxies/cluster")
+	get("/api/v1/persistentvolumes")
+	require.Equal(t, []Usage{
+		{Verb: "list", Resource: "persistentvolumes"},
+		{Verb: "get", Group: "config.openshift.io", Resource: "proxies"},
+	}, reported)
+}
@@ -0,0 +1,95 @@
+// Package apiversion resolves the version of kinds the exporter reads as unstructured objects. After an upgrade
+// the version the exporter was built against may no longer be served, while a newer version with the same fields is.
+package apiversion
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// Kind is a kind read as unstructured objects, with the versions the exporter can read
+type Kind struct {
+	mutex    sync.RWMutex
+	gvk      schema.GroupVersionKind
+	versions []string
+}
+
+// NewKind creates a Kind using gvk until Resolve finds it is no longer served. Fallback versions are only
+// used then, so they must only be listed if the fields the exporter reads are the same in them.
+func NewKind(gvk schema.GroupVersionKind, fallbackVersions ...string) *Kind {
+	return &Kind{
+		gvk:      gvk,
+		versions: append([]string{gvk.Version}, fallbackVersions...),
+	}
+}
+
+// GroupVersionKind returns the version to read the kind with
+func (k *Kind) GroupVersionKind() schema.GroupVersionKind {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+	return k.gvk
+}
+
+// PreferredGroupVersionKind returns the version the exporter was built against
+func (k *Kind) PreferredGroupVersionKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: k.gvk.Group, Version: k.versions[0], Kind: k.gvk.Kind}
+}
+
+// GroupVersionKinds returns all versions the kind can be read with, the preferred one first
+func (k *Kind) GroupVersionKinds() []schema.GroupVersionKind {
+	gvks := make([]schema.GroupVersionKind, 0, len(k.versions))
+	for _, version := range k.versions {
+		gvks = append(gvks, schema.GroupVersionKind{Group: k.gvk.Group, Version: version, Kind: k.gvk.Kind})
+	}
+	return gvks
+}
+
+// GroupKind returns the group and kind, which are the same for all versions
+func (k *Kind) GroupKind() schema.GroupKind {
+	return k.PreferredGroupVersionKind().GroupKind()
+}
+
+// ListGroupVersionKind returns the list kind to list the kind with
+func (k *Kind) ListGroupVersionKind() schema.GroupVersionKind {
+	gvk := k.GroupVersionKind()
+	return gvk.GroupVersion().WithKind(gvk.Kind + "List")
+}
+
+// Resolve switches to the first version served by the API server. It returns whether the preferred version
+// is no longer served, and an error if none of the versions is served.
+func (k *Kind) Resolve(d discovery.ServerResourcesInterface) (bool, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	for i, version := range k.versions {
+		gvk := schema.GroupVersionKind{Group: k.gvk.Group, Version: version, Kind: k.gvk.Kind}
+		served, err := isServed(d, gvk)
+		if err != nil {
+			return false, err
+		}
+		if served {
+			k.gvk = gvk
+			return i > 0, nil
+		}
+	}
+	return true, fmt.Errorf("no version of %s is served, tried %v", k.gvk.GroupKind(), k.versions)
+}
+
+func isServed(d discovery.ServerResourcesInterface, gvk schema.GroupVersionKind) (bool, error) {
+	resources, err := d.ServerResourcesForGroupVersion(gvk.GroupVersion().String())
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Kind == gvk.Kind {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,52 @@
+package apiversion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func newFakeDiscovery(resources ...*metav1.APIResourceList) *fakediscovery.FakeDiscovery {
+	return &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: resources}}
+}
+
+func TestKind_Resolve(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "machine.openshift.io", Version: "v1beta1", Kind: "MachineSet"}
+
+	t.Run("preferred version served", func(t *testing.T) {
+		kind := NewKind(gvk, "v1")
+		deprecated, err := kind.Resolve(newFakeDiscovery(
+			&metav1.APIResourceList{GroupVersion: "machine.openshift.io/v1beta1", APIResources: []metav1.APIResource{{Kind: "MachineSet"}}},
+			&metav1.APIResourceList{GroupVersion: "machine.openshift.io/v1", APIResources: []metav1.APIResource{{Kind: "MachineSet"}}},
+		))
+		require.NoError(t, err)
+		require.False(t, deprecated)
+		require.Equal(t, gvk, kind.GroupVersionKind())
+	})
+
+	t.Run("switch to fallback version", func(t *testing.T) {
+		kind := NewKind(gvk, "v1")
+		deprecated, err := kind.Resolve(newFakeDiscovery(
+			&metav1.APIResourceList{GroupVersion: "machine.openshift.io/v1", APIResources: []metav1.APIResource{{Kind: "MachineSet"}}},
+		))
+		require.NoError(t, err)
+		require.True(t, deprecated)
+		require.Equal(t, "v1", kind.GroupVersionKind().Version)
+		require.Equal(t, "MachineSetList", kind.ListGroupVersionKind().Kind)
+		require.Equal(t, gvk, kind.PreferredGroupVersionKind())
+		require.Equal(t, []schema.GroupVersionKind{gvk, gvk.GroupKind().WithVersion("v1")}, kind.GroupVersionKinds())
+	})
+
+	t.Run("no version served", func(t *testing.T) {
+		kind := NewKind(gvk)
+		deprecated, err := kind.Resolve(newFakeDiscovery(
+			&metav1.APIResourceList{GroupVersion: "machine.openshift.io/v1beta1", APIResources: []metav1.APIResource{{Kind: "Machine"}}},
+		))
+		require.Error(t, err)
+		require.True(t, deprecated)
+	})
+}
@@ -0,0 +1,181 @@
+// Package cloudwatch publishes selected gauges of the exporter to CloudWatch, for AWS-native alerting pipelines.
+// Every publish sends the current value of every series of the gauges, with the labels of a series as dimensions.
+package cloudwatch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DefaultInterval is how often the gauges are published, like the default scrape interval of the exporter
+	DefaultInterval = time.Minute
+	// DefaultNamespace is the CloudWatch namespace the gauges are published in
+	DefaultNamespace = "OSDMetricsExporter"
+	// maxDatumsPerRequest is how many series are published in a single PutMetricData request
+	maxDatumsPerRequest = 20
+	// maxDimensions is how many dimensions CloudWatch allows per series, further labels are dropped
+	maxDimensions = 30
+	// maxResponseBody is how much of a response is read
+	maxResponseBody = 64 << 10
+)
+
+var log = logf.Log.WithName("cloudwatch")
+
+// datum is a series of a gauge as a CloudWatch metric datum
+type datum struct {
+	name       string
+	dimensions [][2]string
+	value      float64
+}
+
+// Publisher is a manager Runnable that periodically publishes the selected gauges gathered from gatherer to
+// CloudWatch. It only runs on the leader, so the series are not published once per replica.
+type Publisher struct {
+	httpClient  *http.Client
+	endpoint    string
+	region      string
+	namespace   string
+	names       map[string]bool
+	credentials CredentialsProvider
+	gatherer    prometheus.Gatherer
+	interval    time.Duration
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// NewPublisher creates a Publisher publishing the gauges named by names from gatherer to the CloudWatch namespace
+// of region every interval, signed with the credentials
+func NewPublisher(httpClient *http.Client, region, namespace string, names []string, credentials CredentialsProvider, gatherer prometheus.Gatherer, interval time.Duration) *Publisher {
+	selected := make(map[string]bool, len(names))
+	for _, name := range names {
+		selected[name] = true
+	}
+	return &Publisher{
+		httpClient:  httpClient,
+		endpoint:    fmt.Sprintf("https://monitoring.%s.amazonaws.com/", region),
+		region:      region,
+		namespace:   namespace,
+		names:       selected,
+		credentials: credentials,
+		gatherer:    gatherer,
+		interval:    interval,
+		now:         time.Now,
+	}
+}
+
+// Start implements manager.Runnable. It publishes immediately and then on every interval until the context is
+// cancelled. Failed publishes are not retried, the next publish sends the current values.
+func (p *Publisher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		if err := p.publish(ctx); err != nil {
+			log.Error(err, "unable to publish metrics", "namespace", p.namespace)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// data returns the series of the selected gauges of families
+func (p *Publisher) data(families []*dto.MetricFamily) []datum {
+	var data []datum
+	for _, family := range families {
+		if !p.names[family.GetName()] || family.GetType() != dto.MetricType_GAUGE {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			d := datum{name: family.GetName(), value: m.GetGauge().GetValue()}
+			for _, label := range m.GetLabel() {
+				// CloudWatch rejects empty dimension values
+				if label.GetValue() == "" || len(d.dimensions) == maxDimensions {
+					continue
+				}
+				d.dimensions = append(d.dimensions, [2]string{label.GetName(), label.GetValue()})
+			}
+			data = append(data, d)
+		}
+	}
+	return data
+}
+
+// Flush publishes the gauges once, e.g. a final time when the exporter stops after the controllers
+func (p *Publisher) Flush(ctx context.Context) error {
+	return p.publish(ctx)
+}
+
+func (p *Publisher) publish(ctx context.Context) error {
+	families, err := p.gatherer.Gather()
+	if err != nil {
+		// a partial gather still has the series of the collectors which succeeded
+		log.Error(err, "unable to gather all metrics")
+	}
+	data := p.data(families)
+	timestamp := p.now().UTC().Format(time.RFC3339)
+	for start := 0; start < len(data); start += maxDatumsPerRequest {
+		end := start + maxDatumsPerRequest
+		if end > len(data) {
+			end = len(data)
+		}
+		form := url.Values{
+			"Action":    {"PutMetricData"},
+			"Version":   {"2010-08-01"},
+			"Namespace": {p.namespace},
+		}
+		for i, d := range data[start:end] {
+			prefix := "MetricData.member." + strconv.Itoa(i+1) + "."
+			form.Set(prefix+"MetricName", d.name)
+			form.Set(prefix+"Value", strconv.FormatFloat(d.value, 'g', -1, 64))
+			form.Set(prefix+"Timestamp", timestamp)
+			for j, dimension := range d.dimensions {
+				dimensionPrefix := prefix + "Dimensions.member." + strconv.Itoa(j+1) + "."
+				form.Set(dimensionPrefix+"Name", dimension[0])
+				form.Set(dimensionPrefix+"Value", dimension[1])
+			}
+		}
+		if err := p.put(ctx, form); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// put sends a PutMetricData request signed with the current credentials
+func (p *Publisher) put(ctx context.Context, form url.Values) error {
+	credentials, err := p.credentials.Retrieve(ctx)
+	if err != nil {
+		return err
+	}
+	body := []byte(form.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	sign(req, hashHex(body), credentials, p.region, "monitoring", p.now())
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package cloudwatch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublisher_publish(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "cpms_enabled", Help: "Indicates if the ControlPlaneMachineSet is enabled"}, []string{"_id", "empty"})
+	other := prometheus.NewGauge(prometheus.GaugeOpts{Name: "not_selected", Help: "Indicates a gauge which is not published"})
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "selected_total", Help: "Indicates a counter, which is not published"})
+	registry.MustRegister(gauge, other, counter)
+	gauge.WithLabelValues("cluster-id", "").Set(1)
+
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		requests = append(requests, r)
+		if r.Form.Get("Namespace") == "Failing" {
+			http.Error(w, "<ErrorResponse>throttled</ErrorResponse>", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	credentials := staticCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", SessionToken: "session-token"}
+	p := NewPublisher(server.Client(), "us-east-1", DefaultNamespace, []string{"cpms_enabled", "selected_total"}, credentials, registry, DefaultInterval)
+	p.endpoint = server.URL
+	p.now = func() time.Time { return time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC) }
+	require.NoError(t, p.publish(context.TODO()))
+
+	require.Len(t, requests, 1)
+	form := requests[0].Form
+	require.Equal(t, "PutMetricData", form.Get("Action"))
+	require.Equal(t, DefaultNamespace, form.Get("Namespace"))
+	require.Equal(t, "cpms_enabled", form.Get("MetricData.member.1.MetricName"))
+	require.Equal(t, "1", form.Get("MetricData.member.1.Value"))
+	require.Equal(t, "2022-10-01T12:00:00Z", form.Get("MetricData.member.1.Timestamp"))
+	require.Equal(t, "_id", form.Get("MetricData.member.1.Dimensions.member.1.Name"))
+	require.Equal(t, "cluster-id", form.Get("MetricData.member.1.Dimensions.member.1.Value"))
+	require.Empty(t, form.Get("MetricData.member.1.Dimensions.member.2.Name"), "empty labels are not dimensions")
+	require.Empty(t, form.Get("MetricData.member.2.MetricName"), "only the selected gauges are published")
+	require.Equal(t, "session-token", requests[0].Header.Get("X-Amz-Security-Token"))
+	require.True(t, strings.HasPrefix(requests[0].Header.Get("Authorization"),
+		"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20221001/us-east-1/monitoring/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-security-token, "))
+
+	p.namespace = "Failing"
+	require.EqualError(t, p.publish(context.TODO()), "unexpected status 400 Bad Request: <ErrorResponse>throttled</ErrorResponse>")
+}
+
+// staticCredentials are credentials which never expire
+type staticCredentials Credentials
+
+func (c staticCredentials) Retrieve(context.Context) (Credentials, error) {
+	return Credentials(c), nil
+}
+
+func TestWebIdentityCredentials_Retrieve(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("token\n"), 0600))
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "AssumeRoleWithWebIdentity", r.Form.Get("Action"))
+		require.Equal(t, "arn:aws:iam::123456789012:role/exporter", r.Form.Get("RoleArn"))
+		require.Equal(t, "osd-metrics-exporter", r.Form.Get("RoleSessionName"))
+		require.Equal(t, "token", r.Form.Get("WebIdentityToken"))
+		calls++
+		_, _ = w.Write([]byte(`<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>ASIAEXAMPLE</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>session-token</SessionToken>
+      <Expiration>2022-10-01T13:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`))
+	}))
+	defer server.Close()
+
+	c := NewWebIdentityCredentials(server.Client(), "us-east-1", "arn:aws:iam::123456789012:role/exporter", tokenFile, "osd-metrics-exporter")
+	c.endpoint = server.URL
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	credentials, err := c.Retrieve(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, Credentials{
+		AccessKeyID:     "ASIAEXAMPLE",
+		SecretAccessKey: "secret",
+		SessionToken:    "session-token",
+		Expiration:      time.Date(2022, 10, 1, 13, 0, 0, 0, time.UTC),
+	}, credentials)
+
+	// the credentials are cached until shortly before they expire
+	_, err = c.Retrieve(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+	now = now.Add(58 * time.Minute)
+	_, err = c.Retrieve(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+}
@@ -0,0 +1,122 @@
+package cloudwatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// credentialsRefreshMargin is how long before they expire the credentials of the role are refreshed
+const credentialsRefreshMargin = 5 * time.Minute
+
+// Credentials are the temporary credentials of an AWS role
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expiration      time.Time
+}
+
+// CredentialsProvider returns the credentials metrics are published with
+type CredentialsProvider interface {
+	Retrieve(ctx context.Context) (Credentials, error)
+}
+
+// WebIdentityCredentials assumes a role with the projected service account token of the exporter, like the
+// components of a cluster installed with STS. The credentials are cached until shortly before they expire, and
+// the token file is reread for every refresh as it is rotated.
+type WebIdentityCredentials struct {
+	httpClient  *http.Client
+	endpoint    string
+	roleARN     string
+	tokenFile   string
+	sessionName string
+
+	mutex  sync.Mutex
+	cached Credentials
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// NewWebIdentityCredentials creates WebIdentityCredentials assuming roleARN with the token of tokenFile, with the
+// STS endpoint of region
+func NewWebIdentityCredentials(httpClient *http.Client, region, roleARN, tokenFile, sessionName string) *WebIdentityCredentials {
+	return &WebIdentityCredentials{
+		httpClient:  httpClient,
+		endpoint:    fmt.Sprintf("https://sts.%s.amazonaws.com/", region),
+		roleARN:     roleARN,
+		tokenFile:   tokenFile,
+		sessionName: sessionName,
+		now:         time.Now,
+	}
+}
+
+// assumeRoleResponse is the response of AssumeRoleWithWebIdentity
+type assumeRoleResponse struct {
+	Credentials struct {
+		AccessKeyID     string    `xml:"AccessKeyId"`
+		SecretAccessKey string    `xml:"SecretAccessKey"`
+		SessionToken    string    `xml:"SessionToken"`
+		Expiration      time.Time `xml:"Expiration"`
+	} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+}
+
+// Retrieve implements CredentialsProvider
+func (c *WebIdentityCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.cached.AccessKeyID != "" && c.now().Add(credentialsRefreshMargin).Before(c.cached.Expiration) {
+		return c.cached, nil
+	}
+	token, err := os.ReadFile(c.tokenFile)
+	if err != nil {
+		return Credentials{}, err
+	}
+	// AssumeRoleWithWebIdentity is not signed, the token authenticates the request
+	form := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {c.roleARN},
+		"RoleSessionName":  {c.sessionName},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return Credentials{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return Credentials{}, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return Credentials{}, fmt.Errorf("unable to assume role %s: unexpected status %s: %s", c.roleARN, resp.Status, bytes.TrimSpace(body))
+	}
+	response := assumeRoleResponse{}
+	if err := xml.Unmarshal(body, &response); err != nil {
+		return Credentials{}, fmt.Errorf("unable to assume role %s: %w", c.roleARN, err)
+	}
+	if response.Credentials.AccessKeyID == "" {
+		return Credentials{}, fmt.Errorf("unable to assume role %s: no credentials in the response", c.roleARN)
+	}
+	c.cached = Credentials{
+		AccessKeyID:     response.Credentials.AccessKeyID,
+		SecretAccessKey: response.Credentials.SecretAccessKey,
+		SessionToken:    response.Credentials.SessionToken,
+		Expiration:      response.Credentials.Expiration,
+	}
+	return c.cached, nil
+}
@@ -0,0 +1,166 @@
+// Package detection implements declarative detections: simple metrics described in configuration as a resource kind,
+// a JSONPath into the objects of that kind and a comparison. A detection reports how many objects match.
+package detection
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+)
+
+// Operator compares the values found by a JSONPath with the configured value
+type Operator string
+
+const (
+	OperatorExists      Operator = "Exists"
+	OperatorNotExists   Operator = "NotExists"
+	OperatorEquals      Operator = "Equals"
+	OperatorNotEquals   Operator = "NotEquals"
+	OperatorGreaterThan Operator = "GreaterThan"
+	OperatorLessThan    Operator = "LessThan"
+)
+
+// Config is the content of the detections file
+type Config struct {
+	Detections []Detection `json:"detections"`
+}
+
+// Detection describes which objects of a resource kind match
+type Detection struct {
+	// Name identifies the detection and is used as the detection label value
+	Name string `json:"name"`
+	// APIVersion and Kind of the objects to evaluate, e.g. operator.openshift.io/v1 and IngressController
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Namespace restricts the detection to objects in a single namespace
+	Namespace string `json:"namespace,omitempty"`
+	// JSONPath selects the values to compare, e.g. {.spec.replicas}
+	JSONPath string   `json:"jsonPath"`
+	Operator Operator `json:"operator"`
+	// Value to compare with. Not used by Exists and NotExists.
+	Value string `json:"value,omitempty"`
+
+	path *jsonpath.JSONPath
+}
+
+// Load reads and validates a detections file
+func Load(path string) ([]Detection, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses and validates the content of a detections file
+func Parse(data []byte) ([]Detection, error) {
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	for i := range config.Detections {
+		d := &config.Detections[i]
+		if err := d.compile(); err != nil {
+			return nil, fmt.Errorf("detection %q: %w", d.Name, err)
+		}
+		if names[d.Name] {
+			return nil, fmt.Errorf("detection %q is defined more than once", d.Name)
+		}
+		names[d.Name] = true
+	}
+	return config.Detections, nil
+}
+
+func (d *Detection) compile() error {
+	if d.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if d.APIVersion == "" || d.Kind == "" {
+		return fmt.Errorf("apiVersion and kind are required")
+	}
+	if _, err := schema.ParseGroupVersion(d.APIVersion); err != nil {
+		return err
+	}
+	switch d.Operator {
+	case OperatorExists, OperatorNotExists, OperatorEquals, OperatorNotEquals:
+	case OperatorGreaterThan, OperatorLessThan:
+		if _, err := strconv.ParseFloat(d.Value, 64); err != nil {
+			return fmt.Errorf("operator %s requires a numeric value: %w", d.Operator, err)
+		}
+	default:
+		return fmt.Errorf("unknown operator %q", d.Operator)
+	}
+	d.path = jsonpath.New(d.Name).AllowMissingKeys(true)
+	return d.path.Parse(d.JSONPath)
+}
+
+// GroupVersionKind returns the kind of objects the detection evaluates
+func (d *Detection) GroupVersionKind() schema.GroupVersionKind {
+	gv, _ := schema.ParseGroupVersion(d.APIVersion)
+	return gv.WithKind(d.Kind)
+}
+
+// Matches evaluates the detection against a single object
+func (d *Detection) Matches(obj *unstructured.Unstructured) (bool, error) {
+	results, err := d.path.FindResults(obj.UnstructuredContent())
+	if err != nil {
+		return false, err
+	}
+	var values []string
+	for _, result := range results {
+		for _, v := range result {
+			values = append(values, stringValue(v))
+		}
+	}
+
+	switch d.Operator {
+	case OperatorExists:
+		return len(values) > 0, nil
+	case OperatorNotExists:
+		return len(values) == 0, nil
+	case OperatorEquals:
+		for _, v := range values {
+			if v == d.Value {
+				return true, nil
+			}
+		}
+		return false, nil
+	case OperatorNotEquals:
+		for _, v := range values {
+			if v == d.Value {
+				return false, nil
+			}
+		}
+		return len(values) > 0, nil
+	case OperatorGreaterThan, OperatorLessThan:
+		threshold, _ := strconv.ParseFloat(d.Value, 64)
+		for _, v := range values {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			if (d.Operator == OperatorGreaterThan && f > threshold) || (d.Operator == OperatorLessThan && f < threshold) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("unknown operator %q", d.Operator)
+}
+
+func stringValue(v reflect.Value) string {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return ""
+	}
+	return fmt.Sprint(v.Interface())
+}
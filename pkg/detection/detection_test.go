@@ -0,0 +1,95 @@
+package detection
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func makeTestObject(content map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: content}
+}
+
+func TestParse(t *testing.T) {
+	detections, err := Parse([]byte(`
+detections:
+  - name: ingresscontroller_replicas_high
+    apiVersion: operator.openshift.io/v1
+    kind: IngressController
+    namespace: openshift-ingress-operator
+    jsonPath: "{.spec.replicas}"
+    operator: GreaterThan
+    value: "3"
+`))
+	require.NoError(t, err)
+	require.Len(t, detections, 1)
+	require.Equal(t, "operator.openshift.io", detections[0].GroupVersionKind().Group)
+	require.Equal(t, "IngressController", detections[0].GroupVersionKind().Kind)
+
+	for name, config := range map[string]string{
+		"unknown operator": `
+detections:
+  - {name: a, apiVersion: v1, kind: Node, jsonPath: "{.spec}", operator: Matches}`,
+		"non numeric value": `
+detections:
+  - {name: a, apiVersion: v1, kind: Node, jsonPath: "{.spec}", operator: LessThan, value: abc}`,
+		"invalid jsonpath": `
+detections:
+  - {name: a, apiVersion: v1, kind: Node, jsonPath: "{.spec", operator: Exists}`,
+		"missing kind": `
+detections:
+  - {name: a, apiVersion: v1, jsonPath: "{.spec}", operator: Exists}`,
+		"duplicate name": `
+detections:
+  - {name: a, apiVersion: v1, kind: Node, jsonPath: "{.spec}", operator: Exists}
+  - {name: a, apiVersion: v1, kind: Node, jsonPath: "{.spec}", operator: Exists}`,
+		"unknown field": `
+detections:
+  - {name: a, apiVersion: v1, kind: Node, jsonPath: "{.spec}", operator: Exists, selector: foo}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(config))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestDetection_Matches(t *testing.T) {
+	obj := makeTestObject(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": int64(4),
+			"domain":   "apps.example.com",
+			"tolerations": []interface{}{
+				map[string]interface{}{"key": "node-role.kubernetes.io/infra"},
+				map[string]interface{}{"key": "node-role.kubernetes.io/master"},
+			},
+		},
+	})
+
+	for _, tc := range []struct {
+		name     string
+		jsonPath string
+		operator Operator
+		value    string
+		expected bool
+	}{
+		{name: "exists", jsonPath: "{.spec.domain}", operator: OperatorExists, expected: true},
+		{name: "exists missing", jsonPath: "{.spec.missing}", operator: OperatorExists, expected: false},
+		{name: "not exists", jsonPath: "{.spec.missing}", operator: OperatorNotExists, expected: true},
+		{name: "equals", jsonPath: "{.spec.domain}", operator: OperatorEquals, value: "apps.example.com", expected: true},
+		{name: "equals any list item", jsonPath: "{.spec.tolerations[*].key}", operator: OperatorEquals, value: "node-role.kubernetes.io/master", expected: true},
+		{name: "not equals", jsonPath: "{.spec.domain}", operator: OperatorNotEquals, value: "apps.example.com", expected: false},
+		{name: "not equals missing", jsonPath: "{.spec.missing}", operator: OperatorNotEquals, value: "x", expected: false},
+		{name: "greater than", jsonPath: "{.spec.replicas}", operator: OperatorGreaterThan, value: "3", expected: true},
+		{name: "less than", jsonPath: "{.spec.replicas}", operator: OperatorLessThan, value: "3", expected: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d := Detection{Name: tc.name, APIVersion: "v1", Kind: "Test", JSONPath: tc.jsonPath, Operator: tc.operator, Value: tc.value}
+			require.NoError(t, d.compile())
+			matches, err := d.Matches(obj)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, matches)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+// Package exporterstatus maintains the MetricsExporterStatus, which reports the health of the controllers of the
+// exporter from the reconcile counts controller-runtime records, for must-gather and OLM.
+package exporterstatus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/api/v1alpha1"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultInterval is how often the MetricsExporterStatus is updated
+const DefaultInterval = time.Minute
+
+const (
+	notReconciledReason      = "NotReconciled"
+	reconcileFailedReason    = "ReconcileFailed"
+	reconcileSucceededReason = "ReconcileSucceeded"
+)
+
+var log = logf.Log.WithName("exporterstatus")
+
+// controllerState is what the Reporter observed of a controller
+type controllerState struct {
+	count metrics.ReconcileCount
+	// failing is whether the reconciles since the previous report only returned errors
+	failing bool
+}
+
+// Reporter is a manager Runnable which periodically writes the health of the controllers to the
+// MetricsExporterStatus, creating it if needed. It only runs on the leader, which runs the controllers.
+type Reporter struct {
+	client   client.Client
+	gatherer prometheus.Gatherer
+	interval time.Duration
+	// controllers are the controllers observed so far, by name
+	controllers map[string]*controllerState
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// NewReporter creates a Reporter reading the reconcile counts from gatherer every interval
+func NewReporter(c client.Client, gatherer prometheus.Gatherer, interval time.Duration) *Reporter {
+	return &Reporter{
+		client:      c,
+		gatherer:    gatherer,
+		interval:    interval,
+		controllers: make(map[string]*controllerState),
+		now:         time.Now,
+	}
+}
+
+// Start implements manager.Runnable. It reports immediately and then on every interval until the context is
+// cancelled.
+func (r *Reporter) Start(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		if err := r.report(ctx); err != nil {
+			log.Error(err, "unable to update the MetricsExporterStatus")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Reporter) report(ctx context.Context) error {
+	counts, err := metrics.ReconcileCounts(r.gatherer)
+	if err != nil {
+		return err
+	}
+	status := &v1alpha1.MetricsExporterStatus{}
+	if err := r.client.Get(ctx, client.ObjectKey{Name: v1alpha1.MetricsExporterStatusName}, status); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		status = &v1alpha1.MetricsExporterStatus{ObjectMeta: metav1.ObjectMeta{Name: v1alpha1.MetricsExporterStatusName}}
+		if err := r.client.Create(ctx, status); err != nil {
+			return err
+		}
+	}
+	status.Status.Controllers = r.observe(counts, status.Status.Controllers)
+	return r.client.Status().Update(ctx, status)
+}
+
+// observe returns the status of the controllers with counts. The times and conditions of previous are kept until
+// they change, so they survive restarts of the exporter.
+func (r *Reporter) observe(counts map[string]metrics.ReconcileCount, previous []v1alpha1.ControllerStatus) []v1alpha1.ControllerStatus {
+	byName := make(map[string]v1alpha1.ControllerStatus, len(previous))
+	for _, status := range previous {
+		byName[status.Name] = status
+	}
+	now := metav1.NewTime(r.now())
+	controllers := make([]v1alpha1.ControllerStatus, 0, len(counts))
+	for name, count := range counts {
+		state, ok := r.controllers[name]
+		if !ok {
+			// the counts of a controller start at zero with the exporter
+			state = &controllerState{}
+			r.controllers[name] = state
+		}
+		status, ok := byName[name]
+		if !ok {
+			status = v1alpha1.ControllerStatus{Name: name}
+		}
+		successes, errs := count.Successes-state.count.Successes, count.Errors-state.count.Errors
+		if successes > 0 || errs > 0 {
+			status.LastReconcileTime = &now
+			state.failing = successes == 0
+		}
+		if successes > 0 {
+			status.LastSuccessfulReconcileTime = &now
+		}
+		state.count = count
+		status.Errors = int64(count.Errors)
+
+		condition := metav1.Condition{
+			Type:    v1alpha1.ReconciledCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  reconcileSucceededReason,
+			Message: "The last reconciles did not return an error",
+		}
+		switch {
+		case status.LastReconcileTime == nil:
+			condition.Status = metav1.ConditionUnknown
+			condition.Reason = notReconciledReason
+			condition.Message = "The controller has not reconciled yet"
+		case state.failing:
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = reconcileFailedReason
+			condition.Message = fmt.Sprintf("The last reconciles returned errors, %d since the exporter started", status.Errors)
+		}
+		apimeta.SetStatusCondition(&status.Conditions, condition)
+		controllers = append(controllers, status)
+	}
+	sort.Slice(controllers, func(i, j int) bool {
+		return controllers[i].Name < controllers[j].Name
+	})
+	return controllers
+}
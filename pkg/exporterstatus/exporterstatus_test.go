@@ -0,0 +1,73 @@
+package exporterstatus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReporter_report(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(s))
+	fakeClient := fake.NewClientBuilder().WithScheme(s).Build()
+	registry := prometheus.NewRegistry()
+	reconciles := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "controller_runtime_reconcile_total", Help: "Total number of reconciliations per controller"}, []string{"controller", "result"})
+	registry.MustRegister(reconciles)
+	for _, controller := range []string{"node", "proxy"} {
+		for _, result := range []string{"success", "error", "requeue"} {
+			reconciles.WithLabelValues(controller, result)
+		}
+	}
+
+	r := NewReporter(fakeClient, registry, DefaultInterval)
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	report := func() []v1alpha1.ControllerStatus {
+		require.NoError(t, r.report(context.TODO()))
+		status := &v1alpha1.MetricsExporterStatus{}
+		require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: v1alpha1.MetricsExporterStatusName}, status))
+		return status.Status.Controllers
+	}
+	reconciled := func(status v1alpha1.ControllerStatus) *metav1.Condition {
+		return apimeta.FindStatusCondition(status.Conditions, v1alpha1.ReconciledCondition)
+	}
+
+	// the status is created with the controllers which have not reconciled yet
+	controllers := report()
+	require.Len(t, controllers, 2)
+	require.Equal(t, "node", controllers[0].Name)
+	require.Nil(t, controllers[0].LastReconcileTime)
+	require.Equal(t, metav1.ConditionUnknown, reconciled(controllers[0]).Status)
+
+	reconciles.WithLabelValues("node", "success").Inc()
+	reconciles.WithLabelValues("proxy", "error").Add(2)
+	now = now.Add(time.Minute)
+	controllers = report()
+	require.Equal(t, now, controllers[0].LastSuccessfulReconcileTime.Time.UTC())
+	require.Equal(t, metav1.ConditionTrue, reconciled(controllers[0]).Status)
+	require.Equal(t, now, controllers[1].LastReconcileTime.Time.UTC())
+	require.Nil(t, controllers[1].LastSuccessfulReconcileTime)
+	require.EqualValues(t, 2, controllers[1].Errors)
+	require.Equal(t, metav1.ConditionFalse, reconciled(controllers[1]).Status)
+	require.Equal(t, "ReconcileFailed", reconciled(controllers[1]).Reason)
+
+	// a controller stays failing until a reconcile succeeds
+	now = now.Add(time.Minute)
+	controllers = report()
+	require.Equal(t, metav1.ConditionFalse, reconciled(controllers[1]).Status)
+	reconciles.WithLabelValues("proxy", "requeue").Inc()
+	now = now.Add(time.Minute)
+	controllers = report()
+	require.Equal(t, metav1.ConditionTrue, reconciled(controllers[1]).Status)
+	require.Equal(t, now, controllers[1].LastSuccessfulReconcileTime.Time.UTC())
+	require.Equal(t, now.Add(-time.Minute*2), controllers[0].LastReconcileTime.Time.UTC(), "the times are kept until the controller reconciles again")
+}
@@ -0,0 +1,215 @@
+package gate
+
+import (
+	"context"
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/apiversion"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	defaultPollInterval = time.Minute
+)
+
+var log = logf.Log.WithName("gate")
+
+// watchVerbs are the verbs a controller needs on every resource it watches
+var watchVerbs = []string{"get", "list", "watch"}
+
+// Controller is a controller which might not be able to run yet, either because the custom resource
+// it watches is not installed or because the exporter has not been granted access to its resources.
+type Controller struct {
+	// Name is used for logging and as the controller label of the collector_enabled and collector_setup_failed metrics
+	Name string
+	// CRDName is the name of the CustomResourceDefinition the controller depends on, e.g. upgradeconfigs.upgrade.managed.openshift.io.
+	// Leave empty for built-in resources.
+	CRDName string
+	// Resources the controller watches. The controller is only set up once get, list and watch are allowed on all of them.
+	Resources []authorizationv1.ResourceAttributes
+	// Kinds the controller reads as unstructured objects. They are resolved through discovery before the controller is
+	// set up, so the controller reads a fallback version if the preferred one is no longer served.
+	Kinds []*apiversion.Kind
+	// Setup registers the controller with the manager, usually the reconcilers SetupWithManager
+	Setup func(mgr ctrl.Manager) error
+}
+
+// Gate is a manager Runnable that sets up controllers once their CRD is Established and the exporter
+// is allowed to watch their resources. Preconditions are re-checked on every poll interval, so granting
+// RBAC or installing an operator later does not require restarting the exporter.
+// CRDs are looked up by name through an uncached reader so the exporter does not need to cache every CRD.
+type Gate struct {
+	mgr               ctrl.Manager
+	reader            client.Reader
+	discovery         discovery.ServerResourcesInterface
+	metricsAggregator *metrics.AdoptionMetricsAggregator
+	clusterId         string
+	pollInterval      time.Duration
+	pending           []Controller
+	// canWatch is replaced in tests, as the fake client does not evaluate access reviews
+	canWatch func(ctx context.Context, attributes authorizationv1.ResourceAttributes) (bool, error)
+}
+
+// NewGate creates a Gate for the given manager. Controllers are added with Register.
+func NewGate(mgr ctrl.Manager, metricsAggregator *metrics.AdoptionMetricsAggregator, clusterId string) (*Gate, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	g := &Gate{
+		mgr:               mgr,
+		reader:            mgr.GetAPIReader(),
+		discovery:         discoveryClient,
+		metricsAggregator: metricsAggregator,
+		clusterId:         clusterId,
+		pollInterval:      defaultPollInterval,
+	}
+	g.canWatch = func(ctx context.Context, attributes authorizationv1.ResourceAttributes) (bool, error) {
+		return selfSubjectAccessReview(ctx, mgr.GetClient(), attributes)
+	}
+	return g, nil
+}
+
+// Register adds a controller to be set up once its preconditions are met. Must be called before the manager is started.
+func (g *Gate) Register(c Controller) {
+	g.pending = append(g.pending, c)
+	g.metricsAggregator.SetCollectorEnabled(g.clusterId, c.Name, false)
+	g.metricsAggregator.SetCollectorSetupFailed(g.clusterId, c.Name, false)
+}
+
+// Start implements manager.Runnable. It checks pending controllers immediately and then on every poll interval
+// until all of them have been set up or the context is cancelled.
+func (g *Gate) Start(ctx context.Context) error {
+	ticker := time.NewTicker(g.pollInterval)
+	defer ticker.Stop()
+	for {
+		g.poll(ctx)
+		if len(g.pending) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Controllers started by the gate are
+// leader elected themselves, so the gate can run on every replica.
+func (g *Gate) NeedLeaderElection() bool {
+	return false
+}
+
+// poll sets up every pending controller whose preconditions are met and drops it from the pending list
+func (g *Gate) poll(ctx context.Context) {
+	var stillPending []Controller
+	for _, c := range g.pending {
+		ready, err := g.isReady(ctx, c)
+		if err != nil {
+			log.Error(err, "failed to check controller preconditions", "controller", c.Name)
+			stillPending = append(stillPending, c)
+			continue
+		}
+		if !ready {
+			stillPending = append(stillPending, c)
+			continue
+		}
+		log.Info("preconditions met, starting controller", "controller", c.Name)
+		if err := c.Setup(g.mgr); err != nil {
+			// setting up a controller twice is not supported by the manager, so don't retry. The failure is
+			// exported, as the controller stays disabled like one which is missing permissions.
+			log.Error(err, "unable to create controller", "controller", c.Name)
+			g.metricsAggregator.SetCollectorSetupFailed(g.clusterId, c.Name, true)
+			continue
+		}
+		g.metricsAggregator.SetCollectorEnabled(g.clusterId, c.Name, true)
+	}
+	g.pending = stillPending
+}
+
+func (g *Gate) isReady(ctx context.Context, c Controller) (bool, error) {
+	if c.CRDName != "" {
+		established, err := g.isEstablished(ctx, c.CRDName)
+		if err != nil || !established {
+			return false, err
+		}
+	}
+	for _, kind := range c.Kinds {
+		served, err := g.resolve(kind)
+		if err != nil || !served {
+			return false, err
+		}
+	}
+	for _, resource := range c.Resources {
+		for _, verb := range watchVerbs {
+			attributes := resource
+			attributes.Verb = verb
+			allowed, err := g.canWatch(ctx, attributes)
+			if err != nil {
+				return false, err
+			}
+			if !allowed {
+				log.Info("missing permissions, controller stays disabled", "controller", c.Name,
+					"group", attributes.Group, "resource", attributes.Resource, "verb", verb)
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// resolve switches kind to a served version and reports if its preferred version is no longer served
+func (g *Gate) resolve(kind *apiversion.Kind) (bool, error) {
+	preferred := kind.PreferredGroupVersionKind()
+	deprecated, err := kind.Resolve(g.discovery)
+	g.metricsAggregator.SetWatchAPIDeprecated(g.clusterId, preferred.Group, preferred.Kind, preferred.Version, deprecated)
+	if err != nil {
+		if deprecated {
+			log.Info("no readable version is served, controller stays disabled", "kind", preferred.GroupKind().String(), "reason", err.Error())
+			return false, nil
+		}
+		return false, err
+	}
+	if deprecated {
+		log.Info("preferred version is no longer served, using fallback version", "kind", preferred.GroupKind().String(),
+			"preferred", preferred.Version, "version", kind.GroupVersionKind().Version)
+	}
+	return true, nil
+}
+
+func (g *Gate) isEstablished(ctx context.Context, name string) (bool, error) {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := g.reader.Get(ctx, types.NamespacedName{Name: name}, crd); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type == apiextensionsv1.Established {
+			return condition.Status == apiextensionsv1.ConditionTrue, nil
+		}
+	}
+	return false, nil
+}
+
+func selfSubjectAccessReview(ctx context.Context, c client.Client, attributes authorizationv1.ResourceAttributes) (bool, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &attributes,
+		},
+	}
+	if err := c.Create(ctx, review); err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
@@ -0,0 +1,194 @@
+package gate
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/apiversion"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testCRDName    = "upgradeconfigs.upgrade.managed.openshift.io"
+	testController = "UpgradeConfig"
+	testClusterId  = "cluster-id"
+)
+
+func makeTestCRD(established apiextensionsv1.ConditionStatus) *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: testCRDName},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{
+			Conditions: []apiextensionsv1.CustomResourceDefinitionCondition{
+				{Type: apiextensionsv1.Established, Status: established},
+			},
+		},
+	}
+}
+
+func TestGate_Poll(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		objects       []client.Object
+		allowed       bool
+		expectedSetup bool
+	}{
+		{
+			name:    "crd not installed",
+			allowed: true,
+		},
+		{
+			name:    "crd not established",
+			objects: []client.Object{makeTestCRD(apiextensionsv1.ConditionFalse)},
+			allowed: true,
+		},
+		{
+			name:    "missing permissions",
+			objects: []client.Object{makeTestCRD(apiextensionsv1.ConditionTrue)},
+		},
+		{
+			name:          "crd established and permissions granted",
+			objects:       []client.Object{makeTestCRD(apiextensionsv1.ConditionTrue)},
+			allowed:       true,
+			expectedSetup: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, apiextensionsv1.AddToScheme(scheme))
+			metricsAggregator := metrics.NewMetricsAggregator(testClusterId)
+			setupCalls := 0
+			gate := &Gate{
+				reader:            fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build(),
+				metricsAggregator: metricsAggregator,
+				clusterId:         testClusterId,
+				canWatch: func(ctx context.Context, attributes authorizationv1.ResourceAttributes) (bool, error) {
+					return tc.allowed, nil
+				},
+			}
+			gate.Register(Controller{
+				Name:    testController,
+				CRDName: testCRDName,
+				Resources: []authorizationv1.ResourceAttributes{
+					{Group: "upgrade.managed.openshift.io", Resource: "upgradeconfigs"},
+				},
+				Setup: func(mgr ctrl.Manager) error {
+					setupCalls++
+					return nil
+				},
+			})
+
+			gate.poll(context.TODO())
+			gate.poll(context.TODO())
+
+			enabled := testutil.ToFloat64(metricsAggregator.GetCollectorEnabledMetric().WithLabelValues(testClusterId, testController))
+			if tc.expectedSetup {
+				require.Equal(t, 1, setupCalls)
+				require.Empty(t, gate.pending)
+				require.EqualValues(t, 1, enabled)
+			} else {
+				require.Equal(t, 0, setupCalls)
+				require.Len(t, gate.pending, 1)
+				require.EqualValues(t, 0, enabled)
+			}
+		})
+	}
+}
+
+func TestGate_PermissionsGrantedLater(t *testing.T) {
+	metricsAggregator := metrics.NewMetricsAggregator(testClusterId)
+	allowed := false
+	setupCalls := 0
+	gate := &Gate{
+		metricsAggregator: metricsAggregator,
+		clusterId:         testClusterId,
+		canWatch: func(ctx context.Context, attributes authorizationv1.ResourceAttributes) (bool, error) {
+			return allowed, nil
+		},
+	}
+	gate.Register(Controller{
+		Name:      "PersistentVolume",
+		Resources: []authorizationv1.ResourceAttributes{{Resource: "persistentvolumes"}},
+		Setup: func(mgr ctrl.Manager) error {
+			setupCalls++
+			return nil
+		},
+	})
+
+	gate.poll(context.TODO())
+	require.Equal(t, 0, setupCalls)
+
+	allowed = true
+	gate.poll(context.TODO())
+	require.Equal(t, 1, setupCalls)
+	require.EqualValues(t, 1, testutil.ToFloat64(metricsAggregator.GetCollectorEnabledMetric().WithLabelValues(testClusterId, "PersistentVolume")))
+}
+
+func TestGate_SetupFailed(t *testing.T) {
+	metricsAggregator := metrics.NewMetricsAggregator(testClusterId)
+	setupCalls := 0
+	gate := &Gate{
+		metricsAggregator: metricsAggregator,
+		clusterId:         testClusterId,
+		canWatch: func(ctx context.Context, attributes authorizationv1.ResourceAttributes) (bool, error) {
+			return true, nil
+		},
+	}
+	gate.Register(Controller{
+		Name:      "PersistentVolume",
+		Resources: []authorizationv1.ResourceAttributes{{Resource: "persistentvolumes"}},
+		Setup: func(mgr ctrl.Manager) error {
+			setupCalls++
+			return errors.New("no kind is registered")
+		},
+	})
+	require.EqualValues(t, 0, testutil.ToFloat64(metricsAggregator.GetCollectorSetupFailedMetric().WithLabelValues(testClusterId, "PersistentVolume")))
+
+	gate.poll(context.TODO())
+	gate.poll(context.TODO())
+	require.Equal(t, 1, setupCalls, "a failed setup is not retried")
+	require.Empty(t, gate.pending)
+	require.EqualValues(t, 0, testutil.ToFloat64(metricsAggregator.GetCollectorEnabledMetric().WithLabelValues(testClusterId, "PersistentVolume")))
+	require.EqualValues(t, 1, testutil.ToFloat64(metricsAggregator.GetCollectorSetupFailedMetric().WithLabelValues(testClusterId, "PersistentVolume")))
+}
+
+func TestGate_FallbackVersion(t *testing.T) {
+	metricsAggregator := metrics.NewMetricsAggregator(testClusterId)
+	kind := apiversion.NewKind(schema.GroupVersionKind{Group: "machine.openshift.io", Version: "v1beta1", Kind: "MachineSet"}, "v1")
+	var setupVersion string
+	gate := &Gate{
+		discovery: &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+			{GroupVersion: "machine.openshift.io/v1", APIResources: []metav1.APIResource{{Kind: "MachineSet"}}},
+		}}},
+		metricsAggregator: metricsAggregator,
+		clusterId:         testClusterId,
+		canWatch: func(ctx context.Context, attributes authorizationv1.ResourceAttributes) (bool, error) {
+			return true, nil
+		},
+	}
+	gate.Register(Controller{
+		Name:  "MachineSet",
+		Kinds: []*apiversion.Kind{kind},
+		Setup: func(mgr ctrl.Manager) error {
+			setupVersion = kind.GroupVersionKind().Version
+			return nil
+		},
+	})
+
+	gate.poll(context.TODO())
+	require.Equal(t, "v1", setupVersion)
+	deprecated := testutil.ToFloat64(metricsAggregator.GetWatchAPIDeprecatedMetric().WithLabelValues(testClusterId, "machine.openshift.io", "MachineSet", "v1beta1"))
+	require.EqualValues(t, 1, deprecated)
+}
@@ -0,0 +1,95 @@
+// Package lifecycle embeds the OpenShift lifecycle calendar, so the end of life of the running version is known
+// without querying an external service.
+package lifecycle
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const dateLayout = "2006-01-02"
+
+//go:embed lifecycle.yaml
+var calendarYAML []byte
+
+// calendar is parsed when the exporter starts, so an invalid embedded calendar fails every test of the package
+var calendar = mustParse(calendarYAML)
+
+// Calendar is the content of the lifecycle calendar
+type Calendar struct {
+	Versions []Version `json:"versions"`
+}
+
+// Version is the lifecycle of a minor version
+type Version struct {
+	// Minor version, e.g. 4.10
+	Minor string `json:"minor"`
+	// EndOfMaintenanceSupport is when the version reaches its end of life, as YYYY-MM-DD
+	EndOfMaintenanceSupport string `json:"endOfMaintenanceSupport"`
+	// EndOfExtendedUpdateSupport is when Extended Update Support of the version ends, as YYYY-MM-DD. It is
+	// empty for versions without Extended Update Support.
+	EndOfExtendedUpdateSupport string `json:"endOfExtendedUpdateSupport,omitempty"`
+}
+
+func mustParse(data []byte) map[string]Version {
+	versions, err := Parse(data)
+	if err != nil {
+		panic(err)
+	}
+	return versions
+}
+
+// Parse parses a lifecycle calendar and returns its versions by minor version
+func Parse(data []byte) (map[string]Version, error) {
+	c := &Calendar{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, err
+	}
+	versions := make(map[string]Version, len(c.Versions))
+	for _, v := range c.Versions {
+		if _, err := time.Parse(dateLayout, v.EndOfMaintenanceSupport); err != nil {
+			return nil, fmt.Errorf("version %s: invalid endOfMaintenanceSupport: %w", v.Minor, err)
+		}
+		if v.EndOfExtendedUpdateSupport != "" {
+			if _, err := time.Parse(dateLayout, v.EndOfExtendedUpdateSupport); err != nil {
+				return nil, fmt.Errorf("version %s: invalid endOfExtendedUpdateSupport: %w", v.Minor, err)
+			}
+		}
+		versions[v.Minor] = v
+	}
+	return versions, nil
+}
+
+// MinorVersion returns the minor version of a version, e.g. 4.10 for 4.10.36
+func MinorVersion(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// IsEUSChannel returns true for the Extended Update Support channels, e.g. eus-4.10
+func IsEUSChannel(channel string) bool {
+	return strings.HasPrefix(channel, "eus-")
+}
+
+// EndOfLife returns when the minor version of version reaches its end of life. Versions on an Extended Update
+// Support channel reach it when Extended Update Support ends, if the version has it.
+func EndOfLife(version string, eus bool) (time.Time, bool) {
+	v, ok := calendar[MinorVersion(version)]
+	if !ok {
+		return time.Time{}, false
+	}
+	end := v.EndOfMaintenanceSupport
+	if eus && v.EndOfExtendedUpdateSupport != "" {
+		end = v.EndOfExtendedUpdateSupport
+	}
+	// the dates were validated when parsing
+	t, _ := time.Parse(dateLayout, end)
+	return t, true
+}
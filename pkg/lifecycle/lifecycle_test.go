@@ -0,0 +1,38 @@
+package lifecycle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndOfLife(t *testing.T) {
+	eol, ok := EndOfLife("4.10.36", false)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2023, 9, 10, 0, 0, 0, 0, time.UTC), eol)
+
+	eol, ok = EndOfLife("4.10.36", true)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), eol)
+
+	// odd versions have no Extended Update Support
+	eol, ok = EndOfLife("4.11.9", true)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC), eol)
+
+	_, ok = EndOfLife("4.99.0", false)
+	require.False(t, ok)
+	_, ok = EndOfLife("", false)
+	require.False(t, ok)
+}
+
+func TestIsEUSChannel(t *testing.T) {
+	require.True(t, IsEUSChannel("eus-4.10"))
+	require.False(t, IsEUSChannel("stable-4.10"))
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := Parse([]byte("versions:\n  - minor: \"4.10\"\n    endOfMaintenanceSupport: 10 September 2023\n"))
+	require.Error(t, err)
+}
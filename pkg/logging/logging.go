@@ -0,0 +1,123 @@
+// Package logging sets the log level of single loggers, e.g. to debug one controller without the logs of all the
+// others. Loggers are matched by the name they are created with, like controller_cpms.
+package logging
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Levels are the log levels of single loggers, and the default level of all others
+type Levels struct {
+	defaultLevel zapcore.Level
+	byName       map[string]zapcore.Level
+}
+
+// ParseLevels parses comma separated logger=level pairs, e.g. controller_cpms=debug,gate=error. The levels are
+// those of --zap-log-level: debug, info, error, or an integer verbosity greater than 0. The loggers which are not
+// listed log at defaultLevel.
+func ParseLevels(s string, defaultLevel zapcore.LevelEnabler) (*Levels, error) {
+	levels := &Levels{defaultLevel: minEnabled(defaultLevel), byName: make(map[string]zapcore.Level)}
+	if s == "" {
+		return levels, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid log level %q, want logger=level", pair)
+		}
+		level, err := parseLevel(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level of logger %s: %w", name, err)
+		}
+		levels.byName[name] = level
+	}
+	return levels, nil
+}
+
+// parseLevel parses a level like the --zap-log-level flag, where an integer is a logr verbosity
+func parseLevel(value string) (zapcore.Level, error) {
+	if verbosity, err := strconv.Atoi(value); err == nil {
+		if verbosity <= 0 {
+			return 0, fmt.Errorf("verbosity %d is not greater than 0", verbosity)
+		}
+		return zapcore.Level(-verbosity), nil
+	}
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return 0, err
+	}
+	return level, nil
+}
+
+// minEnabled returns the lowest level enabler enables
+func minEnabled(enabler zapcore.LevelEnabler) zapcore.Level {
+	for level := zapcore.Level(-127); level < zapcore.FatalLevel; level++ {
+		if enabler.Enabled(level) {
+			return level
+		}
+	}
+	return zapcore.FatalLevel
+}
+
+// Enabled returns whether the logger named name logs at level. A logger without a level of its own has the level
+// of its closest parent, e.g. controller.cpms has the level of controller, or else the default level.
+func (l *Levels) Enabled(name string, level zapcore.Level) bool {
+	return level >= l.levelOf(name)
+}
+
+func (l *Levels) levelOf(name string) zapcore.Level {
+	for {
+		if level, ok := l.byName[name]; ok {
+			return level
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return l.defaultLevel
+		}
+		name = name[:i]
+	}
+}
+
+// Min returns the lowest level any logger logs at, which the core of the logger must enable
+func (l *Levels) Min() zapcore.Level {
+	min := l.defaultLevel
+	for _, level := range l.byName {
+		if level < min {
+			min = level
+		}
+	}
+	return min
+}
+
+// WrapCore returns an option dropping the entries of every logger below its level. The wrapped core must enable
+// Min, as the entries it does not enable are dropped before their logger is known.
+func (l *Levels) WrapCore() zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if len(l.byName) == 0 {
+			return core
+		}
+		return &levelCore{Core: core, levels: l}
+	})
+}
+
+// levelCore is a core checking the level of the logger of every entry
+type levelCore struct {
+	zapcore.Core
+	levels *Levels
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.levels.Enabled(entry.LoggerName, entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels("controller_cpms=debug, gate=error,controller=2", zapcore.InfoLevel)
+	require.NoError(t, err)
+	require.Equal(t, map[string]zapcore.Level{
+		"controller_cpms": zapcore.DebugLevel,
+		"gate":            zapcore.ErrorLevel,
+		"controller":      zapcore.Level(-2),
+	}, levels.byName)
+	require.Equal(t, zapcore.Level(-2), levels.Min())
+
+	require.True(t, levels.Enabled("controller_cpms", zapcore.DebugLevel))
+	require.False(t, levels.Enabled("gate", zapcore.InfoLevel))
+	require.True(t, levels.Enabled("controller.cpms", zapcore.Level(-2)), "a logger has the level of its parent")
+	require.False(t, levels.Enabled("controller_group", zapcore.DebugLevel), "the other loggers have the default level")
+	require.True(t, levels.Enabled("controller_group", zapcore.InfoLevel))
+
+	for _, invalid := range []string{"controller_cpms", "=debug", "gate=verbose", "gate=0"} {
+		_, err := ParseLevels(invalid, zapcore.InfoLevel)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestParseLevels_defaultLevel(t *testing.T) {
+	levels, err := ParseLevels("", zap.NewAtomicLevelAt(zapcore.Level(-3)))
+	require.NoError(t, err)
+	require.Equal(t, zapcore.Level(-3), levels.Min())
+}
+
+func TestLevels_WrapCore(t *testing.T) {
+	levels, err := ParseLevels("controller_cpms=debug,gate=error", zapcore.InfoLevel)
+	require.NoError(t, err)
+	core, logs := observer.New(levels.Min())
+	logger := zap.New(core, levels.WrapCore())
+
+	logger.Named("controller_cpms").With(zap.String("Request.Name", "cluster")).Debug("reconciling")
+	logger.Named("controller_group").Debug("dropped")
+	logger.Named("controller_group").Info("reconciling")
+	logger.Named("gate").Info("dropped")
+	logger.Named("gate").Error("failed")
+
+	var messages []string
+	for _, entry := range logs.All() {
+		messages = append(messages, entry.LoggerName+": "+entry.Message)
+	}
+	require.Equal(t, []string{"controller_cpms: reconciling", "controller_group: reconciling", "gate: failed"}, messages)
+	require.Equal(t, "cluster", logs.All()[0].ContextMap()["Request.Name"])
+}
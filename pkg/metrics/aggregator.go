@@ -1,7 +1,9 @@
 package metrics
 
 import (
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -9,13 +11,21 @@ import (
 )
 
 const (
-	providerLabel       = "provider"
-	osdExporterValue    = "osd_exporter"
-	proxyHTTPLabel      = "http"
-	proxyHTTPSLabel     = "https"
-	proxyCALabel        = "trusted_ca"
-	proxyCASubjectLabel = "subject"
-	clusterIDLabel      = "_id"
+	providerLabel          = "provider"
+	osdExporterValue       = "osd_exporter"
+	clusterIDLabel         = "_id"
+	controllerLabel        = "controller"
+	verbLabel              = "verb"
+	apiGroupLabel          = "group"
+	resourceLabel          = "resource"
+	detectionLabel         = "detection"
+	blockingReasonLabel    = "blocking_reason"
+	kindLabel              = "kind"
+	selectorLabel          = "selector"
+	namespaceSelectorLabel = "namespace_selector"
+	versionLabel           = "version"
+	previousClusterIDLabel = "previous_id"
+	metricLabel            = "metric"
 )
 
 var knownIdentityProviderTypes = []configv1.IdentityProviderType{
@@ -30,86 +40,148 @@ var knownIdentityProviderTypes = []configv1.IdentityProviderType{
 	configv1.IdentityProviderTypeRequestHeader,
 }
 
+var labelValuesPool = sync.Pool{
+	New: func() interface{} {
+		values := make([]string, 0, 8)
+		return &values
+	},
+}
+
+// Reasons blocking an upgrade, used as the blocking_reason label of upgrade_ready
+const (
+	UpgradeBlockerDegradedOperators        = "DegradedOperators"
+	UpgradeBlockerPodDisruptionBudgets     = "PodDisruptionBudgets"
+	UpgradeBlockerDeprecatedAPIs           = "DeprecatedAPIs"
+	UpgradeBlockerPausedMachineConfigPools = "PausedMachineConfigPools"
+	UpgradeBlockerAdminAck                 = "AdminAck"
+)
+
 type providerKey struct {
 	name      string
 	namespace string
 }
 
 type AdoptionMetricsAggregator struct {
+	// clusterId is the cluster id the aggregator was created with
+	clusterId            string
 	identityProviders    *prometheus.GaugeVec
-	clusterAdmin         prometheus.GaugeVec
-	limitedSupport       *prometheus.GaugeVec
 	providerMap          map[providerKey][]configv1.IdentityProviderType
-	clusterProxy         *prometheus.GaugeVec
-	clusterProxyCAExpiry *prometheus.GaugeVec
-	clusterProxyCAValid  prometheus.GaugeVec
+	providerCounts       map[configv1.IdentityProviderType]int
+	providerGauges       map[configv1.IdentityProviderType]prometheus.Gauge
 	clusterID            *prometheus.GaugeVec
-	mutex                sync.Mutex
-	aggregationInterval  time.Duration
+	collectorEnabled     *prometheus.GaugeVec
+	collectorSetupFailed *prometheus.GaugeVec
+	apiUsage             *prometheus.GaugeVec
+	detections           *prometheus.GaugeVec
+	upgradeReady         *prometheus.GaugeVec
+	upgradeBlockers      map[string]bool
+	objectCounts         *prometheus.GaugeVec
+	watchAPIDeprecated   *prometheus.GaugeVec
+	clusterIDChanged     *prometheus.GaugeVec
+	droppedSeries        *prometheus.CounterVec
+	clusterInfoMetric    *prometheus.GaugeVec
+	// clusterInfo holds the facts of osd_cluster_info by cluster id, guarded by mutex
+	clusterInfo      map[string]map[ClusterInfoFact]string
+	labelValues      *labelInterner
+	tracers          map[*prometheus.MetricVec]*metricTracer
+	observe          func(metric string, labelValues []string, value float64)
+	expiries         map[*prometheus.MetricVec]*seriesExpiry
+	limits           map[*prometheus.MetricVec]*seriesLimit
+	limitClusterId   string
+	clusterIDAliases atomic.Pointer[map[string]string]
+	ownership        atomic.Pointer[map[string]Ownership]
+	relabelMutex     sync.RWMutex
+	// relabelEpoch counts the relabelings, series looked up before one are looked up again when they are updated
+	relabelEpoch atomic.Uint64
+	// registered are the collectors registered by controllers, guarded by registryMutex
+	registered    []Collector
+	registryMutex sync.Mutex
+	// exported is set once GetMetrics returned the collectors, after which no collector can be registered
+	exported bool
+	// reconcileMetrics export the reconcile metrics of controller-runtime, guarded by registryMutex
+	reconcileMetrics []prometheus.Collector
+	// mutex guards the identity providers of the OAuth configs, the upgrade blockers and the cluster info
+	mutex sync.Mutex
 }
 
 // NewMetricsAggregator creates a metric aggregator. Should not be used directory but through GetMetricsAggregator
-func NewMetricsAggregator(aggregationInterval time.Duration, clusterId string) *AdoptionMetricsAggregator {
+func NewMetricsAggregator(clusterId string) *AdoptionMetricsAggregator {
 	collector := &AdoptionMetricsAggregator{
+		clusterId: clusterId,
 		identityProviders: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name:        "identity_provider",
 			Help:        "Indicates if an identity provider is enabled",
 			ConstLabels: map[string]string{"name": osdExporterValue},
 		}, []string{providerLabel}),
-		clusterAdmin: *prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name:        "cluster_admin_enabled",
-			Help:        "Indicates if the cluster-admin role is enabled",
+		clusterID: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "cluster_id",
+			Help:        "Indicates the cluster id",
 			ConstLabels: map[string]string{"name": osdExporterValue},
 		}, []string{clusterIDLabel}),
-		limitedSupport: prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name:        "limited_support_enabled",
-			Help:        "Indicates if limited support is enabled",
+		collectorEnabled: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "collector_enabled",
+			Help:        "Indicates if a collector is running or waiting for its CRD or RBAC permissions",
 			ConstLabels: map[string]string{"name": osdExporterValue},
-		}, []string{clusterIDLabel}),
-		clusterProxy: prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name:        "cluster_proxy",
-			Help:        "Indicates cluster proxy state",
+		}, []string{clusterIDLabel, controllerLabel}),
+		collectorSetupFailed: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "collector_setup_failed",
+			Help:        "Indicates if a collector failed to start after its CRD and RBAC permissions were available",
 			ConstLabels: map[string]string{"name": osdExporterValue},
-		}, []string{clusterIDLabel, proxyHTTPLabel, proxyHTTPSLabel, proxyCALabel}),
-		clusterProxyCAExpiry: prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name:        "cluster_proxy_ca_expiry_timestamp",
-			Help:        "Indicates cluster proxy CA expiry unix timestamp in UTC",
+		}, []string{clusterIDLabel, controllerLabel}),
+		apiUsage: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "rbac_permission_used",
+			Help:        "Indicates a verb and resource the exporter has used on the API since it started",
 			ConstLabels: map[string]string{"name": osdExporterValue},
-		}, []string{clusterIDLabel, proxyCASubjectLabel}),
-		clusterProxyCAValid: *prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name:        "cluster_proxy_ca_valid",
-			Help:        "Indicates if cluster proxy CA valid",
+		}, []string{clusterIDLabel, verbLabel, apiGroupLabel, resourceLabel}),
+		detections: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "detection_match_count",
+			Help:        "Indicates the number of objects matching a configured detection",
 			ConstLabels: map[string]string{"name": osdExporterValue},
-		}, []string{clusterIDLabel}),
-		clusterID: prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name:        "cluster_id",
-			Help:        "Indicates the cluster id",
+		}, []string{clusterIDLabel, detectionLabel}),
+		upgradeReady: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "upgrade_ready",
+			Help:        "Indicates if the cluster is ready to upgrade, with a series per reason blocking the upgrade",
 			ConstLabels: map[string]string{"name": osdExporterValue},
-		}, []string{clusterIDLabel}),
-		providerMap:         make(map[providerKey][]configv1.IdentityProviderType),
-		aggregationInterval: aggregationInterval,
+		}, []string{clusterIDLabel, blockingReasonLabel}),
+		objectCounts: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "object_count",
+			Help:        "Indicates the number of objects of a kind matching a configured selector",
+			ConstLabels: map[string]string{"name": osdExporterValue},
+		}, []string{clusterIDLabel, kindLabel, selectorLabel, namespaceSelectorLabel}),
+		watchAPIDeprecated: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "watch_api_deprecated",
+			Help:        "Indicates if the API version a collector was built against is no longer served",
+			ConstLabels: map[string]string{"name": osdExporterValue},
+		}, []string{clusterIDLabel, apiGroupLabel, kindLabel, versionLabel}),
+		clusterIDChanged: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "cluster_id_changed",
+			Help:        "Indicates the unix timestamp the cluster id changed from previous_id, after which all series were moved to the new _id",
+			ConstLabels: map[string]string{"name": osdExporterValue},
+		}, []string{clusterIDLabel, previousClusterIDLabel}),
+		droppedSeries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "osd_exporter_dropped_series_total",
+			Help:        "Indicates the number of updates of new series of a metric which were dropped as the metric reached its series limit",
+			ConstLabels: map[string]string{"name": osdExporterValue},
+		}, []string{clusterIDLabel, metricLabel}),
+		clusterInfoMetric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "osd_cluster_info",
+			Help:        "Indicates the version, platform, network type and if FIPS and STS are enabled, with value 1",
+			ConstLabels: map[string]string{"name": osdExporterValue},
+		}, []string{clusterIDLabel, string(ClusterInfoVersion), string(ClusterInfoPlatform), string(ClusterInfoNetworkType),
+			string(ClusterInfoFIPS), string(ClusterInfoSTS)}),
+		clusterInfo:     make(map[string]map[ClusterInfoFact]string),
+		providerMap:     make(map[providerKey][]configv1.IdentityProviderType),
+		providerCounts:  make(map[configv1.IdentityProviderType]int, len(knownIdentityProviderTypes)),
+		providerGauges:  make(map[configv1.IdentityProviderType]prometheus.Gauge, len(knownIdentityProviderTypes)),
+		upgradeBlockers: make(map[string]bool),
+		labelValues:     newLabelInterner(),
+	}
+	for _, t := range knownIdentityProviderTypes {
+		collector.providerGauges[t] = collector.identityProviders.WithLabelValues(string(t))
 	}
-	collector.SetClusterAdmin(clusterId, false)
-	collector.SetLimitedSupport(clusterId, false)
 	return collector
 }
 
-func (a *AdoptionMetricsAggregator) Run() chan interface{} {
-	ticker := time.NewTicker(a.aggregationInterval)
-	done := make(chan interface{})
-	go func() {
-		for {
-			select {
-			case <-done:
-				return
-			case <-ticker.C:
-				a.aggregate()
-			}
-		}
-	}()
-	return done
-}
-
 func (a *AdoptionMetricsAggregator) SetOAuthIDP(name, namespace string, provider []configv1.IdentityProvider) {
 	providerTypes := make([]configv1.IdentityProviderType, len(provider))
 	for i, p := range provider {
@@ -117,121 +189,271 @@ func (a *AdoptionMetricsAggregator) SetOAuthIDP(name, namespace string, provider
 	}
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
-	a.providerMap[providerKey{name: name, namespace: namespace}] = providerTypes
+	a.providerMap[providerKey{name: a.labelValues.intern(name), namespace: a.labelValues.intern(namespace)}] = providerTypes
+	a.aggregateIdentityProviders()
 }
 
 func (a *AdoptionMetricsAggregator) DeleteOAuthIDP(name, namespace string) {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 	delete(a.providerMap, providerKey{name: name, namespace: namespace})
+	a.aggregateIdentityProviders()
 }
 
-func (a *AdoptionMetricsAggregator) aggregate() {
-	a.mutex.Lock()
-	defer a.mutex.Unlock()
-	providers := make(map[configv1.IdentityProviderType]int)
+// aggregateIdentityProviders counts the identity providers of all OAuth configs, so the metric reflects an
+// update as soon as the setter returns. It must be called with the mutex held.
+func (a *AdoptionMetricsAggregator) aggregateIdentityProviders() {
+	for t := range a.providerCounts {
+		a.providerCounts[t] = 0
+	}
 	for _, v := range a.providerMap {
 		for _, p := range v {
-			if _, ok := providers[p]; !ok {
-				providers[p] = 0
-			}
-			providers[p] += 1
+			a.providerCounts[p] += 1
 		}
 	}
 
 	for _, t := range knownIdentityProviderTypes {
-		if count, ok := providers[t]; ok {
-			a.identityProviders.With(prometheus.Labels{providerLabel: string(t)}).Set(float64(count))
-		} else {
-			a.identityProviders.With(prometheus.Labels{providerLabel: string(t)}).Set(0)
+		a.providerGauges[t].Set(float64(a.providerCounts[t]))
+	}
+}
+
+// gauge returns the child of vec for the interned label values. Label values must be passed in the
+// order the vec was declared with, which avoids building a prometheus.Labels map on every update.
+// The values are copied into a pooled buffer so lvs does not escape and updating an existing
+// series does not allocate.
+func (a *AdoptionMetricsAggregator) gauge(vec *prometheus.GaugeVec, lvs ...string) Gauge {
+	return Gauge{a.child(vec.MetricVec, lvs...)}
+}
+
+// child returns the series of the vec of any metric type for the interned label values, with the cluster id
+// aliases applied. The series is looked up while no relabeling is in progress, so it is not created for an id
+// whose series were moved already.
+func (a *AdoptionMetricsAggregator) child(vec *prometheus.MetricVec, lvs ...string) series {
+	a.relabelMutex.RLock()
+	defer a.relabelMutex.RUnlock()
+	return a.lookup(vec, lvs...)
+}
+
+// lookup returns the series like child, with the relabel lock held. Gauges are traced if a tracer is set for them.
+func (a *AdoptionMetricsAggregator) lookup(vec *prometheus.MetricVec, lvs ...string) series {
+	s := series{aggregator: a, vec: vec, epoch: a.relabelEpoch.Load()}
+	buf := labelValuesPool.Get().(*[]string)
+	values := append((*buf)[:0], lvs...)
+	a.labelValues.internValues(values)
+	if aliases := a.clusterIDAliases.Load(); aliases != nil && len(values) > 0 {
+		// _id is the first label of every metric that has it
+		if id, ok := (*aliases)[values[0]]; ok {
+			values[0] = id
+		}
+	}
+	if len(a.limits) > 0 {
+		if l, ok := a.limits[vec]; ok && !l.admit(values) {
+			*buf = values
+			labelValuesPool.Put(buf)
+			a.dropSeries(l)
+			s.metric = l.discard
+			return s
 		}
 	}
+	// the update is looked up before the series, so a series expiring in between is created again by its write
+	if len(a.expiries) > 0 {
+		if e, ok := a.expiries[vec]; ok {
+			s.expiry, s.update = e, e.entry(values)
+		}
+	}
+	// the vec copies label values when it creates a new child, so the buffer can be reused
+	m, err := vec.GetMetricWithLabelValues(values...)
+	if err != nil {
+		panic(err)
+	}
+	s.metric = a.wrap(vec, values, m)
+	*buf = values
+	labelValuesPool.Put(buf)
+	return s
 }
 
-func (a *AdoptionMetricsAggregator) SetClusterAdmin(uuid string, enabled bool) {
-	labels := prometheus.Labels{
-		clusterIDLabel: uuid,
+// wrap traces and observes the updates of a gauge series if a tracer is set for its metric or updates are observed
+func (a *AdoptionMetricsAggregator) wrap(vec *prometheus.MetricVec, lvs []string, m prometheus.Metric) prometheus.Metric {
+	if len(a.tracers) > 0 {
+		if t, ok := a.tracers[vec]; ok {
+			m = t.wrap(m.(prometheus.Gauge))
+		}
 	}
-	if enabled {
-		a.clusterAdmin.With(labels).Set(1)
-	} else {
-		a.clusterAdmin.With(labels).Set(0)
+	if a.observe != nil {
+		if g, ok := m.(prometheus.Gauge); ok {
+			m = &observedGauge{Gauge: g, metric: collectorName(vec), labelValues: append([]string(nil), lvs...), observe: a.observe}
+		}
 	}
+	return m
 }
 
-func (a *AdoptionMetricsAggregator) SetLimitedSupport(uuid string, enabled bool) {
-	labels := prometheus.Labels{
-		clusterIDLabel: uuid,
+// recreate looks up the series of vec with the label values again after it was deleted, with the relabel lock and the
+// mutex of its expiry held. It returns the discarded series without an update if the series is dropped by the limit.
+func (a *AdoptionMetricsAggregator) recreate(vec *prometheus.MetricVec, e *seriesExpiry, lvs []string) (prometheus.Metric, *seriesUpdate) {
+	if l, ok := a.limits[vec]; ok && !l.admit(lvs) {
+		a.dropSeries(l)
+		return l.discard, nil
 	}
+	m, err := vec.GetMetricWithLabelValues(lvs...)
+	if err != nil {
+		panic(err)
+	}
+	return a.wrap(vec, lvs, m), e.record(lvs, time.Time{})
+}
 
-	if enabled {
-		a.limitedSupport.With(labels).Set(1)
-	} else {
-		a.limitedSupport.With(labels).Set(0)
+// ClusterID returns the cluster id the aggregator was created with, for metrics which have a series before their
+// controller reconciled. The series are moved to the id of the ClusterVersion like all others.
+func (a *AdoptionMetricsAggregator) ClusterID() string {
+	return a.clusterId
+}
+
+// BoolToFloat returns the gauge value of a flag
+func BoolToFloat(b bool) float64 {
+	if b {
+		return 1
 	}
+	return 0
+}
+
+func (a *AdoptionMetricsAggregator) SetClusterID(uuid string) {
+	a.gauge(a.clusterID, uuid).Set(1)
+}
+
+func (a *AdoptionMetricsAggregator) SetCollectorEnabled(uuid string, controller string, enabled bool) {
+	a.gauge(a.collectorEnabled, uuid, controller).Set(BoolToFloat(enabled))
+}
+
+func (a *AdoptionMetricsAggregator) SetCollectorSetupFailed(uuid string, controller string, failed bool) {
+	a.gauge(a.collectorSetupFailed, uuid, controller).Set(BoolToFloat(failed))
+}
+
+func (a *AdoptionMetricsAggregator) SetAPIUsage(uuid string, verb string, group string, resource string) {
+	a.gauge(a.apiUsage, uuid, verb, group, resource).Set(1)
 }
 
-func (a *AdoptionMetricsAggregator) SetClusterProxy(uuid string, proxyHTTP string, proxyHTTPS string, proxyTrustedCA string, proxyEnabled int) {
-	a.clusterProxy.With(prometheus.Labels{
-		clusterIDLabel:  uuid,
-		proxyHTTPLabel:  proxyHTTP,
-		proxyHTTPSLabel: proxyHTTPS,
-		proxyCALabel:    proxyTrustedCA,
-	}).Set(float64(proxyEnabled))
+func (a *AdoptionMetricsAggregator) SetDetectionMatchCount(uuid string, detection string, count int) {
+	a.gauge(a.detections, uuid, detection).Set(float64(count))
 }
 
-func (a *AdoptionMetricsAggregator) SetClusterProxyCAExpiry(uuid string, subject string, clusterProxyCAExpiry int64) {
-	a.clusterProxyCAExpiry.With(prometheus.Labels{
-		clusterIDLabel:      uuid,
-		proxyCASubjectLabel: subject,
-	}).Set(float64(clusterProxyCAExpiry))
+// SetUpgradeBlocker records whether a collector currently sees a reason blocking upgrades.
+// The upgrade_ready metric is computed from the recorded reasons by EvaluateUpgradeReadiness.
+func (a *AdoptionMetricsAggregator) SetUpgradeBlocker(reason string, blocking bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.upgradeBlockers[reason] = blocking
 }
 
-func (a *AdoptionMetricsAggregator) SetClusterProxyCAValid(uuid string, valid bool) {
-	labels := prometheus.Labels{
-		clusterIDLabel: uuid,
+// EvaluateUpgradeReadiness replaces the upgrade_ready series with one series per blocking reason with value 0,
+// or a single series without a reason with value 1 if nothing blocks the upgrade. It returns the blocking reasons.
+func (a *AdoptionMetricsAggregator) EvaluateUpgradeReadiness(uuid string) []string {
+	a.mutex.Lock()
+	var reasons []string
+	for reason, blocking := range a.upgradeBlockers {
+		if blocking {
+			reasons = append(reasons, reason)
+		}
 	}
-	if valid {
-		a.clusterProxyCAValid.With(labels).Set(1)
-	} else {
-		a.clusterProxyCAValid.With(labels).Set(0)
+	a.mutex.Unlock()
+	sort.Strings(reasons)
+
+	samples := []Sample{{LabelValues: []string{""}, Value: 1}}
+	if len(reasons) > 0 {
+		samples = make([]Sample, len(reasons))
+		for i, reason := range reasons {
+			samples[i] = Sample{LabelValues: []string{reason}}
+		}
 	}
+	a.setSnapshot(a.upgradeReady, uuid, samples)
+	return reasons
 }
 
-func (a *AdoptionMetricsAggregator) SetClusterID(uuid string) {
-	a.clusterID.With(prometheus.Labels{
-		clusterIDLabel: uuid,
-	}).Set(1)
+func (a *AdoptionMetricsAggregator) SetObjectCount(uuid string, kind string, selector string, namespaceSelector string, count int) {
+	a.gauge(a.objectCounts, uuid, kind, selector, namespaceSelector).Set(float64(count))
 }
 
+func (a *AdoptionMetricsAggregator) SetWatchAPIDeprecated(uuid string, group string, kind string, version string, deprecated bool) {
+	a.gauge(a.watchAPIDeprecated, uuid, group, kind, version).Set(BoolToFloat(deprecated))
+}
+
+// GetMetrics returns the collectors to register. Collection waits for RelabelClusterID to move all series.
 func (a *AdoptionMetricsAggregator) GetMetrics() []prometheus.Collector {
-	return []prometheus.Collector{a.identityProviders, a.clusterAdmin, a.limitedSupport, a.clusterProxy, a.clusterProxyCAExpiry, a.clusterProxyCAValid, a.clusterID}
+	a.registryMutex.Lock()
+	a.exported = true
+	a.registryMutex.Unlock()
+	collectors := a.collectors()
+	for i, c := range collectors {
+		locked := &lockedCollector{Collector: c, mutex: &a.relabelMutex}
+		switch v := c.(type) {
+		case *prometheus.GaugeVec:
+			locked.expiry = a.expiries[v.MetricVec]
+			locked.vec, locked.limit = v.MetricVec, a.limits[v.MetricVec]
+		case *prometheus.CounterVec:
+			locked.expiry = a.expiries[v.MetricVec]
+			locked.vec, locked.limit = v.MetricVec, a.limits[v.MetricVec]
+		case *prometheus.HistogramVec:
+			locked.expiry = a.expiries[v.MetricVec]
+			locked.vec, locked.limit = v.MetricVec, a.limits[v.MetricVec]
+		}
+		collectors[i] = locked
+	}
+	return collectors
 }
 
-func (a *AdoptionMetricsAggregator) GetClusterRoleMetric() prometheus.GaugeVec {
-	return a.clusterAdmin
+// collectors returns the metrics of the aggregator followed by the reconcile metrics and the metrics of the
+// registered collectors
+func (a *AdoptionMetricsAggregator) collectors() []prometheus.Collector {
+	a.registryMutex.Lock()
+	defer a.registryMutex.Unlock()
+	collectors := append(a.builtinCollectors(), a.reconcileMetrics...)
+	return append(collectors, a.registeredCollectors()...)
 }
 
-func (a *AdoptionMetricsAggregator) GetLimitedsupportStatus() *prometheus.GaugeVec {
-	return a.limitedSupport
+func (a *AdoptionMetricsAggregator) builtinCollectors() []prometheus.Collector {
+	return []prometheus.Collector{a.identityProviders, a.clusterID, a.collectorEnabled, a.collectorSetupFailed, a.apiUsage,
+		a.detections, a.upgradeReady, a.objectCounts, a.watchAPIDeprecated, a.clusterIDChanged, a.droppedSeries,
+		a.clusterInfoMetric}
 }
 
 func (a *AdoptionMetricsAggregator) GetIdentityProviderMetric() *prometheus.GaugeVec {
 	return a.identityProviders
 }
 
-func (a *AdoptionMetricsAggregator) GetClusterProxyMetric() *prometheus.GaugeVec {
-	return a.clusterProxy
-}
-
 func (a *AdoptionMetricsAggregator) GetClusterIDMetric() *prometheus.GaugeVec {
 	return a.clusterID
 }
 
-func (a *AdoptionMetricsAggregator) GetClusterProxyCAExpiryMetrics() *prometheus.GaugeVec {
-	return a.clusterProxyCAExpiry
+func (a *AdoptionMetricsAggregator) GetCollectorEnabledMetric() *prometheus.GaugeVec {
+	return a.collectorEnabled
+}
+
+func (a *AdoptionMetricsAggregator) GetCollectorSetupFailedMetric() *prometheus.GaugeVec {
+	return a.collectorSetupFailed
+}
+
+func (a *AdoptionMetricsAggregator) GetAPIUsageMetric() *prometheus.GaugeVec {
+	return a.apiUsage
+}
+
+func (a *AdoptionMetricsAggregator) GetDetectionMetric() *prometheus.GaugeVec {
+	return a.detections
+}
+
+func (a *AdoptionMetricsAggregator) GetUpgradeReadyMetric() *prometheus.GaugeVec {
+	return a.upgradeReady
+}
+
+func (a *AdoptionMetricsAggregator) GetObjectCountMetric() *prometheus.GaugeVec {
+	return a.objectCounts
+}
+
+func (a *AdoptionMetricsAggregator) GetWatchAPIDeprecatedMetric() *prometheus.GaugeVec {
+	return a.watchAPIDeprecated
+}
+
+func (a *AdoptionMetricsAggregator) GetClusterIDChangedMetric() *prometheus.GaugeVec {
+	return a.clusterIDChanged
 }
 
-func (a *AdoptionMetricsAggregator) GetClusterProxyCAValidMetrics() prometheus.GaugeVec {
-	return a.clusterProxyCAValid
+func (a *AdoptionMetricsAggregator) GetClusterInfoMetric() *prometheus.GaugeVec {
+	return a.clusterInfoMetric
 }
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"fmt"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+const benchmarkSeriesCount = 1000
+
+func newBenchmarkAggregator() *AdoptionMetricsAggregator {
+	return NewMetricsAggregator("cluster-id")
+}
+
+// newBenchmarkGauges returns a gauge with a series per CA certificate subject, like a proxy with many CAs
+func newBenchmarkGauges(a *AdoptionMetricsAggregator) *Gauges {
+	return a.NewGauges("test_ca_expiry_timestamp", "Indicates the expiry of a test CA", "subject")
+}
+
+func benchmarkSubjects() []string {
+	subjects := make([]string, benchmarkSeriesCount)
+	for i := range subjects {
+		subjects[i] = fmt.Sprintf("O=Company %d,L=Default City,C=XX", i)
+	}
+	return subjects
+}
+
+func BenchmarkGaugesWith(b *testing.B) {
+	g := newBenchmarkGauges(newBenchmarkAggregator())
+	subjects := benchmarkSubjects()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.With("cluster-id", subjects[i%benchmarkSeriesCount]).Set(float64(i))
+	}
+}
+
+func BenchmarkSetObjectCount(b *testing.B) {
+	a := newBenchmarkAggregator()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		a.SetObjectCount("cluster-id", "Pod", "app=test", "", i)
+	}
+}
+
+func BenchmarkSetDetectionMatchCount(b *testing.B) {
+	a := newBenchmarkAggregator()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		a.SetDetectionMatchCount("cluster-id", "test", i%2)
+	}
+}
+
+func BenchmarkAggregateIdentityProviders(b *testing.B) {
+	a := newBenchmarkAggregator()
+	for i := 0; i < benchmarkSeriesCount; i++ {
+		a.SetOAuthIDP(fmt.Sprintf("oauth-%d", i), "test", []configv1.IdentityProvider{
+			{IdentityProviderConfig: configv1.IdentityProviderConfig{Type: configv1.IdentityProviderTypeGitHub}},
+			{IdentityProviderConfig: configv1.IdentityProviderConfig{Type: configv1.IdentityProviderTypeLDAP}},
+		})
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		a.aggregateIdentityProviders()
+	}
+}
+
+// TestSteadyStateUpdatesDoNotAllocate guards the hot path: updating an existing series must not allocate.
+func TestSteadyStateUpdatesDoNotAllocate(t *testing.T) {
+	a := newBenchmarkAggregator()
+	a.SetOAuthIDP("oauth", "test", []configv1.IdentityProvider{
+		{IdentityProviderConfig: configv1.IdentityProviderConfig{Type: configv1.IdentityProviderTypeGitHub}},
+	})
+	g := newBenchmarkGauges(a)
+	g.With("cluster-id", "O=Default Company Ltd").Set(1)
+
+	for name, f := range map[string]func(){
+		"SetDetectionMatchCount":     func() { a.SetDetectionMatchCount("cluster-id", "test", 1) },
+		"Gauges.With":                func() { g.With("cluster-id", "O=Default Company Ltd").Set(2) },
+		"aggregateIdentityProviders": a.aggregateIdentityProviders,
+	} {
+		if allocs := testing.AllocsPerRun(100, f); allocs > 0 {
+			t.Errorf("%s: expected no allocations, got %v", name, allocs)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+)
+
+// AlertRule is an alert a Collector recommends on its metrics, so alerts ship and are versioned together with
+// the metrics they depend on
+type AlertRule struct {
+	Alert string
+	// Expr is the PromQL expression of the alert
+	Expr string
+	// For is how long Expr must hold before the alert fires
+	For         time.Duration
+	Severity    string
+	Summary     string
+	Description string
+}
+
+// AlertGroup are the alert rules of a Collector
+type AlertGroup struct {
+	// Name is the name of the Collector
+	Name  string
+	Rules []AlertRule
+}
+
+// alertingCollector is a Collector recommending alert rules, like a MetricSet created with alert rules
+type alertingCollector interface {
+	Collector
+	AlertRules() []AlertRule
+}
+
+// WithAlertRules returns the MetricSet recommending the alert rules
+func (s MetricSet) WithAlertRules(rules ...AlertRule) MetricSet {
+	s.alerts = append(append([]AlertRule(nil), s.alerts...), rules...)
+	return s
+}
+
+// AlertRules returns the alert rules recommended for the metrics of the MetricSet
+func (s MetricSet) AlertRules() []AlertRule {
+	return s.alerts
+}
+
+// AlertGroups returns the alert rules of the registered collectors which recommend any, in registration order
+func (a *AdoptionMetricsAggregator) AlertGroups() []AlertGroup {
+	a.registryMutex.Lock()
+	defer a.registryMutex.Unlock()
+	var groups []AlertGroup
+	for _, c := range a.registered {
+		if ac, ok := c.(alertingCollector); ok && len(ac.AlertRules()) > 0 {
+			groups = append(groups, AlertGroup{Name: c.Name(), Rules: ac.AlertRules()})
+		}
+	}
+	return groups
+}
+
+// checkAlertRules returns an error if a rule of the collector has no name or expression, or the name of an alert
+// of another collector. It must be called with the registry mutex held.
+func (a *AdoptionMetricsAggregator) checkAlertRules(c Collector) error {
+	ac, ok := c.(alertingCollector)
+	if !ok {
+		return nil
+	}
+	alerts := make(map[string]bool)
+	for _, registered := range a.registered {
+		if rac, ok := registered.(alertingCollector); ok {
+			for _, r := range rac.AlertRules() {
+				alerts[r.Alert] = true
+			}
+		}
+	}
+	for _, r := range ac.AlertRules() {
+		if r.Alert == "" || r.Expr == "" {
+			return fmt.Errorf("collector %q: alert rules must have an alert name and an expression", c.Name())
+		}
+		if alerts[r.Alert] {
+			return fmt.Errorf("collector %q: alert %q is already registered", c.Name(), r.Alert)
+		}
+		alerts[r.Alert] = true
+	}
+	return nil
+}
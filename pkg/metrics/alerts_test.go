@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdoptionMetricsAggregator_AlertGroups(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	rule := AlertRule{Alert: "TestMismatch", Expr: "test_mismatch == 1", For: time.Hour, Severity: "warning"}
+	set := NewMetricSet("Test", a.NewGauges("test_mismatch", "Indicates a test mismatch"))
+	require.NoError(t, a.Register(set.WithAlertRules(rule)))
+	require.NoError(t, a.Register(NewMetricSet("NoAlerts", a.NewGauges("test_other", "Indicates a test metric"))))
+	require.Empty(t, set.AlertRules(), "the rules are added to a copy")
+
+	// alerts need a name and an expression, and their names are unique
+	require.Error(t, a.Register(NewMetricSet("Duplicate", a.NewGauges("test_duplicate", "Indicates a test metric")).WithAlertRules(rule)))
+	require.Error(t, a.Register(NewMetricSet("NoExpr", a.NewGauges("test_no_expr", "Indicates a test metric")).WithAlertRules(AlertRule{Alert: "TestNoExpr"})))
+
+	require.Equal(t, []AlertGroup{{Name: "Test", Rules: []AlertRule{rule}}}, a.AlertGroups())
+}
@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CatalogPath is the path the catalog of metrics is served on
+const CatalogPath = "/catalog"
+
+// descRegexp extracts the name, HELP text and variable labels from prometheus.Desc.String
+var descRegexp = regexp.MustCompile(`^Desc\{fqName: ("(?:[^"\\]|\\.)*"), help: ("(?:[^"\\]|\\.)*"), constLabels: \{.*\}, variableLabels: \[(.*)\]\}$`)
+
+// The types of the metrics in the catalog
+const (
+	GaugeType     = "gauge"
+	CounterType   = "counter"
+	HistogramType = "histogram"
+)
+
+// CatalogEntry describes a metric of the exporter
+type CatalogEntry struct {
+	Name   string   `json:"name"`
+	Help   string   `json:"help"`
+	Type   string   `json:"type,omitempty"`
+	Labels []string `json:"labels"`
+	Team   string   `json:"team,omitempty"`
+	SLO    string   `json:"slo,omitempty"`
+	// Controller is the name of the collector registering the metric, empty for the builtin metrics of the
+	// aggregator and the reconcile metrics
+	Controller string `json:"controller,omitempty"`
+}
+
+// ownedCollector is a collector with the name of the Collector registering it, if any
+type ownedCollector struct {
+	collector  prometheus.Collector
+	controller string
+}
+
+// ownedCollectors returns the collectors of the aggregator like collectors, with the Collector registering them
+func (a *AdoptionMetricsAggregator) ownedCollectors() []ownedCollector {
+	a.registryMutex.Lock()
+	defer a.registryMutex.Unlock()
+	var owned []ownedCollector
+	for _, c := range append(a.builtinCollectors(), a.reconcileMetrics...) {
+		owned = append(owned, ownedCollector{collector: c})
+	}
+	for _, c := range a.registered {
+		for _, m := range c.Metrics() {
+			owned = append(owned, ownedCollector{collector: m.collector(), controller: c.Name()})
+		}
+	}
+	return owned
+}
+
+// typedCollector is a collector of a single metric which is not a vec, e.g. a reconcile metric
+type typedCollector interface {
+	metricType() string
+}
+
+// collectorType returns the type of the metrics of a collector, or the empty string if it is not known
+func collectorType(c prometheus.Collector) string {
+	switch v := c.(type) {
+	case *prometheus.GaugeVec:
+		return GaugeType
+	case *prometheus.CounterVec:
+		return CounterType
+	case *prometheus.HistogramVec:
+		return HistogramType
+	case typedCollector:
+		return v.metricType()
+	}
+	return ""
+}
+
+// Catalog returns the metrics of the aggregator sorted by name, with the ownership registered for them and the
+// Collector registering them
+func (a *AdoptionMetricsAggregator) Catalog() []CatalogEntry {
+	var entries []CatalogEntry
+	for _, o := range a.ownedCollectors() {
+		c := o.collector
+		descs := make(chan *prometheus.Desc, 16)
+		go func() {
+			c.Describe(descs)
+			close(descs)
+		}()
+		for desc := range descs {
+			entry, ok := parseDesc(desc)
+			if !ok {
+				continue
+			}
+			entry.Type = collectorType(c)
+			entry.Controller = o.controller
+			if o, ok := a.getOwnership(entry.Name); ok {
+				entry.Team, entry.SLO = o.Team, o.SLO
+			}
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+func parseDesc(desc *prometheus.Desc) (CatalogEntry, bool) {
+	match := descRegexp.FindStringSubmatch(desc.String())
+	if match == nil {
+		return CatalogEntry{}, false
+	}
+	name, err := strconv.Unquote(match[1])
+	if err != nil {
+		return CatalogEntry{}, false
+	}
+	help, err := strconv.Unquote(match[2])
+	if err != nil {
+		return CatalogEntry{}, false
+	}
+	return CatalogEntry{Name: name, Help: help, Labels: strings.Fields(match[3])}, true
+}
+
+// NewCatalogHandler serves the catalog of the aggregator as JSON
+func (a *AdoptionMetricsAggregator) NewCatalogHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(a.Catalog()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdoptionMetricsAggregator_NewCatalogHandler(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	a.MustRegister(NewMetricSet("Test", newBenchmarkGauges(a)))
+	require.NoError(t, a.SetOwnership(map[string]Ownership{
+		"test_ca_expiry_timestamp": {Team: "sre-platform", SLO: "ticket within 1d"},
+	}))
+
+	recorder := httptest.NewRecorder()
+	a.NewCatalogHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, CatalogPath, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var entries []CatalogEntry
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &entries))
+	byName := make(map[string]CatalogEntry, len(entries))
+	for i, e := range entries {
+		if i > 0 {
+			require.Less(t, entries[i-1].Name, e.Name)
+		}
+		byName[e.Name] = e
+	}
+	require.Equal(t, CatalogEntry{
+		Name:       "test_ca_expiry_timestamp",
+		Help:       "Indicates the expiry of a test CA",
+		Type:       GaugeType,
+		Labels:     []string{"_id", "subject"},
+		Controller: "Test",
+		Team:       "sre-platform",
+		SLO:        "ticket within 1d",
+	}, byName["test_ca_expiry_timestamp"])
+	require.Equal(t, CatalogEntry{
+		Name:   "cluster_id",
+		Help:   "Indicates the cluster id",
+		Type:   GaugeType,
+		Labels: []string{"_id"},
+	}, byName["cluster_id"])
+}
+
+func TestAdoptionMetricsAggregator_Catalog_controller(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	a.MustRegister(NewMetricSet("Test", a.NewGauges("test_enabled", "Indicates if the test is enabled", "name")))
+
+	var controllers []string
+	for _, e := range a.Catalog() {
+		switch e.Name {
+		case "test_enabled", "cluster_id":
+			controllers = append(controllers, e.Name+": "+e.Controller)
+		}
+	}
+	require.Equal(t, []string{"cluster_id: ", "test_enabled: Test"}, controllers,
+		"the builtin metrics have no controller")
+}
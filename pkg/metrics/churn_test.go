@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// churnSeriesCounts are the numbers of series updated per aggregation tick, like a reconcile of every object of
+// a large cluster
+var churnSeriesCounts = []int{1000, 5000}
+
+// churnPercent is the share of the series replaced by new series on every tick, e.g. pods or instance types
+// coming and going
+const churnPercent = 10
+
+// churnTicks returns the label values of the series of a number of ticks: on every tick churnPercent of the
+// series of the previous tick are replaced
+func churnTicks(series, ticks int) [][]string {
+	replaced := series * churnPercent / 100
+	result := make([][]string, ticks)
+	for tick := range result {
+		values := make([]string, series)
+		for i := range values {
+			// the first series are replaced on every tick, the others are stable
+			if i < replaced {
+				values[i] = fmt.Sprintf("series-%d-%d", tick, i)
+			} else {
+				values[i] = fmt.Sprintf("series-%d", i)
+			}
+		}
+		result[tick] = values
+	}
+	return result
+}
+
+// BenchmarkSetSnapshotChurn reports a snapshot per tick, which deletes the replaced series
+func BenchmarkSetSnapshotChurn(b *testing.B) {
+	for _, series := range churnSeriesCounts {
+		b.Run(fmt.Sprintf("series=%d", series), func(b *testing.B) {
+			a := newBenchmarkAggregator()
+			gauges := a.NewGauges("test_churn", "Indicates a test gauge with churning series", "series")
+			ticks := churnTicks(series, 8)
+			snapshots := make([][]Sample, len(ticks))
+			for tick, values := range ticks {
+				for _, v := range values {
+					snapshots[tick] = append(snapshots[tick], Sample{LabelValues: []string{v}, Value: 1})
+				}
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				gauges.SetSnapshot("cluster-id", snapshots[i%len(snapshots)])
+			}
+			b.ReportMetric(float64(series), "series/op")
+		})
+	}
+}
+
+// BenchmarkCreateDeleteChurn creates series one by one and deletes them all per tick, like the CA certificates
+// of a proxy which are replaced
+func BenchmarkCreateDeleteChurn(b *testing.B) {
+	for _, series := range churnSeriesCounts {
+		b.Run(fmt.Sprintf("series=%d", series), func(b *testing.B) {
+			g := newBenchmarkGauges(newBenchmarkAggregator())
+			subjects := churnTicks(series, 1)[0]
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for j, subject := range subjects {
+					g.With("cluster-id", subject).Set(float64(j))
+				}
+				g.DeleteSeries("cluster-id")
+			}
+			b.ReportMetric(float64(series), "series/op")
+		})
+	}
+}
+
+// BenchmarkParallelUpdates updates series from concurrent reconciles, which contend for the locks of the vecs
+func BenchmarkParallelUpdates(b *testing.B) {
+	g := newBenchmarkGauges(newBenchmarkAggregator())
+	subjects := benchmarkSubjects()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			g.With("cluster-id", subjects[i%benchmarkSeriesCount]).Set(float64(i))
+			i++
+		}
+	})
+}
+
+// BenchmarkUpdatesWhileGathering updates churning series while the metrics are gathered continuously, as when
+// Prometheus scrapes during a resync of all objects
+func BenchmarkUpdatesWhileGathering(b *testing.B) {
+	for _, series := range churnSeriesCounts {
+		b.Run(fmt.Sprintf("series=%d", series), func(b *testing.B) {
+			a := newBenchmarkAggregator()
+			gauges := a.NewGauges("test_churn", "Indicates a test gauge with churning series", "series")
+			a.MustRegister(NewMetricSet("Churn", gauges))
+			registry := prometheus.NewRegistry()
+			registry.MustRegister(a.GetMetrics()...)
+			ticks := churnTicks(series, 8)
+
+			stop := make(chan struct{})
+			var wg sync.WaitGroup
+			wg.Add(1)
+			gathers := 0
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+					if _, err := registry.Gather(); err != nil {
+						b.Error(err)
+						return
+					}
+					gathers++
+				}
+			}()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				tick := ticks[i%len(ticks)]
+				for _, v := range tick {
+					gauges.With("cluster-id", v).Set(1)
+				}
+				gauges.DeleteSeries("cluster-id")
+			}
+			b.StopTimer()
+			close(stop)
+			wg.Wait()
+			b.ReportMetric(float64(gathers)/float64(b.N), "gathers/op")
+		})
+	}
+}
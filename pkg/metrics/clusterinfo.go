@@ -0,0 +1,51 @@
+package metrics
+
+// ClusterInfoFact is a label of osd_cluster_info, each set by the controller which knows it
+type ClusterInfoFact string
+
+const (
+	ClusterInfoVersion     ClusterInfoFact = "version"
+	ClusterInfoPlatform    ClusterInfoFact = "platform"
+	ClusterInfoNetworkType ClusterInfoFact = "network_type"
+	ClusterInfoFIPS        ClusterInfoFact = "fips"
+	ClusterInfoSTS         ClusterInfoFact = "sts"
+)
+
+// clusterInfoFacts are the labels of osd_cluster_info after _id, in declaration order
+var clusterInfoFacts = []ClusterInfoFact{ClusterInfoVersion, ClusterInfoPlatform, ClusterInfoNetworkType, ClusterInfoFIPS, ClusterInfoSTS}
+
+// SetClusterInfo sets a fact of the cluster and replaces the osd_cluster_info series of the cluster with one with
+// all facts known so far, facts which were not set yet are empty. Dashboards join on the info series instead of a
+// gauge per fact.
+func (a *AdoptionMetricsAggregator) SetClusterInfo(uuid string, fact ClusterInfoFact, value string) {
+	// the relabel lock is taken before the mutex, like RelabelClusterID does
+	a.relabelMutex.RLock()
+	defer a.relabelMutex.RUnlock()
+	uuid = a.resolveClusterID(uuid)
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	facts, ok := a.clusterInfo[uuid]
+	if !ok {
+		facts = make(map[ClusterInfoFact]string, len(clusterInfoFacts))
+		a.clusterInfo[uuid] = facts
+	}
+	facts[fact] = value
+	a.removeSeries(a.clusterInfoMetric.MetricVec, uuid)
+	lvs := make([]string, 0, len(clusterInfoFacts)+1)
+	lvs = append(lvs, uuid)
+	for _, f := range clusterInfoFacts {
+		lvs = append(lvs, facts[f])
+	}
+	// the relabel lock is held, so the series is looked up and set directly
+	Gauge{a.lookup(a.clusterInfoMetric.MetricVec, lvs...)}.set(1)
+}
+
+// relabelClusterInfo moves the facts of oldID to newID, after the series were moved
+func (a *AdoptionMetricsAggregator) relabelClusterInfo(oldID, newID string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if facts, ok := a.clusterInfo[oldID]; ok {
+		a.clusterInfo[newID] = facts
+		delete(a.clusterInfo, oldID)
+	}
+}
@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdoptionMetricsAggregator_SetClusterInfo(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	a.SetClusterInfo("cluster-id", ClusterInfoVersion, "4.13.1")
+	a.SetClusterInfo("cluster-id", ClusterInfoPlatform, "AWS")
+	a.SetClusterInfo("hosted-cluster-id", ClusterInfoPlatform, "AWS")
+	a.SetClusterInfo("cluster-id", ClusterInfoVersion, "4.13.2")
+	require.NoError(t, testutil.CollectAndCompare(a.GetClusterInfoMetric(), strings.NewReader(`
+# HELP osd_cluster_info Indicates the version, platform, network type and if FIPS and STS are enabled, with value 1
+# TYPE osd_cluster_info gauge
+osd_cluster_info{_id="cluster-id",fips="",name="osd_exporter",network_type="",platform="AWS",sts="",version="4.13.2"} 1
+osd_cluster_info{_id="hosted-cluster-id",fips="",name="osd_exporter",network_type="",platform="AWS",sts="",version=""} 1
+`)))
+
+	// the facts move with the series, so facts set with the old id afterwards keep the other facts
+	a.RelabelClusterID("cluster-id", "new-id")
+	a.SetClusterInfo("cluster-id", ClusterInfoSTS, "true")
+	require.Equal(t, 2, testutil.CollectAndCount(a.GetClusterInfoMetric()))
+	require.Equal(t, float64(1), testutil.ToFloat64(a.GetClusterInfoMetric().WithLabelValues("new-id", "4.13.2", "AWS", "", "", "true")))
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// deleteSeries deletes all series of vec with the _id uuid, for setters of resources which were deleted
+func (a *AdoptionMetricsAggregator) deleteSeries(vec *prometheus.MetricVec, uuid string) {
+	a.relabelMutex.RLock()
+	defer a.relabelMutex.RUnlock()
+	a.removeSeries(vec, uuid)
+}
+
+// removeSeries deletes all series of vec with the _id uuid, with the relabel lock held
+func (a *AdoptionMetricsAggregator) removeSeries(vec *prometheus.MetricVec, uuid string) {
+	uuid = a.resolveClusterID(uuid)
+	if e, ok := a.expiries[vec]; ok {
+		e.forgetCluster(uuid)
+	}
+	for _, pb := range clusterSeries(vec, uuid) {
+		labels := seriesLabels(pb)
+		vec.Delete(labels)
+		a.releaseSeries(vec, labels)
+	}
+}
+
+// DeleteSeries deletes all series of the metric with the _id uuid
+func (g *Gauges) DeleteSeries(uuid string) {
+	g.aggregator.deleteSeries(g.vec.MetricVec, uuid)
+}
+
+// DeleteSeries deletes all series of the metric with the _id uuid
+func (c *Counters) DeleteSeries(uuid string) {
+	c.aggregator.deleteSeries(c.vec.MetricVec, uuid)
+}
+
+// DeleteSeries deletes all series of the metric with the _id uuid
+func (h *Histograms) DeleteSeries(uuid string) {
+	h.aggregator.deleteSeries(h.vec.MetricVec, uuid)
+}
+
+// DeleteSeries deletes all series of the metrics with the _id uuid, after the resource they report on was deleted
+func (s MetricSet) DeleteSeries(uuid string) {
+	for _, m := range s.metrics {
+		m.owner().deleteSeries(m.metricVec(), uuid)
+	}
+}
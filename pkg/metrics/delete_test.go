@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGauges_DeleteSeries(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	g := newBenchmarkGauges(a)
+	require.NoError(t, a.Register(NewMetricSet("Test", g)))
+	g.With("cluster-id", "O=First").Set(1)
+	g.With("cluster-id", "O=Second").Set(2)
+	g.With("hosted-cluster-id", "O=Hosted").Set(3)
+	a.RelabelClusterID("cluster-id", "new-id")
+
+	// the old id deletes the series of the new id, the series of other clusters are kept
+	g.DeleteSeries("cluster-id")
+	require.NoError(t, testutil.CollectAndCompare(g, strings.NewReader(`
+# HELP test_ca_expiry_timestamp Indicates the expiry of a test CA
+# TYPE test_ca_expiry_timestamp gauge
+test_ca_expiry_timestamp{_id="hosted-cluster-id",name="osd_exporter",subject="O=Hosted"} 3
+`)))
+}
+
+func TestMetricSet_DeleteSeries(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	set, g := newTestGaugeSet(a, "Test")
+	require.NoError(t, a.Register(set))
+	g.With("cluster-id", "a").Set(1)
+	g.With("cluster-id", "b").Set(1)
+	g.With("other-id", "a").Set(1)
+
+	set.DeleteSeries("cluster-id")
+	require.Equal(t, 1, testutil.CollectAndCount(g))
+	require.Equal(t, float64(1), testutil.ToFloat64(g.With("other-id", "a")))
+}
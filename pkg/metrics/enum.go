@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Enums is a gauge metric of a state out of a fixed set of states, e.g. the state of the ControlPlaneMachineSet.
+// Every state has its own series, the series of the current state is 1 and the others are 0, so dashboards and
+// alerts select a state by name instead of by an encoded value.
+type Enums struct {
+	name   string
+	gauges *Gauges
+	states []string
+}
+
+// NewEnums creates an enum metric with the _id label, the given labels and the state label last. It is exported
+// once the Collector it belongs to is registered.
+func (a *AdoptionMetricsAggregator) NewEnums(name, help, stateLabel string, states []string, labels ...string) *Enums {
+	return &Enums{
+		name:   name,
+		gauges: a.NewGauges(name, help, append(append([]string(nil), labels...), stateLabel)...),
+		states: states,
+	}
+}
+
+// Set sets the series of state to 1 and the series of the other states to 0, for the label values starting with
+// the cluster id. States which are not one of the states of the metric are rejected, so no two series are 1.
+func (e *Enums) Set(state string, lvs ...string) error {
+	known := false
+	for _, s := range e.states {
+		known = known || s == state
+	}
+	if !known {
+		return fmt.Errorf("metric %s: unknown state %q", e.name, state)
+	}
+	values := make([]string, len(lvs)+1)
+	copy(values, lvs)
+	for _, s := range e.states {
+		values[len(lvs)] = s
+		e.gauges.With(values...).Set(BoolToFloat(s == state))
+	}
+	return nil
+}
+
+// DeleteSeries deletes the series of all states with the _id uuid
+func (e *Enums) DeleteSeries(uuid string) {
+	e.gauges.DeleteSeries(uuid)
+}
+
+func (e *Enums) Describe(ch chan<- *prometheus.Desc) {
+	e.gauges.Describe(ch)
+}
+
+func (e *Enums) Collect(ch chan<- prometheus.Metric) {
+	e.gauges.Collect(ch)
+}
+
+func (e *Enums) owner() *AdoptionMetricsAggregator { return e.gauges.owner() }
+func (e *Enums) metricVec() *prometheus.MetricVec  { return e.gauges.metricVec() }
+func (e *Enums) collector() prometheus.Collector   { return e.gauges.collector() }
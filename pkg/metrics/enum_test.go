@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnums_Set(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	enums := a.NewEnums("test_state", "Indicates a test state", "state", []string{"Pending", "Running", "Done"}, "object")
+	require.NoError(t, a.Register(NewMetricSet("Test", enums)))
+
+	require.NoError(t, enums.Set("Running", "cluster-id", "a"))
+	require.NoError(t, enums.Set("Done", "cluster-id", "b"))
+	require.Equal(t, 6, testutil.CollectAndCount(enums))
+	require.Equal(t, float64(1), testutil.ToFloat64(enums.gauges.With("cluster-id", "a", "Running")))
+
+	// moving to another state clears the previous one
+	require.NoError(t, enums.Set("Done", "cluster-id", "a"))
+	require.Equal(t, float64(0), testutil.ToFloat64(enums.gauges.With("cluster-id", "a", "Running")))
+	require.Equal(t, float64(1), testutil.ToFloat64(enums.gauges.With("cluster-id", "a", "Done")))
+
+	// unknown states do not change the series
+	require.Error(t, enums.Set("Unknown", "cluster-id", "a"))
+	require.Equal(t, float64(1), testutil.ToFloat64(enums.gauges.With("cluster-id", "a", "Done")))
+	require.Equal(t, 6, testutil.CollectAndCount(enums))
+
+	enums.DeleteSeries("cluster-id")
+	require.Equal(t, 0, testutil.CollectAndCount(enums))
+}
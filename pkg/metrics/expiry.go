@@ -0,0 +1,200 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// now is the clock of the series expiry, replaced in tests
+var now = time.Now
+
+// seriesExpiry removes the series of a metric which were not updated within the ttl, so series of deleted
+// resources do not remain forever. Without a ttl it only records the last updates, for the snapshot.
+type seriesExpiry struct {
+	// vec is only set with a ttl
+	vec *prometheus.GaugeVec
+	ttl time.Duration
+
+	mutex sync.Mutex
+	// updated holds the label values and last update of every series, by hash of the label values
+	updated map[uint64]*seriesUpdate
+}
+
+// seriesUpdate is the last update of a series. The series looked up by the aggregator record their writes in it,
+// with the mutex of the expiry held.
+type seriesUpdate struct {
+	// labelValues are not modified once the update is recorded
+	labelValues []string
+	at          time.Time
+	// deleted is set once the series was deleted, so writes through a series looked up before create it again
+	deleted bool
+}
+
+// ParseSeriesTTLs parses comma separated metric=duration pairs, e.g. cpms_instance_type_mismatch=30m
+func ParseSeriesTTLs(s string) (map[string]time.Duration, error) {
+	ttls := make(map[string]time.Duration)
+	if s == "" {
+		return ttls, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid series ttl %q, expected metric=duration", pair)
+		}
+		ttl, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid series ttl %q: %w", pair, err)
+		}
+		if ttl <= 0 {
+			return nil, fmt.Errorf("invalid series ttl %q: must be positive", pair)
+		}
+		ttls[name] = ttl
+	}
+	return ttls, nil
+}
+
+// ExpireSeries removes the series of the named metrics when they were not updated within their ttl. Expired
+// series are removed when the metrics are collected. It must be called before the aggregator is used.
+func (a *AdoptionMetricsAggregator) ExpireSeries(ttls map[string]time.Duration) error {
+	vecs := a.gaugeVecsByName()
+	for name, ttl := range ttls {
+		vec, ok := vecs[name]
+		if !ok {
+			return fmt.Errorf("unknown metric %q", name)
+		}
+		if a.expiries == nil {
+			a.expiries = make(map[*prometheus.MetricVec]*seriesExpiry)
+		}
+		a.expiries[vec.MetricVec] = &seriesExpiry{vec: vec, ttl: ttl, updated: make(map[uint64]*seriesUpdate)}
+	}
+	return nil
+}
+
+// entry returns the update of the series with the label values when it is looked up. A new series counts as
+// updated when it is created. Looking up a known series does not allocate.
+func (e *seriesExpiry) entry(lvs []string) *seriesUpdate {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.record(lvs, time.Time{})
+}
+
+// record returns the update of the series with the label values, which is created if the series is unknown, and sets
+// it to at unless at is zero. It must be called with the mutex held.
+func (e *seriesExpiry) record(lvs []string, at time.Time) *seriesUpdate {
+	key := seriesKey(lvs)
+	if u, ok := e.updated[key]; ok && equalValues(u.labelValues, lvs) {
+		if !at.IsZero() {
+			u.at = at
+		}
+		return u
+	}
+	if at.IsZero() {
+		at = now()
+	}
+	u := &seriesUpdate{labelValues: append([]string(nil), lvs...), at: at}
+	e.updated[key] = u
+	return u
+}
+
+// forget drops the update of a deleted series, it must be called with the mutex held
+func (e *seriesExpiry) forget(key uint64, u *seriesUpdate) {
+	u.deleted = true
+	delete(e.updated, key)
+}
+
+// forgetSeries drops the series with the label values after it was deleted
+func (e *seriesExpiry) forgetSeries(lvs []string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	key := seriesKey(lvs)
+	if u, ok := e.updated[key]; ok && equalValues(u.labelValues, lvs) {
+		e.forget(key, u)
+	}
+}
+
+// forgetCluster drops the series of the cluster after they were deleted
+func (e *seriesExpiry) forgetCluster(uuid string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	for key, u := range e.updated {
+		if u.labelValues[0] == uuid {
+			e.forget(key, u)
+		}
+	}
+}
+
+// expire deletes the series which were not updated within the ttl
+func (e *seriesExpiry) expire() {
+	if e.ttl == 0 {
+		return
+	}
+	deadline := now().Add(-e.ttl)
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	for key, u := range e.updated {
+		if u.at.Before(deadline) {
+			e.vec.DeleteLabelValues(u.labelValues...)
+			e.forget(key, u)
+		}
+	}
+}
+
+// lastUpdate returns the last update of the series with the label values
+func (e *seriesExpiry) lastUpdate(lvs []string) (time.Time, bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	u, ok := e.updated[seriesKey(lvs)]
+	if !ok || !equalValues(u.labelValues, lvs) {
+		return time.Time{}, false
+	}
+	return u.at, true
+}
+
+// relabel moves the series of oldID to newID, keeping their last update
+func (e *seriesExpiry) relabel(oldID, newID string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	var moved []*seriesUpdate
+	for key, u := range e.updated {
+		if u.labelValues[0] == oldID {
+			moved = append(moved, u)
+			e.forget(key, u)
+		}
+	}
+	lvs := make([]string, 0)
+	for _, u := range moved {
+		lvs = append(append(lvs[:0], newID), u.labelValues[1:]...)
+		e.record(lvs, u.at)
+	}
+}
+
+// seriesKey hashes the label values with FNV-1a, separating them by a byte which is not valid UTF-8
+func seriesKey(lvs []string) uint64 {
+	const offset64, prime64 = 14695981039346656037, 1099511628211
+	h := uint64(offset64)
+	for _, v := range lvs {
+		for i := 0; i < len(v); i++ {
+			h ^= uint64(v[i])
+			h *= prime64
+		}
+		h ^= 0xff
+		h *= prime64
+	}
+	return h
+}
+
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
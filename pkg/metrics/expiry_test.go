@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeriesTTLs(t *testing.T) {
+	ttls, err := ParseSeriesTTLs("cluster_proxy_ca_expiry_timestamp=30m,egressip_count=1h")
+	require.NoError(t, err)
+	require.Equal(t, map[string]time.Duration{"cluster_proxy_ca_expiry_timestamp": 30 * time.Minute, "egressip_count": time.Hour}, ttls)
+
+	ttls, err = ParseSeriesTTLs("")
+	require.NoError(t, err)
+	require.Empty(t, ttls)
+
+	for _, invalid := range []string{"egressip_count", "egressip_count=soon", "egressip_count=0s"} {
+		_, err := ParseSeriesTTLs(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestAdoptionMetricsAggregator_ExpireSeries(t *testing.T) {
+	current := time.Date(2023, 9, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	a := NewMetricsAggregator("cluster-id")
+	g := newBenchmarkGauges(a)
+	require.NoError(t, a.Register(NewMetricSet("Test", g)))
+	require.NoError(t, a.ExpireSeries(map[string]time.Duration{"test_ca_expiry_timestamp": 30 * time.Minute}))
+	require.Error(t, a.ExpireSeries(map[string]time.Duration{"does_not_exist": time.Minute}))
+	collected := func() int {
+		for _, c := range a.GetMetrics() {
+			if count := testutil.CollectAndCount(c, "test_ca_expiry_timestamp"); count > 0 {
+				return count
+			}
+		}
+		return 0
+	}
+
+	g.With("cluster-id", "O=Old").Set(1)
+	g.With("cluster-id", "O=Current").Set(1)
+	current = current.Add(20 * time.Minute)
+	g.With("cluster-id", "O=Current").Set(2)
+	require.Equal(t, 2, collected())
+
+	// the series which was not updated expires
+	current = current.Add(20 * time.Minute)
+	require.Equal(t, 1, collected())
+	require.Equal(t, float64(2), testutil.ToFloat64(g.vec.WithLabelValues("cluster-id", "O=Current")))
+
+	// relabelled series keep their last update
+	a.RelabelClusterID("cluster-id", "new-id")
+	current = current.Add(5 * time.Minute)
+	require.Equal(t, 1, collected())
+	current = current.Add(10 * time.Minute)
+	require.Equal(t, 0, collected())
+
+	// series deleted by a snapshot are forgotten
+	g.SetSnapshot("new-id", []Sample{{LabelValues: []string{"O=Current"}, Value: 3}, {LabelValues: []string{"O=New"}, Value: 3}})
+	g.SetSnapshot("new-id", []Sample{{LabelValues: []string{"O=New"}, Value: 3}})
+	_, ok := a.expiries[g.vec.MetricVec].lastUpdate([]string{"new-id", "O=Current"})
+	require.False(t, ok)
+
+	// metrics without a ttl are not expired
+	a.SetClusterID("new-id")
+	current = current.Add(24 * time.Hour)
+	require.Equal(t, float64(1), testutil.ToFloat64(a.GetClusterIDMetric()))
+}
+
+func TestAdoptionMetricsAggregator_ExpireSeriesBeforeSet(t *testing.T) {
+	current := time.Date(2023, 9, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	a := NewMetricsAggregator("cluster-id")
+	g := newBenchmarkGauges(a)
+	require.NoError(t, a.Register(NewMetricSet("Test", g)))
+	require.NoError(t, a.ExpireSeries(map[string]time.Duration{"test_ca_expiry_timestamp": 30 * time.Minute}))
+	collected := func() int {
+		for _, c := range a.GetMetrics() {
+			if count := testutil.CollectAndCount(c, "test_ca_expiry_timestamp"); count > 0 {
+				return count
+			}
+		}
+		return 0
+	}
+
+	// the series expires between its lookup and its set
+	held := g.With("cluster-id", "O=Held")
+	current = current.Add(40 * time.Minute)
+	require.Equal(t, 0, collected())
+	held.Set(5)
+	require.Equal(t, 1, collected())
+	require.Equal(t, float64(5), testutil.ToFloat64(g.vec.WithLabelValues("cluster-id", "O=Held")))
+	updated, ok := a.expiries[g.vec.MetricVec].lastUpdate([]string{"cluster-id", "O=Held"})
+	require.True(t, ok)
+	require.Equal(t, current, updated)
+
+	// the set records the update, the lookup does not
+	current = current.Add(20 * time.Minute)
+	held = g.With("cluster-id", "O=Held")
+	current = current.Add(20 * time.Minute)
+	require.Equal(t, 0, collected())
+	held.Set(6)
+	current = current.Add(20 * time.Minute)
+	require.Equal(t, 1, collected())
+}
+
+func TestSeriesExpiry_UpdateDoesNotAllocate(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	g := newBenchmarkGauges(a)
+	require.NoError(t, a.Register(NewMetricSet("Test", g)))
+	require.NoError(t, a.ExpireSeries(map[string]time.Duration{"test_ca_expiry_timestamp": time.Minute}))
+	g.With("cluster-id", "O=Default Company Ltd").Set(1)
+	if allocs := testing.AllocsPerRun(100, func() { g.With("cluster-id", "O=Default Company Ltd").Set(2) }); allocs > 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+// ParseExtraLabels parses comma separated name=value pairs, e.g. environment=stage,sector=canary
+func ParseExtraLabels(s string) (prometheus.Labels, error) {
+	labels := prometheus.Labels{}
+	if s == "" {
+		return labels, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid extra label %q, expected name=value", pair)
+		}
+		labels[name] = value
+	}
+	return labels, nil
+}
+
+// CheckExtraLabels returns an error if the labels cannot be added to every metric of the aggregator, because a
+// name is not a valid label name or already is a label of a metric
+func (a *AdoptionMetricsAggregator) CheckExtraLabels(labels map[string]string) error {
+	reserved := map[string]bool{"name": true, schemaVersionLabel: true}
+	for _, entry := range a.Catalog() {
+		for _, l := range entry.Labels {
+			reserved[l] = true
+		}
+	}
+	for name := range labels {
+		if !model.LabelName(name).IsValid() || strings.HasPrefix(name, model.ReservedLabelPrefix) {
+			return fmt.Errorf("invalid extra label name %q", name)
+		}
+		if reserved[name] {
+			return fmt.Errorf("extra label %q is already a label of the metrics", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExtraLabels(t *testing.T) {
+	labels, err := ParseExtraLabels("environment=stage,sector=canary,empty=")
+	require.NoError(t, err)
+	require.Equal(t, prometheus.Labels{"environment": "stage", "sector": "canary", "empty": ""}, labels)
+
+	labels, err = ParseExtraLabels("")
+	require.NoError(t, err)
+	require.Empty(t, labels)
+
+	_, err = ParseExtraLabels("environment")
+	require.Error(t, err)
+}
+
+func TestAdoptionMetricsAggregator_CheckExtraLabels(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	require.NoError(t, a.CheckExtraLabels(map[string]string{"environment": "stage"}))
+	require.NoError(t, a.CheckExtraLabels(nil))
+
+	for _, name := range []string{"_id", "name", "schema_version", "controller", "__reserved", "not-valid", ""} {
+		require.Error(t, a.CheckExtraLabels(map[string]string{name: "value"}), name)
+	}
+}
@@ -0,0 +1,17 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// NewHandler serves the metrics of gatherer in the format negotiated from the Accept header: the classic
+// text format, protobuf or OpenMetrics. Native histograms and exemplars are only exposed in the last two.
+// Requests to the handler are counted in promhttp_metric_handler_requests_total on registerer.
+func NewHandler(registerer prometheus.Registerer, gatherer prometheus.Gatherer) http.Handler {
+	return promhttp.InstrumentMetricHandler(registerer, promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+	}))
+}
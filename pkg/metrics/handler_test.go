@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHandler(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	a.SetClusterID("cluster-id")
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(a.GetClusterIDMetric()))
+	handler := NewHandler(registry, registry)
+
+	tests := []struct {
+		name   string
+		accept string
+		format expfmt.Format
+	}{
+		{
+			name:   "text",
+			format: expfmt.FmtText,
+		},
+		{
+			name:   "protobuf",
+			accept: "application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited",
+			format: expfmt.FmtProtoDelim,
+		},
+		{
+			name:   "openmetrics",
+			accept: "application/openmetrics-text;version=0.0.1",
+			format: expfmt.FmtOpenMetrics,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.Equal(t, string(tt.format), rec.Header().Get("Content-Type"))
+
+			if tt.format == expfmt.FmtOpenMetrics {
+				require.True(t, strings.HasSuffix(rec.Body.String(), "# EOF\n"))
+				require.Contains(t, rec.Body.String(), `cluster_id{_id="cluster-id",name="osd_exporter"} 1`)
+				return
+			}
+			decoder := expfmt.NewDecoder(rec.Body, tt.format)
+			families := make(map[string]*dto.MetricFamily)
+			for {
+				family := &dto.MetricFamily{}
+				if err := decoder.Decode(family); err != nil {
+					break
+				}
+				families[family.GetName()] = family
+			}
+			require.Contains(t, families, "cluster_id")
+			require.Equal(t, float64(1), families["cluster_id"].GetMetric()[0].GetGauge().GetValue())
+			require.Contains(t, families, "promhttp_metric_handler_requests_total")
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+)
+
+const (
+	// maxInternedLabelValues bounds the interner so label values from deleted
+	// resources can not accumulate forever
+	maxInternedLabelValues = 1 << 16
+)
+
+// labelInterner deduplicates label values so that repeated strings decoded from API
+// objects (instance types, namespaces, zones, ...) share a single allocation
+type labelInterner struct {
+	mutex  sync.RWMutex
+	values map[string]string
+}
+
+func newLabelInterner() *labelInterner {
+	return &labelInterner{
+		values: make(map[string]string),
+	}
+}
+
+// intern returns the canonical copy of s
+func (i *labelInterner) intern(s string) string {
+	i.mutex.RLock()
+	v, ok := i.values[s]
+	i.mutex.RUnlock()
+	if ok {
+		return v
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if v, ok := i.values[s]; ok {
+		return v
+	}
+	if len(i.values) >= maxInternedLabelValues {
+		i.values = make(map[string]string)
+	}
+	// clone so the canonical copy does not pin the buffer s was sliced from
+	v = strings.Clone(s)
+	i.values[v] = v
+	return v
+}
+
+// internValues interns all label values in place
+func (i *labelInterner) internValues(values []string) {
+	for j, v := range values {
+		values[j] = i.intern(v)
+	}
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sameString returns if a and b share their bytes. unsafe.StringData replaces reflect.StringHeader from Go 1.20, but
+// the module still builds with go 1.19.
+func sameString(a, b string) bool {
+	return len(a) == len(b) &&
+		(*reflect.StringHeader)(unsafe.Pointer(&a)).Data == (*reflect.StringHeader)(unsafe.Pointer(&b)).Data
+}
+
+func TestLabelInterner_intern(t *testing.T) {
+	i := newLabelInterner()
+	first := i.intern(string([]byte("m5.xlarge")))
+	second := i.intern(string([]byte("m5.xlarge")))
+	require.Equal(t, "m5.xlarge", second)
+	require.True(t, sameString(first, second), "the canonical copy is reused")
+}
+
+func TestLabelInterner_intern_clone(t *testing.T) {
+	i := newLabelInterner()
+	// a label value sliced from a larger decoded payload
+	payload := string([]byte(`{"instanceType":"m5.xlarge"}`))
+	s := payload[17:26]
+	v := i.intern(s)
+	require.Equal(t, "m5.xlarge", v)
+	require.False(t, sameString(s, v), "the canonical copy does not retain the payload")
+	require.True(t, sameString(v, i.intern(s)))
+}
+
+func TestLabelInterner_intern_reset(t *testing.T) {
+	i := newLabelInterner()
+	first := i.intern("value-0")
+	for j := 1; j < maxInternedLabelValues; j++ {
+		i.intern(fmt.Sprintf("value-%d", j))
+	}
+	require.Len(t, i.values, maxInternedLabelValues)
+	require.True(t, sameString(first, i.intern("value-0")))
+
+	i.intern("value-overflow")
+	require.Len(t, i.values, 1, "the interner is reset once it is full")
+	require.False(t, sameString(first, i.intern("value-0")), "the values before the reset are interned again")
+}
+
+func TestLabelInterner_internValues(t *testing.T) {
+	i := newLabelInterner()
+	canonical := i.intern("openshift-monitoring")
+	values := []string{string([]byte("openshift-monitoring")), "zone-a"}
+	i.internValues(values)
+	require.Equal(t, []string{"openshift-monitoring", "zone-a"}, values)
+	require.True(t, sameString(canonical, values[0]))
+}
+
+// BenchmarkLabelInterner_intern interns label values decoded again on every reconcile, which allocate nothing once
+// they are interned
+func BenchmarkLabelInterner_intern(b *testing.B) {
+	i := newLabelInterner()
+	values := make([]string, 100)
+	for j := range values {
+		values[j] = fmt.Sprintf("instance-type-%d", j)
+		i.intern(string([]byte(values[j])))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for j := 0; j < b.N; j++ {
+		i.intern(values[j%len(values)])
+	}
+}
+
+// BenchmarkLabelInterner_intern_new interns label values seen for the first time, which are cloned once
+func BenchmarkLabelInterner_intern_new(b *testing.B) {
+	values := make([]string, maxInternedLabelValues)
+	for j := range values {
+		values[j] = fmt.Sprintf("instance-type-%d", j)
+	}
+	var i *labelInterner
+	b.ReportAllocs()
+	b.ResetTimer()
+	for j := 0; j < b.N; j++ {
+		if j%len(values) == 0 {
+			b.StopTimer()
+			i = newLabelInterner()
+			b.StartTimer()
+		}
+		i.intern(values[j%len(values)])
+	}
+}
@@ -0,0 +1,180 @@
+package metrics
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var limitLog = logf.Log.WithName("metrics_limit")
+
+// seriesLimit caps the number of series of a metric, so a controller adding a series per pod or per object
+// cannot overload Prometheus. Updates of new series beyond the limit are dropped, existing series are still
+// updated.
+type seriesLimit struct {
+	name  string
+	limit int
+	// labels are the variable labels of the metric in declaration order, to hash collected series like the
+	// label values of updates
+	labels []string
+	// discard receives the updates of dropped series, it is not collected
+	discard prometheus.Metric
+
+	mutex sync.Mutex
+	// series holds the label values of the series of the metric by their hash, which several series may share
+	series map[uint64][][]string
+	// count is the number of series
+	count int
+	// dropping is set once a series was dropped, so the limit is logged once until the metric is below it
+	dropping bool
+}
+
+// LimitSeries caps the number of series of every metric of the aggregator. New series of a metric at the limit
+// are dropped and counted by osd_exporter_dropped_series_total with clusterId as _id. A limit of 0 disables the
+// limit. It must be called after the collectors are registered and before the aggregator is used.
+func (a *AdoptionMetricsAggregator) LimitSeries(clusterId string, limit int) error {
+	if limit < 0 {
+		return fmt.Errorf("invalid series limit %d: must not be negative", limit)
+	}
+	if limit == 0 {
+		return nil
+	}
+	for _, c := range a.collectors() {
+		var vec *prometheus.MetricVec
+		var discard prometheus.Metric
+		switch v := c.(type) {
+		case *prometheus.GaugeVec:
+			vec, discard = v.MetricVec, prometheus.NewGauge(prometheus.GaugeOpts{Name: "discarded"})
+		case *prometheus.CounterVec:
+			vec, discard = v.MetricVec, prometheus.NewCounter(prometheus.CounterOpts{Name: "discarded"})
+		case *prometheus.HistogramVec:
+			vec, discard = v.MetricVec, prometheus.NewHistogram(prometheus.HistogramOpts{Name: "discarded"})
+		default:
+			continue
+		}
+		// the series of the dropped series counter are bound by the number of metrics
+		if vec == a.droppedSeries.MetricVec {
+			continue
+		}
+		descs := make(chan *prometheus.Desc, 1)
+		c.Describe(descs)
+		entry, ok := parseDesc(<-descs)
+		if !ok {
+			continue
+		}
+		if a.limits == nil {
+			a.limits = make(map[*prometheus.MetricVec]*seriesLimit)
+		}
+		l := &seriesLimit{name: entry.Name, limit: limit, labels: entry.Labels, discard: discard}
+		l.sync(vec)
+		a.limits[vec] = l
+	}
+	a.limitClusterId = clusterId
+	return nil
+}
+
+// admit records the series with the label values and returns true, or returns false if it is a new series
+// and the metric is at its limit. Admitting a known series does not allocate.
+func (l *seriesLimit) admit(lvs []string) bool {
+	key := seriesKey(lvs)
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	// a series whose label values collide with a known one is a new series
+	known := l.series[key]
+	for _, values := range known {
+		if equalValues(values, lvs) {
+			return true
+		}
+	}
+	if l.count >= l.limit {
+		if !l.dropping {
+			l.dropping = true
+			limitLog.Info("dropping new series of metric at its series limit", "metric", l.name, "limit", l.limit, "labelValues", lvs)
+		}
+		return false
+	}
+	l.series[key] = append(known, append([]string(nil), lvs...))
+	l.count++
+	return true
+}
+
+// release forgets the series with the label values after it was deleted, so it no longer counts towards the limit
+func (l *seriesLimit) release(lvs []string) {
+	key := seriesKey(lvs)
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	known := l.series[key]
+	for i, values := range known {
+		if !equalValues(values, lvs) {
+			continue
+		}
+		if len(known) == 1 {
+			delete(l.series, key)
+		} else {
+			l.series[key] = append(known[:i], known[i+1:]...)
+		}
+		l.count--
+		break
+	}
+	if l.count < l.limit {
+		l.dropping = false
+	}
+}
+
+// releaseSeries releases the slot of a deleted series of vec with the labels, if the series of vec are limited
+func (a *AdoptionMetricsAggregator) releaseSeries(vec *prometheus.MetricVec, labels prometheus.Labels) {
+	l, ok := a.limits[vec]
+	if !ok {
+		return
+	}
+	lvs := make([]string, len(l.labels))
+	for i, name := range l.labels {
+		lvs[i] = labels[name]
+	}
+	l.release(lvs)
+}
+
+// sync replaces the recorded series by the series of vec, so series deleted by resets, expiry, relabeling or
+// deletes of a cluster no longer count towards the limit
+func (l *seriesLimit) sync(vec *prometheus.MetricVec) {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		vec.Collect(ch)
+		close(ch)
+	}()
+	series := make(map[uint64][][]string)
+	count := 0
+	for m := range ch {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			continue
+		}
+		lvs := make([]string, len(l.labels))
+		for i, name := range l.labels {
+			for _, lp := range pb.GetLabel() {
+				if lp.GetName() == name {
+					lvs[i] = lp.GetValue()
+					break
+				}
+			}
+		}
+		key := seriesKey(lvs)
+		series[key] = append(series[key], lvs)
+		count++
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.series, l.count = series, count
+	if count < l.limit {
+		l.dropping = false
+	}
+}
+
+// dropSeries counts an update of a new series which was dropped by the limit of the metric
+func (a *AdoptionMetricsAggregator) dropSeries(l *seriesLimit) {
+	// series are dropped while they are looked up, with the relabel lock held
+	Counter{a.lookup(a.droppedSeries.MetricVec, a.limitClusterId, l.name)}.inc()
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdoptionMetricsAggregator_LimitSeries(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	require.Error(t, a.LimitSeries("cluster-id", -1))
+	require.NoError(t, a.LimitSeries("cluster-id", 2))
+	collectors := a.GetMetrics()
+	collected := func(name string) int {
+		for _, c := range collectors {
+			if count := testutil.CollectAndCount(c, name); count > 0 {
+				return count
+			}
+		}
+		return 0
+	}
+
+	a.SetDetectionMatchCount("cluster-id", "first", 1)
+	a.SetDetectionMatchCount("cluster-id", "second", 1)
+	a.SetDetectionMatchCount("cluster-id", "third", 1)
+	require.Equal(t, 2, collected("detection_match_count"))
+	require.Equal(t, float64(1), testutil.ToFloat64(a.droppedSeries.WithLabelValues("cluster-id", "detection_match_count")))
+
+	// existing series are still updated at the limit
+	a.SetDetectionMatchCount("cluster-id", "second", 3)
+	require.Equal(t, float64(3), testutil.ToFloat64(a.detections.WithLabelValues("cluster-id", "second")))
+	require.Equal(t, float64(1), testutil.ToFloat64(a.droppedSeries.WithLabelValues("cluster-id", "detection_match_count")))
+
+	// the series of a deleted cluster no longer count, before the metrics are collected
+	a.deleteSeries(a.detections.MetricVec, "cluster-id")
+	a.SetDetectionMatchCount("cluster-id", "third", 1)
+	require.Equal(t, 1, collected("detection_match_count"))
+	require.Equal(t, float64(1), testutil.ToFloat64(a.droppedSeries.WithLabelValues("cluster-id", "detection_match_count")))
+}
+
+func TestAdoptionMetricsAggregator_LimitSeriesSnapshot(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	g := a.NewGauges("test_instance_count", "Indicates test instances by instance type", "instance_type")
+	a.MustRegister(NewMetricSet("Test", g))
+	require.NoError(t, a.LimitSeries("cluster-id", 2))
+
+	// the series a snapshot deletes release their slot right away, without collecting the metrics
+	for _, instanceType := range []string{"m5.xlarge", "m5.2xlarge", "m5.4xlarge"} {
+		g.SetSnapshot("cluster-id", []Sample{{LabelValues: []string{instanceType}, Value: 1}})
+	}
+	require.Equal(t, float64(1), testutil.ToFloat64(g.With("cluster-id", "m5.4xlarge")))
+	require.Equal(t, 1, testutil.CollectAndCount(g))
+	require.Equal(t, 0, testutil.CollectAndCount(a.droppedSeries))
+}
+
+func TestSeriesLimit_admitCollision(t *testing.T) {
+	// a series whose hash collides with a known series is a new series
+	l := &seriesLimit{name: "test", limit: 1, series: map[uint64][][]string{seriesKey([]string{"a"}): {{"b"}}}, count: 1}
+	require.False(t, l.admit([]string{"a"}))
+
+	l.limit = 2
+	require.True(t, l.admit([]string{"a"}))
+	require.Equal(t, 2, l.count)
+	l.release([]string{"a"})
+	require.Equal(t, map[uint64][][]string{seriesKey([]string{"a"}): {{"b"}}}, l.series)
+	require.Equal(t, 1, l.count)
+}
+
+func TestAdoptionMetricsAggregator_LimitSeriesRegistered(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	counters := a.NewCounters("test_errors_total", "Indicates test errors", "object")
+	a.MustRegister(NewMetricSet("test", counters))
+	require.NoError(t, a.LimitSeries("cluster-id", 1))
+
+	counters.With("cluster-id", "first").Inc()
+	counters.With("cluster-id", "second").Inc()
+	require.Equal(t, 1, testutil.CollectAndCount(counters, "test_errors_total"))
+	require.Equal(t, float64(1), testutil.ToFloat64(a.droppedSeries.WithLabelValues("cluster-id", "test_errors_total")))
+
+	// a limit of 0 does not limit the series
+	unlimited := NewMetricsAggregator("cluster-id")
+	require.NoError(t, unlimited.LimitSeries("cluster-id", 0))
+	for _, object := range []string{"first", "second"} {
+		unlimited.gauge(unlimited.detections, "cluster-id", object).Set(1)
+	}
+	require.Equal(t, 2, testutil.CollectAndCount(unlimited.detections, "detection_match_count"))
+	require.Equal(t, 0, testutil.CollectAndCount(unlimited.droppedSeries))
+}
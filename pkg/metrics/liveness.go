@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// LivenessChecker returns a healthz check failing when the mutex guarding the aggregated metrics cannot be
+// acquired within timeout, e.g. because a setter deadlocked while holding it. Serving the metrics of a wedged
+// aggregator would report stale values forever, a restart recovers it.
+func (a *AdoptionMetricsAggregator) LivenessChecker(timeout time.Duration) func(*http.Request) error {
+	return func(_ *http.Request) error {
+		acquired := make(chan struct{})
+		go func() {
+			a.mutex.Lock()
+			defer a.mutex.Unlock()
+			close(acquired)
+		}()
+		select {
+		case <-acquired:
+			return nil
+		case <-time.After(timeout):
+			return fmt.Errorf("the aggregator has been locked for more than %s", timeout)
+		}
+	}
+}
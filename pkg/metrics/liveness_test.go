@@ -0,0 +1,20 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLivenessChecker(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	check := a.LivenessChecker(10 * time.Millisecond)
+	require.NoError(t, check(nil))
+
+	// a wedged aggregator
+	a.mutex.Lock()
+	require.Error(t, check(nil))
+	a.mutex.Unlock()
+	require.NoError(t, check(nil))
+}
@@ -1,18 +1,31 @@
 package metrics
 
-import "time"
-
-const (
-	aggregatorResyncInterval = time.Minute
+import (
+	configv1 "github.com/openshift/api/config/v1"
 )
 
+// MetricsAggregator is the API controllers update the builtin metrics through. It is implemented by
+// AdoptionMetricsAggregator, and by metricsfakes.FakeMetricsAggregator to record the updates in tests.
+type MetricsAggregator interface {
+	SetOAuthIDP(name, namespace string, provider []configv1.IdentityProvider)
+	DeleteOAuthIDP(name, namespace string)
+	SetClusterID(uuid string)
+	SetDetectionMatchCount(uuid string, detection string, count int)
+	SetUpgradeBlocker(reason string, blocking bool)
+	SetObjectCount(uuid string, kind string, selector string, namespaceSelector string, count int)
+	SetClusterInfo(uuid string, fact ClusterInfoFact, value string)
+	RelabelClusterID(oldID, newID string)
+}
+
+var _ MetricsAggregator = &AdoptionMetricsAggregator{}
+
 var (
 	aggregator *AdoptionMetricsAggregator
 )
 
 func GetMetricsAggregator(clusterId string) *AdoptionMetricsAggregator {
 	if aggregator == nil {
-		aggregator = NewMetricsAggregator(aggregatorResyncInterval, clusterId)
+		aggregator = NewMetricsAggregator(clusterId)
 	}
 	return aggregator
 }
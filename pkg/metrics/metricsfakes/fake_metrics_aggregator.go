@@ -0,0 +1,101 @@
+// Package metricsfakes provides a fake metrics aggregator, so controller tests can assert on the updates of a
+// reconcile, to the builtin metrics and to the metrics of the controller, instead of collecting the metrics.
+package metricsfakes
+
+import (
+	"sync"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+// Update is a call of a method of the aggregator
+type Update struct {
+	Method string
+	Args   []interface{}
+}
+
+// FakeMetricsAggregator records the updates, in the order they were made. The builtin setters of the
+// metrics.MetricsAggregator interface are only recorded. The embedded aggregator backs the MetricSets of the controllers,
+// every set of their gauges is applied and recorded as a "Set" update with the metric, the label values and the value.
+type FakeMetricsAggregator struct {
+	*metrics.AdoptionMetricsAggregator
+	mutex   sync.Mutex
+	updates []Update
+}
+
+var _ metrics.MetricsAggregator = &FakeMetricsAggregator{}
+
+// NewFakeMetricsAggregator creates a fake aggregator, whose embedded aggregator is created with clusterId
+func NewFakeMetricsAggregator(clusterId string) *FakeMetricsAggregator {
+	f := &FakeMetricsAggregator{AdoptionMetricsAggregator: metrics.NewMetricsAggregator(clusterId)}
+	f.ObserveUpdates(func(metric string, labelValues []string, value float64) {
+		f.record("Set", metric, labelValues, value)
+	})
+	return f
+}
+
+func (f *FakeMetricsAggregator) record(method string, args ...interface{}) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.updates = append(f.updates, Update{Method: method, Args: args})
+}
+
+// Updates returns all recorded updates
+func (f *FakeMetricsAggregator) Updates() []Update {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]Update(nil), f.updates...)
+}
+
+// ArgsForCalls returns the arguments of every call of method
+func (f *FakeMetricsAggregator) ArgsForCalls(method string) [][]interface{} {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var args [][]interface{}
+	for _, u := range f.updates {
+		if u.Method == method {
+			args = append(args, u.Args)
+		}
+	}
+	return args
+}
+
+// Reset forgets the recorded updates
+func (f *FakeMetricsAggregator) Reset() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.updates = nil
+}
+
+func (f *FakeMetricsAggregator) SetOAuthIDP(name, namespace string, provider []configv1.IdentityProvider) {
+	f.record("SetOAuthIDP", name, namespace, provider)
+}
+
+func (f *FakeMetricsAggregator) DeleteOAuthIDP(name, namespace string) {
+	f.record("DeleteOAuthIDP", name, namespace)
+}
+
+func (f *FakeMetricsAggregator) SetClusterID(uuid string) {
+	f.record("SetClusterID", uuid)
+}
+
+func (f *FakeMetricsAggregator) SetDetectionMatchCount(uuid string, detection string, count int) {
+	f.record("SetDetectionMatchCount", uuid, detection, count)
+}
+
+func (f *FakeMetricsAggregator) SetUpgradeBlocker(reason string, blocking bool) {
+	f.record("SetUpgradeBlocker", reason, blocking)
+}
+
+func (f *FakeMetricsAggregator) SetObjectCount(uuid string, kind string, selector string, namespaceSelector string, count int) {
+	f.record("SetObjectCount", uuid, kind, selector, namespaceSelector, count)
+}
+
+func (f *FakeMetricsAggregator) SetClusterInfo(uuid string, fact metrics.ClusterInfoFact, value string) {
+	f.record("SetClusterInfo", uuid, fact, value)
+}
+
+func (f *FakeMetricsAggregator) RelabelClusterID(oldID, newID string) {
+	f.record("RelabelClusterID", oldID, newID)
+}
@@ -0,0 +1,42 @@
+package metricsfakes
+
+import (
+	"testing"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeMetricsAggregator(t *testing.T) {
+	f := NewFakeMetricsAggregator("cluster-id")
+	f.SetDetectionMatchCount("cluster-id", "test", 2)
+	f.SetUpgradeBlocker("DegradedOperators", true)
+	f.SetDetectionMatchCount("cluster-id", "test", 0)
+
+	require.Equal(t, []Update{
+		{Method: "SetDetectionMatchCount", Args: []interface{}{"cluster-id", "test", 2}},
+		{Method: "SetUpgradeBlocker", Args: []interface{}{"DegradedOperators", true}},
+		{Method: "SetDetectionMatchCount", Args: []interface{}{"cluster-id", "test", 0}},
+	}, f.Updates())
+	require.Equal(t, [][]interface{}{{"cluster-id", "test", 2}, {"cluster-id", "test", 0}}, f.ArgsForCalls("SetDetectionMatchCount"))
+	require.Empty(t, f.ArgsForCalls("SetClusterID"))
+
+	f.Reset()
+	require.Empty(t, f.Updates())
+}
+
+func TestFakeMetricsAggregator_MetricSet(t *testing.T) {
+	f := NewFakeMetricsAggregator("cluster-id")
+	g := f.NewGauges("test_modified", "Indicates if a test object was modified", "object")
+	f.MustRegister(metrics.NewMetricSet("Test", g))
+
+	g.With("cluster-id", "a").Set(1)
+	g.SetSnapshot("cluster-id", []metrics.Sample{{LabelValues: []string{"b"}, Value: 0}})
+	require.Equal(t, []Update{
+		{Method: "Set", Args: []interface{}{"test_modified", []string{"cluster-id", "a"}, float64(1)}},
+		{Method: "Set", Args: []interface{}{"test_modified", []string{"cluster-id", "b"}, float64(0)}},
+	}, f.Updates())
+	// the updates are applied
+	require.Equal(t, 1, testutil.CollectAndCount(g))
+}
@@ -0,0 +1,60 @@
+// Package metricstest compares all metrics of an aggregator with golden files, so controller tests catch a renamed
+// metric, a changed HELP text or a new label explicitly rather than only for the series they compare inline.
+// Run the tests with -update to rewrite the golden files of the failing scenarios.
+package metricstest
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the metricstest scenarios")
+
+// GoldenPath returns the golden file of scenario, in the testdata directory of the package under test
+func GoldenPath(scenario string) string {
+	return filepath.Join("testdata", scenario+".golden")
+}
+
+// Gather returns all metrics of a in the text format, sorted by name and labels. The collectors of the
+// controllers must be registered with the aggregator before, as none can be registered once they are gathered.
+func Gather(t testing.TB, a *metrics.AdoptionMetricsAggregator) string {
+	registry := prometheus.NewPedanticRegistry()
+	for _, c := range a.GetMetrics() {
+		require.NoError(t, registry.Register(c))
+	}
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	for _, family := range families {
+		_, err := expfmt.MetricFamilyToText(&buf, family)
+		require.NoError(t, err)
+	}
+	return buf.String()
+}
+
+// RequireGolden fails t unless all metrics of a equal the golden file of scenario, or rewrites the golden file
+// with -update
+func RequireGolden(t testing.TB, a *metrics.AdoptionMetricsAggregator, scenario string) {
+	t.Helper()
+	actual := Gather(t, a)
+	path := GoldenPath(scenario)
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(actual), 0644))
+		return
+	}
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err, "run the tests with -update to create the golden file")
+	if diff := cmp.Diff(string(expected), actual); diff != "" {
+		t.Fatalf("metrics differ from %s, run the tests with -update to accept them (-want +got):\n%s", path, diff)
+	}
+}
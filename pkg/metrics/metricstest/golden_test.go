@@ -0,0 +1,19 @@
+package metricstest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireGolden(t *testing.T) {
+	a := metrics.NewMetricsAggregator("cluster-id")
+	a.SetClusterID("cluster-id")
+	RequireGolden(t, a, "cluster-id")
+
+	content, err := os.ReadFile(GoldenPath("cluster-id"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), `cluster_id{_id="cluster-id",name="osd_exporter"} 1`+"\n")
+}
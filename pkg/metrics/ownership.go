@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"sigs.k8s.io/yaml"
+)
+
+// OwnershipConfig is the content of the metric ownership file
+type OwnershipConfig struct {
+	// Metrics maps metric names, e.g. cluster_admin_enabled, to their ownership
+	Metrics map[string]Ownership `json:"metrics"`
+}
+
+// Ownership tells consumers of a metric who to contact about its series and what its alerts are held to
+type Ownership struct {
+	// Team owning the metric
+	Team string `json:"team"`
+	// SLO the alerts on the metric are intended for, e.g. "page within 15m"
+	SLO string `json:"slo,omitempty"`
+}
+
+// helpSuffix is appended to the HELP text of the metric
+func (o Ownership) helpSuffix() string {
+	if o.SLO == "" {
+		return fmt.Sprintf(" (owner: %s)", o.Team)
+	}
+	return fmt.Sprintf(" (owner: %s, SLO: %s)", o.Team, o.SLO)
+}
+
+// LoadOwnership reads a metric ownership file
+func LoadOwnership(path string) (map[string]Ownership, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseOwnership(data)
+}
+
+// ParseOwnership parses the content of a metric ownership file
+func ParseOwnership(data []byte) (map[string]Ownership, error) {
+	config := &OwnershipConfig{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, err
+	}
+	for name, o := range config.Metrics {
+		if o.Team == "" {
+			return nil, fmt.Errorf("metric %s: team is required", name)
+		}
+	}
+	return config.Metrics, nil
+}
+
+// SetOwnership registers the owning team and SLO of metrics of the aggregator. It replaces the ownership set
+// before and fails for unknown metrics, so renamed metrics do not silently lose their owner.
+func (a *AdoptionMetricsAggregator) SetOwnership(ownership map[string]Ownership) error {
+	vecs := a.gaugeVecsByName()
+	owned := make(map[string]Ownership, len(ownership))
+	for name, o := range ownership {
+		if _, ok := vecs[name]; !ok {
+			return fmt.Errorf("ownership of unknown metric %q", name)
+		}
+		owned[name] = o
+	}
+	a.ownership.Store(&owned)
+	return nil
+}
+
+// getOwnership returns the ownership of the metric name, if any was registered
+func (a *AdoptionMetricsAggregator) getOwnership(name string) (Ownership, bool) {
+	ownership := a.ownership.Load()
+	if ownership == nil {
+		return Ownership{}, false
+	}
+	o, ok := (*ownership)[name]
+	return o, ok
+}
+
+// OwnershipGatherer appends the registered ownership to the HELP text of the metrics gathered by gatherer.
+// The HELP text is fixed when a metric is created, so it is rewritten in the gathered families instead.
+func (a *AdoptionMetricsAggregator) OwnershipGatherer(gatherer prometheus.Gatherer) prometheus.Gatherer {
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		families, err := gatherer.Gather()
+		for _, family := range families {
+			if o, ok := a.getOwnership(family.GetName()); ok {
+				help := family.GetHelp() + o.helpSuffix()
+				family.Help = &help
+			}
+		}
+		return families, err
+	})
+}
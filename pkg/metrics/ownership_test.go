@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+const testOwnership = `
+metrics:
+  cluster_id:
+    team: sre-platform
+    slo: page within 15m
+  detection_match_count:
+    team: networking
+`
+
+func TestAdoptionMetricsAggregator_OwnershipGatherer(t *testing.T) {
+	ownership, err := ParseOwnership([]byte(testOwnership))
+	require.NoError(t, err)
+
+	a := NewMetricsAggregator("cluster-id")
+	require.NoError(t, a.SetOwnership(ownership))
+	a.SetClusterID("cluster-id")
+	a.SetDetectionMatchCount("cluster-id", "test", 2)
+	a.EvaluateUpgradeReadiness("cluster-id")
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(a.GetMetrics()...)
+	err = testutil.GatherAndCompare(a.OwnershipGatherer(registry), strings.NewReader(`
+# HELP cluster_id Indicates the cluster id (owner: sre-platform, SLO: page within 15m)
+# TYPE cluster_id gauge
+cluster_id{_id="cluster-id",name="osd_exporter"} 1
+# HELP detection_match_count Indicates the number of objects matching a configured detection (owner: networking)
+# TYPE detection_match_count gauge
+detection_match_count{_id="cluster-id",detection="test",name="osd_exporter"} 2
+# HELP upgrade_ready Indicates if the cluster is ready to upgrade, with a series per reason blocking the upgrade
+# TYPE upgrade_ready gauge
+upgrade_ready{_id="cluster-id",blocking_reason="",name="osd_exporter"} 1
+`), "cluster_id", "detection_match_count", "upgrade_ready")
+	require.NoError(t, err)
+}
+
+func TestParseOwnershipInvalid(t *testing.T) {
+	_, err := ParseOwnership([]byte("metrics:\n  cluster_id:\n    slo: page within 15m\n"))
+	require.EqualError(t, err, "metric cluster_id: team is required")
+}
+
+func TestAdoptionMetricsAggregator_SetOwnershipUnknown(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	err := a.SetOwnership(map[string]Ownership{"unknown_metric": {Team: "sre-platform"}})
+	require.EqualError(t, err, `ownership of unknown metric "unknown_metric"`)
+}
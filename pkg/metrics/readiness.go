@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	reconcileTotalMetric = "controller_runtime_reconcile_total"
+	reconcileErrorResult = "error"
+)
+
+// ReconcileCount is the number of reconciles of a controller by result
+type ReconcileCount struct {
+	// Successes are the reconciles which did not return an error, including the ones which requeued
+	Successes float64
+	Errors    float64
+}
+
+// ReconcileCounts returns the reconcile counts of every controller, by the name controller-runtime records them
+// with in gatherer
+func ReconcileCounts(gatherer prometheus.Gatherer) (map[string]ReconcileCount, error) {
+	families, err := gatherer.Gather()
+	if err != nil {
+		return nil, fmt.Errorf("unable to gather the reconcile metrics: %w", err)
+	}
+	counts := make(map[string]ReconcileCount)
+	for _, family := range families {
+		if family.GetName() != reconcileTotalMetric {
+			continue
+		}
+		for _, pb := range family.GetMetric() {
+			controller := labelValue(pb, controllerLabel)
+			count := counts[controller]
+			if labelValue(pb, "result") == reconcileErrorResult {
+				count.Errors += pb.GetCounter().GetValue()
+			} else {
+				count.Successes += pb.GetCounter().GetValue()
+			}
+			counts[controller] = count
+		}
+	}
+	return counts, nil
+}
+
+// reconcileChecker tracks when the controllers last reconciled without an error, from the reconcile counts
+// controller-runtime records in gatherer
+type reconcileChecker struct {
+	gatherer prometheus.Gatherer
+	timeout  time.Duration
+
+	mutex sync.Mutex
+	// counts are the successful reconciles of each controller when they last changed, at lastSuccess
+	counts      map[string]float64
+	lastSuccess map[string]time.Time
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// ReconcileChecker returns a readyz check failing when a controller has not reconciled without an error for longer
+// than timeout, e.g. because its reconciles keep failing or its watch is stuck. A controller which was just started
+// has timeout to complete its first reconcile. Controllers which requeue count as successful, as they did not fail.
+func ReconcileChecker(gatherer prometheus.Gatherer, timeout time.Duration) func(*http.Request) error {
+	c := &reconcileChecker{
+		gatherer:    gatherer,
+		timeout:     timeout,
+		counts:      make(map[string]float64),
+		lastSuccess: make(map[string]time.Time),
+		now:         time.Now,
+	}
+	return c.check
+}
+
+func (c *reconcileChecker) check(_ *http.Request) error {
+	counts, err := ReconcileCounts(c.gatherer)
+	if err != nil {
+		return err
+	}
+
+	now := c.now()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	var stale []string
+	for controller, count := range counts {
+		if last, ok := c.counts[controller]; !ok || count.Successes != last {
+			c.counts[controller] = count.Successes
+			c.lastSuccess[controller] = now
+			continue
+		}
+		if now.Sub(c.lastSuccess[controller]) > c.timeout {
+			stale = append(stale, controller)
+		}
+	}
+	if len(stale) > 0 {
+		sort.Strings(stale)
+		return fmt.Errorf("no successful reconcile for more than %s: %s", c.timeout, strings.Join(stale, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileChecker(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	reconcileTotal := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_reconcile_total",
+	}, []string{"controller", "result"})
+	registry.MustRegister(reconcileTotal)
+	// controller-runtime initializes the counts of every controller when it starts
+	for _, controller := range []string{"proxy", "network"} {
+		reconcileTotal.WithLabelValues(controller, "success").Add(0)
+		reconcileTotal.WithLabelValues(controller, "error").Add(0)
+	}
+
+	now := time.Unix(0, 0)
+	c := &reconcileChecker{
+		gatherer:    registry,
+		timeout:     time.Minute,
+		counts:      make(map[string]float64),
+		lastSuccess: make(map[string]time.Time),
+		now:         func() time.Time { return now },
+	}
+	require.NoError(t, c.check(nil))
+
+	// started controllers have the timeout to reconcile
+	now = now.Add(30 * time.Second)
+	reconcileTotal.WithLabelValues("proxy", "success").Inc()
+	reconcileTotal.WithLabelValues("network", "requeue_after").Inc()
+	require.NoError(t, c.check(nil))
+
+	// failing reconciles do not count
+	now = now.Add(time.Minute + time.Second)
+	reconcileTotal.WithLabelValues("proxy", "error").Inc()
+	reconcileTotal.WithLabelValues("network", "success").Inc()
+	require.EqualError(t, c.check(nil), "no successful reconcile for more than 1m0s: proxy")
+
+	reconcileTotal.WithLabelValues("proxy", "success").Inc()
+	require.NoError(t, c.check(nil))
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// reconcileMetric exports a metric controller-runtime records for every controller, with the _id label and the
+// name of the controller as controller label. controller-runtime measures every reconcile and work queue, so
+// the reconcilers do not need to be instrumented.
+type reconcileMetric struct {
+	aggregator *AdoptionMetricsAggregator
+	gatherer   prometheus.Gatherer
+	clusterId  string
+	desc       *prometheus.Desc
+	// source is the name of the controller-runtime metric and sourceLabel its label with the controller name
+	source      string
+	sourceLabel string
+	// valueType is the type of the source metric
+	valueType string
+}
+
+// ExportReconcileMetrics exports the reconcile durations, reconcile errors and work queue depths of the controllers,
+// as recorded by controller-runtime in gatherer, so alerts can tell when the exporter is unhealthy or falling
+// behind. As the aggregator applies updates synchronously, the work queues of the controllers are the only
+// queues of the exporter. It must be called before GetMetrics.
+func (a *AdoptionMetricsAggregator) ExportReconcileMetrics(clusterId string, gatherer prometheus.Gatherer) error {
+	a.registryMutex.Lock()
+	defer a.registryMutex.Unlock()
+	if a.exported {
+		return fmt.Errorf("reconcile metrics exported after the metrics were exported")
+	}
+	if len(a.reconcileMetrics) > 0 {
+		return fmt.Errorf("reconcile metrics are already exported")
+	}
+	newMetric := func(name, help, valueType, source, sourceLabel string) prometheus.Collector {
+		return &reconcileMetric{
+			aggregator:  a,
+			gatherer:    gatherer,
+			clusterId:   clusterId,
+			desc:        prometheus.NewDesc(name, help, []string{clusterIDLabel, controllerLabel}, prometheus.Labels{"name": osdExporterValue}),
+			source:      source,
+			sourceLabel: sourceLabel,
+			valueType:   valueType,
+		}
+	}
+	a.reconcileMetrics = []prometheus.Collector{
+		newMetric("osd_exporter_reconcile_duration_seconds", "Indicates the duration of the reconciles of a controller", HistogramType,
+			"controller_runtime_reconcile_time_seconds", controllerLabel),
+		newMetric("osd_exporter_reconcile_errors_total", "Indicates the number of reconciles of a controller which returned an error", CounterType,
+			"controller_runtime_reconcile_errors_total", controllerLabel),
+		newMetric("osd_exporter_queue_depth", "Indicates the number of objects waiting to be reconciled by a controller", GaugeType,
+			"workqueue_depth", "name"),
+	}
+	return nil
+}
+
+func (m *reconcileMetric) metricType() string {
+	return m.valueType
+}
+
+func (m *reconcileMetric) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.desc
+}
+
+func (m *reconcileMetric) Collect(ch chan<- prometheus.Metric) {
+	families, err := m.gatherer.Gather()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(m.desc, err)
+		return
+	}
+	uuid := m.aggregator.resolveClusterID(m.clusterId)
+	for _, family := range families {
+		if family.GetName() != m.source {
+			continue
+		}
+		for _, pb := range family.GetMetric() {
+			controller := labelValue(pb, m.sourceLabel)
+			var metric prometheus.Metric
+			switch family.GetType() {
+			case dto.MetricType_HISTOGRAM:
+				h := pb.GetHistogram()
+				buckets := make(map[float64]uint64, len(h.GetBucket()))
+				for _, b := range h.GetBucket() {
+					buckets[b.GetUpperBound()] = b.GetCumulativeCount()
+				}
+				metric, err = prometheus.NewConstHistogram(m.desc, h.GetSampleCount(), h.GetSampleSum(), buckets, uuid, controller)
+			case dto.MetricType_COUNTER:
+				metric, err = prometheus.NewConstMetric(m.desc, prometheus.CounterValue, pb.GetCounter().GetValue(), uuid, controller)
+			case dto.MetricType_GAUGE:
+				metric, err = prometheus.NewConstMetric(m.desc, prometheus.GaugeValue, pb.GetGauge().GetValue(), uuid, controller)
+			default:
+				err = fmt.Errorf("metric %q has the unsupported type %s", m.source, family.GetType())
+			}
+			if err != nil {
+				ch <- prometheus.NewInvalidMetric(m.desc, err)
+				continue
+			}
+			ch <- metric
+		}
+	}
+}
+
+// labelValue returns the value of the label of the series, or the empty string
+func labelValue(pb *dto.Metric, name string) string {
+	for _, l := range pb.GetLabel() {
+		if l.GetName() == name {
+			return l.GetValue()
+		}
+	}
+	return ""
+}
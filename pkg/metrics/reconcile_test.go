@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdoptionMetricsAggregator_ExportReconcileMetrics(t *testing.T) {
+	// the metrics controller-runtime records for every controller
+	registry := prometheus.NewRegistry()
+	reconcileTime := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "controller_runtime_reconcile_time_seconds",
+		Buckets: []float64{0.1, 1},
+	}, []string{"controller"})
+	reconcileErrors := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_reconcile_errors_total",
+	}, []string{"controller"})
+	depth := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "workqueue_depth",
+	}, []string{"name"})
+	registry.MustRegister(reconcileTime, reconcileErrors, depth)
+	reconcileTime.WithLabelValues("proxy").Observe(0.5)
+	reconcileTime.WithLabelValues("proxy").Observe(2)
+	reconcileErrors.WithLabelValues("proxy").Add(3)
+	depth.WithLabelValues("proxy").Set(7)
+
+	a := NewMetricsAggregator("cluster-id")
+	require.NoError(t, a.ExportReconcileMetrics("cluster-id", registry))
+	require.Error(t, a.ExportReconcileMetrics("cluster-id", registry))
+	// collectors cannot reuse the names of the reconcile metrics
+	require.Error(t, a.Register(NewMetricSet("Test", a.NewGauges("osd_exporter_queue_depth", "Indicates a clashing test metric"))))
+	a.RelabelClusterID("cluster-id", "new-id")
+	for _, entry := range a.Catalog() {
+		if entry.Name == "osd_exporter_reconcile_duration_seconds" {
+			require.Equal(t, HistogramType, entry.Type)
+		}
+	}
+
+	exported := prometheus.NewRegistry()
+	for _, c := range a.GetMetrics() {
+		exported.MustRegister(c)
+	}
+	require.NoError(t, testutil.GatherAndCompare(exported, strings.NewReader(`
+# HELP osd_exporter_queue_depth Indicates the number of objects waiting to be reconciled by a controller
+# TYPE osd_exporter_queue_depth gauge
+osd_exporter_queue_depth{_id="new-id",controller="proxy",name="osd_exporter"} 7
+# HELP osd_exporter_reconcile_duration_seconds Indicates the duration of the reconciles of a controller
+# TYPE osd_exporter_reconcile_duration_seconds histogram
+osd_exporter_reconcile_duration_seconds_bucket{_id="new-id",controller="proxy",name="osd_exporter",le="0.1"} 0
+osd_exporter_reconcile_duration_seconds_bucket{_id="new-id",controller="proxy",name="osd_exporter",le="1"} 1
+osd_exporter_reconcile_duration_seconds_bucket{_id="new-id",controller="proxy",name="osd_exporter",le="+Inf"} 2
+osd_exporter_reconcile_duration_seconds_sum{_id="new-id",controller="proxy",name="osd_exporter"} 2.5
+osd_exporter_reconcile_duration_seconds_count{_id="new-id",controller="proxy",name="osd_exporter"} 2
+# HELP osd_exporter_reconcile_errors_total Indicates the number of reconciles of a controller which returned an error
+# TYPE osd_exporter_reconcile_errors_total counter
+osd_exporter_reconcile_errors_total{_id="new-id",controller="proxy",name="osd_exporter"} 3
+`), "osd_exporter_queue_depth", "osd_exporter_reconcile_duration_seconds", "osd_exporter_reconcile_errors_total"))
+}
+
+func TestAdoptionMetricsAggregator_ExportReconcileMetricsAfterExport(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	a.GetMetrics()
+	require.Error(t, a.ExportReconcileMetrics("cluster-id", prometheus.NewRegistry()))
+}
@@ -0,0 +1,229 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector is a named set of metrics registered by a controller, which exposes its own API to update them.
+// Registering a Collector adds its metrics to the aggregator without changing the aggregator itself.
+type Collector interface {
+	prometheus.Collector
+	// Name identifies the Collector, it must be unique within an aggregator
+	Name() string
+	// Metrics returns the metrics of the Collector
+	Metrics() []Metric
+}
+
+// Metric is a metric created by the aggregator: Gauges, Counters or Histograms. Its series are updated through
+// the aggregator, so like the metrics of the aggregator they have the _id label first and are relabelled when
+// the cluster id changes.
+type Metric interface {
+	prometheus.Collector
+	// owner is the aggregator which created the metric
+	owner() *AdoptionMetricsAggregator
+	// metricVec holds the series of the metric
+	metricVec() *prometheus.MetricVec
+	// collector is the vec of the metric, as exported by the aggregator
+	collector() prometheus.Collector
+}
+
+// metricOpts returns the options of a metric with the name constant label
+func metricOpts(name, help string) prometheus.Opts {
+	return prometheus.Opts{
+		Name:        name,
+		Help:        help,
+		ConstLabels: map[string]string{"name": osdExporterValue},
+	}
+}
+
+// Gauges is a gauge metric of a Collector. Gauges can also be seeded, traced and expired.
+type Gauges struct {
+	aggregator *AdoptionMetricsAggregator
+	vec        *prometheus.GaugeVec
+}
+
+// NewGauges creates a gauge metric with the _id label followed by the given labels. It is exported once the
+// Collector it belongs to is registered.
+func (a *AdoptionMetricsAggregator) NewGauges(name, help string, labels ...string) *Gauges {
+	return &Gauges{
+		aggregator: a,
+		vec:        prometheus.NewGaugeVec(prometheus.GaugeOpts(metricOpts(name, help)), append([]string{clusterIDLabel}, labels...)),
+	}
+}
+
+// With returns the series for the label values, starting with the cluster id
+func (g *Gauges) With(lvs ...string) Gauge {
+	return g.aggregator.gauge(g.vec, lvs...)
+}
+
+func (g *Gauges) Describe(ch chan<- *prometheus.Desc) {
+	g.vec.Describe(ch)
+}
+
+func (g *Gauges) Collect(ch chan<- prometheus.Metric) {
+	g.vec.Collect(ch)
+}
+
+func (g *Gauges) owner() *AdoptionMetricsAggregator { return g.aggregator }
+func (g *Gauges) metricVec() *prometheus.MetricVec  { return g.vec.MetricVec }
+func (g *Gauges) collector() prometheus.Collector   { return g.vec }
+
+// Counters is a counter metric of a Collector, e.g. of errors
+type Counters struct {
+	aggregator *AdoptionMetricsAggregator
+	vec        *prometheus.CounterVec
+}
+
+// NewCounters creates a counter metric with the _id label followed by the given labels. It is exported once the
+// Collector it belongs to is registered.
+func (a *AdoptionMetricsAggregator) NewCounters(name, help string, labels ...string) *Counters {
+	return &Counters{
+		aggregator: a,
+		vec:        prometheus.NewCounterVec(prometheus.CounterOpts(metricOpts(name, help)), append([]string{clusterIDLabel}, labels...)),
+	}
+}
+
+// With returns the series for the label values, starting with the cluster id
+func (c *Counters) With(lvs ...string) Counter {
+	return Counter{c.aggregator.child(c.vec.MetricVec, lvs...)}
+}
+
+func (c *Counters) Describe(ch chan<- *prometheus.Desc) {
+	c.vec.Describe(ch)
+}
+
+func (c *Counters) Collect(ch chan<- prometheus.Metric) {
+	c.vec.Collect(ch)
+}
+
+func (c *Counters) owner() *AdoptionMetricsAggregator { return c.aggregator }
+func (c *Counters) metricVec() *prometheus.MetricVec  { return c.vec.MetricVec }
+func (c *Counters) collector() prometheus.Collector   { return c.vec }
+
+// Histograms is a histogram metric of a Collector, e.g. of durations
+type Histograms struct {
+	aggregator *AdoptionMetricsAggregator
+	vec        *prometheus.HistogramVec
+}
+
+// NewHistograms creates a histogram metric with the _id label followed by the given labels. The default
+// buckets of prometheus are used when buckets is nil. It is exported once the Collector it belongs to is
+// registered.
+func (a *AdoptionMetricsAggregator) NewHistograms(name, help string, buckets []float64, labels ...string) *Histograms {
+	opts := metricOpts(name, help)
+	return &Histograms{
+		aggregator: a,
+		vec: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        opts.Name,
+			Help:        opts.Help,
+			ConstLabels: opts.ConstLabels,
+			Buckets:     buckets,
+		}, append([]string{clusterIDLabel}, labels...)),
+	}
+}
+
+// With returns the series for the label values, starting with the cluster id
+func (h *Histograms) With(lvs ...string) Observer {
+	return Observer{h.aggregator.child(h.vec.MetricVec, lvs...)}
+}
+
+func (h *Histograms) Describe(ch chan<- *prometheus.Desc) {
+	h.vec.Describe(ch)
+}
+
+func (h *Histograms) Collect(ch chan<- prometheus.Metric) {
+	h.vec.Collect(ch)
+}
+
+func (h *Histograms) owner() *AdoptionMetricsAggregator { return h.aggregator }
+func (h *Histograms) metricVec() *prometheus.MetricVec  { return h.vec.MetricVec }
+func (h *Histograms) collector() prometheus.Collector   { return h.vec }
+
+// MetricSet implements Collector for a fixed list of metrics. It is meant to be embedded in the metrics
+// type of a controller, which adds the methods updating them.
+type MetricSet struct {
+	name    string
+	metrics []Metric
+	// alerts are the alert rules recommended for the metrics
+	alerts []AlertRule
+}
+
+// NewMetricSet creates a MetricSet named name of the given metrics
+func NewMetricSet(name string, metrics ...Metric) MetricSet {
+	return MetricSet{name: name, metrics: metrics}
+}
+
+func (s MetricSet) Name() string {
+	return s.name
+}
+
+func (s MetricSet) Metrics() []Metric {
+	return s.metrics
+}
+
+func (s MetricSet) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range s.metrics {
+		m.Describe(ch)
+	}
+}
+
+func (s MetricSet) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range s.metrics {
+		m.Collect(ch)
+	}
+}
+
+// Register adds the metrics of the Collector to the aggregator. It must be called before GetMetrics, as the
+// collectors returned by GetMetrics are registered once with prometheus.
+func (a *AdoptionMetricsAggregator) Register(c Collector) error {
+	a.registryMutex.Lock()
+	defer a.registryMutex.Unlock()
+	if a.exported {
+		return fmt.Errorf("collector %q registered after the metrics were exported", c.Name())
+	}
+	for _, registered := range a.registered {
+		if registered.Name() == c.Name() {
+			return fmt.Errorf("collector %q is already registered", c.Name())
+		}
+	}
+	names := make(map[string]bool)
+	collectors := append(a.builtinCollectors(), a.reconcileMetrics...)
+	for _, collector := range append(collectors, a.registeredCollectors()...) {
+		names[collectorName(collector)] = true
+	}
+	for _, m := range c.Metrics() {
+		if m.owner() != a {
+			return fmt.Errorf("collector %q: metric created by another aggregator", c.Name())
+		}
+		name := collectorName(m)
+		if names[name] {
+			return fmt.Errorf("collector %q: metric %q is already registered", c.Name(), name)
+		}
+		names[name] = true
+	}
+	if err := a.checkAlertRules(c); err != nil {
+		return err
+	}
+	a.registered = append(a.registered, c)
+	return nil
+}
+
+// MustRegister registers the Collector and panics if it cannot be registered
+func (a *AdoptionMetricsAggregator) MustRegister(c Collector) {
+	if err := a.Register(c); err != nil {
+		panic(err)
+	}
+}
+
+// registeredCollectors returns the metrics of the registered collectors, in registration order
+func (a *AdoptionMetricsAggregator) registeredCollectors() []prometheus.Collector {
+	var collectors []prometheus.Collector
+	for _, c := range a.registered {
+		for _, m := range c.Metrics() {
+			collectors = append(collectors, m.collector())
+		}
+	}
+	return collectors
+}
@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGaugeSet(a *AdoptionMetricsAggregator, name string) (MetricSet, *Gauges) {
+	g := a.NewGauges("test_registered", "Indicates a registered test metric", "kind")
+	return NewMetricSet(name, g), g
+}
+
+func TestAdoptionMetricsAggregator_Register(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	set, g := newTestGaugeSet(a, "Test")
+	require.NoError(t, a.Register(set))
+	g.With("cluster-id", "a").Set(2)
+
+	// registered metrics are exported, relabelled and seeded like the metrics of the aggregator
+	require.Contains(t, a.gaugeVecsByName(), "test_registered")
+	a.RelabelClusterID("cluster-id", "new-id")
+	require.NoError(t, a.Seed("new-id", []SeedMetric{{Name: "test_registered", Labels: map[string]string{"kind": "b"}, Value: 1}}))
+	var exported bool
+	for _, c := range a.GetMetrics() {
+		exported = exported || testutil.CollectAndCount(c, "test_registered") > 0
+	}
+	require.True(t, exported)
+	require.NoError(t, testutil.CollectAndCompare(set, strings.NewReader(`
+# HELP test_registered Indicates a registered test metric
+# TYPE test_registered gauge
+test_registered{_id="new-id",kind="a",name="osd_exporter"} 2
+test_registered{_id="new-id",kind="b",name="osd_exporter"} 1
+`)))
+
+	g.SetSnapshot("cluster-id", nil)
+	require.Equal(t, 0, testutil.CollectAndCount(g))
+}
+
+// There is no aggregation loop, so there is nothing to flush before a test gathers the metrics, e.g. after a reconcile
+func TestAdoptionMetricsAggregator_UpdateVisibleToImmediateGather(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	set, g := newTestGaugeSet(a, "Test")
+	require.NoError(t, a.Register(set))
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(a.GetMetrics()...)
+
+	for _, value := range []float64{1, 2, 0} {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			g.With("cluster-id", "a").Set(value)
+			a.SetClusterID("cluster-id")
+		}()
+		<-done
+		families, err := registry.Gather()
+		require.NoError(t, err)
+		values := map[string]float64{}
+		for _, family := range families {
+			for _, m := range family.GetMetric() {
+				values[family.GetName()] = m.GetGauge().GetValue()
+			}
+		}
+		require.Equal(t, value, values["test_registered"])
+		require.Contains(t, values, "cluster_id")
+	}
+}
+
+func TestAdoptionMetricsAggregator_RegisterInvalid(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	set, _ := newTestGaugeSet(a, "Test")
+	require.NoError(t, a.Register(set))
+
+	duplicateName, _ := newTestGaugeSet(a, "Test")
+	require.Error(t, a.Register(NewMetricSet(duplicateName.Name())))
+	duplicateMetric, _ := newTestGaugeSet(a, "Other")
+	require.Error(t, a.Register(duplicateMetric))
+	require.Error(t, a.Register(NewMetricSet("Builtin", a.NewGauges("cluster_id", "Indicates the cluster id"))))
+	other := NewMetricsAggregator("cluster-id")
+	require.Error(t, a.Register(NewMetricSet("Foreign", other.NewGauges("test_foreign", "Indicates a foreign test metric"))))
+
+	a.GetMetrics()
+	require.Error(t, a.Register(NewMetricSet("Late", a.NewGauges("test_late", "Indicates a late test metric"))))
+	require.Panics(t, func() { a.MustRegister(NewMetricSet("Late")) })
+}
+
+func TestAdoptionMetricsAggregator_RegisterCountersAndHistograms(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	errors := a.NewCounters("test_errors_total", "Indicates the number of test errors", "kind")
+	durations := a.NewHistograms("test_duration_seconds", "Indicates the duration of tests", []float64{1, 10}, "kind")
+	set := NewMetricSet("Test", errors, durations)
+	require.NoError(t, a.Register(set))
+	require.Error(t, a.Register(NewMetricSet("Other", a.NewGauges("test_errors_total", "Indicates a clashing test metric"))))
+
+	errors.With("cluster-id", "a").Inc()
+	errors.With("cluster-id", "a").Add(2)
+	durations.With("cluster-id", "a").Observe(5)
+	require.NoError(t, testutil.CollectAndCompare(set, strings.NewReader(`
+# HELP test_duration_seconds Indicates the duration of tests
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{_id="cluster-id",kind="a",name="osd_exporter",le="1"} 0
+test_duration_seconds_bucket{_id="cluster-id",kind="a",name="osd_exporter",le="10"} 1
+test_duration_seconds_bucket{_id="cluster-id",kind="a",name="osd_exporter",le="+Inf"} 1
+test_duration_seconds_sum{_id="cluster-id",kind="a",name="osd_exporter"} 5
+test_duration_seconds_count{_id="cluster-id",kind="a",name="osd_exporter"} 1
+# HELP test_errors_total Indicates the number of test errors
+# TYPE test_errors_total counter
+test_errors_total{_id="cluster-id",kind="a",name="osd_exporter"} 3
+`)))
+
+	// counters move to the new id, the histograms of the new id start empty
+	a.RelabelClusterID("cluster-id", "new-id")
+	errors.With("cluster-id", "a").Inc()
+	require.NoError(t, testutil.CollectAndCompare(errors, strings.NewReader(`
+# HELP test_errors_total Indicates the number of test errors
+# TYPE test_errors_total counter
+test_errors_total{_id="new-id",kind="a",name="osd_exporter"} 4
+`)))
+	require.Equal(t, 0, testutil.CollectAndCount(durations))
+
+	durations.With("new-id", "a").Observe(1)
+	set.DeleteSeries("new-id")
+	require.Equal(t, 0, testutil.CollectAndCount(set))
+}
+
+func TestCounters_WithDoesNotAllocate(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	errors := a.NewCounters("test_errors_total", "Indicates the number of test errors", "kind")
+	errors.With("cluster-id", "a").Inc()
+	if allocs := testing.AllocsPerRun(100, func() { errors.With("cluster-id", "a").Inc() }); allocs > 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
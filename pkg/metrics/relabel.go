@@ -0,0 +1,315 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// constLabelNames are the constant labels of the metrics, which are not passed when selecting a series
+var constLabelNames = map[string]bool{"name": true}
+
+// lockedCollector collects a metric while no relabeling is in progress
+type lockedCollector struct {
+	prometheus.Collector
+	mutex *sync.RWMutex
+	// expiry removes the stale series of the metric before it is collected, if a ttl is set for it, and holds the
+	// last updates of its series if they are tracked
+	expiry *seriesExpiry
+	// limit counts the series of vec after they are collected, if the series of the metric are limited
+	limit *seriesLimit
+	vec   *prometheus.MetricVec
+}
+
+func (c *lockedCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if c.expiry != nil {
+		c.expiry.expire()
+	}
+	c.Collector.Collect(ch)
+	if c.limit != nil {
+		c.limit.sync(c.vec)
+	}
+}
+
+// series is a series looked up by the aggregator. It is updated while no relabeling is in progress, and if the
+// cluster id was relabelled after it was looked up the series it was moved to is updated instead, so an update
+// is neither lost nor recreates a series of the old id.
+type series struct {
+	aggregator *AdoptionMetricsAggregator
+	vec        *prometheus.MetricVec
+	metric     prometheus.Metric
+	// epoch is the number of relabelings before the series was looked up
+	epoch uint64
+	// expiry and update record the writes of the series, if the metric expires or tracks its updates
+	expiry *seriesExpiry
+	update *seriesUpdate
+}
+
+// lock waits for a relabeling in progress and returns the current series, unlock must be called once it is read
+func (s series) lock() prometheus.Metric {
+	s.aggregator.relabelMutex.RLock()
+	if s.aggregator.relabelEpoch.Load() == s.epoch {
+		return s.metric
+	}
+	return s.aggregator.lookupMoved(s.vec, s.metric).metric
+}
+
+func (s series) unlock() {
+	s.aggregator.relabelMutex.RUnlock()
+}
+
+// apply writes the current series with f while no relabeling is in progress
+func (s series) apply(f func(prometheus.Metric)) {
+	s.aggregator.relabelMutex.RLock()
+	defer s.aggregator.relabelMutex.RUnlock()
+	if s.aggregator.relabelEpoch.Load() != s.epoch {
+		s = s.aggregator.lookupMoved(s.vec, s.metric)
+	}
+	s.write(f)
+}
+
+// write writes the series with f, with the relabel lock held. A series which expires or tracks its updates records
+// the write afterwards with the mutex of its expiry held, so it cannot expire between the write and the record, and a
+// series deleted since it was looked up is created again rather than written after it was deleted.
+func (s series) write(f func(prometheus.Metric)) {
+	if s.update == nil {
+		f(s.metric)
+		return
+	}
+	s.expiry.mutex.Lock()
+	defer s.expiry.mutex.Unlock()
+	m, u := s.metric, s.update
+	if u.deleted {
+		m, u = s.aggregator.recreate(s.vec, s.expiry, u.labelValues)
+	}
+	f(m)
+	if u != nil {
+		u.at = now()
+	}
+}
+
+// Desc, Write, Describe and Collect read the current series, so a series also implements prometheus.Metric and
+// prometheus.Collector like the series of a vec
+
+func (s series) Desc() *prometheus.Desc {
+	defer s.unlock()
+	return s.lock().Desc()
+}
+
+func (s series) Write(pb *dto.Metric) error {
+	defer s.unlock()
+	return s.lock().Write(pb)
+}
+
+func (s series) Describe(ch chan<- *prometheus.Desc) {
+	defer s.unlock()
+	ch <- s.lock().Desc()
+}
+
+func (s series) Collect(ch chan<- prometheus.Metric) {
+	defer s.unlock()
+	ch <- s.lock()
+}
+
+// Gauge is a series of a gauge metric
+type Gauge struct{ series }
+
+var _ prometheus.Gauge = Gauge{}
+
+func (g Gauge) Set(v float64) {
+	g.apply(func(m prometheus.Metric) { m.(prometheus.Gauge).Set(v) })
+}
+
+func (g Gauge) Inc() {
+	g.apply(func(m prometheus.Metric) { m.(prometheus.Gauge).Inc() })
+}
+
+func (g Gauge) Dec() {
+	g.apply(func(m prometheus.Metric) { m.(prometheus.Gauge).Dec() })
+}
+
+func (g Gauge) Add(v float64) {
+	g.apply(func(m prometheus.Metric) { m.(prometheus.Gauge).Add(v) })
+}
+
+func (g Gauge) Sub(v float64) {
+	g.apply(func(m prometheus.Metric) { m.(prometheus.Gauge).Sub(v) })
+}
+
+func (g Gauge) SetToCurrentTime() {
+	g.apply(func(m prometheus.Metric) { m.(prometheus.Gauge).SetToCurrentTime() })
+}
+
+// set sets the series with the relabel lock held
+func (g Gauge) set(v float64) {
+	g.write(func(m prometheus.Metric) { m.(prometheus.Gauge).Set(v) })
+}
+
+// Counter is a series of a counter metric
+type Counter struct{ series }
+
+var _ prometheus.Counter = Counter{}
+
+func (c Counter) Inc() {
+	c.apply(func(m prometheus.Metric) { m.(prometheus.Counter).Inc() })
+}
+
+func (c Counter) Add(v float64) {
+	c.apply(func(m prometheus.Metric) { m.(prometheus.Counter).Add(v) })
+}
+
+// inc increments the series with the relabel lock held
+func (c Counter) inc() {
+	c.write(func(m prometheus.Metric) { m.(prometheus.Counter).Inc() })
+}
+
+// Observer is a series of a histogram metric
+type Observer struct{ series }
+
+var _ prometheus.Observer = Observer{}
+
+func (o Observer) Observe(v float64) {
+	o.apply(func(m prometheus.Metric) { m.(prometheus.Observer).Observe(v) })
+}
+
+// lookupMoved returns the series m of vec was moved to by a relabeling, which must not be in progress
+func (a *AdoptionMetricsAggregator) lookupMoved(vec *prometheus.MetricVec, m prometheus.Metric) series {
+	unmoved := series{aggregator: a, vec: vec, metric: m}
+	pb := &dto.Metric{}
+	if err := m.Write(pb); err != nil {
+		return unmoved
+	}
+	labels := seriesLabels(pb)
+	if _, ok := labels[clusterIDLabel]; !ok {
+		// a series without the cluster id is not moved, e.g. the discarded series of a limited metric
+		return unmoved
+	}
+	descs := make(chan *prometheus.Desc, 1)
+	vec.Describe(descs)
+	entry, ok := parseDesc(<-descs)
+	if !ok {
+		return unmoved
+	}
+	lvs := make([]string, len(entry.Labels))
+	for i, name := range entry.Labels {
+		lvs[i] = labels[name]
+	}
+	return a.lookup(vec, lvs...)
+}
+
+// RelabelClusterID moves all series of oldID to newID, after the external id of the cluster changed, e.g.
+// when it was registered again in OCM. Scrapes and updates wait for all series to be moved, so they never see
+// or create a mix of both ids. Setters called with oldID afterwards update the series of newID.
+func (a *AdoptionMetricsAggregator) RelabelClusterID(oldID, newID string) {
+	a.relabelMutex.Lock()
+	defer a.relabelMutex.Unlock()
+	// the series looked up before are looked up again when they are updated
+	defer a.relabelEpoch.Add(1)
+
+	aliases := map[string]string{oldID: newID}
+	if previous := a.clusterIDAliases.Load(); previous != nil {
+		for from, to := range *previous {
+			if to == oldID {
+				to = newID
+			}
+			aliases[from] = to
+		}
+	}
+	a.clusterIDAliases.Store(&aliases)
+
+	for _, c := range a.collectors() {
+		switch v := c.(type) {
+		case *prometheus.GaugeVec:
+			relabel(v, oldID, newID)
+		case *prometheus.CounterVec:
+			relabelCounters(v, oldID, newID)
+		case *prometheus.HistogramVec:
+			// observations cannot be moved to another series, the histograms of newID start empty
+			for _, pb := range clusterSeries(v.MetricVec, oldID) {
+				v.Delete(seriesLabels(pb))
+			}
+		}
+	}
+	for _, e := range a.expiries {
+		e.relabel(oldID, newID)
+	}
+	a.relabelClusterInfo(oldID, newID)
+	// the relabel lock is held, so the series is looked up and set directly
+	Gauge{a.lookup(a.clusterIDChanged.MetricVec, newID, oldID)}.set(float64(time.Now().Unix()))
+}
+
+// resolveClusterID returns the id the series of uuid were moved to by RelabelClusterID, or uuid
+func (a *AdoptionMetricsAggregator) resolveClusterID(uuid string) string {
+	if aliases := a.clusterIDAliases.Load(); aliases != nil {
+		if id, ok := (*aliases)[uuid]; ok {
+			return id
+		}
+	}
+	return uuid
+}
+
+// relabel moves the series of vec with the _id oldID to newID
+func relabel(vec *prometheus.GaugeVec, oldID, newID string) {
+	for _, pb := range clusterSeries(vec.MetricVec, oldID) {
+		labels := seriesLabels(pb)
+		vec.Delete(labels)
+		labels[clusterIDLabel] = newID
+		vec.With(labels).Set(pb.GetGauge().GetValue())
+	}
+}
+
+// relabelCounters moves the series of vec with the _id oldID to newID, adding to series of newID which exist
+func relabelCounters(vec *prometheus.CounterVec, oldID, newID string) {
+	for _, pb := range clusterSeries(vec.MetricVec, oldID) {
+		labels := seriesLabels(pb)
+		vec.Delete(labels)
+		labels[clusterIDLabel] = newID
+		vec.With(labels).Add(pb.GetCounter().GetValue())
+	}
+}
+
+// clusterSeries returns the series of vec with the _id uuid
+func clusterSeries(vec *prometheus.MetricVec, uuid string) []*dto.Metric {
+	var series []*dto.Metric
+	for _, pb := range collectSeries(vec) {
+		for _, l := range pb.GetLabel() {
+			if l.GetName() == clusterIDLabel && l.GetValue() == uuid {
+				series = append(series, pb)
+				break
+			}
+		}
+	}
+	return series
+}
+
+// collectSeries returns all series of vec
+func collectSeries(vec *prometheus.MetricVec) []*dto.Metric {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		vec.Collect(ch)
+		close(ch)
+	}()
+	var series []*dto.Metric
+	for m := range ch {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err == nil {
+			series = append(series, pb)
+		}
+	}
+	return series
+}
+
+// seriesLabels returns the labels selecting the series, without the constant labels
+func seriesLabels(pb *dto.Metric) prometheus.Labels {
+	labels := prometheus.Labels{}
+	for _, l := range pb.GetLabel() {
+		if !constLabelNames[l.GetName()] {
+			labels[l.GetName()] = l.GetValue()
+		}
+	}
+	return labels
+}
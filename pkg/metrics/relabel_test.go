@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelabelClusterID(t *testing.T) {
+	a := NewMetricsAggregator("old-id")
+	g := newBenchmarkGauges(a)
+	a.MustRegister(NewMetricSet("Test", g))
+	a.SetClusterID("old-id")
+	a.SetDetectionMatchCount("old-id", "test", 2)
+	g.SetSnapshot("old-id", []Sample{{LabelValues: []string{"O=Test"}, Value: 3}})
+	g.With("hosted-id", "O=Hosted").Set(4)
+
+	a.RelabelClusterID("old-id", "new-id")
+
+	err := testutil.CollectAndCompare(a.GetClusterIDMetric(), strings.NewReader(`
+# HELP cluster_id Indicates the cluster id
+# TYPE cluster_id gauge
+cluster_id{_id="new-id",name="osd_exporter"} 1
+`))
+	require.NoError(t, err)
+	// series of other clusters keep their id
+	err = testutil.CollectAndCompare(g.vec, strings.NewReader(`
+# HELP test_ca_expiry_timestamp Indicates the expiry of a test CA
+# TYPE test_ca_expiry_timestamp gauge
+test_ca_expiry_timestamp{_id="hosted-id",name="osd_exporter",subject="O=Hosted"} 4
+test_ca_expiry_timestamp{_id="new-id",name="osd_exporter",subject="O=Test"} 3
+`))
+	require.NoError(t, err)
+	require.Equal(t, float64(2), testutil.ToFloat64(a.GetDetectionMetric().WithLabelValues("new-id", "test")))
+	require.Equal(t, 1, testutil.CollectAndCount(a.GetClusterIDChangedMetric()))
+	require.NotZero(t, testutil.ToFloat64(a.GetClusterIDChangedMetric().WithLabelValues("new-id", "old-id")))
+
+	// controllers still passing the old id update the new series
+	a.SetDetectionMatchCount("old-id", "test", 5)
+	require.Equal(t, float64(5), testutil.ToFloat64(a.GetDetectionMetric().WithLabelValues("new-id", "test")))
+	require.Equal(t, 1, testutil.CollectAndCount(a.GetDetectionMetric()))
+
+	// a second change moves the series of both previous ids
+	a.RelabelClusterID("new-id", "newer-id")
+	a.SetDetectionMatchCount("old-id", "test", 6)
+	require.Equal(t, float64(6), testutil.ToFloat64(a.GetDetectionMetric().WithLabelValues("newer-id", "test")))
+	require.Equal(t, 1, testutil.CollectAndCount(a.GetDetectionMetric()))
+}
+
+func TestRelabelClusterID_ScrapesWait(t *testing.T) {
+	a := NewMetricsAggregator("old-id")
+	registry := prometheus.NewRegistry()
+	for _, c := range a.GetMetrics() {
+		require.NoError(t, registry.Register(c))
+	}
+
+	a.relabelMutex.Lock()
+	gathered := make(chan struct{})
+	go func() {
+		_, _ = registry.Gather()
+		close(gathered)
+	}()
+	select {
+	case <-gathered:
+		t.Fatal("gathered while relabeling")
+	case <-time.After(50 * time.Millisecond):
+	}
+	a.relabelMutex.Unlock()
+	<-gathered
+}
+
+func TestRelabelClusterID_ConcurrentUpdates(t *testing.T) {
+	const writers, relabelings = 4, 20
+	a := NewMetricsAggregator("id-0")
+	gauges := a.NewGauges("test_relabel_value", "Indicates the last value of a test writer", "writer")
+	counters := a.NewCounters("test_relabel_updates_total", "Counts the updates of a test writer", "writer")
+	snapshots := a.NewGauges("test_relabel_snapshot", "Indicates the last value of the first test writer", "writer")
+	a.MustRegister(NewMetricSet("Test", gauges, counters, snapshots))
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(a.GetMetrics()...)
+	a.SetClusterID("id-0")
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	updates := make([]int, writers)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			writer := fmt.Sprint(w)
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				updates[w]++
+				// controllers keep passing the id they started with
+				gauges.With("id-0", writer).Set(float64(updates[w]))
+				counters.With("id-0", writer).Inc()
+				if w == 0 {
+					snapshots.SetSnapshot("id-0", []Sample{{LabelValues: []string{writer}, Value: float64(updates[w])}})
+					a.SetDetectionMatchCount("id-0", "test", updates[w])
+				}
+			}
+		}(w)
+	}
+
+	ids := func() map[string]bool {
+		families, err := registry.Gather()
+		require.NoError(t, err)
+		ids := map[string]bool{}
+		for _, family := range families {
+			for _, m := range family.GetMetric() {
+				for _, l := range m.GetLabel() {
+					if l.GetName() == clusterIDLabel && !strings.HasPrefix(family.GetName(), "cluster_id_changed") {
+						ids[l.GetValue()] = true
+					}
+				}
+			}
+		}
+		return ids
+	}
+	for i := 1; i <= relabelings; i++ {
+		a.RelabelClusterID(fmt.Sprintf("id-%d", i-1), fmt.Sprintf("id-%d", i))
+		require.Len(t, ids(), 1, "a scrape sees the series of a single cluster id")
+	}
+	close(stop)
+	wg.Wait()
+
+	newest := fmt.Sprintf("id-%d", relabelings)
+	require.Equal(t, map[string]bool{newest: true}, ids(), "no series of an old id is created while relabeling")
+	for w, n := range updates {
+		writer := fmt.Sprint(w)
+		require.Equal(t, float64(n), testutil.ToFloat64(gauges.With(newest, writer)), "the last update of writer %d", w)
+		require.Equal(t, float64(n), testutil.ToFloat64(counters.With(newest, writer)), "no increment of writer %d is lost", w)
+	}
+	require.Equal(t, float64(updates[0]), testutil.ToFloat64(snapshots.With(newest, "0")))
+	require.Equal(t, float64(updates[0]), testutil.ToFloat64(a.GetDetectionMetric().WithLabelValues(newest, "test")))
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Schema versions of the exposed metrics. A breaking change to the label set of a metric ships in a new
+// schema version, so consumers can migrate while the previous version is still served.
+const (
+	SchemaVersionV1 = "1"
+	SchemaVersionV2 = "2"
+
+	// MetricsV2Path is the path the v2 schema is served on
+	MetricsV2Path = "/metrics/v2"
+
+	schemaVersionLabel = "schema_version"
+)
+
+// GetMetricsForSchema returns the collectors exposing the given schema version.
+// No metric has changed shape yet, so both versions expose the same collectors.
+func (a *AdoptionMetricsAggregator) GetMetricsForSchema(version string) ([]prometheus.Collector, error) {
+	switch version {
+	case SchemaVersionV1, SchemaVersionV2:
+		return a.GetMetrics(), nil
+	}
+	return nil, fmt.Errorf("unknown metrics schema version %q", version)
+}
+
+// NewSchemaRegistry creates a registry exposing the collectors with a schema_version constant label and the
+// extra labels, alongside the Go and process collectors of the default registry
+func NewSchemaRegistry(version string, extraLabels prometheus.Labels, collectorList []prometheus.Collector) (*prometheus.Registry, error) {
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(collectors.NewGoCollector()); err != nil {
+		return nil, err
+	}
+	if err := registry.Register(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{})); err != nil {
+		return nil, err
+	}
+	versioned := prometheus.WrapRegistererWith(prometheus.Labels{schemaVersionLabel: version}, prometheus.WrapRegistererWith(extraLabels, registry))
+	for _, collector := range collectorList {
+		if err := versioned.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
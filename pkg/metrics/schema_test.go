@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSchemaRegistry(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	a.SetClusterID("cluster-id")
+
+	collectorList, err := a.GetMetricsForSchema(SchemaVersionV2)
+	require.NoError(t, err)
+	registry, err := NewSchemaRegistry(SchemaVersionV2, prometheus.Labels{"environment": "stage"}, collectorList)
+	require.NoError(t, err)
+
+	expected := `
+# HELP cluster_id Indicates the cluster id
+# TYPE cluster_id gauge
+cluster_id{_id="cluster-id",environment="stage",name="osd_exporter",schema_version="2"} 1
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "cluster_id"))
+
+	_, err = a.GetMetricsForSchema("3")
+	require.Error(t, err)
+}
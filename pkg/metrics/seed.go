@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/yaml"
+)
+
+// descNameRegexp extracts the metric name from prometheus.Desc.String, the only way a Desc exposes it
+var descNameRegexp = regexp.MustCompile(`fqName: "([^"]+)"`)
+
+// SeedConfig is the content of the seed metrics file
+type SeedConfig struct {
+	Metrics []SeedMetric `json:"metrics"`
+}
+
+// SeedMetric is the value of a single series set when the exporter starts
+type SeedMetric struct {
+	// Name of the metric, e.g. cluster_admin_enabled
+	Name string `json:"name"`
+	// Labels of the series, without the constant name label. The _id label defaults to the cluster id.
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+}
+
+// LoadSeeds reads a seed metrics file
+func LoadSeeds(path string) ([]SeedMetric, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSeeds(data)
+}
+
+// ParseSeeds parses the content of a seed metrics file
+func ParseSeeds(data []byte) ([]SeedMetric, error) {
+	config := &SeedConfig{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, err
+	}
+	for i, m := range config.Metrics {
+		if m.Name == "" {
+			return nil, fmt.Errorf("seed metric %d: name is required", i)
+		}
+	}
+	return config.Metrics, nil
+}
+
+// Seed sets the given series, so dashboards and alerts can be developed against realistic output without a
+// live cluster. Controllers that are running overwrite the seeded series of their metrics.
+func (a *AdoptionMetricsAggregator) Seed(uuid string, seeds []SeedMetric) error {
+	vecs := a.gaugeVecsByName()
+	for i, s := range seeds {
+		vec, ok := vecs[s.Name]
+		if !ok {
+			return fmt.Errorf("seed metric %d: unknown metric %q", i, s.Name)
+		}
+		labels := prometheus.Labels{clusterIDLabel: uuid}
+		for k, v := range s.Labels {
+			labels[k] = v
+		}
+		g, err := vec.GetMetricWith(labels)
+		if err != nil {
+			return fmt.Errorf("seed metric %d: %s: %w", i, s.Name, err)
+		}
+		g.Set(s.Value)
+	}
+	return nil
+}
+
+// gaugeVecsByName returns the metrics of the aggregator by metric name
+func (a *AdoptionMetricsAggregator) gaugeVecsByName() map[string]*prometheus.GaugeVec {
+	return gaugeVecsByName(a.collectors())
+}
+
+func gaugeVecsByName(collectors []prometheus.Collector) map[string]*prometheus.GaugeVec {
+	vecs := make(map[string]*prometheus.GaugeVec)
+	for _, c := range collectors {
+		vec, ok := c.(*prometheus.GaugeVec)
+		if !ok {
+			continue
+		}
+		if name := collectorName(vec); name != "" {
+			vecs[name] = vec
+		}
+	}
+	return vecs
+}
+
+// collectorName returns the metric name of a collector of a single metric
+func collectorName(c prometheus.Collector) string {
+	descs := make(chan *prometheus.Desc, 1)
+	c.Describe(descs)
+	if match := descNameRegexp.FindStringSubmatch((<-descs).String()); match != nil {
+		return match[1]
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+const testSeeds = `
+metrics:
+  - name: cluster_id
+    value: 1
+  - name: object_count
+    labels:
+      kind: Pod
+      selector: app=test
+      namespace_selector: ""
+    value: 12
+  - name: test_ca_expiry_timestamp
+    labels:
+      _id: other-cluster
+      subject: O=Test
+    value: 8901
+`
+
+func TestAdoptionMetricsAggregator_Seed(t *testing.T) {
+	seeds, err := ParseSeeds([]byte(testSeeds))
+	require.NoError(t, err)
+
+	a := NewMetricsAggregator("cluster-id")
+	g := newBenchmarkGauges(a)
+	a.MustRegister(NewMetricSet("Test", g))
+	require.NoError(t, a.Seed("cluster-id", seeds))
+
+	require.Equal(t, float64(1), testutil.ToFloat64(a.GetClusterIDMetric().WithLabelValues("cluster-id")))
+	require.Equal(t, float64(8901), testutil.ToFloat64(g.With("other-cluster", "O=Test")))
+	err = testutil.CollectAndCompare(a.GetObjectCountMetric(), strings.NewReader(`
+# HELP object_count Indicates the number of objects of a kind matching a configured selector
+# TYPE object_count gauge
+object_count{_id="cluster-id",kind="Pod",name="osd_exporter",namespace_selector="",selector="app=test"} 12
+`))
+	require.NoError(t, err)
+}
+
+func TestAdoptionMetricsAggregator_SeedInvalid(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	for name, seed := range map[string]SeedMetric{
+		"unknown metric": {Name: "does_not_exist"},
+		"unknown label":  {Name: "cluster_id", Labels: map[string]string{"foo": "bar"}},
+		"missing label":  {Name: "object_count", Labels: map[string]string{"kind": "Pod"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Error(t, a.Seed("cluster-id", []SeedMetric{seed}))
+		})
+	}
+
+	_, err := ParseSeeds([]byte("metrics:\n  - value: 1\n"))
+	require.Error(t, err)
+}
@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Sample is a series of a snapshot, with the label values following the _id label and its value
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// CountSamples returns a sample for each label value with its count, for snapshots of a metric with one label
+// after the _id
+func CountSamples(counts map[string]int) []Sample {
+	samples := make([]Sample, 0, len(counts))
+	for lv, count := range counts {
+		samples = append(samples, Sample{LabelValues: []string{lv}, Value: float64(count)})
+	}
+	return samples
+}
+
+// ValueSamples returns a sample for each label value with its value, for snapshots of a metric with one label
+// after the _id
+func ValueSamples(values map[string]float64) []Sample {
+	samples := make([]Sample, 0, len(values))
+	for lv, value := range values {
+		samples = append(samples, Sample{LabelValues: []string{lv}, Value: value})
+	}
+	return samples
+}
+
+// SetSnapshot replaces the series of the cluster with the samples of a reconcile. Series of the cluster which are
+// not in the snapshot are deleted, e.g. the series of the previous instance type after it changed, while the series
+// of other clusters are kept. Reconcilers report everything they found instead of tracking what they reported before.
+func (g *Gauges) SetSnapshot(uuid string, samples []Sample) {
+	g.aggregator.setSnapshot(g.vec, uuid, samples)
+}
+
+func (a *AdoptionMetricsAggregator) setSnapshot(vec *prometheus.GaugeVec, uuid string, samples []Sample) {
+	// the samples are set and the other series of the cluster deleted while no relabeling moves them, so both use the
+	// same cluster id
+	a.relabelMutex.RLock()
+	defer a.relabelMutex.RUnlock()
+	uuid = a.resolveClusterID(uuid)
+	reported := make(map[uint64]bool, len(samples))
+	for _, s := range samples {
+		lvs := append([]string{uuid}, s.LabelValues...)
+		Gauge{a.lookup(vec.MetricVec, lvs...)}.set(s.Value)
+		reported[seriesKey(lvs)] = true
+	}
+	a.deleteUnreported(vec, clusterSeries(vec.MetricVec, uuid), reported)
+}
+
+// ReplaceSeries replaces all series of the metric with the samples, whose label values start with the _id. It is
+// meant for metrics reporting on other clusters, e.g. the hosted clusters of a management cluster, whose series are
+// deleted once the cluster is gone.
+func (g *Gauges) ReplaceSeries(samples []Sample) {
+	g.aggregator.replaceSeries(g.vec, samples)
+}
+
+func (a *AdoptionMetricsAggregator) replaceSeries(vec *prometheus.GaugeVec, samples []Sample) {
+	a.relabelMutex.RLock()
+	defer a.relabelMutex.RUnlock()
+	reported := make(map[uint64]bool, len(samples))
+	for _, s := range samples {
+		Gauge{a.lookup(vec.MetricVec, s.LabelValues...)}.set(s.Value)
+		// the series is kept under the id it was moved to by a relabeling
+		reported[seriesKey(append([]string{a.resolveClusterID(s.LabelValues[0])}, s.LabelValues[1:]...))] = true
+	}
+	a.deleteUnreported(vec, collectSeries(vec.MetricVec), reported)
+}
+
+// deleteUnreported deletes the series which are not in the reported keys, it must be called with the relabel lock held
+func (a *AdoptionMetricsAggregator) deleteUnreported(vec *prometheus.GaugeVec, series []*dto.Metric, reported map[uint64]bool) {
+	descs := make(chan *prometheus.Desc, 1)
+	vec.Describe(descs)
+	entry, ok := parseDesc(<-descs)
+	if !ok {
+		return
+	}
+	e := a.expiries[vec.MetricVec]
+	lvs := make([]string, len(entry.Labels))
+	for _, pb := range series {
+		labels := seriesLabels(pb)
+		for i, name := range entry.Labels {
+			lvs[i] = labels[name]
+		}
+		if reported[seriesKey(lvs)] {
+			continue
+		}
+		vec.Delete(labels)
+		a.releaseSeries(vec.MetricVec, labels)
+		if e != nil {
+			e.forgetSeries(lvs)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGauges_SetSnapshot(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	g := a.NewGauges("test_instance_count", "Indicates test instances by instance type", "instance_type")
+	require.NoError(t, a.Register(NewMetricSet("Test", g)))
+	g.With("other-id", "m5.xlarge").Set(3)
+
+	g.SetSnapshot("cluster-id", []Sample{
+		{LabelValues: []string{"m5.2xlarge"}, Value: 3},
+		{LabelValues: []string{"m5.xlarge"}, Value: 1},
+	})
+	require.Equal(t, 3, testutil.CollectAndCount(g))
+
+	// the series of the previous instance type is deleted, the series of other clusters are kept
+	g.SetSnapshot("cluster-id", []Sample{{LabelValues: []string{"m5.4xlarge"}, Value: 3}, {LabelValues: []string{"m5.xlarge"}, Value: 2}})
+	require.Equal(t, 3, testutil.CollectAndCount(g))
+	require.Equal(t, float64(3), testutil.ToFloat64(g.With("cluster-id", "m5.4xlarge")))
+	require.Equal(t, float64(2), testutil.ToFloat64(g.With("cluster-id", "m5.xlarge")))
+	require.Equal(t, float64(3), testutil.ToFloat64(g.With("other-id", "m5.xlarge")))
+
+	// snapshots of the old id replace the series of the new id
+	a.RelabelClusterID("cluster-id", "new-id")
+	g.SetSnapshot("cluster-id", []Sample{{LabelValues: []string{"m5.4xlarge"}, Value: 3}})
+	require.Equal(t, 2, testutil.CollectAndCount(g))
+	require.Equal(t, float64(3), testutil.ToFloat64(g.With("new-id", "m5.4xlarge")))
+
+	g.SetSnapshot("cluster-id", nil)
+	require.Equal(t, 1, testutil.CollectAndCount(g))
+}
+
+func TestGauges_ReplaceSeries(t *testing.T) {
+	a := NewMetricsAggregator("cluster-id")
+	g := a.NewGauges("test_nodepool_replicas", "Indicates the replicas of a test NodePool", "nodepool")
+	require.NoError(t, a.Register(NewMetricSet("Test", g)))
+	g.ReplaceSeries([]Sample{
+		{LabelValues: []string{"hosted-a", "workers"}, Value: 2},
+		{LabelValues: []string{"hosted-b", "workers"}, Value: 3},
+	})
+	require.Equal(t, 2, testutil.CollectAndCount(g))
+
+	// the series of the deleted hosted cluster are deleted
+	g.ReplaceSeries([]Sample{{LabelValues: []string{"hosted-b", "workers"}, Value: 4}})
+	require.Equal(t, 1, testutil.CollectAndCount(g))
+	require.Equal(t, float64(4), testutil.ToFloat64(g.With("hosted-b", "workers")))
+
+	// series moved by a relabeling are replaced under the id they were moved to
+	a.RelabelClusterID("hosted-b", "hosted-c")
+	g.ReplaceSeries([]Sample{{LabelValues: []string{"hosted-b", "workers"}, Value: 5}})
+	require.Equal(t, 1, testutil.CollectAndCount(g))
+	require.Equal(t, float64(5), testutil.ToFloat64(g.With("hosted-c", "workers")))
+}
+
+func TestGauges_SetSnapshotDuringRelabel(t *testing.T) {
+	const relabelings = 20
+	a := NewMetricsAggregator("id-0")
+	g := a.NewGauges("test_instance_count", "Indicates test instances by instance type", "instance_type")
+	require.NoError(t, a.Register(NewMetricSet("Test", g)))
+
+	started, stop := make(chan struct{}), make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			// the controller keeps passing the id it started with, and the instance type changes every reconcile
+			g.SetSnapshot("id-0", []Sample{{LabelValues: []string{fmt.Sprintf("m5.%dxlarge", i%2)}, Value: 1}})
+			if i == 0 {
+				close(started)
+			}
+			select {
+			case <-stop:
+				return
+			default:
+			}
+		}
+	}()
+	<-started
+	for i := 1; i <= relabelings; i++ {
+		a.RelabelClusterID(fmt.Sprintf("id-%d", i-1), fmt.Sprintf("id-%d", i))
+	}
+	close(stop)
+	wg.Wait()
+
+	// the last snapshot replaced every series, of the old ids too
+	series := clusterSeries(g.vec.MetricVec, fmt.Sprintf("id-%d", relabelings))
+	require.Len(t, series, 1)
+	require.Equal(t, 1, testutil.CollectAndCount(g))
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/common/expfmt"
+)
+
+// StandbyHandler serves active once elected is closed, and standby until then. The replicas waiting for the
+// leader Lease run no controllers, so their series are incomplete and would duplicate the series of the leader.
+func StandbyHandler(elected <-chan struct{}, active, standby http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-elected:
+			active.ServeHTTP(w, r)
+		default:
+			standby.ServeHTTP(w, r)
+		}
+	})
+}
+
+// EmptyMetricsHandler serves no series in the text format, so scrapes of a standby replica succeed
+func EmptyMetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", string(expfmt.FmtText))
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// EmptySnapshotHandler serves a snapshot without series
+func EmptySnapshotHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("[]\n"))
+	})
+}
+
+// LeaderChecker returns a readyz check failing until elected is closed, so only the replica owning the series is
+// ready
+func LeaderChecker(elected <-chan struct{}) func(*http.Request) error {
+	return func(_ *http.Request) error {
+		select {
+		case <-elected:
+			return nil
+		default:
+			return errors.New("waiting for the leader Lease")
+		}
+	}
+}
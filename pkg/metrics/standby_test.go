@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStandbyHandler(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_gauge", Help: "Indicates a test gauge"})
+	registry.MustRegister(gauge)
+	elected := make(chan struct{})
+	handler := StandbyHandler(elected, NewHandler(registry, registry), EmptyMetricsHandler())
+	checker := LeaderChecker(elected)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Empty(t, recorder.Body.String(), "a standby replica serves no series")
+	require.EqualError(t, checker(nil), "waiting for the leader Lease")
+
+	close(elected)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), "test_gauge 0")
+	require.NoError(t, checker(nil))
+}
+
+func TestEmptySnapshotHandler(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	EmptySnapshotHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, SnapshotPath, nil))
+	require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	require.JSONEq(t, "[]", recorder.Body.String())
+}
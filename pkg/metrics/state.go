@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// SnapshotPath is the path the current series of the aggregator are served on as JSON
+const SnapshotPath = "/snapshot"
+
+// SeriesState is the current value of a series. Histograms are reported as their _count and _sum series.
+type SeriesState struct {
+	Metric string            `json:"metric"`
+	Labels map[string]string `json:"labels"`
+	Value  float64           `json:"value"`
+	// LastUpdated is when a setter last updated the series, if it is known
+	LastUpdated *time.Time `json:"lastUpdated,omitempty"`
+}
+
+// TrackSeriesUpdates records when every series of the aggregator was last updated, for the snapshot. Metrics with
+// a ttl record their updates anyway. It must be called after ExpireSeries and before the aggregator is used.
+func (a *AdoptionMetricsAggregator) TrackSeriesUpdates() {
+	if a.expiries == nil {
+		a.expiries = make(map[*prometheus.MetricVec]*seriesExpiry)
+	}
+	for _, c := range a.collectors() {
+		var vec *prometheus.MetricVec
+		switch v := c.(type) {
+		case *prometheus.GaugeVec:
+			vec = v.MetricVec
+		case *prometheus.CounterVec:
+			vec = v.MetricVec
+		case *prometheus.HistogramVec:
+			vec = v.MetricVec
+		default:
+			continue
+		}
+		if _, ok := a.expiries[vec]; !ok {
+			a.expiries[vec] = &seriesExpiry{updated: make(map[uint64]*seriesUpdate)}
+		}
+	}
+}
+
+// Snapshot returns the current series of the aggregator sorted by metric, as they are collected for /metrics
+func (a *AdoptionMetricsAggregator) Snapshot() []SeriesState {
+	var states []SeriesState
+	for _, c := range a.GetMetrics() {
+		var expiry *seriesExpiry
+		if locked, ok := c.(*lockedCollector); ok {
+			expiry = locked.expiry
+		}
+		ch := make(chan prometheus.Metric)
+		go func() {
+			c.Collect(ch)
+			close(ch)
+		}()
+		for m := range ch {
+			entry, ok := parseDesc(m.Desc())
+			if !ok {
+				continue
+			}
+			pb := &dto.Metric{}
+			if err := m.Write(pb); err != nil {
+				continue
+			}
+			labels := make(map[string]string, len(pb.GetLabel()))
+			for _, l := range pb.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			var lastUpdated *time.Time
+			if expiry != nil {
+				lvs := make([]string, len(entry.Labels))
+				for i, name := range entry.Labels {
+					lvs[i] = labels[name]
+				}
+				if at, ok := expiry.lastUpdate(lvs); ok {
+					lastUpdated = &at
+				}
+			}
+			add := func(name string, value float64) {
+				states = append(states, SeriesState{Metric: name, Labels: labels, Value: value, LastUpdated: lastUpdated})
+			}
+			switch {
+			case pb.Gauge != nil:
+				add(entry.Name, pb.GetGauge().GetValue())
+			case pb.Counter != nil:
+				add(entry.Name, pb.GetCounter().GetValue())
+			case pb.Untyped != nil:
+				add(entry.Name, pb.GetUntyped().GetValue())
+			case pb.Histogram != nil:
+				add(entry.Name+"_count", float64(pb.GetHistogram().GetSampleCount()))
+				add(entry.Name+"_sum", pb.GetHistogram().GetSampleSum())
+			case pb.Summary != nil:
+				add(entry.Name+"_count", float64(pb.GetSummary().GetSampleCount()))
+				add(entry.Name+"_sum", pb.GetSummary().GetSampleSum())
+			}
+		}
+	}
+	sort.SliceStable(states, func(i, j int) bool { return states[i].Metric < states[j].Metric })
+	return states
+}
+
+// NewSnapshotHandler serves the snapshot of the aggregator as JSON, for support tooling and must-gather scripts
+// which cannot parse the Prometheus formats
+func (a *AdoptionMetricsAggregator) NewSnapshotHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(a.Snapshot()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdoptionMetricsAggregator_Snapshot(t *testing.T) {
+	updated := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return updated }
+	defer func() { now = time.Now }()
+
+	a := NewMetricsAggregator("cluster-id")
+	gauges := a.NewGauges("test_instance_count", "Indicates test instances by instance type", "instance_type")
+	histograms := a.NewHistograms("test_duration_seconds", "Indicates a test duration", []float64{1})
+	require.NoError(t, a.Register(NewMetricSet("Test", gauges, histograms)))
+	a.TrackSeriesUpdates()
+	gauges.With("cluster-id", "m5.xlarge").Set(3)
+	histograms.With("cluster-id").Observe(2)
+
+	var states []SeriesState
+	for _, s := range a.Snapshot() {
+		if s.Metric == "test_instance_count" || s.Metric == "test_duration_seconds_count" || s.Metric == "test_duration_seconds_sum" {
+			states = append(states, s)
+		}
+	}
+	require.Equal(t, []SeriesState{
+		{Metric: "test_duration_seconds_count", Labels: map[string]string{"_id": "cluster-id", "name": "osd_exporter"}, Value: 1, LastUpdated: &updated},
+		{Metric: "test_duration_seconds_sum", Labels: map[string]string{"_id": "cluster-id", "name": "osd_exporter"}, Value: 2, LastUpdated: &updated},
+		{Metric: "test_instance_count", Labels: map[string]string{"_id": "cluster-id", "instance_type": "m5.xlarge", "name": "osd_exporter"}, Value: 3, LastUpdated: &updated},
+	}, states)
+
+	// the last updates follow the series to the new id
+	a.RelabelClusterID("cluster-id", "new-id")
+	recorder := httptest.NewRecorder()
+	a.NewSnapshotHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, SnapshotPath, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	var served []SeriesState
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &served))
+	found := false
+	for _, s := range served {
+		if s.Metric == "test_instance_count" {
+			found = true
+			require.Equal(t, "new-id", s.Labels["_id"])
+			require.NotNil(t, s.LastUpdated)
+			require.True(t, updated.Equal(*s.LastUpdated))
+		}
+	}
+	require.True(t, found)
+}
@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	metricsPackage = "github.com/openshift/osd-metrics-exporter/pkg/metrics."
+	// TraceLoggerName is the name of the logger the updates of the traced metrics are logged with
+	TraceLoggerName = "metrics_trace"
+)
+
+var traceLog = logf.Log.WithName(TraceLoggerName)
+
+// metricTracer logs every update of a metric, to debug incorrect values reported from production clusters
+type metricTracer struct {
+	name string
+	log  logr.Logger
+}
+
+// TraceMetrics logs every update of the named metrics with the caller and the old and new values.
+// It must be called before the aggregator is used.
+func (a *AdoptionMetricsAggregator) TraceMetrics(names ...string) error {
+	return a.traceMetrics(traceLog, names...)
+}
+
+func (a *AdoptionMetricsAggregator) traceMetrics(log logr.Logger, names ...string) error {
+	vecs := a.gaugeVecsByName()
+	for _, name := range names {
+		vec, ok := vecs[name]
+		if !ok {
+			return fmt.Errorf("unknown metric %q", name)
+		}
+		if a.tracers == nil {
+			a.tracers = make(map[*prometheus.MetricVec]*metricTracer)
+		}
+		t := &metricTracer{name: name, log: log}
+		a.tracers[vec.MetricVec] = t
+		// the identity provider gauges are created once and updated whenever an OAuth config changes
+		if vec.MetricVec == a.identityProviders.MetricVec {
+			for providerType, g := range a.providerGauges {
+				a.providerGauges[providerType] = t.wrap(g)
+			}
+		}
+	}
+	return nil
+}
+
+// TraceAllMetrics logs every update of all gauges, e.g. to validate the exporter on a new OpenShift version
+// without exporting anything. It must be called before the aggregator is used.
+func (a *AdoptionMetricsAggregator) TraceAllMetrics() {
+	a.traceAllMetrics(traceLog)
+}
+
+// DebugAllMetrics logs every update of all gauges at debug level, so they are only logged when the TraceLoggerName
+// logger is enabled at debug level. It must be called before the aggregator is used.
+func (a *AdoptionMetricsAggregator) DebugAllMetrics() {
+	a.traceAllMetrics(traceLog.V(1))
+}
+
+func (a *AdoptionMetricsAggregator) traceAllMetrics(log logr.Logger) {
+	names := make([]string, 0)
+	for name, vec := range a.gaugeVecsByName() {
+		// the metrics traced with TraceMetrics keep their tracer
+		if _, ok := a.tracers[vec.MetricVec]; !ok {
+			names = append(names, name)
+		}
+	}
+	// the names are those of the aggregator, so none is unknown
+	_ = a.traceMetrics(log, names...)
+}
+
+// ObserveUpdates calls observe after every set of a gauge series with the name of its metric and its label values, e.g.
+// so controller tests can assert on the updates of a reconcile. It must be called before the aggregator is used.
+func (a *AdoptionMetricsAggregator) ObserveUpdates(observe func(metric string, labelValues []string, value float64)) {
+	a.observe = observe
+	// the identity provider gauges are created with the aggregator
+	for providerType, g := range a.providerGauges {
+		a.providerGauges[providerType] = &observedGauge{Gauge: g, metric: "identity_provider", labelValues: []string{string(providerType)}, observe: observe}
+	}
+}
+
+func (t *metricTracer) wrap(g prometheus.Gauge) prometheus.Gauge {
+	return &tracedGauge{Gauge: g, tracer: t}
+}
+
+// oldValue returns the labels and the value of the series before it is set
+func (t *metricTracer) oldValue(g prometheus.Gauge) (string, float64) {
+	pb := &dto.Metric{}
+	_ = g.Write(pb)
+	return labelsKey(pb), pb.GetGauge().GetValue()
+}
+
+type tracedGauge struct {
+	prometheus.Gauge
+	tracer *metricTracer
+}
+
+func (g *tracedGauge) Set(value float64) {
+	labels, old := g.tracer.oldValue(g.Gauge)
+	g.Gauge.Set(value)
+	g.tracer.log.Info("metric updated", "metric", g.tracer.name, "labels", labels, "old", old, "new", value, "caller", caller())
+}
+
+type observedGauge struct {
+	prometheus.Gauge
+	metric      string
+	labelValues []string
+	observe     func(metric string, labelValues []string, value float64)
+}
+
+func (g *observedGauge) Set(value float64) {
+	g.Gauge.Set(value)
+	g.observe(g.metric, g.labelValues, value)
+}
+
+// labelsKey formats the labels of a series, sorted by name
+func labelsKey(pb *dto.Metric) string {
+	pairs := make([]string, 0, len(pb.GetLabel()))
+	for _, l := range pb.GetLabel() {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", l.GetName(), l.GetValue()))
+	}
+	sort.Strings(pairs)
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// caller returns the first function outside of this package on the stack, usually the Reconcile method of a controller
+func caller() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, metricsPackage) {
+			return frame.Function
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
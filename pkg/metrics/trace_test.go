@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceMetrics(t *testing.T) {
+	var lines []string
+	previous := traceLog
+	traceLog = funcr.New(func(_, args string) { lines = append(lines, args) }, funcr.Options{})
+	defer func() { traceLog = previous }()
+
+	a := NewMetricsAggregator("cluster-id")
+	g := newBenchmarkGauges(a)
+	a.MustRegister(NewMetricSet("Test", g))
+	require.NoError(t, a.TraceMetrics("test_ca_expiry_timestamp", "detection_match_count"))
+	require.Error(t, a.TraceMetrics("does_not_exist"))
+
+	g.SetSnapshot("cluster-id", []Sample{{LabelValues: []string{"O=Test"}, Value: 2}})
+	g.SetSnapshot("cluster-id", []Sample{{LabelValues: []string{"O=Test"}, Value: 3}})
+	a.SetDetectionMatchCount("cluster-id", "test", 1)
+	// not traced
+	a.SetClusterID("cluster-id")
+
+	require.Len(t, lines, 3)
+	require.Contains(t, lines[0], `"metric"="test_ca_expiry_timestamp"`)
+	require.Contains(t, lines[0], `"old"=0 "new"=2`)
+	// the snapshot keeps the series which are reported again, so the previous value is logged
+	require.Contains(t, lines[1], `"old"=2 "new"=3`)
+	// the caller is the first function outside of the metrics package, here the test runner
+	require.Contains(t, lines[1], `"caller"="testing.tRunner"`)
+	require.Contains(t, lines[2], `"metric"="detection_match_count"`)
+	require.Contains(t, lines[2], `"old"=0 "new"=1`)
+}
+
+func TestTraceAllMetrics(t *testing.T) {
+	var lines []string
+	previous := traceLog
+	traceLog = funcr.New(func(_, args string) { lines = append(lines, args) }, funcr.Options{})
+	defer func() { traceLog = previous }()
+
+	a := NewMetricsAggregator("cluster-id")
+	a.TraceAllMetrics()
+	a.SetClusterID("cluster-id")
+	a.SetDetectionMatchCount("cluster-id", "test", 1)
+
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], `"metric"="cluster_id"`)
+	require.Contains(t, lines[1], `"metric"="detection_match_count"`)
+}
+
+func TestDebugAllMetrics(t *testing.T) {
+	var lines []string
+	previous := traceLog
+	defer func() { traceLog = previous }()
+
+	// the updates are only logged when debug logs are enabled
+	for verbosity, want := range []int{0, 1} {
+		lines = nil
+		traceLog = funcr.New(func(_, args string) { lines = append(lines, args) }, funcr.Options{Verbosity: verbosity})
+		a := NewMetricsAggregator("cluster-id")
+		a.DebugAllMetrics()
+		a.SetClusterID("cluster-id")
+		require.Len(t, lines, want, "verbosity %d", verbosity)
+	}
+	require.Contains(t, lines[0], `"metric"="cluster_id"`)
+	require.Contains(t, lines[0], `"labels"="{_id=\"cluster-id\",name=\"osd_exporter\"}"`)
+}
+
+func TestObserveUpdates(t *testing.T) {
+	type update struct {
+		metric      string
+		labelValues []string
+		value       float64
+	}
+	var updates []update
+	a := NewMetricsAggregator("cluster-id")
+	a.ObserveUpdates(func(metric string, labelValues []string, value float64) {
+		updates = append(updates, update{metric: metric, labelValues: labelValues, value: value})
+	})
+	g := newBenchmarkGauges(a)
+	a.MustRegister(NewMetricSet("Test", g))
+
+	g.With("cluster-id", "O=Test").Set(2)
+	g.SetSnapshot("cluster-id", []Sample{{LabelValues: []string{"O=Test"}, Value: 3}})
+	a.SetDetectionMatchCount("cluster-id", "test", 1)
+	require.Equal(t, []update{
+		{metric: "test_ca_expiry_timestamp", labelValues: []string{"cluster-id", "O=Test"}, value: 2},
+		{metric: "test_ca_expiry_timestamp", labelValues: []string{"cluster-id", "O=Test"}, value: 3},
+		{metric: "detection_match_count", labelValues: []string{"cluster-id", "test"}, value: 1},
+	}, updates)
+
+	// the identity provider gauges are created with the aggregator
+	updates = nil
+	a.SetOAuthIDP("oauth", "test", []configv1.IdentityProvider{{IdentityProviderConfig: configv1.IdentityProviderConfig{Type: configv1.IdentityProviderTypeGitHub}}})
+	require.Contains(t, updates, update{metric: "identity_provider", labelValues: []string{string(configv1.IdentityProviderTypeGitHub)}, value: 1})
+}
@@ -0,0 +1,143 @@
+// Package metricsauth authenticates and authorizes the scrapes of the metrics endpoint like kube-rbac-proxy: the
+// bearer token of a request is validated with a TokenReview and its user must be allowed to get the path of the
+// request by a SubjectAccessReview. Serving it in the exporter saves a proxy sidecar on every cluster.
+package metricsauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultCacheTTL is how long the review of a token is reused, so scrapes do not each create two reviews
+const DefaultCacheTTL = time.Minute
+
+var log = logf.Log.WithName("metrics_auth")
+
+// Creator creates the TokenReviews and SubjectAccessReviews, it is implemented by client.Client
+type Creator interface {
+	Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error
+}
+
+// decision is the cached result of the reviews of a token
+type decision struct {
+	status int
+	at     time.Time
+}
+
+// Handler serves the requests of users allowed to get their path, it responds with 401 to requests without a
+// valid bearer token and with 403 to users which are not allowed
+type Handler struct {
+	reviews Creator
+	next    http.Handler
+	ttl     time.Duration
+
+	mutex sync.Mutex
+	// decisions are keyed by the hash of the verb, path and token, so the tokens are not kept in memory
+	decisions map[[sha256.Size]byte]decision
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// NewHandler creates a Handler creating the reviews with reviews, which serves the allowed requests with next
+func NewHandler(reviews Creator, next http.Handler, ttl time.Duration) *Handler {
+	return &Handler{
+		reviews:   reviews,
+		next:      next,
+		ttl:       ttl,
+		decisions: make(map[[sha256.Size]byte]decision),
+		now:       time.Now,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, ok := bearerToken(r)
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	status, err := h.authorize(r.Context(), token, r.URL.Path, strings.ToLower(r.Method))
+	if err != nil {
+		log.Error(err, "unable to review the request", "path", r.URL.Path)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if status != http.StatusOK {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	h.next.ServeHTTP(w, r)
+}
+
+// authorize returns the status of a request of the token, from the cache or by reviewing the token. Failed
+// reviews are not cached.
+func (h *Handler) authorize(ctx context.Context, token, path, verb string) (int, error) {
+	key := sha256.Sum256([]byte(verb + " " + path + "\x00" + token))
+	now := h.now()
+	h.mutex.Lock()
+	d, ok := h.decisions[key]
+	h.mutex.Unlock()
+	if ok && now.Sub(d.at) < h.ttl {
+		return d.status, nil
+	}
+
+	status, err := h.review(ctx, token, path, verb)
+	if err != nil {
+		return 0, err
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for k, d := range h.decisions {
+		if now.Sub(d.at) >= h.ttl {
+			delete(h.decisions, k)
+		}
+	}
+	h.decisions[key] = decision{status: status, at: now}
+	return status, nil
+}
+
+func (h *Handler) review(ctx context.Context, token, path, verb string) (int, error) {
+	tr := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+	if err := h.reviews.Create(ctx, tr); err != nil {
+		return 0, err
+	}
+	if !tr.Status.Authenticated {
+		return http.StatusUnauthorized, nil
+	}
+	user := tr.Status.User
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	sar := &authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+		User:                  user.Username,
+		UID:                   user.UID,
+		Groups:                user.Groups,
+		Extra:                 extra,
+		NonResourceAttributes: &authorizationv1.NonResourceAttributes{Path: path, Verb: verb},
+	}}
+	if err := h.reviews.Create(ctx, sar); err != nil {
+		return 0, err
+	}
+	if !sar.Status.Allowed {
+		return http.StatusForbidden, nil
+	}
+	return http.StatusOK, nil
+}
+
+// bearerToken returns the token of the Authorization header
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
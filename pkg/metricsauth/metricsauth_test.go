@@ -0,0 +1,85 @@
+package metricsauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeReviews authenticates the tokens of users and allows the users of allowed
+type fakeReviews struct {
+	users   map[string]string
+	allowed map[string]bool
+	err     error
+	// reviews counts the created reviews
+	reviews int
+}
+
+func (f *fakeReviews) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	f.reviews++
+	if f.err != nil {
+		return f.err
+	}
+	switch review := obj.(type) {
+	case *authenticationv1.TokenReview:
+		user, ok := f.users[review.Spec.Token]
+		review.Status.Authenticated = ok
+		review.Status.User.Username = user
+	case *authorizationv1.SubjectAccessReview:
+		attributes := review.Spec.NonResourceAttributes
+		review.Status.Allowed = f.allowed[review.Spec.User] && attributes.Path == "/metrics" && attributes.Verb == "get"
+	}
+	return nil
+}
+
+func scrape(h http.Handler, authorization string) int {
+	r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if authorization != "" {
+		r.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w.Code
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	reviews := &fakeReviews{
+		users:   map[string]string{"prometheus-token": "system:serviceaccount:openshift-monitoring:prometheus-k8s", "other-token": "developer"},
+		allowed: map[string]bool{"system:serviceaccount:openshift-monitoring:prometheus-k8s": true},
+	}
+	h := NewHandler(reviews, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), DefaultCacheTTL)
+	current := time.Date(2023, 9, 1, 12, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return current }
+
+	require.Equal(t, http.StatusUnauthorized, scrape(h, ""))
+	require.Equal(t, http.StatusUnauthorized, scrape(h, "Basic dXNlcjpwYXNz"))
+	require.Equal(t, http.StatusUnauthorized, scrape(h, "Bearer invalid-token"))
+	require.Equal(t, http.StatusForbidden, scrape(h, "Bearer other-token"))
+	require.Equal(t, http.StatusOK, scrape(h, "Bearer prometheus-token"))
+
+	// the decisions are cached until the ttl passed
+	reviews.reviews = 0
+	require.Equal(t, http.StatusOK, scrape(h, "Bearer prometheus-token"))
+	require.Equal(t, http.StatusForbidden, scrape(h, "Bearer other-token"))
+	require.Equal(t, 0, reviews.reviews)
+	current = current.Add(DefaultCacheTTL)
+	delete(reviews.allowed, "system:serviceaccount:openshift-monitoring:prometheus-k8s")
+	require.Equal(t, http.StatusForbidden, scrape(h, "Bearer prometheus-token"))
+	require.Equal(t, 2, reviews.reviews)
+
+	// failed reviews are not cached
+	current = current.Add(DefaultCacheTTL)
+	reviews.err = errors.New("unavailable")
+	require.Equal(t, http.StatusInternalServerError, scrape(h, "Bearer prometheus-token"))
+	reviews.err = nil
+	reviews.allowed["system:serviceaccount:openshift-monitoring:prometheus-k8s"] = true
+	require.Equal(t, http.StatusOK, scrape(h, "Bearer prometheus-token"))
+}
@@ -0,0 +1,93 @@
+// Package mustgather reads the objects dumped in a must-gather, so the controllers can compute the metrics a
+// cluster would have reported after it is gone.
+package mustgather
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+// Load reads all objects from the YAML files below dir. Lists are expanded to their items, and files that do
+// not contain Kubernetes objects, like the logs, are skipped.
+func Load(dir string) ([]*unstructured.Unstructured, error) {
+	seen := make(map[string]bool)
+	var objects []*unstructured.Unstructured
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, obj := range parse(data) {
+			// an object can be dumped more than once, e.g. by inspecting both its namespace and its kind
+			key := obj.GroupVersionKind().String() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			objects = append(objects, obj)
+		}
+		return nil
+	})
+	return objects, err
+}
+
+func parse(data []byte) []*unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(data, &obj.Object); err != nil || obj.GetKind() == "" || obj.GetAPIVersion() == "" {
+		return nil
+	}
+	if !obj.IsList() {
+		if obj.GetName() == "" {
+			return nil
+		}
+		return []*unstructured.Unstructured{obj}
+	}
+	list, err := obj.ToList()
+	if err != nil {
+		return nil
+	}
+	itemKind := strings.TrimSuffix(obj.GetKind(), "List")
+	objects := make([]*unstructured.Unstructured, 0, len(list.Items))
+	for i := range list.Items {
+		item := &list.Items[i]
+		// the items of a List of mixed kinds carry their own kind
+		if item.GetKind() == "" {
+			item.SetAPIVersion(obj.GetAPIVersion())
+			item.SetKind(itemKind)
+		}
+		if item.GetName() != "" {
+			objects = append(objects, item)
+		}
+	}
+	return objects
+}
+
+// NewClient returns a client serving the objects of the must-gather in dir. Objects of kinds known to scheme
+// can be read as typed objects.
+func NewClient(scheme *runtime.Scheme, dir string) (client.Client, error) {
+	objects, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	runtimeObjects := make([]runtime.Object, len(objects))
+	for i, obj := range objects {
+		// the resourceVersion of the dump is rejected by the client when the object is added
+		obj.SetResourceVersion("")
+		runtimeObjects[i] = obj
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(runtimeObjects...).Build(), nil
+}
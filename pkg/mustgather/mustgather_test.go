@@ -0,0 +1,108 @@
+package mustgather
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var testFiles = map[string]string{
+	"cluster-scoped-resources/config.openshift.io/clusterversions/version.yaml": `
+apiVersion: config.openshift.io/v1
+kind: ClusterVersion
+metadata:
+  name: version
+  resourceVersion: "12345"
+spec:
+  clusterID: cluster-id
+`,
+	"namespaces/openshift-config/core/configmaps.yaml": `
+apiVersion: v1
+kind: ConfigMapList
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: user-ca-bundle
+    namespace: openshift-config
+- metadata:
+    name: without-kind
+    namespace: openshift-config
+`,
+	// the same ConfigMap dumped again
+	"namespaces/openshift-config/core/configmaps/user-ca-bundle.yaml": `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: user-ca-bundle
+  namespace: openshift-config
+`,
+	"namespaces/openshift-ovn-kubernetes/k8s.ovn.org/egressfirewalls/default.yaml": `
+apiVersion: k8s.ovn.org/v1
+kind: EgressFirewall
+metadata:
+  name: default
+  namespace: openshift-ovn-kubernetes
+`,
+	"namespaces/openshift-config/pods/foo/foo/logs/current.log": "not an object",
+	"timestamp.yaml": "2022-10-01 12:00:00",
+}
+
+func writeTestMustGather(t *testing.T) string {
+	dir := t.TempDir()
+	for name, content := range testFiles {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	objects, err := Load(writeTestMustGather(t))
+	require.NoError(t, err)
+
+	var names []string
+	for _, obj := range objects {
+		names = append(names, obj.GetKind()+"/"+obj.GetName())
+	}
+	require.ElementsMatch(t, []string{
+		"ClusterVersion/version",
+		"ConfigMap/user-ca-bundle",
+		"ConfigMap/without-kind",
+		"EgressFirewall/default",
+	}, names)
+}
+
+func TestNewClient(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, configv1.Install(s))
+	c, err := NewClient(s, writeTestMustGather(t))
+	require.NoError(t, err)
+
+	cv := &configv1.ClusterVersion{}
+	require.NoError(t, c.Get(context.TODO(), types.NamespacedName{Name: "version"}, cv))
+	require.Equal(t, configv1.ClusterID("cluster-id"), cv.Spec.ClusterID)
+
+	configMaps := &corev1.ConfigMapList{}
+	require.NoError(t, c.List(context.TODO(), configMaps, client.InNamespace("openshift-config")))
+	require.Len(t, configMaps.Items, 2)
+
+	// kinds unknown to the scheme are read as unstructured objects
+	egressFirewalls := &unstructured.UnstructuredList{}
+	egressFirewalls.SetGroupVersionKind(schema.GroupVersionKind{Group: "k8s.ovn.org", Version: "v1", Kind: "EgressFirewallList"})
+	require.NoError(t, c.List(context.TODO(), egressFirewalls))
+	require.Len(t, egressFirewalls.Items, 1)
+}
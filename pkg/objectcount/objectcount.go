@@ -0,0 +1,110 @@
+// Package objectcount implements configurable object counters: the number of objects of a kind matching a
+// label selector, optionally restricted to namespaces matching a namespace selector.
+package objectcount
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// Config is the content of the object counters file
+type Config struct {
+	ObjectCounters []Counter `json:"objectCounters"`
+}
+
+// Counter describes which objects to count
+type Counter struct {
+	// APIVersion and Kind of the objects to count, e.g. apps/v1 and Deployment
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Selector is a label selector in its string form, e.g. app.kubernetes.io/managed-by=Helm. All objects are counted when empty.
+	Selector string `json:"selector,omitempty"`
+	// NamespaceSelector restricts counting to objects in namespaces with matching labels
+	NamespaceSelector string `json:"namespaceSelector,omitempty"`
+
+	index             int
+	selector          labels.Selector
+	namespaceSelector labels.Selector
+}
+
+// Load reads and validates an object counters file
+func Load(path string) ([]Counter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses and validates the content of an object counters file
+func Parse(data []byte) ([]Counter, error) {
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for i := range config.ObjectCounters {
+		c := &config.ObjectCounters[i]
+		c.index = i
+		if err := c.compile(); err != nil {
+			return nil, fmt.Errorf("object counter %d: %w", i, err)
+		}
+		key := c.KindLabel() + "/" + c.Selector + "/" + c.NamespaceSelector
+		if seen[key] {
+			return nil, fmt.Errorf("object counter %d: kind %s with selector %q and namespace selector %q is defined more than once",
+				i, c.KindLabel(), c.Selector, c.NamespaceSelector)
+		}
+		seen[key] = true
+	}
+	return config.ObjectCounters, nil
+}
+
+func (c *Counter) compile() error {
+	if c.APIVersion == "" || c.Kind == "" {
+		return fmt.Errorf("apiVersion and kind are required")
+	}
+	if _, err := schema.ParseGroupVersion(c.APIVersion); err != nil {
+		return err
+	}
+	var err error
+	if c.selector, err = labels.Parse(c.Selector); err != nil {
+		return fmt.Errorf("invalid selector: %w", err)
+	}
+	if c.NamespaceSelector != "" {
+		if c.namespaceSelector, err = labels.Parse(c.NamespaceSelector); err != nil {
+			return fmt.Errorf("invalid namespace selector: %w", err)
+		}
+	}
+	return nil
+}
+
+// GroupVersionKind returns the kind of objects the counter counts
+func (c *Counter) GroupVersionKind() schema.GroupVersionKind {
+	gv, _ := schema.ParseGroupVersion(c.APIVersion)
+	return gv.WithKind(c.Kind)
+}
+
+// KindLabel returns the kind label value, the kind qualified with its group, e.g. Deployment.apps
+func (c *Counter) KindLabel() string {
+	return c.GroupVersionKind().GroupKind().String()
+}
+
+// ControllerName returns a name unique to the counter within its file
+func (c *Counter) ControllerName() string {
+	return fmt.Sprintf("objectcount_%s_%d", strings.ToLower(c.Kind), c.index)
+}
+
+// LabelSelector returns the parsed selector
+func (c *Counter) LabelSelector() labels.Selector {
+	return c.selector
+}
+
+// NamespaceLabelSelector returns the parsed namespace selector, or nil if objects in all namespaces are counted
+func (c *Counter) NamespaceLabelSelector() labels.Selector {
+	return c.namespaceSelector
+}
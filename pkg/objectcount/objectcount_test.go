@@ -0,0 +1,51 @@
+package objectcount
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	counters, err := Parse([]byte(`
+objectCounters:
+  - apiVersion: apps/v1
+    kind: Deployment
+    selector: app.kubernetes.io/managed-by=Helm
+    namespaceSelector: "!openshift.io/run-level"
+  - apiVersion: v1
+    kind: Pod
+`))
+	require.NoError(t, err)
+	require.Len(t, counters, 2)
+	require.Equal(t, "Deployment.apps", counters[0].KindLabel())
+	require.Equal(t, "objectcount_deployment_0", counters[0].ControllerName())
+	require.NotNil(t, counters[0].NamespaceLabelSelector())
+	require.Equal(t, "Pod", counters[1].KindLabel())
+	require.True(t, counters[1].LabelSelector().Empty())
+	require.Nil(t, counters[1].NamespaceLabelSelector())
+
+	for name, config := range map[string]string{
+		"missing kind": `
+objectCounters:
+  - {apiVersion: v1}`,
+		"invalid selector": `
+objectCounters:
+  - {apiVersion: v1, kind: Pod, selector: "a=b=c"}`,
+		"invalid namespace selector": `
+objectCounters:
+  - {apiVersion: v1, kind: Pod, namespaceSelector: "a in"}`,
+		"duplicate counter": `
+objectCounters:
+  - {apiVersion: v1, kind: Pod, selector: a=b}
+  - {apiVersion: v1, kind: Pod, selector: a=b}`,
+		"unknown field": `
+objectCounters:
+  - {apiVersion: v1, kind: Pod, fieldSelector: a=b}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(config))
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// Package ocm is the client of the OCM API used by the features of the exporter talking to OCM. It handles
+// the access token, limits the request rate and retries requests failing with transient errors, so OCM is
+// not overloaded by a fleet of exporters retrying at once.
+package ocm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+const (
+	// DefaultURL is the URL of the production OCM API
+	DefaultURL = "https://api.openshift.com"
+
+	defaultQPS        = 1
+	defaultBurst      = 5
+	defaultMaxRetries = 4
+)
+
+// Config configures a Client. Zero values select the defaults.
+type Config struct {
+	// URL of the OCM API
+	URL string
+	// TokenSource returns the access token of requests. It is required.
+	TokenSource TokenSource
+	// QPS and Burst limit the rate of requests, including retries
+	QPS   float32
+	Burst int
+	// MaxRetries is how often a request failing with a transient error is retried
+	MaxRetries int
+	// Backoff between retries. A Retry-After header of the response takes precedence.
+	Backoff    wait.Backoff
+	HTTPClient *http.Client
+}
+
+// Client sends requests to the OCM API
+type Client struct {
+	url         string
+	tokenSource TokenSource
+	limiter     flowcontrol.RateLimiter
+	maxRetries  int
+	backoff     wait.Backoff
+	httpClient  *http.Client
+}
+
+// Error is an error response of the OCM API
+type Error struct {
+	StatusCode int    `json:"-"`
+	ID         string `json:"id"`
+	Code       string `json:"code"`
+	Reason     string `json:"reason"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("OCM API error %d: %s", e.StatusCode, e.Reason)
+	}
+	return fmt.Sprintf("OCM API error %d %s: %s", e.StatusCode, e.Code, e.Reason)
+}
+
+// IsNotFound returns true if err is an OCM API error for a missing object
+func IsNotFound(err error) bool {
+	var ocmErr *Error
+	return errors.As(err, &ocmErr) && ocmErr.StatusCode == http.StatusNotFound
+}
+
+// NewClient creates a Client
+func NewClient(config Config) (*Client, error) {
+	if config.TokenSource == nil {
+		return nil, errors.New("a token source is required for the OCM client")
+	}
+	if config.URL == "" {
+		config.URL = DefaultURL
+	}
+	if config.QPS == 0 {
+		config.QPS = defaultQPS
+	}
+	if config.Burst == 0 {
+		config.Burst = defaultBurst
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultMaxRetries
+	}
+	if config.Backoff.Duration == 0 {
+		config.Backoff = wait.Backoff{Duration: time.Second, Factor: 2, Jitter: 0.1, Cap: 30 * time.Second}
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Client{
+		url:         strings.TrimSuffix(config.URL, "/"),
+		tokenSource: config.TokenSource,
+		limiter:     flowcontrol.NewTokenBucketRateLimiter(config.QPS, config.Burst),
+		maxRetries:  config.MaxRetries,
+		backoff:     config.Backoff,
+		httpClient:  config.HTTPClient,
+	}, nil
+}
+
+// Get reads the object at path, e.g. /api/clusters_mgmt/v1/clusters/<id>, into out
+func (c *Client) Get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	return c.Do(ctx, http.MethodGet, path, query, nil, out)
+}
+
+// Post sends in to path and reads the response into out, which may be nil
+func (c *Client) Post(ctx context.Context, path string, in interface{}, out interface{}) error {
+	return c.Do(ctx, http.MethodPost, path, nil, in, out)
+}
+
+// Do sends a request with in as JSON body, if not nil, and decodes the JSON response into out, if not nil.
+// Requests failing with 429 are retried, and idempotent requests also when failing with a connection error or
+// a 5xx status, as a POST may have been processed before failing.
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, in interface{}, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+	u := c.url + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	idempotent := method != http.MethodPost && method != http.MethodPatch
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := c.send(ctx, method, u, body, out)
+		if err == nil || retryAfter < 0 || attempt >= c.maxRetries {
+			return err
+		}
+		var ocmErr *Error
+		if !idempotent && !(errors.As(err, &ocmErr) && ocmErr.StatusCode == http.StatusTooManyRequests) {
+			return err
+		}
+		delay := backoff.Step()
+		if retryAfter > 0 {
+			delay = retryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// send sends a single request. The returned duration is negative if the request must not be retried, and
+// otherwise the delay requested by the server, or zero to use the backoff.
+func (c *Client) send(ctx context.Context, method, u string, body []byte, out interface{}) (time.Duration, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return -1, err
+	}
+	token, err := c.tokenSource.Token(ctx)
+	if err != nil {
+		return -1, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return -1, err
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if out == nil {
+			return 0, nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+			return -1, fmt.Errorf("unable to parse the OCM API response: %w", err)
+		}
+		return 0, nil
+	}
+
+	ocmErr := &Error{}
+	_ = json.NewDecoder(resp.Body).Decode(ocmErr)
+	ocmErr.StatusCode = resp.StatusCode
+	if ocmErr.Reason == "" {
+		ocmErr.Reason = http.StatusText(resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+		return -1, ocmErr
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, ocmErr
+	}
+	return 0, ocmErr
+}
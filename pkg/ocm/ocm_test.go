@@ -0,0 +1,99 @@
+package ocm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+type testCluster struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	c, err := NewClient(Config{
+		URL:         server.URL,
+		TokenSource: StaticToken("access-token"),
+		QPS:         1000,
+		Backoff:     wait.Backoff{Duration: time.Millisecond, Factor: 2},
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func TestClient_Get(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer access-token", r.Header.Get("Authorization"))
+		require.Equal(t, "/api/clusters_mgmt/v1/clusters", r.URL.Path)
+		require.Equal(t, "external_id = 'cluster-id'", r.URL.Query().Get("search"))
+		_ = json.NewEncoder(w).Encode(testCluster{ID: "1", Name: "foo"})
+	})
+	cluster := &testCluster{}
+	err := c.Get(context.TODO(), "/api/clusters_mgmt/v1/clusters", url.Values{"search": {"external_id = 'cluster-id'"}}, cluster)
+	require.NoError(t, err)
+	require.Equal(t, &testCluster{ID: "1", Name: "foo"}, cluster)
+}
+
+func TestClient_Retry(t *testing.T) {
+	var requests atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(testCluster{ID: "1"})
+	})
+	cluster := &testCluster{}
+	require.NoError(t, c.Get(context.TODO(), "/api/clusters_mgmt/v1/clusters/1", nil, cluster))
+	require.Equal(t, int32(3), requests.Load())
+	require.Equal(t, "1", cluster.ID)
+}
+
+func TestClient_RetryExhausted(t *testing.T) {
+	var requests atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	err := c.Get(context.TODO(), "/api/clusters_mgmt/v1/clusters/1", nil, nil)
+	require.EqualError(t, err, "OCM API error 502: Bad Gateway")
+	require.Equal(t, int32(defaultMaxRetries+1), requests.Load())
+}
+
+func TestClient_PostNotRetried(t *testing.T) {
+	var requests atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	err := c.Post(context.TODO(), "/api/service_logs/v1/cluster_logs", map[string]string{"summary": "foo"}, nil)
+	require.Error(t, err)
+	require.Equal(t, int32(1), requests.Load())
+}
+
+func TestClient_Error(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"kind":"Error","id":"404","code":"CLUSTERS-MGMT-404","reason":"Cluster '1' not found"}`))
+	})
+	err := c.Get(context.TODO(), "/api/clusters_mgmt/v1/clusters/1", nil, nil)
+	require.True(t, IsNotFound(err))
+	require.EqualError(t, err, "OCM API error 404 CLUSTERS-MGMT-404: Cluster '1' not found")
+}
+
+func TestNewClientWithoutToken(t *testing.T) {
+	_, err := NewClient(Config{})
+	require.Error(t, err)
+}
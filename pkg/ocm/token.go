@@ -0,0 +1,117 @@
+package ocm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTokenURL is the token endpoint of the Red Hat SSO the OCM API accepts tokens from
+	DefaultTokenURL = "https://sso.redhat.com/auth/realms/redhat-external/protocol/openid-connect/token"
+	// DefaultClientID is the client the OCM offline tokens are issued for
+	DefaultClientID = "cloud-services"
+
+	// tokenExpiryMargin refreshes access tokens before they expire, so requests in flight do not fail
+	tokenExpiryMargin = time.Minute
+)
+
+// TokenSource returns the access token to send OCM API requests with
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is a TokenSource returning the same access token on every request
+type StaticToken string
+
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// RefreshTokenSource exchanges an offline or refresh token for access tokens, and caches each access token
+// until shortly before it expires
+type RefreshTokenSource struct {
+	tokenURL     string
+	clientID     string
+	refreshToken string
+	httpClient   *http.Client
+	// now is replaced in tests
+	now func() time.Time
+
+	mutex       sync.Mutex
+	accessToken string
+	expiry      time.Time
+}
+
+// NewRefreshTokenSource creates a RefreshTokenSource. The default token URL and client id are used if empty,
+// and http.DefaultClient if httpClient is nil.
+func NewRefreshTokenSource(tokenURL, clientID, refreshToken string, httpClient *http.Client) *RefreshTokenSource {
+	if tokenURL == "" {
+		tokenURL = DefaultTokenURL
+	}
+	if clientID == "" {
+		clientID = DefaultClientID
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &RefreshTokenSource{
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		refreshToken: refreshToken,
+		httpClient:   httpClient,
+		now:          time.Now,
+	}
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+func (s *RefreshTokenSource) Token(ctx context.Context) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.accessToken != "" && s.now().Before(s.expiry) {
+		return s.accessToken, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {s.clientID},
+		"refresh_token": {s.refreshToken},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to refresh the OCM access token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to refresh the OCM access token: %s", resp.Status)
+	}
+	token := &tokenResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(token); err != nil {
+		return "", fmt.Errorf("unable to parse the OCM access token: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("no OCM access token returned by %s", s.tokenURL)
+	}
+	s.accessToken = token.AccessToken
+	s.expiry = s.now().Add(time.Duration(token.ExpiresIn)*time.Second - tokenExpiryMargin)
+	// the SSO rotates refresh tokens, the previous one may no longer be accepted
+	if token.RefreshToken != "" {
+		s.refreshToken = token.RefreshToken
+	}
+	return s.accessToken, nil
+}
@@ -0,0 +1,52 @@
+package ocm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshTokenSource_Token(t *testing.T) {
+	var refreshTokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+		require.Equal(t, DefaultClientID, r.PostForm.Get("client_id"))
+		refreshTokens = append(refreshTokens, r.PostForm.Get("refresh_token"))
+		_, _ = w.Write([]byte(`{"access_token":"access-token","refresh_token":"rotated","expires_in":900}`))
+	}))
+	defer server.Close()
+
+	current := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	source := NewRefreshTokenSource(server.URL, "", "offline-token", nil)
+	source.now = func() time.Time { return current }
+
+	token, err := source.Token(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, "access-token", token)
+
+	// cached until shortly before it expires
+	current = current.Add(10 * time.Minute)
+	_, err = source.Token(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, []string{"offline-token"}, refreshTokens)
+
+	current = current.Add(5 * time.Minute)
+	_, err = source.Token(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, []string{"offline-token", "rotated"}, refreshTokens)
+}
+
+func TestRefreshTokenSource_TokenError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	_, err := NewRefreshTokenSource(server.URL, "", "invalid", nil).Token(context.TODO())
+	require.EqualError(t, err, "unable to refresh the OCM access token: 400 Bad Request")
+}
@@ -0,0 +1,201 @@
+package otlp
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// cumulativeTemporality is AGGREGATION_TEMPORALITY_CUMULATIVE, Prometheus counters and histograms only grow
+const cumulativeTemporality = 2
+
+// resource describes the process the metrics are exported by
+type resource struct {
+	attributes map[string]string
+	scope      string
+	// start is the start time of the cumulative metrics
+	start time.Time
+}
+
+// encodeExportRequest encodes the metric families as an ExportMetricsServiceRequest of the OTLP metrics protocol, with
+// one Metric per family and one data point per series. The labels of a series become the attributes of its data
+// point. The field numbers are the ones of opentelemetry/proto/metrics/v1/metrics.proto.
+func encodeExportRequest(families []*dto.MetricFamily, r resource, now time.Time) []byte {
+	var res []byte
+	for _, k := range sortedKeys(r.attributes) {
+		res = appendBytes(res, 1, keyValue(k, r.attributes[k]))
+	}
+	var scope []byte
+	scope = appendString(scope, 1, r.scope)
+
+	var scopeMetrics []byte
+	scopeMetrics = appendBytes(scopeMetrics, 1, scope)
+	for _, family := range families {
+		if metric := encodeMetric(family, uint64(r.start.UnixNano()), uint64(now.UnixNano())); metric != nil {
+			scopeMetrics = appendBytes(scopeMetrics, 2, metric)
+		}
+	}
+
+	var resourceMetrics []byte
+	resourceMetrics = appendBytes(resourceMetrics, 1, res)
+	resourceMetrics = appendBytes(resourceMetrics, 2, scopeMetrics)
+	return appendBytes(nil, 1, resourceMetrics)
+}
+
+// encodeMetric encodes a Metric, or returns nil for families of an unsupported type
+func encodeMetric(family *dto.MetricFamily, start, now uint64) []byte {
+	switch family.GetType() {
+	case dto.MetricType_GAUGE, dto.MetricType_UNTYPED, dto.MetricType_COUNTER, dto.MetricType_HISTOGRAM, dto.MetricType_SUMMARY:
+	default:
+		return nil
+	}
+	var data []byte
+	for _, pb := range family.GetMetric() {
+		timestamp := now
+		if pb.TimestampMs != nil {
+			timestamp = uint64(pb.GetTimestampMs()) * uint64(time.Millisecond)
+		}
+		var point []byte
+		switch family.GetType() {
+		case dto.MetricType_GAUGE:
+			point = numberDataPoint(pb, 0, timestamp, pb.GetGauge().GetValue())
+		case dto.MetricType_UNTYPED:
+			point = numberDataPoint(pb, 0, timestamp, pb.GetUntyped().GetValue())
+		case dto.MetricType_COUNTER:
+			point = numberDataPoint(pb, start, timestamp, pb.GetCounter().GetValue())
+		case dto.MetricType_HISTOGRAM:
+			point = histogramDataPoint(pb, start, timestamp)
+		case dto.MetricType_SUMMARY:
+			point = summaryDataPoint(pb, start, timestamp)
+		}
+		data = appendBytes(data, 1, point)
+	}
+
+	var metric []byte
+	metric = appendString(metric, 1, family.GetName())
+	metric = appendString(metric, 2, family.GetHelp())
+	switch family.GetType() {
+	case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+		metric = appendBytes(metric, 5, data)
+	case dto.MetricType_COUNTER:
+		data = appendVarint(data, 2, cumulativeTemporality)
+		data = appendVarint(data, 3, 1)
+		metric = appendBytes(metric, 7, data)
+	case dto.MetricType_HISTOGRAM:
+		data = appendVarint(data, 2, cumulativeTemporality)
+		metric = appendBytes(metric, 9, data)
+	case dto.MetricType_SUMMARY:
+		metric = appendBytes(metric, 11, data)
+	}
+	return metric
+}
+
+// numberDataPoint encodes a NumberDataPoint, gauges have no start time
+func numberDataPoint(pb *dto.Metric, start, timestamp uint64, value float64) []byte {
+	point := attributes(pb, 7)
+	if start != 0 {
+		point = appendFixed64(point, 2, start)
+	}
+	point = appendFixed64(point, 3, timestamp)
+	return appendDouble(point, 4, value)
+}
+
+// histogramDataPoint encodes a HistogramDataPoint. The buckets of Prometheus are cumulative and end with +Inf while
+// the buckets of OTLP are not, and the +Inf bound is implied.
+func histogramDataPoint(pb *dto.Metric, start, timestamp uint64) []byte {
+	h := pb.GetHistogram()
+	var bounds, counts []byte
+	var previous uint64
+	for _, b := range h.GetBucket() {
+		if math.IsInf(b.GetUpperBound(), 1) {
+			continue
+		}
+		bounds = protowire.AppendFixed64(bounds, math.Float64bits(b.GetUpperBound()))
+		counts = protowire.AppendFixed64(counts, b.GetCumulativeCount()-previous)
+		previous = b.GetCumulativeCount()
+	}
+	counts = protowire.AppendFixed64(counts, h.GetSampleCount()-previous)
+
+	point := attributes(pb, 9)
+	point = appendFixed64(point, 2, start)
+	point = appendFixed64(point, 3, timestamp)
+	point = appendFixed64(point, 4, h.GetSampleCount())
+	point = appendDouble(point, 5, h.GetSampleSum())
+	point = appendBytes(point, 6, counts)
+	if len(bounds) > 0 {
+		point = appendBytes(point, 7, bounds)
+	}
+	return point
+}
+
+// summaryDataPoint encodes a SummaryDataPoint
+func summaryDataPoint(pb *dto.Metric, start, timestamp uint64) []byte {
+	s := pb.GetSummary()
+	point := attributes(pb, 7)
+	point = appendFixed64(point, 2, start)
+	point = appendFixed64(point, 3, timestamp)
+	point = appendFixed64(point, 4, s.GetSampleCount())
+	point = appendDouble(point, 5, s.GetSampleSum())
+	for _, q := range s.GetQuantile() {
+		var quantile []byte
+		quantile = appendDouble(quantile, 1, q.GetQuantile())
+		quantile = appendDouble(quantile, 2, q.GetValue())
+		point = appendBytes(point, 6, quantile)
+	}
+	return point
+}
+
+// attributes encodes the labels of the series as the KeyValue attributes with field number num
+func attributes(pb *dto.Metric, num protowire.Number) []byte {
+	var b []byte
+	for _, l := range pb.GetLabel() {
+		b = appendBytes(b, num, keyValue(l.GetName(), l.GetValue()))
+	}
+	return b
+}
+
+// sortedKeys returns the keys of m in order, so the requests are reproducible
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// keyValue encodes a KeyValue with a string AnyValue
+func keyValue(key, value string) []byte {
+	var anyValue []byte
+	anyValue = appendString(anyValue, 1, value)
+	var kv []byte
+	kv = appendString(kv, 1, key)
+	return appendBytes(kv, 2, anyValue)
+}
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendFixed64(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, v)
+}
+
+func appendDouble(b []byte, num protowire.Number, v float64) []byte {
+	return appendFixed64(b, num, math.Float64bits(v))
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
@@ -0,0 +1,155 @@
+// Package otlp exports the metrics of the exporter over OTLP/gRPC to an OpenTelemetry collector, for clusters feeding
+// an observability stack which does not scrape Prometheus endpoints. The same series as on /metrics are exported,
+// every interval, while /metrics is still served.
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/http2"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DefaultInterval is how often the metrics are exported, like the default scrape interval of the exporter
+	DefaultInterval = time.Minute
+
+	exportPath = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+	// grpcOK is the status of a successful gRPC call
+	grpcOK = "0"
+
+	serviceName = "osd-metrics-exporter"
+)
+
+var log = logf.Log.WithName("otlp_exporter")
+
+// NewClient creates the HTTP/2 client of the collector at endpoint, e.g. https://otel-collector:4317. Endpoints
+// with the http scheme are called without TLS, e.g. a collector in the same pod.
+func NewClient(endpoint string, tlsConfig *tls.Config) (*http.Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "https":
+		return &http.Client{Transport: &http2.Transport{TLSClientConfig: tlsConfig}}, nil
+	case "http":
+		return &http.Client{Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, addr)
+			},
+		}}, nil
+	default:
+		return nil, fmt.Errorf("OTLP endpoint %q is neither http nor https", endpoint)
+	}
+}
+
+// Exporter is a manager Runnable that periodically exports the metrics gathered from gatherer. It only runs on the
+// leader, which is the replica running the controllers, so the series are not exported once per replica.
+type Exporter struct {
+	httpClient *http.Client
+	endpoint   string
+	gatherer   prometheus.Gatherer
+	interval   time.Duration
+	resource   resource
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// NewExporter creates an Exporter exporting the metrics of gatherer to the collector at endpoint every interval, with
+// an httpClient created by NewClient. The metrics are exported with the service.name and the attributes as resource.
+func NewExporter(httpClient *http.Client, endpoint string, gatherer prometheus.Gatherer, interval time.Duration, attributes map[string]string) *Exporter {
+	resourceAttributes := map[string]string{"service.name": serviceName}
+	for k, v := range attributes {
+		resourceAttributes[k] = v
+	}
+	return &Exporter{
+		httpClient: httpClient,
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		gatherer:   gatherer,
+		interval:   interval,
+		resource:   resource{attributes: resourceAttributes, scope: serviceName, start: time.Now()},
+		now:        time.Now,
+	}
+}
+
+// Start implements manager.Runnable. It exports immediately and then on every interval until the context is
+// cancelled. Failed exports are not retried, the next export sends the current values.
+func (e *Exporter) Start(ctx context.Context) error {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		if err := e.export(ctx); err != nil {
+			log.Error(err, "unable to export metrics", "endpoint", e.endpoint)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush exports the metrics once, e.g. a final time when the exporter stops after the controllers
+func (e *Exporter) Flush(ctx context.Context) error {
+	return e.export(ctx)
+}
+
+func (e *Exporter) export(ctx context.Context) error {
+	families, err := e.gatherer.Gather()
+	if err != nil {
+		// a partial gather still has the series of the collectors which succeeded
+		log.Error(err, "unable to gather all metrics")
+	}
+	ctx, cancel := context.WithTimeout(ctx, e.interval)
+	defer cancel()
+	return e.call(ctx, encodeExportRequest(families, e.resource, e.now()))
+}
+
+// call makes the unary gRPC call of MetricsService.Export with the encoded request. The message is framed by a
+// byte for the compression, which is none, and its length.
+func (e *Exporter) call(ctx context.Context, message []byte) error {
+	body := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(body[1:], uint32(len(message)))
+	body = append(body, message...)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint+exportPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// the trailers are only read once the body is consumed
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	status, statusMessage := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		// errors without a response are sent in the headers
+		status, statusMessage = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	if status != grpcOK {
+		return fmt.Errorf("export failed with gRPC status %q: %s", status, statusMessage)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package otlp
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// message is a decoded protobuf message, the values are []byte for length delimited fields and uint64 for fixed64
+// and varint fields
+type message map[protowire.Number][]interface{}
+
+func decode(t *testing.T, b []byte) message {
+	result := make(message)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		var v interface{}
+		switch typ {
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		case protowire.Fixed64Type:
+			v, n = protowire.ConsumeFixed64(b)
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(b)
+		default:
+			t.Fatalf("unexpected wire type %d", typ)
+		}
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		result[num] = append(result[num], v)
+	}
+	return result
+}
+
+func (m message) message(t *testing.T, num protowire.Number, i int) message {
+	require.Greater(t, len(m[num]), i)
+	return decode(t, m[num][i].([]byte))
+}
+
+func (m message) string(num protowire.Number) string {
+	return string(m[num][0].([]byte))
+}
+
+func (m message) double(num protowire.Number) float64 {
+	return math.Float64frombits(m[num][0].(uint64))
+}
+
+// packed decodes a packed repeated fixed64 field
+func (m message) packed(num protowire.Number) []uint64 {
+	b := m[num][0].([]byte)
+	values := make([]uint64, len(b)/8)
+	for i := range values {
+		values[i] = binary.LittleEndian.Uint64(b[i*8:])
+	}
+	return values
+}
+
+func TestExporter_export(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_gauge", Help: "Indicates a test gauge"}, []string{"_id"})
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_total", Help: "Indicates a test counter"})
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_duration_seconds", Help: "Indicates a test histogram", Buckets: []float64{0.5, 1}})
+	registry.MustRegister(gauge, counter, histogram)
+	gauge.WithLabelValues("cluster-id").Set(3)
+	counter.Add(2)
+	histogram.Observe(0.25)
+	histogram.Observe(0.75)
+	histogram.Observe(2)
+
+	var requests []message
+	status := grpcOK
+	server := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, exportPath, r.URL.Path)
+		require.Equal(t, "application/grpc", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Zero(t, body[0], "the message is not compressed")
+		require.Len(t, body[5:], int(binary.BigEndian.Uint32(body[1:5])))
+		requests = append(requests, decode(t, body[5:]))
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set(http.TrailerPrefix+"Grpc-Status", status)
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", "collector unavailable")
+	}), &http2.Server{}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, nil)
+	require.NoError(t, err)
+	e := NewExporter(client, server.URL, registry, DefaultInterval, map[string]string{"k8s.cluster.uid": "cluster-id"})
+	e.resource.start = time.Unix(10, 0)
+	e.now = func() time.Time { return time.Unix(20, 0) }
+	require.NoError(t, e.export(context.TODO()))
+
+	resourceMetrics := requests[0].message(t, 1, 0)
+	resource := resourceMetrics.message(t, 1, 0)
+	require.Len(t, resource[1], 2)
+	require.Equal(t, "k8s.cluster.uid", resource.message(t, 1, 0).string(1))
+	require.Equal(t, "service.name", resource.message(t, 1, 1).string(1))
+	require.Equal(t, serviceName, resource.message(t, 1, 1).message(t, 2, 0).string(1))
+	scopeMetrics := resourceMetrics.message(t, 2, 0)
+	require.Equal(t, serviceName, scopeMetrics.message(t, 1, 0).string(1))
+	require.Len(t, scopeMetrics[2], 3)
+
+	// the families are gathered sorted by name
+	histogramMetric := scopeMetrics.message(t, 2, 0)
+	require.Equal(t, "test_duration_seconds", histogramMetric.string(1))
+	histogramData := histogramMetric.message(t, 9, 0)
+	require.Equal(t, []interface{}{uint64(cumulativeTemporality)}, histogramData[2])
+	point := histogramData.message(t, 1, 0)
+	require.Equal(t, uint64(10*time.Second), point[2][0])
+	require.Equal(t, uint64(20*time.Second), point[3][0])
+	require.Equal(t, uint64(3), point[4][0])
+	require.Equal(t, 3.0, point.double(5))
+	require.Equal(t, []uint64{1, 1, 1}, point.packed(6))
+	require.Equal(t, []uint64{math.Float64bits(0.5), math.Float64bits(1)}, point.packed(7))
+
+	gaugeMetric := scopeMetrics.message(t, 2, 1)
+	require.Equal(t, "test_gauge", gaugeMetric.string(1))
+	require.Equal(t, "Indicates a test gauge", gaugeMetric.string(2))
+	point = gaugeMetric.message(t, 5, 0).message(t, 1, 0)
+	require.Empty(t, point[2], "gauges have no start time")
+	require.Equal(t, 3.0, point.double(4))
+	attribute := point.message(t, 7, 0)
+	require.Equal(t, "_id", attribute.string(1))
+	require.Equal(t, "cluster-id", attribute.message(t, 2, 0).string(1))
+
+	counterMetric := scopeMetrics.message(t, 2, 2)
+	require.Equal(t, "test_total", counterMetric.string(1))
+	sum := counterMetric.message(t, 7, 0)
+	require.Equal(t, []interface{}{uint64(cumulativeTemporality)}, sum[2])
+	require.Equal(t, []interface{}{uint64(1)}, sum[3], "counters are monotonic")
+	require.Equal(t, 2.0, sum.message(t, 1, 0).double(4))
+
+	status = "14"
+	require.EqualError(t, e.export(context.TODO()), `export failed with gRPC status "14": collector unavailable`)
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := NewClient("https://otel-collector:4317", nil)
+	require.NoError(t, err)
+	_, err = NewClient("otel-collector:4317", nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,56 @@
+// Package profiling serves the pprof profiles and the Go runtime metrics of the exporter, to debug its memory
+// growth on busy clusters. They are served on their own address, which must be a loopback address, so profiles
+// are only reachable with a port-forward and never scraped along with the cluster metrics.
+package profiling
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsPath is the path the Go runtime metrics are served on
+const MetricsPath = "/metrics"
+
+// ValidateAddr returns an error unless addr is the address of a loopback interface, e.g. localhost:6060.
+// Addresses without a host, like :6060, listen on every interface and are rejected.
+func ValidateAddr(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid profiling address %q: %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("profiling address %q is not a loopback address", addr)
+	}
+	return nil
+}
+
+// Handlers returns the handlers of the pprof profiles under /debug/pprof/ and of the Go runtime, GC and process
+// metrics on MetricsPath, by path
+func Handlers() (map[string]http.Handler, error) {
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(collectors.NewGoCollector(
+		collectors.WithGoCollections(collectors.GoRuntimeMemStatsCollection | collectors.GoRuntimeMetricsCollection),
+	)); err != nil {
+		return nil, err
+	}
+	if err := registry.Register(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{})); err != nil {
+		return nil, err
+	}
+	return map[string]http.Handler{
+		"/debug/pprof/":        http.HandlerFunc(pprof.Index),
+		"/debug/pprof/cmdline": http.HandlerFunc(pprof.Cmdline),
+		"/debug/pprof/profile": http.HandlerFunc(pprof.Profile),
+		"/debug/pprof/symbol":  http.HandlerFunc(pprof.Symbol),
+		"/debug/pprof/trace":   http.HandlerFunc(pprof.Trace),
+		MetricsPath:            promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+	}, nil
+}
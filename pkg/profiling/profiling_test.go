@@ -0,0 +1,38 @@
+package profiling
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAddr(t *testing.T) {
+	for _, addr := range []string{"localhost:6060", "127.0.0.1:6060", "[::1]:6060"} {
+		require.NoError(t, ValidateAddr(addr), addr)
+	}
+	for _, addr := range []string{":6060", "0.0.0.0:6060", "10.0.0.1:6060", "example.com:6060", "localhost"} {
+		require.Error(t, ValidateAddr(addr), addr)
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	handlers, err := Handlers()
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	for path, handler := range handlers {
+		mux.Handle(path, handler)
+	}
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), "go_gc_duration_seconds")
+	require.Contains(t, recorder.Body.String(), "go_memstats_heap_alloc_bytes")
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/pprof/heap?debug=1", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), "heap profile")
+}
@@ -0,0 +1,145 @@
+package remotewrite
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+	"strconv"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	nameLabel     = "__name__"
+	bucketLabel   = "le"
+	quantileLabel = "quantile"
+
+	// maxLiteral is the longest literal of the snappy encoding, so its length fits in two bytes
+	maxLiteral = 1 << 16
+)
+
+type label struct {
+	name, value string
+}
+
+// series is a time series of the remote write protocol with a single sample
+type series struct {
+	labels      []label
+	value       float64
+	timestampMs int64
+}
+
+// toSeries flattens the metric families into series, the way Prometheus stores them after a scrape. Histograms
+// and summaries become their _bucket or quantile, _sum and _count series. Samples without a timestamp get nowMs.
+func toSeries(families []*dto.MetricFamily, nowMs int64) []series {
+	var result []series
+	for _, family := range families {
+		name := family.GetName()
+		for _, pb := range family.GetMetric() {
+			timestampMs := nowMs
+			if pb.TimestampMs != nil {
+				timestampMs = pb.GetTimestampMs()
+			}
+			add := func(name string, value float64, extra ...label) {
+				labels := make([]label, 0, len(pb.GetLabel())+len(extra)+1)
+				labels = append(labels, label{nameLabel, name})
+				for _, l := range pb.GetLabel() {
+					labels = append(labels, label{l.GetName(), l.GetValue()})
+				}
+				labels = append(labels, extra...)
+				sort.Slice(labels, func(i, j int) bool { return labels[i].name < labels[j].name })
+				result = append(result, series{labels: labels, value: value, timestampMs: timestampMs})
+			}
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				add(name, pb.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add(name, pb.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add(name, pb.GetUntyped().GetValue())
+			case dto.MetricType_HISTOGRAM:
+				h := pb.GetHistogram()
+				hasInf := false
+				for _, b := range h.GetBucket() {
+					hasInf = hasInf || math.IsInf(b.GetUpperBound(), 1)
+					add(name+"_bucket", float64(b.GetCumulativeCount()), label{bucketLabel, formatFloat(b.GetUpperBound())})
+				}
+				if !hasInf {
+					add(name+"_bucket", float64(h.GetSampleCount()), label{bucketLabel, "+Inf"})
+				}
+				add(name+"_sum", h.GetSampleSum())
+				add(name+"_count", float64(h.GetSampleCount()))
+			case dto.MetricType_SUMMARY:
+				s := pb.GetSummary()
+				for _, q := range s.GetQuantile() {
+					add(name, q.GetValue(), label{quantileLabel, formatFloat(q.GetQuantile())})
+				}
+				add(name+"_sum", s.GetSampleSum())
+				add(name+"_count", float64(s.GetSampleCount()))
+			}
+		}
+	}
+	return result
+}
+
+// formatFloat formats bucket bounds and quantiles like the Prometheus text format
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// encodeWriteRequest encodes the series as a prometheus.WriteRequest protobuf:
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label { string name = 1; string value = 2; }
+//	message Sample { double value = 1; int64 timestamp = 2; }
+func encodeWriteRequest(all []series) []byte {
+	var request []byte
+	for _, s := range all {
+		var ts []byte
+		for _, l := range s.labels {
+			var pb []byte
+			pb = protowire.AppendTag(pb, 1, protowire.BytesType)
+			pb = protowire.AppendString(pb, l.name)
+			pb = protowire.AppendTag(pb, 2, protowire.BytesType)
+			pb = protowire.AppendString(pb, l.value)
+			ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, pb)
+		}
+		var sample []byte
+		sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+		sample = protowire.AppendFixed64(sample, math.Float64bits(s.value))
+		sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+		sample = protowire.AppendVarint(sample, uint64(s.timestampMs))
+		ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, sample)
+		request = protowire.AppendTag(request, 1, protowire.BytesType)
+		request = protowire.AppendBytes(request, ts)
+	}
+	return request
+}
+
+// snappyEncode encodes src in the snappy block format required by remote write, as literals only. The request is
+// not compressed, which any snappy decoder accepts, so the exporter does not need a snappy implementation.
+func snappyEncode(src []byte) []byte {
+	dst := binary.AppendUvarint(make([]byte, 0, len(src)+(len(src)/maxLiteral+1)*3+binary.MaxVarintLen64), uint64(len(src)))
+	for len(src) > 0 {
+		n := len(src)
+		if n > maxLiteral {
+			n = maxLiteral
+		}
+		if n <= 60 {
+			dst = append(dst, byte(n-1)<<2)
+		} else {
+			// the tag 61 is followed by the length minus one in two little endian bytes
+			dst = append(dst, 61<<2, byte(n-1), byte((n-1)>>8))
+		}
+		dst = append(dst, src[:n]...)
+		src = src[n:]
+	}
+	return dst
+}
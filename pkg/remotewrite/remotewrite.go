@@ -0,0 +1,96 @@
+// Package remotewrite pushes the metrics of the exporter to a Prometheus remote write endpoint, for restricted
+// clusters where the in-cluster Prometheus cannot federate the exporter. Every push sends the current value of
+// every series, like a scrape would.
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultInterval is how often the metrics are pushed, like the default scrape interval of the exporter
+const DefaultInterval = time.Minute
+
+// maxErrorBody is how much of the response to a failed push is logged
+const maxErrorBody = 256
+
+var log = logf.Log.WithName("remote_write")
+
+// Pusher is a manager Runnable that periodically pushes the metrics gathered from gatherer to a remote write
+// endpoint. It only runs on the leader, which is the replica running the controllers, so the series are not
+// pushed once per replica.
+type Pusher struct {
+	httpClient *http.Client
+	url        string
+	gatherer   prometheus.Gatherer
+	interval   time.Duration
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// NewPusher creates a Pusher pushing the metrics of gatherer to url every interval with httpClient, which must
+// authenticate to the endpoint, e.g. with a client certificate or a bearer token
+func NewPusher(httpClient *http.Client, url string, gatherer prometheus.Gatherer, interval time.Duration) *Pusher {
+	return &Pusher{
+		httpClient: httpClient,
+		url:        url,
+		gatherer:   gatherer,
+		interval:   interval,
+		now:        time.Now,
+	}
+}
+
+// Start implements manager.Runnable. It pushes immediately and then on every interval until the context is
+// cancelled. Failed pushes are not retried, the next push sends the current values.
+func (p *Pusher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		if err := p.push(ctx); err != nil {
+			log.Error(err, "unable to push metrics", "url", p.url)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush pushes the metrics once, e.g. a final time when the exporter stops after the controllers
+func (p *Pusher) Flush(ctx context.Context) error {
+	return p.push(ctx)
+}
+
+func (p *Pusher) push(ctx context.Context) error {
+	families, err := p.gatherer.Gather()
+	if err != nil {
+		// a partial gather still has the series of the collectors which succeeded
+		log.Error(err, "unable to gather all metrics")
+	}
+	body := snappyEncode(encodeWriteRequest(toSeries(families, p.now().UnixMilli())))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(message))
+	}
+	return nil
+}
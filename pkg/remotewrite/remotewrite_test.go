@@ -0,0 +1,134 @@
+package remotewrite
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// snappyDecode decodes the literals written by snappyEncode
+func snappyDecode(t *testing.T, src []byte) []byte {
+	length, n := binary.Uvarint(src)
+	require.Greater(t, n, 0)
+	src = src[n:]
+	dst := make([]byte, 0, length)
+	for len(src) > 0 {
+		tag := src[0]
+		require.Zero(t, tag&0x03, "only literals are written")
+		n := int(tag>>2) + 1
+		src = src[1:]
+		if tag>>2 == 61 {
+			n = int(binary.LittleEndian.Uint16(src)) + 1
+			src = src[2:]
+		}
+		dst = append(dst, src[:n]...)
+		src = src[n:]
+	}
+	require.Len(t, dst, int(length))
+	return dst
+}
+
+// fields returns the values of the length delimited and fixed64 fields of a message, and the varints as uint64
+func fields(t *testing.T, b []byte) map[protowire.Number][]interface{} {
+	result := make(map[protowire.Number][]interface{})
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		var v interface{}
+		switch typ {
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		case protowire.Fixed64Type:
+			var bits uint64
+			bits, n = protowire.ConsumeFixed64(b)
+			v = math.Float64frombits(bits)
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(b)
+		default:
+			t.Fatalf("unexpected wire type %d", typ)
+		}
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		result[num] = append(result[num], v)
+	}
+	return result
+}
+
+// decodeWriteRequest returns the series of a WriteRequest in the text format, with their timestamp
+func decodeWriteRequest(t *testing.T, b []byte) []string {
+	var result []string
+	for _, ts := range fields(t, b)[1] {
+		tsFields := fields(t, ts.([]byte))
+		var name string
+		var labels []string
+		for _, l := range tsFields[1] {
+			lFields := fields(t, l.([]byte))
+			labelName, labelValue := string(lFields[1][0].([]byte)), string(lFields[2][0].([]byte))
+			if labelName == nameLabel {
+				name = labelValue
+				continue
+			}
+			labels = append(labels, labelName+`="`+labelValue+`"`)
+		}
+		require.Len(t, tsFields[2], 1)
+		sample := fields(t, tsFields[2][0].([]byte))
+		result = append(result, name+"{"+strings.Join(labels, ",")+"} "+
+			formatFloat(sample[1][0].(float64))+" "+formatFloat(float64(sample[2][0].(uint64))))
+	}
+	return result
+}
+
+func TestPusher_push(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_gauge"}, []string{"_id"})
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_duration_seconds", Buckets: []float64{0.5}})
+	registry.MustRegister(gauge, histogram)
+	gauge.WithLabelValues("cluster-id").Set(3)
+	histogram.Observe(0.25)
+	histogram.Observe(2)
+
+	var requests [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		require.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests = append(requests, decodeWriteRequest(t, snappyDecode(t, body)))
+		if len(requests) > 1 {
+			http.Error(w, "out of order sample", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	p := NewPusher(server.Client(), server.URL, registry, DefaultInterval)
+	p.now = func() time.Time { return time.UnixMilli(1000) }
+	require.NoError(t, p.push(context.TODO()))
+	require.Equal(t, []string{
+		`test_duration_seconds_bucket{le="0.5"} 1 1000`,
+		`test_duration_seconds_bucket{le="+Inf"} 2 1000`,
+		`test_duration_seconds_sum{} 2.25 1000`,
+		`test_duration_seconds_count{} 2 1000`,
+		`test_gauge{_id="cluster-id"} 3 1000`,
+	}, requests[0])
+
+	require.EqualError(t, p.push(context.TODO()), "unexpected status 400 Bad Request: out of order sample")
+}
+
+func TestSnappyEncode(t *testing.T) {
+	for _, length := range []int{0, 1, 60, 61, maxLiteral, maxLiteral + 1, 3*maxLiteral + 7} {
+		src := []byte(strings.Repeat("a", length))
+		require.Equal(t, src, snappyDecode(t, snappyEncode(src)), length)
+	}
+}
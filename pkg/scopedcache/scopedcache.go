@@ -0,0 +1,127 @@
+// Package scopedcache provides a manager cache which caches most kinds in a fixed set of namespaces, while
+// selected namespaced kinds are cached in all namespaces.
+package scopedcache
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Builder returns a NewCacheFunc like cache.MultiNamespacedCacheBuilder, except that objects of the given
+// kinds are cached in all namespaces. Only the listed kinds are cached cluster wide, so watching them does not
+// require caching every object of the cluster. Selectors further restrict which objects are cached cluster wide.
+func Builder(namespaces []string, selectors cache.SelectorsByObject, clusterWideKinds ...schema.GroupKind) cache.NewCacheFunc {
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		namespaced, err := cache.MultiNamespacedCacheBuilder(namespaces)(config, opts)
+		if err != nil {
+			return nil, err
+		}
+		opts.Namespace = ""
+		opts.SelectorsByObject = selectors
+		clusterWide, err := cache.New(config, opts)
+		if err != nil {
+			return nil, err
+		}
+		s := opts.Scheme
+		if s == nil {
+			s = scheme.Scheme
+		}
+		return newScopedCache(namespaced, clusterWide, s, clusterWideKinds), nil
+	}
+}
+
+type scopedCache struct {
+	namespaced  cache.Cache
+	clusterWide cache.Cache
+	scheme      *runtime.Scheme
+	kinds       map[schema.GroupKind]bool
+}
+
+func newScopedCache(namespaced, clusterWide cache.Cache, scheme *runtime.Scheme, clusterWideKinds []schema.GroupKind) *scopedCache {
+	kinds := make(map[schema.GroupKind]bool, len(clusterWideKinds))
+	for _, gk := range clusterWideKinds {
+		kinds[gk] = true
+	}
+	return &scopedCache{
+		namespaced:  namespaced,
+		clusterWide: clusterWide,
+		scheme:      scheme,
+		kinds:       kinds,
+	}
+}
+
+func (c *scopedCache) cacheForKind(gk schema.GroupKind) cache.Cache {
+	gk.Kind = strings.TrimSuffix(gk.Kind, "List")
+	if c.kinds[gk] {
+		return c.clusterWide
+	}
+	return c.namespaced
+}
+
+func (c *scopedCache) cacheFor(obj runtime.Object) (cache.Cache, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return nil, err
+	}
+	return c.cacheForKind(gvk.GroupKind()), nil
+}
+
+func (c *scopedCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	delegate, err := c.cacheFor(obj)
+	if err != nil {
+		return err
+	}
+	return delegate.Get(ctx, key, obj, opts...)
+}
+
+func (c *scopedCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	delegate, err := c.cacheFor(list)
+	if err != nil {
+		return err
+	}
+	return delegate.List(ctx, list, opts...)
+}
+
+func (c *scopedCache) GetInformer(ctx context.Context, obj client.Object) (cache.Informer, error) {
+	delegate, err := c.cacheFor(obj)
+	if err != nil {
+		return nil, err
+	}
+	return delegate.GetInformer(ctx, obj)
+}
+
+func (c *scopedCache) GetInformerForKind(ctx context.Context, gvk schema.GroupVersionKind) (cache.Informer, error) {
+	return c.cacheForKind(gvk.GroupKind()).GetInformerForKind(ctx, gvk)
+}
+
+func (c *scopedCache) IndexField(ctx context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
+	delegate, err := c.cacheFor(obj)
+	if err != nil {
+		return err
+	}
+	return delegate.IndexField(ctx, obj, field, extractValue)
+}
+
+// Start runs both caches until the context is closed. It blocks.
+func (c *scopedCache) Start(ctx context.Context) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- c.clusterWide.Start(ctx)
+	}()
+	if err := c.namespaced.Start(ctx); err != nil {
+		return err
+	}
+	return <-errs
+}
+
+func (c *scopedCache) WaitForCacheSync(ctx context.Context) bool {
+	return c.namespaced.WaitForCacheSync(ctx) && c.clusterWide.WaitForCacheSync(ctx)
+}
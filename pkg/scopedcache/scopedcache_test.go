@@ -0,0 +1,54 @@
+package scopedcache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// recordingCache records which cache served a request. Methods not used by the test are left unimplemented.
+type recordingCache struct {
+	cache.Cache
+	name   string
+	served *[]string
+}
+
+func (c *recordingCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	*c.served = append(*c.served, c.name)
+	return nil
+}
+
+func (c *recordingCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	*c.served = append(*c.served, c.name)
+	return nil
+}
+
+func TestScopedCache_Routing(t *testing.T) {
+	var served []string
+	egressFirewall := schema.GroupKind{Group: "k8s.ovn.org", Kind: "EgressFirewall"}
+	c := newScopedCache(
+		&recordingCache{name: "namespaced", served: &served},
+		&recordingCache{name: "clusterWide", served: &served},
+		scheme.Scheme,
+		[]schema.GroupKind{egressFirewall, {Kind: "Service"}},
+	)
+
+	egressFirewalls := &unstructured.UnstructuredList{}
+	egressFirewalls.SetGroupVersionKind(schema.GroupVersionKind{Group: "k8s.ovn.org", Version: "v1", Kind: "EgressFirewallList"})
+
+	ctx := context.TODO()
+	require.NoError(t, c.List(ctx, egressFirewalls))
+	require.NoError(t, c.List(ctx, &corev1.ServiceList{}))
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "a", Namespace: "b"}, &corev1.Service{}))
+	require.NoError(t, c.List(ctx, &corev1.ConfigMapList{}))
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "a", Namespace: "b"}, &corev1.ConfigMap{}))
+
+	require.Equal(t, []string{"clusterWide", "clusterWide", "clusterWide", "namespaced", "namespaced"}, served)
+}
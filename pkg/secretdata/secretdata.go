@@ -0,0 +1,72 @@
+// Package secretdata reads the data of Secrets on demand. Controllers deriving metrics from Secrets watch
+// them by their metadata only, so the contents of Secrets are never held in the informer cache.
+package secretdata
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reader gets Secrets from an uncached reader and remembers only a hash of their data
+type Reader struct {
+	reader client.Reader
+	mutex  sync.Mutex
+	hashes map[types.NamespacedName][sha256.Size]byte
+}
+
+// NewReader creates a Reader getting Secrets from reader, which should be the API reader of the manager
+func NewReader(reader client.Reader) *Reader {
+	return &Reader{
+		reader: reader,
+		hashes: make(map[types.NamespacedName][sha256.Size]byte),
+	}
+}
+
+// Get reads the Secret key and returns whether its data changed since it was last read, so callers can skip
+// recomputing metrics when only the metadata of the Secret changed. The Secret must not be retained.
+func (r *Reader) Get(ctx context.Context, key types.NamespacedName) (*corev1.Secret, bool, error) {
+	secret := &corev1.Secret{}
+	if err := r.reader.Get(ctx, key, secret); err != nil {
+		if errors.IsNotFound(err) {
+			r.mutex.Lock()
+			delete(r.hashes, key)
+			r.mutex.Unlock()
+		}
+		return nil, false, err
+	}
+	hash := Hash(secret.Data)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	previous, ok := r.hashes[key]
+	r.hashes[key] = hash
+	return secret, !ok || previous != hash, nil
+}
+
+// Hash returns the SHA-256 of the data of a Secret, independent of the order of its keys
+func Hash(data map[string][]byte) [sha256.Size]byte {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	length := make([]byte, binary.MaxVarintLen64)
+	for _, k := range keys {
+		// length prefixes keep the boundaries between keys and values unambiguous
+		h.Write(length[:binary.PutUvarint(length, uint64(len(k)))])
+		h.Write([]byte(k))
+		h.Write(length[:binary.PutUvarint(length, uint64(len(data[k])))])
+		h.Write(data[k])
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
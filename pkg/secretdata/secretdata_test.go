@@ -0,0 +1,58 @@
+package secretdata
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReader_Get(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: "openshift-config"},
+		Data:       map[string][]byte{"a": []byte("1")},
+	}
+	key := types.NamespacedName{Name: "pull-secret", Namespace: "openshift-config"}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+	reader := NewReader(fakeClient)
+
+	got, changed, err := reader.Get(context.TODO(), key)
+	require.NoError(t, err)
+	require.True(t, changed, "the first read is a change")
+	require.Equal(t, []byte("1"), got.Data["a"])
+
+	// a metadata only change
+	got.Labels = map[string]string{"foo": "bar"}
+	require.NoError(t, fakeClient.Update(context.TODO(), got))
+	_, changed, err = reader.Get(context.TODO(), key)
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	got.Data["a"] = []byte("2")
+	require.NoError(t, fakeClient.Update(context.TODO(), got))
+	_, changed, err = reader.Get(context.TODO(), key)
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	// a recreated secret is a change even if its data is the same
+	require.NoError(t, fakeClient.Delete(context.TODO(), got))
+	_, _, err = reader.Get(context.TODO(), key)
+	require.True(t, errors.IsNotFound(err))
+	got.ResourceVersion = ""
+	require.NoError(t, fakeClient.Create(context.TODO(), got))
+	_, changed, err = reader.Get(context.TODO(), key)
+	require.NoError(t, err)
+	require.True(t, changed)
+}
+
+func TestHash(t *testing.T) {
+	require.Equal(t, Hash(map[string][]byte{"a": []byte("1"), "b": []byte("2")}), Hash(map[string][]byte{"b": []byte("2"), "a": []byte("1")}))
+	require.NotEqual(t, Hash(map[string][]byte{"ab": []byte("c")}), Hash(map[string][]byte{"a": []byte("bc")}))
+	require.NotEqual(t, Hash(map[string][]byte{"a": []byte("1")}), Hash(nil))
+}
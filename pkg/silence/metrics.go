@@ -0,0 +1,32 @@
+package silence
+
+import (
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+)
+
+// Metrics report the active Alertmanager silences which can match platform alerts, so alerts SRE would be paged
+// for are not silenced by the customer for long
+type Metrics struct {
+	metrics.MetricSet
+	count        *metrics.Gauges
+	maxRemaining *metrics.Gauges
+}
+
+// NewMetrics registers the count of these silences and the longest time until one of them expires
+func NewMetrics(a *metrics.AdoptionMetricsAggregator) *Metrics {
+	m := &Metrics{
+		count: a.NewGauges("platform_alert_silence_count", "Indicates the number of active Alertmanager silences which can match platform alerts"),
+		maxRemaining: a.NewGauges("platform_alert_silence_max_remaining_seconds",
+			"Indicates the longest remaining duration of the active silences which can match platform alerts"),
+	}
+	m.MetricSet = metrics.NewMetricSet("PlatformAlertSilence", m.count, m.maxRemaining)
+	a.MustRegister(m)
+	return m
+}
+
+func (m *Metrics) SetPlatformAlertSilences(uuid string, count int, maxRemaining time.Duration) {
+	m.count.With(uuid).Set(float64(count))
+	m.maxRemaining.With(uuid).Set(maxRemaining.Seconds())
+}
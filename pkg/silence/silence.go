@@ -0,0 +1,147 @@
+// Package silence reports the Alertmanager silences which hide platform alerts. Customers can create blanket
+// silences which also hide the alerts SRE is paged for, so the incidents behind them go unnoticed.
+package silence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DefaultAlertmanagerURL is the in-cluster platform Alertmanager
+	DefaultAlertmanagerURL = "https://alertmanager-main.openshift-monitoring.svc:9094"
+	// DefaultPlatformNamespaces matches the namespaces of the platform alerts
+	DefaultPlatformNamespaces = `^(openshift-.*|kube-.*|default)$`
+
+	defaultPollInterval = 5 * time.Minute
+
+	silencesPath   = "/api/v2/silences"
+	activeState    = "active"
+	namespaceLabel = "namespace"
+)
+
+var log = logf.Log.WithName("silence_poller")
+
+// Silence is a silence of the Alertmanager API v2, with the fields used by the exporter
+type Silence struct {
+	ID       string    `json:"id"`
+	Matchers []Matcher `json:"matchers"`
+	EndsAt   time.Time `json:"endsAt"`
+	Status   struct {
+		State string `json:"state"`
+	} `json:"status"`
+}
+
+// Matcher is a label matcher of a silence. IsEqual defaults to true in the API.
+type Matcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual *bool  `json:"isEqual,omitempty"`
+}
+
+// Poller is a manager Runnable that periodically lists the silences of the Alertmanager
+type Poller struct {
+	httpClient         *http.Client
+	url                string
+	platformNamespaces *regexp.Regexp
+	metrics            *Metrics
+	clusterId          string
+	interval           time.Duration
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// NewPoller creates a Poller listing the silences of the Alertmanager at url with httpClient, which must
+// authenticate with a token allowed to read the silences. Silences of alerts in namespaces matching
+// platformNamespaces are reported to m.
+func NewPoller(httpClient *http.Client, url string, platformNamespaces *regexp.Regexp, m *Metrics, clusterId string) *Poller {
+	return &Poller{
+		httpClient:         httpClient,
+		url:                url,
+		platformNamespaces: platformNamespaces,
+		metrics:            m,
+		clusterId:          clusterId,
+		interval:           defaultPollInterval,
+		now:                time.Now,
+	}
+}
+
+// Start implements manager.Runnable. It polls immediately and then on every interval until the context is cancelled.
+func (p *Poller) Start(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		if err := p.poll(ctx); err != nil {
+			// the Alertmanager is optional, the previous values are kept until it can be reached again
+			log.Error(err, "unable to list silences", "url", p.url)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Poller) poll(ctx context.Context) error {
+	silences, err := p.list(ctx)
+	if err != nil {
+		return err
+	}
+	current := p.now()
+	count := 0
+	var maxRemaining time.Duration
+	for _, s := range silences {
+		if s.Status.State != activeState || !p.matchesPlatform(s) {
+			continue
+		}
+		count++
+		if remaining := s.EndsAt.Sub(current); remaining > maxRemaining {
+			maxRemaining = remaining
+		}
+	}
+	p.metrics.SetPlatformAlertSilences(p.clusterId, count, maxRemaining)
+	return nil
+}
+
+func (p *Poller) list(ctx context.Context) ([]Silence, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+silencesPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	var silences []Silence
+	if err := json.NewDecoder(resp.Body).Decode(&silences); err != nil {
+		return nil, err
+	}
+	return silences, nil
+}
+
+// matchesPlatform returns true unless a matcher of the silence restricts it to namespaces which are not
+// platform namespaces. Regex and negative namespace matchers can match platform namespaces, so silences
+// with them are counted.
+func (p *Poller) matchesPlatform(s Silence) bool {
+	for _, m := range s.Matchers {
+		if m.Name != namespaceLabel || m.IsRegex || (m.IsEqual != nil && !*m.IsEqual) {
+			continue
+		}
+		if !p.platformNamespaces.MatchString(m.Value) {
+			return false
+		}
+	}
+	return true
+}
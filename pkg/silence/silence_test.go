@@ -0,0 +1,66 @@
+package silence
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+const testSilences = `[
+  {"id": "blanket", "matchers": [{"name": "severity", "value": "critical", "isRegex": false}],
+   "endsAt": "2022-10-08T12:00:00Z", "status": {"state": "active"}},
+  {"id": "platform", "matchers": [{"name": "namespace", "value": "openshift-monitoring", "isRegex": false, "isEqual": true}],
+   "endsAt": "2022-10-01T14:00:00Z", "status": {"state": "active"}},
+  {"id": "customer", "matchers": [{"name": "namespace", "value": "my-app", "isRegex": false}],
+   "endsAt": "2022-12-01T12:00:00Z", "status": {"state": "active"}},
+  {"id": "not-customer", "matchers": [{"name": "namespace", "value": "my-app", "isRegex": false, "isEqual": false}],
+   "endsAt": "2022-10-01T13:00:00Z", "status": {"state": "active"}},
+  {"id": "expired", "matchers": [{"name": "alertname", "value": ".*", "isRegex": true}],
+   "endsAt": "2022-09-01T12:00:00Z", "status": {"state": "expired"}},
+  {"id": "pending", "matchers": [{"name": "alertname", "value": ".*", "isRegex": true}],
+   "endsAt": "2022-12-01T12:00:00Z", "status": {"state": "pending"}}
+]`
+
+func TestPoller_poll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, silencesPath, r.URL.Path)
+		_, _ = w.Write([]byte(testSilences))
+	}))
+	defer server.Close()
+
+	m := NewMetrics(metrics.NewMetricsAggregator("cluster-id"))
+	p := NewPoller(server.Client(), server.URL, regexp.MustCompile(DefaultPlatformNamespaces), m, "cluster-id")
+	p.now = func() time.Time { return time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC) }
+	require.NoError(t, p.poll(context.TODO()))
+
+	require.NoError(t, testutil.CollectAndCompare(m.count, strings.NewReader(`
+# HELP platform_alert_silence_count Indicates the number of active Alertmanager silences which can match platform alerts
+# TYPE platform_alert_silence_count gauge
+platform_alert_silence_count{_id="cluster-id",name="osd_exporter"} 3
+`)))
+	require.NoError(t, testutil.CollectAndCompare(m.maxRemaining, strings.NewReader(`
+# HELP platform_alert_silence_max_remaining_seconds Indicates the longest remaining duration of the active silences which can match platform alerts
+# TYPE platform_alert_silence_max_remaining_seconds gauge
+platform_alert_silence_max_remaining_seconds{_id="cluster-id",name="osd_exporter"} 604800
+`)))
+}
+
+func TestPoller_pollError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	m := NewMetrics(metrics.NewMetricsAggregator("cluster-id"))
+	p := NewPoller(server.Client(), server.URL, regexp.MustCompile(DefaultPlatformNamespaces), m, "cluster-id")
+	require.EqualError(t, p.poll(context.TODO()), "unexpected status 403 Forbidden")
+	require.Equal(t, 0, testutil.CollectAndCount(m.count))
+}
@@ -0,0 +1,53 @@
+// Package upgrade combines signals recorded by other collectors into the upgrade_ready metric.
+package upgrade
+
+import (
+	"context"
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	defaultEvaluationInterval = time.Minute
+)
+
+var log = logf.Log.WithName("upgrade_readiness")
+
+// ReadinessEvaluator is a manager Runnable that periodically evaluates the upgrade blockers recorded
+// in the aggregator. Collectors only record whether they see a blocker, so a reason which is not
+// collected on a cluster, e.g. because its collector is still gated, never blocks the upgrade.
+type ReadinessEvaluator struct {
+	metricsAggregator *metrics.AdoptionMetricsAggregator
+	clusterId         string
+	interval          time.Duration
+}
+
+func NewReadinessEvaluator(metricsAggregator *metrics.AdoptionMetricsAggregator, clusterId string) *ReadinessEvaluator {
+	return &ReadinessEvaluator{
+		metricsAggregator: metricsAggregator,
+		clusterId:         clusterId,
+		interval:          defaultEvaluationInterval,
+	}
+}
+
+// Start implements manager.Runnable. It evaluates immediately and then on every interval until the context is cancelled.
+func (e *ReadinessEvaluator) Start(ctx context.Context) error {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		e.evaluate()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *ReadinessEvaluator) evaluate() {
+	if reasons := e.metricsAggregator.EvaluateUpgradeReadiness(e.clusterId); len(reasons) > 0 {
+		log.V(1).Info("upgrade blocked", "reasons", reasons)
+	}
+}
@@ -0,0 +1,37 @@
+package upgrade
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+const testClusterId = "cluster-id"
+
+func TestReadinessEvaluator_Evaluate(t *testing.T) {
+	metricsAggregator := metrics.NewMetricsAggregator(testClusterId)
+	evaluator := NewReadinessEvaluator(metricsAggregator, testClusterId)
+
+	evaluator.evaluate()
+	expected := `
+# HELP upgrade_ready Indicates if the cluster is ready to upgrade, with a series per reason blocking the upgrade
+# TYPE upgrade_ready gauge
+upgrade_ready{_id="cluster-id",blocking_reason="",name="osd_exporter"} 1
+`
+	require.NoError(t, testutil.CollectAndCompare(metricsAggregator.GetUpgradeReadyMetric(), strings.NewReader(expected)))
+
+	metricsAggregator.SetUpgradeBlocker(metrics.UpgradeBlockerDegradedOperators, true)
+	metricsAggregator.SetUpgradeBlocker(metrics.UpgradeBlockerAdminAck, true)
+	metricsAggregator.SetUpgradeBlocker(metrics.UpgradeBlockerDeprecatedAPIs, false)
+	evaluator.evaluate()
+	expected = `
+# HELP upgrade_ready Indicates if the cluster is ready to upgrade, with a series per reason blocking the upgrade
+# TYPE upgrade_ready gauge
+upgrade_ready{_id="cluster-id",blocking_reason="AdminAck",name="osd_exporter"} 0
+upgrade_ready{_id="cluster-id",blocking_reason="DegradedOperators",name="osd_exporter"} 0
+`
+	require.NoError(t, testutil.CollectAndCompare(metricsAggregator.GetUpgradeReadyMetric(), strings.NewReader(expected)))
+}
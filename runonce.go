@@ -0,0 +1,57 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/detection"
+	"github.com/openshift/osd-metrics-exporter/pkg/objectcount"
+)
+
+// pushgatewayJob is the job the metrics are pushed to the Pushgateway with
+const pushgatewayJob = "osd-metrics-exporter"
+
+// runOnce reconciles every object of the named controllers, or of all of them if names is empty, once against the
+// cluster and pushes the metrics they report to the Pushgateway at pushgatewayURL, or writes them to stdout in the
+// text format without one. It is the mode of the exporter run as a CronJob. c reads from informers, which are
+// started and synced first, so the objects are read like the controllers of a Deployment read them.
+func runOnce(ctx context.Context, informers cache.Cache, c client.Client, clusterId string, names []string, detections []detection.Detection, objectCounters []objectcount.Counter, extraLabels prometheus.Labels, pushgatewayURL string) error {
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+	go func() {
+		if err := informers.Start(ctx); err != nil {
+			setupLog.Error(err, "unable to start informers")
+		}
+	}()
+	if !informers.WaitForCacheSync(ctx) {
+		return errors.New("the informers did not sync")
+	}
+	registry, err := reconcileOnce(ctx, c, clusterId, names, detections, objectCounters, extraLabels)
+	if err != nil {
+		return err
+	}
+	if pushgatewayURL == "" {
+		return writeMetrics(registry, os.Stdout)
+	}
+	// the metrics of the previous run are replaced, so series which are gone are not pushed forever
+	return push.New(pushgatewayURL, pushgatewayJob).Gatherer(registry).Push()
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// finalFlushTimeout is how long the final flush of all sinks may take when the exporter stops
+const finalFlushTimeout = 10 * time.Second
+
+// flusher pushes the current values of the metrics to a sink once
+type flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// namedFlusher is a flusher with the name of its sink for the logs
+type namedFlusher struct {
+	name string
+	flusher
+}
+
+// pushgatewayFlusher replaces the metrics of the job of the exporter on a Pushgateway
+type pushgatewayFlusher struct {
+	url      string
+	gatherer prometheus.Gatherer
+}
+
+func (f pushgatewayFlusher) Flush(ctx context.Context) error {
+	timeout := finalFlushTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	return push.New(f.url, pushgatewayJob).Client(&http.Client{Timeout: timeout}).Gatherer(f.gatherer).Push()
+}
+
+// finalFlusher is a manager Runnable pushing the final values of the metrics to every sink when the manager stops,
+// if this replica was the leader. The manager stops the runnables with a cache after the controllers finished their
+// last reconciles and the periodic pushes stopped, and releases the Lease only after that, so the flushed values are
+// the final ones and the next leader does not push before the flush finished.
+type finalFlusher struct {
+	cache    cache.Cache
+	elected  <-chan struct{}
+	flushers []namedFlusher
+	// started is set once the manager started the flusher, done is closed once the flush finished
+	started atomic.Bool
+	done    chan struct{}
+}
+
+func newFinalFlusher(cache cache.Cache, elected <-chan struct{}, flushers []namedFlusher) *finalFlusher {
+	return &finalFlusher{cache: cache, elected: elected, flushers: flushers, done: make(chan struct{})}
+}
+
+// GetCache makes the manager stop the flusher with the caches, the last runnables stopped before the Lease is released
+func (f *finalFlusher) GetCache() cache.Cache {
+	return f.cache
+}
+
+func (f *finalFlusher) Start(ctx context.Context) error {
+	f.started.Store(true)
+	defer close(f.done)
+	<-ctx.Done()
+	flushMetrics(f.elected, f.flushers)
+	return nil
+}
+
+// Wait blocks until the flush finished, as a manager which exceeded its graceful shutdown timeout returns before it.
+// It returns right away if the manager failed before starting the flusher.
+func (f *finalFlusher) Wait() {
+	if !f.started.Load() {
+		return
+	}
+	select {
+	case <-f.done:
+	case <-time.After(finalFlushTimeout):
+	}
+}
+
+// flushMetrics pushes the final values of the metrics to every sink, if this replica was the leader. Failed flushes
+// are logged, as the exporter is exiting.
+func flushMetrics(elected <-chan struct{}, flushers []namedFlusher) {
+	select {
+	case <-elected:
+	default:
+		// the leader flushes the series, a standby replica has none
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), finalFlushTimeout)
+	defer cancel()
+	for _, f := range flushers {
+		if err := f.Flush(ctx); err != nil {
+			setupLog.Error(err, "unable to flush metrics", "sink", f.name)
+			continue
+		}
+		setupLog.Info("flushed metrics", "sink", f.name)
+	}
+}
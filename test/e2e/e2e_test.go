@@ -0,0 +1,286 @@
+//go:build e2e
+
+// Package e2e tests the exporter deployed to a real cluster: it changes the resources the exporter reports on and
+// asserts the metrics scraped from the exporter pod. The cluster is the one of --kubeconfig or KUBECONFIG, e.g.
+//
+//	E2E_IMAGE=quay.io/app-sre/osd-metrics-exporter:latest go test -tags e2e ./test/e2e/... -timeout 30m
+//
+// With E2E_IMAGE, the manifests of deploy/ and resources/ are applied with the image first, otherwise the deployed exporter is tested.
+// The tests restore every resource they change.
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	userv1 "github.com/openshift/api/user/v1"
+	"github.com/openshift/osd-metrics-exporter/config"
+	"github.com/openshift/osd-metrics-exporter/controllers/cpms"
+	"github.com/openshift/osd-metrics-exporter/controllers/group"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// metricsPort is the port the exporter serves /metrics on
+	metricsPort = "8383"
+	// fieldOwner owns the fields of the manifests applied by the tests
+	fieldOwner = "osd-metrics-exporter-e2e"
+	// testUser is the user added to the cluster-admins Group
+	testUser = "osd-metrics-exporter-e2e"
+	// timeout is how long a test waits for the scraped metrics, which change once the exporter has reconciled
+	timeout = 2 * time.Minute
+)
+
+// manifestNamespace matches the namespace in the file name of a namespaced manifest
+var manifestNamespace = regexp.MustCompile(`^\d+_` + config.OperatorName + `_([a-z0-9-]+)\.`)
+
+// cluster is the cluster under test
+type cluster struct {
+	client    client.Client
+	clientset kubernetes.Interface
+	clusterId string
+}
+
+func newCluster(t *testing.T) *cluster {
+	cfg, err := ctrl.GetConfig()
+	require.NoError(t, err)
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, configv1.Install(scheme))
+	require.NoError(t, userv1.Install(scheme))
+	require.NoError(t, machinev1beta1.Install(scheme))
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	require.NoError(t, err)
+	clientset, err := kubernetes.NewForConfig(cfg)
+	require.NoError(t, err)
+
+	cv := &configv1.ClusterVersion{}
+	require.NoError(t, c.Get(context.TODO(), types.NamespacedName{Name: "version"}, cv))
+	return &cluster{client: c, clientset: clientset, clusterId: string(cv.Spec.ClusterID)}
+}
+
+// deploy applies the manifests of deploy/ and resources/ with image, and waits for the Deployment to be available
+func (c *cluster) deploy(t *testing.T, image string) {
+	ctx := context.TODO()
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: config.OperatorNamespace}}
+	if err := c.client.Create(ctx, namespace); err != nil && !errors.IsAlreadyExists(err) {
+		require.NoError(t, err)
+	}
+	var files []string
+	for _, pattern := range []string{"deploy/crds/*.yaml", "deploy/*.yaml", "resources/*.yaml"} {
+		matches, err := filepath.Glob(filepath.Join("..", "..", pattern))
+		require.NoError(t, err)
+		files = append(files, matches...)
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		obj := &unstructured.Unstructured{}
+		require.NoError(t, yaml.Unmarshal(content, &obj.Object), file)
+		if match := manifestNamespace.FindStringSubmatch(filepath.Base(file)); match != nil && obj.GetNamespace() == "" {
+			obj.SetNamespace(match[1])
+		}
+		if obj.GetKind() == "Deployment" {
+			containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+			require.NoError(t, err)
+			containers[0].(map[string]interface{})["image"] = image
+			require.NoError(t, unstructured.SetNestedSlice(obj.Object, containers, "spec", "template", "spec", "containers"))
+		}
+		require.NoError(t, c.client.Patch(ctx, obj, client.Apply, client.FieldOwner(fieldOwner), client.ForceOwnership), file)
+	}
+
+	require.Eventually(t, func() bool {
+		deployment := &appsv1.Deployment{}
+		err := c.client.Get(ctx, types.NamespacedName{Namespace: config.OperatorNamespace, Name: config.OperatorName}, deployment)
+		return err == nil && deployment.Status.ObservedGeneration == deployment.Generation &&
+			deployment.Status.UpdatedReplicas == *deployment.Spec.Replicas &&
+			deployment.Status.AvailableReplicas == *deployment.Spec.Replicas
+	}, 5*time.Minute, 5*time.Second, "the Deployment of the exporter did not become available")
+}
+
+// scrape returns the metrics served by the ready exporter pod, through the proxy of the API server
+func (c *cluster) scrape(ctx context.Context) (string, error) {
+	pods := &corev1.PodList{}
+	if err := c.client.List(ctx, pods, client.InNamespace(config.OperatorNamespace), client.MatchingLabels{"name": config.OperatorName}); err != nil {
+		return "", err
+	}
+	for _, pod := range pods.Items {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+				body, err := c.clientset.CoreV1().Pods(pod.Namespace).ProxyGet("http", pod.Name, metricsPort, "/metrics", nil).DoRaw(ctx)
+				return string(body), err
+			}
+		}
+	}
+	return "", fmt.Errorf("no ready pod of the exporter")
+}
+
+// series formats a series of the exporter with the cluster id and the name label, followed by the other labels
+func (c *cluster) series(name string, value int, labels ...string) string {
+	all := append([]string{fmt.Sprintf("_id=%q", c.clusterId), `name="osd_exporter"`}, labels...)
+	return fmt.Sprintf("%s{%s} %d", name, strings.Join(all, ","), value)
+}
+
+// requireMetrics waits until the scraped metrics contain all series
+func (c *cluster) requireMetrics(t *testing.T, series ...string) {
+	var body string
+	var err error
+	ok := assert.Eventually(t, func() bool {
+		if body, err = c.scrape(context.TODO()); err != nil {
+			return false
+		}
+		for _, s := range series {
+			if !strings.Contains(body, s+"\n") {
+				return false
+			}
+		}
+		return true
+	}, timeout, 5*time.Second)
+	require.True(t, ok, "want series %q, last scrape failed with %v:\n%s", series, err, body)
+}
+
+// newTestCluster returns the cluster under test, with the exporter of E2E_IMAGE deployed if it is set
+func newTestCluster(t *testing.T) *cluster {
+	c := newCluster(t)
+	if image := os.Getenv("E2E_IMAGE"); image != "" {
+		c.deploy(t, image)
+	}
+	return c
+}
+
+func TestClusterAdmin(t *testing.T) {
+	c := newTestCluster(t)
+	ctx := context.TODO()
+
+	clusterAdmins := &userv1.Group{}
+	err := c.client.Get(ctx, types.NamespacedName{Name: group.ClusterAdminGroupName}, clusterAdmins)
+	if errors.IsNotFound(err) {
+		clusterAdmins = &userv1.Group{ObjectMeta: metav1.ObjectMeta{Name: group.ClusterAdminGroupName}}
+		require.NoError(t, c.client.Create(ctx, clusterAdmins))
+		t.Cleanup(func() { require.NoError(t, client.IgnoreNotFound(c.client.Delete(context.TODO(), clusterAdmins))) })
+	} else {
+		require.NoError(t, err)
+	}
+	if len(clusterAdmins.Users) > 0 {
+		// the users of the cluster are not removed, so only the granted state is tested
+		c.requireMetrics(t, c.series("cluster_admin_enabled", 1))
+		return
+	}
+	c.requireMetrics(t, c.series("cluster_admin_enabled", 0))
+
+	clusterAdmins.Users = append(clusterAdmins.Users, testUser)
+	require.NoError(t, c.client.Update(ctx, clusterAdmins))
+	removeUser := func() error {
+		current := &userv1.Group{}
+		if err := c.client.Get(context.TODO(), client.ObjectKeyFromObject(clusterAdmins), current); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		users := current.Users[:0]
+		for _, user := range current.Users {
+			if user != testUser {
+				users = append(users, user)
+			}
+		}
+		current.Users = users
+		return c.client.Update(context.TODO(), current)
+	}
+	t.Cleanup(func() { require.NoError(t, removeUser()) })
+	c.requireMetrics(t, c.series("cluster_admin_enabled", 1))
+
+	require.NoError(t, removeUser())
+	c.requireMetrics(t, c.series("cluster_admin_enabled", 0))
+}
+
+func TestControlPlaneMachineSet(t *testing.T) {
+	c := newTestCluster(t)
+	ctx := context.TODO()
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(cpms.ControlPlaneMachineSetKind.GroupVersionKind())
+	err := c.client.Get(ctx, types.NamespacedName{Namespace: utils.MachineAPINamespace, Name: "cluster"}, existing)
+	if err == nil {
+		// the ControlPlaneMachineSet of the cluster is not changed, as it may replace the control plane
+		state, _, _ := unstructured.NestedString(existing.Object, "spec", "state")
+		if state == "" {
+			state = "Inactive"
+		}
+		c.requireMetrics(t, c.series("cpms_state", 1, fmt.Sprintf("state=%q", state)))
+		return
+	}
+	if !errors.IsNotFound(err) {
+		require.NoError(t, err)
+	}
+
+	// an Inactive ControlPlaneMachineSet does not manage the control plane machines, so it is safe to create
+	masters := &machinev1beta1.MachineList{}
+	masterLabels := client.MatchingLabels{
+		"machine.openshift.io/cluster-api-machine-role": "master",
+		"machine.openshift.io/cluster-api-machine-type": "master",
+	}
+	require.NoError(t, c.client.List(ctx, masters, client.InNamespace(utils.MachineAPINamespace), masterLabels))
+	if len(masters.Items) == 0 || masters.Items[0].Spec.ProviderSpec.Value == nil {
+		t.Skip("the cluster has no master Machines to build a ControlPlaneMachineSet from")
+	}
+	var providerSpec map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(masters.Items[0].Spec.ProviderSpec.Value.Raw, &providerSpec))
+	selectorLabels := map[string]interface{}{}
+	for k, v := range masterLabels {
+		selectorLabels[k] = v
+	}
+	selectorLabels["machine.openshift.io/cluster-api-cluster"] = masters.Items[0].Labels["machine.openshift.io/cluster-api-cluster"]
+	controlPlaneMachineSet := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"state":    "Inactive",
+			"replicas": int64(len(masters.Items)),
+			"selector": map[string]interface{}{"matchLabels": selectorLabels},
+			"template": map[string]interface{}{
+				"machineType": "machines_v1beta1_machine_openshift_io",
+				"machines_v1beta1_machine_openshift_io": map[string]interface{}{
+					"metadata": map[string]interface{}{"labels": selectorLabels},
+					"spec": map[string]interface{}{
+						"providerSpec": map[string]interface{}{"value": providerSpec},
+					},
+				},
+			},
+		},
+	}}
+	controlPlaneMachineSet.SetGroupVersionKind(cpms.ControlPlaneMachineSetKind.GroupVersionKind())
+	controlPlaneMachineSet.SetNamespace(utils.MachineAPINamespace)
+	controlPlaneMachineSet.SetName("cluster")
+	require.NoError(t, c.client.Create(ctx, controlPlaneMachineSet))
+	t.Cleanup(func() {
+		require.NoError(t, client.IgnoreNotFound(c.client.Delete(context.TODO(), controlPlaneMachineSet)))
+	})
+	c.requireMetrics(t,
+		c.series("cpms_state", 1, `state="Inactive"`),
+		c.series("cpms_instance_type_mismatch", 0),
+	)
+
+	require.NoError(t, c.client.Delete(ctx, controlPlaneMachineSet))
+	require.Eventually(t, func() bool {
+		body, err := c.scrape(context.TODO())
+		return err == nil && !strings.Contains(body, "cpms_state{")
+	}, timeout, 5*time.Second, "the series of a deleted ControlPlaneMachineSet are deleted")
+}
@@ -0,0 +1,181 @@
+//go:build integration
+
+// Package integration runs the controllers against a real API server started by envtest, with the caches, watches
+// and the served /metrics of the exporter, which the tests of the controllers with the fake client do not cover.
+// The binaries of the API server and etcd are found through KUBEBUILDER_ASSETS, which make integration-test sets
+// with setup-envtest.
+package integration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/osd-metrics-exporter/controllers/cpms"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+const clusterId = "cluster-id"
+
+// timeout is how long a test waits for the served metrics, which change once the cache has seen an event
+const timeout = 30 * time.Second
+
+// startExporter starts an API server with the machine.openshift.io CRDs and a manager running the
+// ControlPlaneMachineSet controller, and returns a client of the API server and the URL of /metrics
+func startExporter(t *testing.T) (client.Client, string) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS is not set")
+	}
+	env := &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("testdata", "crds")},
+		ErrorIfCRDPathMissing: true,
+	}
+	cfg, err := env.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, env.Stop()) })
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, machinev1beta1.Install(scheme))
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:                 scheme,
+		MetricsBindAddress:     "0",
+		HealthProbeBindAddress: "0",
+	})
+	require.NoError(t, err)
+
+	aggregator := metrics.NewMetricsAggregator(clusterId)
+	require.NoError(t, (&cpms.ControlPlaneMachineSetReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Metrics:   cpms.NewMetrics(aggregator),
+		ClusterId: clusterId,
+	}).SetupWithManager(mgr))
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(aggregator.GetMetrics()...)
+	server := httptest.NewServer(metrics.NewHandler(registry, registry))
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- mgr.Start(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	c := mgr.GetClient()
+	require.NoError(t, c.Create(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: utils.MachineAPINamespace}}))
+	return c, server.URL
+}
+
+// scrape returns the metrics served on url in the text format
+func scrape(url string) (string, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	content, err := io.ReadAll(resp.Body)
+	return string(content), err
+}
+
+// requireMetrics waits until the metrics served on url contain all series
+func requireMetrics(t *testing.T, url string, series ...string) {
+	var body string
+	ok := assert.Eventually(t, func() bool {
+		var err error
+		if body, err = scrape(url); err != nil {
+			return false
+		}
+		for _, s := range series {
+			if !strings.Contains(body, s+"\n") {
+				return false
+			}
+		}
+		return true
+	}, timeout, 100*time.Millisecond)
+	require.True(t, ok, "want series %q in:\n%s", series, body)
+}
+
+func newMachine(name, role, instanceType string) *machinev1beta1.Machine {
+	m := &machinev1beta1.Machine{ObjectMeta: metav1.ObjectMeta{
+		Name:      name,
+		Namespace: utils.MachineAPINamespace,
+		Labels:    map[string]string{"machine.openshift.io/cluster-api-machine-role": role},
+	}}
+	m.Spec.ProviderSpec.Value = &runtime.RawExtension{Raw: []byte(fmt.Sprintf(`{"kind":"AWSMachineProviderConfig","instanceType":%q}`, instanceType))}
+	return m
+}
+
+func newControlPlaneMachineSet(state, instanceType string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"state": state,
+			"template": map[string]interface{}{
+				"machines_v1beta1_machine_openshift_io": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"providerSpec": map[string]interface{}{
+							"value": map[string]interface{}{"kind": "AWSMachineProviderConfig", "instanceType": instanceType},
+						},
+					},
+				},
+			},
+		},
+	}}
+	obj.SetGroupVersionKind(cpms.ControlPlaneMachineSetKind.GroupVersionKind())
+	obj.SetNamespace(utils.MachineAPINamespace)
+	obj.SetName("cluster")
+	return obj
+}
+
+func TestControlPlaneMachineSet(t *testing.T) {
+	c, url := startExporter(t)
+	ctx := context.TODO()
+
+	require.NoError(t, c.Create(ctx, newMachine("master-0", "master", "m5.xlarge")))
+	require.NoError(t, c.Create(ctx, newMachine("worker-0", "worker", "m5.large")))
+	controlPlaneMachineSet := newControlPlaneMachineSet("Active", "m5.xlarge")
+	require.NoError(t, c.Create(ctx, controlPlaneMachineSet))
+	requireMetrics(t, url,
+		`cpms_state{_id="cluster-id",name="osd_exporter",state="Active"} 1`,
+		`cpms_instance_type_mismatch{_id="cluster-id",name="osd_exporter"} 0`,
+	)
+
+	// a resize of the control plane, which has not rolled out to the master machine
+	require.NoError(t, unstructured.SetNestedField(controlPlaneMachineSet.Object, "m5.2xlarge",
+		"spec", "template", "machines_v1beta1_machine_openshift_io", "spec", "providerSpec", "value", "instanceType"))
+	require.NoError(t, c.Update(ctx, controlPlaneMachineSet))
+	requireMetrics(t, url, `cpms_instance_type_mismatch{_id="cluster-id",name="osd_exporter"} 1`)
+
+	// the master machine is replaced, which is only seen through the watch of the machines
+	require.NoError(t, c.Create(ctx, newMachine("master-1", "master", "m5.2xlarge")))
+	require.NoError(t, c.Delete(ctx, &machinev1beta1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "master-0", Namespace: utils.MachineAPINamespace}}))
+	requireMetrics(t, url, `cpms_instance_type_mismatch{_id="cluster-id",name="osd_exporter"} 0`)
+
+	require.NoError(t, c.Delete(ctx, controlPlaneMachineSet))
+	require.Eventually(t, func() bool {
+		body, err := scrape(url)
+		return err == nil && !strings.Contains(body, "cpms_state{")
+	}, timeout, 100*time.Millisecond, "the series of a deleted ControlPlaneMachineSet are deleted")
+}